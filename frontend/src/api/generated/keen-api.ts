@@ -28,17 +28,24 @@ import type {
   ConfigObject,
   ConfigStateResponse,
   ConfigUpdateResponse,
+  DnsMatchRequest,
+  DnsMatchResponse,
+  DnsUpstreamTestRequest,
+  DnsUpstreamTestResponse,
   ErrorResponse,
   HealthResponse,
   LifecycleOperationAcceptedResponse,
   ListRefreshRequest,
   ListRefreshResponse,
+  RoutingConntrackRequest,
+  RoutingConntrackResponse,
   RoutingHealthErrorResponse,
   RoutingHealthResponse,
   RoutingTestRequest,
   RoutingTestResponse,
   RuntimeInterfaceInventoryResponse,
-  RuntimeOutboundsResponse
+  RuntimeOutboundsResponse,
+  ServiceStopRequest
 } from './model';
 
 import { apiFetch } from '../client';
@@ -253,7 +260,7 @@ export const usePostServiceStart = <TError = void,
     }
 
 /**
- * Removes keen-pbr routing/firewall runtime state and runs dnsmasq deactivation hooks to load fallback resolver config while keeping the API process running.
+ * Removes keen-pbr routing/firewall runtime state and runs dnsmasq deactivation hooks to load fallback resolver config while keeping the API process running. With `auto_start_after_seconds`, the runtime is started again after that delay unless another lifecycle operation is requested first.
 
  * @summary Stop routing runtime
  */
@@ -262,6 +269,11 @@ export type postServiceStopResponse202 = {
   status: 202
 }
 
+export type postServiceStopResponse400 = {
+  data: ErrorResponse
+  status: 400
+}
+
 export type postServiceStopResponse409 = {
   data: void
   status: 409
@@ -270,7 +282,7 @@ export type postServiceStopResponse409 = {
 export type postServiceStopResponseSuccess = (postServiceStopResponse202) & {
   headers: Headers;
 };
-export type postServiceStopResponseError = (postServiceStopResponse409) & {
+export type postServiceStopResponseError = (postServiceStopResponse400 | postServiceStopResponse409) & {
   headers: Headers;
 };
 
@@ -284,23 +296,24 @@ export const getPostServiceStopUrl = () => {
   return `/api/service/stop`
 }
 
-export const postServiceStop = async ( options?: RequestInit): Promise<postServiceStopResponse> => {
+export const postServiceStop = async (serviceStopRequest?: ServiceStopRequest, options?: RequestInit): Promise<postServiceStopResponse> => {
 
   return apiFetch<postServiceStopResponse>(getPostServiceStopUrl(),
   {
     ...options,
-    method: 'POST'
-
-
+    method: 'POST',
+    headers: { 'Content-Type': 'application/json', ...options?.headers },
+    body: JSON.stringify(
+      serviceStopRequest,)
   }
 );}
 
 
 
 
-export const getPostServiceStopMutationOptions = <TError = void,
-    TContext = unknown>(options?: { mutation?:UseMutationOptions<Awaited<ReturnType<typeof postServiceStop>>, TError,void, TContext>, request?: SecondParameter<typeof apiFetch>}
-): UseMutationOptions<Awaited<ReturnType<typeof postServiceStop>>, TError,void, TContext> => {
+export const getPostServiceStopMutationOptions = <TError = ErrorResponse | void,
+    TContext = unknown>(options?: { mutation?:UseMutationOptions<Awaited<ReturnType<typeof postServiceStop>>, TError,{data: ServiceStopRequest}, TContext>, request?: SecondParameter<typeof apiFetch>}
+): UseMutationOptions<Awaited<ReturnType<typeof postServiceStop>>, TError,{data: ServiceStopRequest}, TContext> => {
 
 const mutationKey = ['postServiceStop'];
 const {mutation: mutationOptions, request: requestOptions} = options ?
@@ -312,10 +325,10 @@ const {mutation: mutationOptions, request: requestOptions} = options ?
 
 
 
-      const mutationFn: MutationFunction<Awaited<ReturnType<typeof postServiceStop>>, void> = () => {
-
+      const mutationFn: MutationFunction<Awaited<ReturnType<typeof postServiceStop>>, {data: ServiceStopRequest}> = (props) => {
+          const {data} = props ?? {};
 
-          return  postServiceStop(requestOptions)
+          return  postServiceStop(data,requestOptions)
         }
 
 
@@ -326,18 +339,18 @@ const {mutation: mutationOptions, request: requestOptions} = options ?
   return  { mutationFn, ...mutationOptions }}
 
     export type PostServiceStopMutationResult = NonNullable<Awaited<ReturnType<typeof postServiceStop>>>
-
-    export type PostServiceStopMutationError = void
+    export type PostServiceStopMutationBody = ServiceStopRequest
+    export type PostServiceStopMutationError = ErrorResponse | void
 
     /**
  * @summary Stop routing runtime
  */
-export const usePostServiceStop = <TError = void,
-    TContext = unknown>(options?: { mutation?:UseMutationOptions<Awaited<ReturnType<typeof postServiceStop>>, TError,void, TContext>, request?: SecondParameter<typeof apiFetch>}
+export const usePostServiceStop = <TError = ErrorResponse | void,
+    TContext = unknown>(options?: { mutation?:UseMutationOptions<Awaited<ReturnType<typeof postServiceStop>>, TError,{data: ServiceStopRequest}, TContext>, request?: SecondParameter<typeof apiFetch>}
  , queryClient?: QueryClient): UseMutationResult<
         Awaited<ReturnType<typeof postServiceStop>>,
         TError,
-        void,
+        {data: ServiceStopRequest},
         TContext
       > => {
       return useMutation(getPostServiceStopMutationOptions(options), queryClient);
@@ -854,6 +867,341 @@ export const usePostConfigSave = <TError = ErrorResponse | void,
       return useMutation(getPostConfigSaveMutationOptions(options), queryClient);
     }
 
+/**
+ * Returns the current configuration with every omitted setting replaced by the default the daemon uses at runtime. Secret-looking values such as tokens, passwords and URL credentials are replaced with `<redacted>`, so the output can be attached to support requests.
+
+ * @summary Get effective config
+ */
+export type getConfigEffectiveResponse200 = {
+  data: ConfigObject
+  status: 200
+}
+
+export type getConfigEffectiveResponseSuccess = (getConfigEffectiveResponse200) & {
+  headers: Headers;
+};
+;
+
+export type getConfigEffectiveResponse = (getConfigEffectiveResponseSuccess)
+
+export const getGetConfigEffectiveUrl = () => {
+
+
+
+
+  return `/api/config/effective`
+}
+
+export const getConfigEffective = async ( options?: RequestInit): Promise<getConfigEffectiveResponse> => {
+
+  return apiFetch<getConfigEffectiveResponse>(getGetConfigEffectiveUrl(),
+  {
+    ...options,
+    method: 'GET'
+
+
+  }
+);}
+
+
+
+
+
+export const getGetConfigEffectiveQueryKey = () => {
+    return [
+    `/api/config/effective`
+    ] as const;
+    }
+
+
+export const getGetConfigEffectiveQueryOptions = <TData = Awaited<ReturnType<typeof getConfigEffective>>, TError = unknown>( options?: { query?:Partial<UseQueryOptions<Awaited<ReturnType<typeof getConfigEffective>>, TError, TData>>, request?: SecondParameter<typeof apiFetch>}
+) => {
+
+const {query: queryOptions, request: requestOptions} = options ?? {};
+
+  const queryKey =  queryOptions?.queryKey ?? getGetConfigEffectiveQueryKey();
+
+
+
+    const queryFn: QueryFunction<Awaited<ReturnType<typeof getConfigEffective>>> = ({ signal }) => getConfigEffective({ signal, ...requestOptions });
+
+
+
+
+
+   return  { queryKey, queryFn, ...queryOptions} as UseQueryOptions<Awaited<ReturnType<typeof getConfigEffective>>, TError, TData> & { queryKey: DataTag<QueryKey, TData, TError> }
+}
+
+export type GetConfigEffectiveQueryResult = NonNullable<Awaited<ReturnType<typeof getConfigEffective>>>
+export type GetConfigEffectiveQueryError = unknown
+
+
+export function useGetConfigEffective<TData = Awaited<ReturnType<typeof getConfigEffective>>, TError = unknown>(
+  options: { query:Partial<UseQueryOptions<Awaited<ReturnType<typeof getConfigEffective>>, TError, TData>> & Pick<
+        DefinedInitialDataOptions<
+          Awaited<ReturnType<typeof getConfigEffective>>,
+          TError,
+          Awaited<ReturnType<typeof getConfigEffective>>
+        > , 'initialData'
+      >, request?: SecondParameter<typeof apiFetch>}
+ , queryClient?: QueryClient
+  ):  DefinedUseQueryResult<TData, TError> & { queryKey: DataTag<QueryKey, TData, TError> }
+export function useGetConfigEffective<TData = Awaited<ReturnType<typeof getConfigEffective>>, TError = unknown>(
+  options?: { query?:Partial<UseQueryOptions<Awaited<ReturnType<typeof getConfigEffective>>, TError, TData>> & Pick<
+        UndefinedInitialDataOptions<
+          Awaited<ReturnType<typeof getConfigEffective>>,
+          TError,
+          Awaited<ReturnType<typeof getConfigEffective>>
+        > , 'initialData'
+      >, request?: SecondParameter<typeof apiFetch>}
+ , queryClient?: QueryClient
+  ):  UseQueryResult<TData, TError> & { queryKey: DataTag<QueryKey, TData, TError> }
+export function useGetConfigEffective<TData = Awaited<ReturnType<typeof getConfigEffective>>, TError = unknown>(
+  options?: { query?:Partial<UseQueryOptions<Awaited<ReturnType<typeof getConfigEffective>>, TError, TData>>, request?: SecondParameter<typeof apiFetch>}
+ , queryClient?: QueryClient
+  ):  UseQueryResult<TData, TError> & { queryKey: DataTag<QueryKey, TData, TError> }
+/**
+ * @summary Get effective config
+ */
+
+export function useGetConfigEffective<TData = Awaited<ReturnType<typeof getConfigEffective>>, TError = unknown>(
+  options?: { query?:Partial<UseQueryOptions<Awaited<ReturnType<typeof getConfigEffective>>, TError, TData>>, request?: SecondParameter<typeof apiFetch>}
+ , queryClient?: QueryClient
+ ):  UseQueryResult<TData, TError> & { queryKey: DataTag<QueryKey, TData, TError> } {
+
+  const queryOptions = getGetConfigEffectiveQueryOptions(options)
+
+  const query = useQuery(queryOptions, queryClient) as  UseQueryResult<TData, TError> & { queryKey: DataTag<QueryKey, TData, TError> };
+
+  return { ...query, queryKey: queryOptions.queryKey };
+}
+
+
+
+
+
+/**
+ * Returns the config file exactly as stored on disk, including comments. Staged drafts are not included.
+
+ * @summary Export config file
+ */
+export type getConfigExportResponse200 = {
+  data: ConfigObject
+  status: 200
+}
+
+export type getConfigExportResponse500 = {
+  data: ErrorResponse
+  status: 500
+}
+
+export type getConfigExportResponseSuccess = (getConfigExportResponse200) & {
+  headers: Headers;
+};
+export type getConfigExportResponseError = (getConfigExportResponse500) & {
+  headers: Headers;
+};
+
+export type getConfigExportResponse = (getConfigExportResponseSuccess | getConfigExportResponseError)
+
+export const getGetConfigExportUrl = () => {
+
+
+
+
+  return `/api/config/export`
+}
+
+export const getConfigExport = async ( options?: RequestInit): Promise<getConfigExportResponse> => {
+
+  return apiFetch<getConfigExportResponse>(getGetConfigExportUrl(),
+  {
+    ...options,
+    method: 'GET'
+
+
+  }
+);}
+
+
+
+
+
+export const getGetConfigExportQueryKey = () => {
+    return [
+    `/api/config/export`
+    ] as const;
+    }
+
+
+export const getGetConfigExportQueryOptions = <TData = Awaited<ReturnType<typeof getConfigExport>>, TError = ErrorResponse>( options?: { query?:Partial<UseQueryOptions<Awaited<ReturnType<typeof getConfigExport>>, TError, TData>>, request?: SecondParameter<typeof apiFetch>}
+) => {
+
+const {query: queryOptions, request: requestOptions} = options ?? {};
+
+  const queryKey =  queryOptions?.queryKey ?? getGetConfigExportQueryKey();
+
+
+
+    const queryFn: QueryFunction<Awaited<ReturnType<typeof getConfigExport>>> = ({ signal }) => getConfigExport({ signal, ...requestOptions });
+
+
+
+
+
+   return  { queryKey, queryFn, ...queryOptions} as UseQueryOptions<Awaited<ReturnType<typeof getConfigExport>>, TError, TData> & { queryKey: DataTag<QueryKey, TData, TError> }
+}
+
+export type GetConfigExportQueryResult = NonNullable<Awaited<ReturnType<typeof getConfigExport>>>
+export type GetConfigExportQueryError = ErrorResponse
+
+
+export function useGetConfigExport<TData = Awaited<ReturnType<typeof getConfigExport>>, TError = ErrorResponse>(
+  options: { query:Partial<UseQueryOptions<Awaited<ReturnType<typeof getConfigExport>>, TError, TData>> & Pick<
+        DefinedInitialDataOptions<
+          Awaited<ReturnType<typeof getConfigExport>>,
+          TError,
+          Awaited<ReturnType<typeof getConfigExport>>
+        > , 'initialData'
+      >, request?: SecondParameter<typeof apiFetch>}
+ , queryClient?: QueryClient
+  ):  DefinedUseQueryResult<TData, TError> & { queryKey: DataTag<QueryKey, TData, TError> }
+export function useGetConfigExport<TData = Awaited<ReturnType<typeof getConfigExport>>, TError = ErrorResponse>(
+  options?: { query?:Partial<UseQueryOptions<Awaited<ReturnType<typeof getConfigExport>>, TError, TData>> & Pick<
+        UndefinedInitialDataOptions<
+          Awaited<ReturnType<typeof getConfigExport>>,
+          TError,
+          Awaited<ReturnType<typeof getConfigExport>>
+        > , 'initialData'
+      >, request?: SecondParameter<typeof apiFetch>}
+ , queryClient?: QueryClient
+  ):  UseQueryResult<TData, TError> & { queryKey: DataTag<QueryKey, TData, TError> }
+export function useGetConfigExport<TData = Awaited<ReturnType<typeof getConfigExport>>, TError = ErrorResponse>(
+  options?: { query?:Partial<UseQueryOptions<Awaited<ReturnType<typeof getConfigExport>>, TError, TData>>, request?: SecondParameter<typeof apiFetch>}
+ , queryClient?: QueryClient
+  ):  UseQueryResult<TData, TError> & { queryKey: DataTag<QueryKey, TData, TError> }
+/**
+ * @summary Export config file
+ */
+
+export function useGetConfigExport<TData = Awaited<ReturnType<typeof getConfigExport>>, TError = ErrorResponse>(
+  options?: { query?:Partial<UseQueryOptions<Awaited<ReturnType<typeof getConfigExport>>, TError, TData>>, request?: SecondParameter<typeof apiFetch>}
+ , queryClient?: QueryClient
+ ):  UseQueryResult<TData, TError> & { queryKey: DataTag<QueryKey, TData, TError> } {
+
+  const queryOptions = getGetConfigExportQueryOptions(options)
+
+  const query = useQuery(queryOptions, queryClient) as  UseQueryResult<TData, TError> & { queryKey: DataTag<QueryKey, TData, TError> };
+
+  return { ...query, queryKey: queryOptions.queryKey };
+}
+
+
+
+
+
+/**
+ * Validates the provided config file and, if it is valid, replaces the config on disk and applies it like `POST /api/config/save`. The body is stored as sent, so comments are kept. The previous file is copied to `<config>.bak` first. An invalid config is rejected without touching the staged draft, the file on disk or the running configuration.
+
+ * @summary Import config file
+ */
+export type postConfigImportResponse202 = {
+  data: LifecycleOperationAcceptedResponse
+  status: 202
+}
+
+export type postConfigImportResponse400 = {
+  data: ErrorResponse
+  status: 400
+}
+
+export type postConfigImportResponse409 = {
+  data: void
+  status: 409
+}
+
+export type postConfigImportResponse500 = {
+  data: ErrorResponse
+  status: 500
+}
+
+export type postConfigImportResponseSuccess = (postConfigImportResponse202) & {
+  headers: Headers;
+};
+export type postConfigImportResponseError = (postConfigImportResponse400 | postConfigImportResponse409 | postConfigImportResponse500) & {
+  headers: Headers;
+};
+
+export type postConfigImportResponse = (postConfigImportResponseSuccess | postConfigImportResponseError)
+
+export const getPostConfigImportUrl = () => {
+
+
+
+
+  return `/api/config/import`
+}
+
+export const postConfigImport = async (configObject: ConfigObject, options?: RequestInit): Promise<postConfigImportResponse> => {
+
+  return apiFetch<postConfigImportResponse>(getPostConfigImportUrl(),
+  {
+    ...options,
+    method: 'POST',
+    headers: { 'Content-Type': 'application/json', ...options?.headers },
+    body: JSON.stringify(
+      configObject,)
+  }
+);}
+
+
+
+
+export const getPostConfigImportMutationOptions = <TError = ErrorResponse | void,
+    TContext = unknown>(options?: { mutation?:UseMutationOptions<Awaited<ReturnType<typeof postConfigImport>>, TError,{data: ConfigObject}, TContext>, request?: SecondParameter<typeof apiFetch>}
+): UseMutationOptions<Awaited<ReturnType<typeof postConfigImport>>, TError,{data: ConfigObject}, TContext> => {
+
+const mutationKey = ['postConfigImport'];
+const {mutation: mutationOptions, request: requestOptions} = options ?
+      options.mutation && 'mutationKey' in options.mutation && options.mutation.mutationKey ?
+      options
+      : {...options, mutation: {...options.mutation, mutationKey}}
+      : {mutation: { mutationKey, }, request: undefined};
+
+
+
+
+      const mutationFn: MutationFunction<Awaited<ReturnType<typeof postConfigImport>>, {data: ConfigObject}> = (props) => {
+          const {data} = props ?? {};
+
+          return  postConfigImport(data,requestOptions)
+        }
+
+
+
+
+
+
+  return  { mutationFn, ...mutationOptions }}
+
+    export type PostConfigImportMutationResult = NonNullable<Awaited<ReturnType<typeof postConfigImport>>>
+    export type PostConfigImportMutationBody = ConfigObject
+    export type PostConfigImportMutationError = ErrorResponse | void
+
+    /**
+ * @summary Import config file
+ */
+export const usePostConfigImport = <TError = ErrorResponse | void,
+    TContext = unknown>(options?: { mutation?:UseMutationOptions<Awaited<ReturnType<typeof postConfigImport>>, TError,{data: ConfigObject}, TContext>, request?: SecondParameter<typeof apiFetch>}
+ , queryClient?: QueryClient): UseMutationResult<
+        Awaited<ReturnType<typeof postConfigImport>>,
+        TError,
+        {data: ConfigObject},
+        TContext
+      > => {
+      return useMutation(getPostConfigImportMutationOptions(options), queryClient);
+    }
+
 /**
  * Resolves the target (if a domain name), scans configured route rules against cached list data to determine the expected outbound, and queries the live kernel firewall sets to determine the actual outbound. Useful for diagnosing routing mismatches without restarting the daemon.
 
@@ -946,6 +1294,103 @@ export const usePostRoutingTest = <TError = ErrorResponse,
       return useMutation(getPostRoutingTestMutationOptions(options), queryClient);
     }
 
+/**
+ * Lists the kernel conntrack entries whose original destination is the given IP address (conntrack -L -d). Each flow carries its connmark and the outbound that mark belongs to, which shows whether established connections still use an outbound after a rule or list change.
+
+ * @summary List conntrack flows to an IP
+ */
+export type postRoutingConntrackResponse200 = {
+  data: RoutingConntrackResponse
+  status: 200
+}
+
+export type postRoutingConntrackResponse400 = {
+  data: ErrorResponse
+  status: 400
+}
+
+export type postRoutingConntrackResponse503 = {
+  data: ErrorResponse
+  status: 503
+}
+
+export type postRoutingConntrackResponseSuccess = (postRoutingConntrackResponse200) & {
+  headers: Headers;
+};
+export type postRoutingConntrackResponseError = (postRoutingConntrackResponse400 | postRoutingConntrackResponse503) & {
+  headers: Headers;
+};
+
+export type postRoutingConntrackResponse = (postRoutingConntrackResponseSuccess | postRoutingConntrackResponseError)
+
+export const getPostRoutingConntrackUrl = () => {
+
+
+
+
+  return `/api/routing/conntrack`
+}
+
+export const postRoutingConntrack = async (routingConntrackRequest: RoutingConntrackRequest, options?: RequestInit): Promise<postRoutingConntrackResponse> => {
+
+  return apiFetch<postRoutingConntrackResponse>(getPostRoutingConntrackUrl(),
+  {
+    ...options,
+    method: 'POST',
+    headers: { 'Content-Type': 'application/json', ...options?.headers },
+    body: JSON.stringify(
+      routingConntrackRequest,)
+  }
+);}
+
+
+
+
+export const getPostRoutingConntrackMutationOptions = <TError = ErrorResponse,
+    TContext = unknown>(options?: { mutation?:UseMutationOptions<Awaited<ReturnType<typeof postRoutingConntrack>>, TError,{data: RoutingConntrackRequest}, TContext>, request?: SecondParameter<typeof apiFetch>}
+): UseMutationOptions<Awaited<ReturnType<typeof postRoutingConntrack>>, TError,{data: RoutingConntrackRequest}, TContext> => {
+
+const mutationKey = ['postRoutingConntrack'];
+const {mutation: mutationOptions, request: requestOptions} = options ?
+      options.mutation && 'mutationKey' in options.mutation && options.mutation.mutationKey ?
+      options
+      : {...options, mutation: {...options.mutation, mutationKey}}
+      : {mutation: { mutationKey, }, request: undefined};
+
+
+
+
+      const mutationFn: MutationFunction<Awaited<ReturnType<typeof postRoutingConntrack>>, {data: RoutingConntrackRequest}> = (props) => {
+          const {data} = props ?? {};
+
+          return  postRoutingConntrack(data,requestOptions)
+        }
+
+
+
+
+
+
+  return  { mutationFn, ...mutationOptions }}
+
+    export type PostRoutingConntrackMutationResult = NonNullable<Awaited<ReturnType<typeof postRoutingConntrack>>>
+    export type PostRoutingConntrackMutationBody = RoutingConntrackRequest
+    export type PostRoutingConntrackMutationError = ErrorResponse
+
+    /**
+ * @summary List conntrack flows to an IP
+ */
+export const usePostRoutingConntrack = <TError = ErrorResponse,
+    TContext = unknown>(options?: { mutation?:UseMutationOptions<Awaited<ReturnType<typeof postRoutingConntrack>>, TError,{data: RoutingConntrackRequest}, TContext>, request?: SecondParameter<typeof apiFetch>}
+ , queryClient?: QueryClient): UseMutationResult<
+        Awaited<ReturnType<typeof postRoutingConntrack>>,
+        TError,
+        {data: RoutingConntrackRequest},
+        TContext
+      > => {
+      return useMutation(getPostRoutingConntrackMutationOptions(options), queryClient);
+    }
+
 /**
  * Verifies the live kernel routing and firewall state against the expected configuration. Checks that the firewall chain exists, all rules are present, route tables are populated, and policy rules are in place.
 
@@ -1514,6 +1959,190 @@ export function useGetDnsTest<TData = Awaited<ReturnType<typeof getDnsTest>>, TE
   return { ...query, queryKey: queryOptions.queryKey };
 }
 
+/**
+ * Sends a single A query to a plain DNS upstream address and reports whether it answered, how long it took and which addresses it returned. Use this to check a server before adding it to `dns.servers`. The query is sent directly from the daemon and does not go through dnsmasq.
+
+ * @summary Test a DNS upstream
+ */
+export type postDnsTestUpstreamResponse200 = {
+  data: DnsUpstreamTestResponse
+  status: 200
+}
+
+export type postDnsTestUpstreamResponse400 = {
+  data: ErrorResponse
+  status: 400
+}
+
+export type postDnsTestUpstreamResponseSuccess = (postDnsTestUpstreamResponse200) & {
+  headers: Headers;
+};
+export type postDnsTestUpstreamResponseError = (postDnsTestUpstreamResponse400) & {
+  headers: Headers;
+};
+
+export type postDnsTestUpstreamResponse = (postDnsTestUpstreamResponseSuccess | postDnsTestUpstreamResponseError)
+
+export const getPostDnsTestUpstreamUrl = () => {
+
+
+
+
+  return `/api/dns/test-upstream`
+}
+
+export const postDnsTestUpstream = async (dnsUpstreamTestRequest: DnsUpstreamTestRequest, options?: RequestInit): Promise<postDnsTestUpstreamResponse> => {
+
+  return apiFetch<postDnsTestUpstreamResponse>(getPostDnsTestUpstreamUrl(),
+  {
+    ...options,
+    method: 'POST',
+    headers: { 'Content-Type': 'application/json', ...options?.headers },
+    body: JSON.stringify(
+      dnsUpstreamTestRequest,)
+  }
+);}
+
+
+
+
+export const getPostDnsTestUpstreamMutationOptions = <TError = ErrorResponse,
+    TContext = unknown>(options?: { mutation?:UseMutationOptions<Awaited<ReturnType<typeof postDnsTestUpstream>>, TError,{data: DnsUpstreamTestRequest}, TContext>, request?: SecondParameter<typeof apiFetch>}
+): UseMutationOptions<Awaited<ReturnType<typeof postDnsTestUpstream>>, TError,{data: DnsUpstreamTestRequest}, TContext> => {
+
+const mutationKey = ['postDnsTestUpstream'];
+const {mutation: mutationOptions, request: requestOptions} = options ?
+      options.mutation && 'mutationKey' in options.mutation && options.mutation.mutationKey ?
+      options
+      : {...options, mutation: {...options.mutation, mutationKey}}
+      : {mutation: { mutationKey, }, request: undefined};
+
+
+
+
+      const mutationFn: MutationFunction<Awaited<ReturnType<typeof postDnsTestUpstream>>, {data: DnsUpstreamTestRequest}> = (props) => {
+          const {data} = props ?? {};
+
+          return  postDnsTestUpstream(data,requestOptions)
+        }
+
+
+
+
+
+
+  return  { mutationFn, ...mutationOptions }}
+
+    export type PostDnsTestUpstreamMutationResult = NonNullable<Awaited<ReturnType<typeof postDnsTestUpstream>>>
+    export type PostDnsTestUpstreamMutationBody = DnsUpstreamTestRequest
+    export type PostDnsTestUpstreamMutationError = ErrorResponse
+
+    /**
+ * @summary Test a DNS upstream
+ */
+export const usePostDnsTestUpstream = <TError = ErrorResponse,
+    TContext = unknown>(options?: { mutation?:UseMutationOptions<Awaited<ReturnType<typeof postDnsTestUpstream>>, TError,{data: DnsUpstreamTestRequest}, TContext>, request?: SecondParameter<typeof apiFetch>}
+ , queryClient?: QueryClient): UseMutationResult<
+        Awaited<ReturnType<typeof postDnsTestUpstream>>,
+        TError,
+        {data: DnsUpstreamTestRequest},
+        TContext
+      > => {
+      return useMutation(getPostDnsTestUpstreamMutationOptions(options), queryClient);
+    }
+
+/**
+ * Reports which route rules and which DNS rule a domain name matches, using cached list data only. Unlike `/api/routing/test` the domain is not resolved and the kernel firewall sets are not queried, so the answer is cheap enough for UI hints.
+
+ * @summary Match a domain against lists
+ */
+export type postDnsMatchResponse200 = {
+  data: DnsMatchResponse
+  status: 200
+}
+
+export type postDnsMatchResponse400 = {
+  data: ErrorResponse
+  status: 400
+}
+
+export type postDnsMatchResponseSuccess = (postDnsMatchResponse200) & {
+  headers: Headers;
+};
+export type postDnsMatchResponseError = (postDnsMatchResponse400) & {
+  headers: Headers;
+};
+
+export type postDnsMatchResponse = (postDnsMatchResponseSuccess | postDnsMatchResponseError)
+
+export const getPostDnsMatchUrl = () => {
+
+
+
+
+  return `/api/dns/match`
+}
+
+export const postDnsMatch = async (dnsMatchRequest: DnsMatchRequest, options?: RequestInit): Promise<postDnsMatchResponse> => {
+
+  return apiFetch<postDnsMatchResponse>(getPostDnsMatchUrl(),
+  {
+    ...options,
+    method: 'POST',
+    headers: { 'Content-Type': 'application/json', ...options?.headers },
+    body: JSON.stringify(
+      dnsMatchRequest,)
+  }
+);}
+
+
+
+
+export const getPostDnsMatchMutationOptions = <TError = ErrorResponse,
+    TContext = unknown>(options?: { mutation?:UseMutationOptions<Awaited<ReturnType<typeof postDnsMatch>>, TError,{data: DnsMatchRequest}, TContext>, request?: SecondParameter<typeof apiFetch>}
+): UseMutationOptions<Awaited<ReturnType<typeof postDnsMatch>>, TError,{data: DnsMatchRequest}, TContext> => {
+
+const mutationKey = ['postDnsMatch'];
+const {mutation: mutationOptions, request: requestOptions} = options ?
+      options.mutation && 'mutationKey' in options.mutation && options.mutation.mutationKey ?
+      options
+      : {...options, mutation: {...options.mutation, mutationKey}}
+      : {mutation: { mutationKey, }, request: undefined};
+
+
+
+
+      const mutationFn: MutationFunction<Awaited<ReturnType<typeof postDnsMatch>>, {data: DnsMatchRequest}> = (props) => {
+          const {data} = props ?? {};
+
+          return  postDnsMatch(data,requestOptions)
+        }
+
+
+
+
+
+
+  return  { mutationFn, ...mutationOptions }}
+
+    export type PostDnsMatchMutationResult = NonNullable<Awaited<ReturnType<typeof postDnsMatch>>>
+    export type PostDnsMatchMutationBody = DnsMatchRequest
+    export type PostDnsMatchMutationError = ErrorResponse
+
+    /**
+ * @summary Match a domain against lists
+ */
+export const usePostDnsMatch = <TError = ErrorResponse,
+    TContext = unknown>(options?: { mutation?:UseMutationOptions<Awaited<ReturnType<typeof postDnsMatch>>, TError,{data: DnsMatchRequest}, TContext>, request?: SecondParameter<typeof apiFetch>}
+ , queryClient?: QueryClient): UseMutationResult<
+        Awaited<ReturnType<typeof postDnsMatch>>,
+        TError,
+        {data: DnsMatchRequest},
+        TContext
+      > => {
+      return useMutation(getPostDnsMatchMutationOptions(options), queryClient);
+    }
+
 
 
 
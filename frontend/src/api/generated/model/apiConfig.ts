@@ -30,4 +30,9 @@ export interface ApiConfig {
      * @minimum 1
      */
   keep_alive_timeout_seconds?: number;
+  /**
+     * How long Keenetic interface descriptions fetched over RCI are cached. 0 fetches them on every request.
+     * @minimum 0
+     */
+  keenetic_interface_cache_seconds?: number;
 }
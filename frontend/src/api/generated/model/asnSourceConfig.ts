@@ -0,0 +1,20 @@
+/**
+ * Generated by orval v8.6.2 🍺
+ * Do not edit manually.
+ * keen-pbr API
+ * REST API for the keen-pbr policy-based routing daemon.
+ * OpenAPI spec version: 3.0.0
+ */
+
+/**
+ * Where lists with an `as:<ASN>` url get the prefixes of that autonomous system. Exactly one of `url_template` or `file` must be set.
+
+ */
+export interface AsnSourceConfig {
+  /** HTTP(S) URL containing `{asn}`, replaced by the decimal ASN. It must return the prefixes as a plain list and is downloaded like a list `url`.
+   */
+  url_template?: string;
+  /** Path to a local BGP prefix-to-AS dump with `<prefix>/<len> <origin>` or CAIDA pfx2as `<address> <len> <origin>` lines. The origin may be `AS`-prefixed or several ASNs joined by `_` or `,`.
+   */
+  file?: string;
+}
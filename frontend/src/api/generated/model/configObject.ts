@@ -6,6 +6,7 @@
  * OpenAPI spec version: 3.0.0
  */
 import type { ApiConfig } from './apiConfig';
+import type { AsnSourceConfig } from './asnSourceConfig';
 import type { ConfigObjectLists } from './configObjectLists';
 import type { DaemonConfig } from './daemonConfig';
 import type { DnsConfig } from './dnsConfig';
@@ -29,4 +30,5 @@ export interface ConfigObject {
   fwmark?: FwmarkConfig;
   iproute?: IprouteConfig;
   lists_autoupdate?: ListsAutoupdateConfig;
+  asn_source?: AsnSourceConfig;
 }
@@ -13,4 +13,7 @@ export interface ConfigUpdateResponse {
   /** Present for `POST /api/config/save`: server-authoritative Unix timestamp (seconds) when apply started.
    */
   apply_started_ts?: number;
+  /** Present for `POST /api/config` when the staged config is valid but has advisory findings, such as lists no rule uses. Warnings never block staging or applying.
+   */
+  warnings?: string[];
 }
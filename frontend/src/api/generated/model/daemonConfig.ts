@@ -26,6 +26,9 @@ export interface DaemonConfig {
   /** Whether a full firewall apply should clear dnsmasq-owned dynamic IP sets before recreating runtime rules. Defaults to `true` when omitted or set to `null`. List-only and preserve-set reconciles never clear dynamic entries.
    */
   clear_dynamic_sets_on_apply?: boolean | null;
+  /** Periodically verify the installed firewall rules and reapply them when some are missing, e.g. after a firewall restart flushed them.
+   */
+  firewall_self_heal?: boolean;
   /** Whether keen-pbr should install IPv6 firewall sets/rules and emit IPv6 resolver set targets. Defaults to `true` when omitted or set to `null`. If enabled but the system lacks IPv6 support, keen-pbr logs an error and continues in IPv4-only mode.
    */
   ipv6_enabled?: boolean | null;
@@ -37,6 +40,9 @@ export interface DaemonConfig {
   /** Maximum allowed size in bytes for downloaded remote content such as URL-backed lists. Defaults to 8 MiB.
    */
   max_file_size_bytes?: number;
+  /** Plain DNS server ("ip", "ip:port" or "[ipv6]:port") used to resolve the hostnames of list URLs before downloading them. Unset uses the system resolver, which may be dnsmasq that is not ready yet at boot.
+   */
+  bootstrap_dns?: string;
   /**
      * Deadline for privileged helper and hook processes.
      * @minimum 1
@@ -17,4 +17,10 @@ export interface DnsConfig {
   fallback?: string[];
   dns_test_server?: DnsTestServer;
   system_resolver?: DnsSystemResolver;
+  /**
+     * Largest EDNS UDP payload size in bytes that dnsmasq advertises upstream and uses for client answers. Omit to keep the dnsmasq default.
+     * @minimum 512
+     * @maximum 4096
+     */
+  edns_packet_max?: number;
 }
@@ -0,0 +1,21 @@
+/**
+ * Generated by orval v8.6.2 🍺
+ * Do not edit manually.
+ * keen-pbr API
+ * REST API for the keen-pbr policy-based routing daemon.
+ * OpenAPI spec version: 3.0.0
+ */
+import type { DnsMatchRouteRule } from './dnsMatchRouteRule';
+import type { DnsMatchServer } from './dnsMatchServer';
+
+export interface DnsMatchResponse {
+  /** The matched domain name. */
+  domain: string;
+  /** Outbound of the first matching route rule. "(default)" when no rule matches.
+   */
+  outbound: string;
+  /** Every enabled route rule whose lists contain the domain, in rule order. Only list membership is checked; other rule conditions such as ports or addresses are not evaluated.
+   */
+  route_matches: DnsMatchRouteRule[];
+  dns_server?: DnsMatchServer;
+}
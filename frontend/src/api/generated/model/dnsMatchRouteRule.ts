@@ -0,0 +1,16 @@
+/**
+ * Generated by orval v8.6.2 🍺
+ * Do not edit manually.
+ * keen-pbr API
+ * REST API for the keen-pbr policy-based routing daemon.
+ * OpenAPI spec version: 3.0.0
+ */
+import type { RoutingTestListMatch } from './routingTestListMatch';
+
+export interface DnsMatchRouteRule {
+  /** Index of the route rule in `route.rules`. */
+  rule_index: number;
+  /** Outbound tag of the rule. */
+  outbound: string;
+  list_match: RoutingTestListMatch;
+}
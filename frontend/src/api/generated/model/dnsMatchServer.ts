@@ -0,0 +1,16 @@
+/**
+ * Generated by orval v8.6.2 🍺
+ * Do not edit manually.
+ * keen-pbr API
+ * REST API for the keen-pbr policy-based routing daemon.
+ * OpenAPI spec version: 3.0.0
+ */
+import type { RoutingTestListMatch } from './routingTestListMatch';
+
+export interface DnsMatchServer {
+  /** Index of the DNS rule in `dns.rules`. */
+  rule_index: number;
+  /** DNS server tag dnsmasq forwards the domain to. */
+  server: string;
+  list_match: RoutingTestListMatch;
+}
@@ -11,4 +11,6 @@ export interface DnsTestServer {
   listen: string;
   /** IPv4 address returned in synthetic `A` answers. Defaults to the host part of `listen`. */
   answer_ipv4?: string;
+  /** Zone forwarded to the DNS test server; the UI check queries random names below it. Defaults to `check.keen.pbr`. */
+  domain_suffix?: string;
 }
@@ -0,0 +1,20 @@
+/**
+ * Generated by orval v8.6.2 🍺
+ * Do not edit manually.
+ * keen-pbr API
+ * REST API for the keen-pbr policy-based routing daemon.
+ * OpenAPI spec version: 3.0.0
+ */
+
+export interface DnsUpstreamTestRequest {
+  /** DNS upstream address in `ip`, `ip:port` or `[ipv6]:port` form. */
+  address: string;
+  /** Domain name to query. Defaults to `example.com`. */
+  domain?: string;
+  /**
+     * Time to wait for the answer. Defaults to 3000.
+     * @minimum 100
+     * @maximum 10000
+     */
+  timeout_ms?: number;
+}
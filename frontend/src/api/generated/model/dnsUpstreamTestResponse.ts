@@ -0,0 +1,24 @@
+/**
+ * Generated by orval v8.6.2 🍺
+ * Do not edit manually.
+ * keen-pbr API
+ * REST API for the keen-pbr policy-based routing daemon.
+ * OpenAPI spec version: 3.0.0
+ */
+
+export interface DnsUpstreamTestResponse {
+  /** The tested upstream address. */
+  address: string;
+  /** The queried domain name. */
+  domain: string;
+  /** true when the upstream answered with NOERROR in time. */
+  ok: boolean;
+  /** Time between sending the query and receiving the answer. */
+  latency_ms: number;
+  /** IPv4 addresses from the answer section. */
+  resolved_ips: string[];
+  /** DNS response code name, or null when no answer was received. */
+  rcode?: string | null;
+  /** Reason the test failed. */
+  error?: string | null;
+}
@@ -7,6 +7,7 @@
  */
 
 export * from './apiConfig';
+export * from './asnSourceConfig';
 export * from './cacheMetadata';
 export * from './checkStatus';
 export * from './circuitBreakerConfig';
@@ -21,11 +22,17 @@ export * from './daemonConfig';
 export * from './daemonConfigFirewallBackend';
 export * from './daemonConfigStrictEnforcementAction';
 export * from './dnsConfig';
+export * from './dnsMatchRequest';
+export * from './dnsMatchResponse';
+export * from './dnsMatchRouteRule';
+export * from './dnsMatchServer';
 export * from './dnsRule';
 export * from './dnsServer';
 export * from './dnsServerType';
 export * from './dnsSystemResolver';
 export * from './dnsTestServer';
+export * from './dnsUpstreamTestRequest';
+export * from './dnsUpstreamTestResponse';
 export * from './errorResponse';
 export * from './firewallChain';
 export * from './firewallRuleCheck';
@@ -34,6 +41,7 @@ export * from './healthResponse';
 export * from './healthResponseRuntimeState';
 export * from './healthResponseStatus';
 export * from './iprouteConfig';
+export * from './iprouteConfigRoutePolicy';
 export * from './lifecycleOperation';
 export * from './lifecycleOperationAcceptedResponse';
 export * from './lifecycleOperationAcceptedResponseStatus';
@@ -47,6 +55,7 @@ export * from './listRefreshResponse';
 export * from './listRefreshResponseStatus';
 export * from './listRefreshState';
 export * from './listsAutoupdateConfig';
+export * from './nexthop';
 export * from './outbound';
 export * from './outboundGroup';
 export * from './outboundStrictEnforcementAction';
@@ -61,6 +70,9 @@ export * from './retryConfig';
 export * from './routeConfig';
 export * from './routeRule';
 export * from './routeTableCheck';
+export * from './routingConntrackFlow';
+export * from './routingConntrackRequest';
+export * from './routingConntrackResponse';
 export * from './routingHealthErrorResponse';
 export * from './routingHealthErrorResponseOverall';
 export * from './routingHealthResponse';
@@ -81,6 +93,7 @@ export * from './runtimeOutboundsResponse';
 export * from './runtimeOutboundState';
 export * from './runtimeOutboundStateType';
 export * from './runtimeOutboundStatus';
+export * from './serviceStopRequest';
 export * from './statusEventInterfaces';
 export * from './statusEventInterfacesType';
 export * from './statusEventOutbounds';
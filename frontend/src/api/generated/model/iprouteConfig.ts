@@ -5,10 +5,14 @@
  * REST API for the keen-pbr policy-based routing daemon.
  * OpenAPI spec version: 3.0.0
  */
+import type { IprouteConfigRoutePolicy } from './iprouteConfigRoutePolicy';
 
 export interface IprouteConfig {
   /** First routing table ID to allocate for outbounds. */
   table_start?: number;
   /** First RPDB priority to allocate. Defaults to table_start. */
   rule_priority_start?: number | null;
+  /** How default routes are installed into keen-pbr tables. `metric` keeps urltest fallback routes next to the selected one, ordered by metric, and refuses to touch foreign routes. `replace` keeps a single default route per family in every table and replaces any default route another program installed there.
+   */
+  route_policy?: IprouteConfigRoutePolicy;
 }
@@ -0,0 +1,19 @@
+/**
+ * Generated by orval v8.6.2 🍺
+ * Do not edit manually.
+ * keen-pbr API
+ * REST API for the keen-pbr policy-based routing daemon.
+ * OpenAPI spec version: 3.0.0
+ */
+
+/**
+ * How default routes are installed into keen-pbr tables. `metric` keeps urltest fallback routes next to the selected one, ordered by metric, and refuses to touch foreign routes. `replace` keeps a single default route per family in every table and replaces any default route another program installed there.
+
+ */
+export type IprouteConfigRoutePolicy = typeof IprouteConfigRoutePolicy[keyof typeof IprouteConfigRoutePolicy];
+
+
+export const IprouteConfigRoutePolicy = {
+  metric: 'metric',
+  replace: 'replace',
+} as const;
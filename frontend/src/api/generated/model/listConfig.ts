@@ -11,8 +11,18 @@
 
  */
 export interface ListConfig {
-  /** HTTP(S) URL to a remote list file to download and cache. */
+  /** HTTP(S) URL to a remote list file to download and cache, or `as:<ASN>` (ASN 1-4294967295) for the prefixes of an autonomous system resolved through `asn_source`, which is then required.
+   */
   url?: string;
+  /** Mirror URLs for the same list, tried in order when downloading from `url` fails. The first successful download is cached. Requires `url`.
+   */
+  fallback_urls?: string[];
+  /** Expected SHA-256 digest of the content downloaded from `url`, as 64 hexadecimal characters. A download that does not match is rejected and the previously cached copy is kept.
+   */
+  sha256?: string;
+  /** Reject a download from `url` whose Content-Type is not text-like (`text/html` included) or whose content starts with `<`, such as an HTML error page. The previously cached copy is kept. Requires `url`.
+   */
+  validate_content?: boolean;
   /** Inline DNS-compatible domain patterns. A leading `*.` is accepted and normalized to the base domain; the same syntax is used for file and URL lists.
    */
   domains?: string[];
@@ -26,4 +36,7 @@ export interface ListConfig {
   /** Optional outbound tag to use when downloading this list. If set, download traffic is marked with the outbound's fwmark and routed via its dedicated routing table. If omitted, the system default routing table is used.
    */
   detour?: string;
+  /** Whether this list is active. `false` disables the list without deleting it: it is not downloaded and contributes no domains or addresses, while rules may keep referencing it. `true`, omitted, or `null` all mean the list is active.
+   */
+  enabled?: boolean | null;
 }
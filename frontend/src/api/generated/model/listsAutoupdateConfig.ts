@@ -12,4 +12,9 @@ export interface ListsAutoupdateConfig {
   /** Standard 5-field cron expression controlling when lists are refreshed. Required when `enabled` is `true`. Validated even when `enabled` is `false`.
    */
   cron?: string;
+  /**
+     * Re-import all cached lists into the kernel sets when no import happened for this many hours, even if no list changed. Runs on its own timer, independent of `enabled` and `cron`. `0` disables it.
+     * @minimum 0
+     */
+  force_reimport_interval_hours?: number;
 }
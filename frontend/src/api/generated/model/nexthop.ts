@@ -0,0 +1,18 @@
+/**
+ * Generated by orval v8.6.2 🍺
+ * Do not edit manually.
+ * keen-pbr API
+ * REST API for the keen-pbr policy-based routing daemon.
+ * OpenAPI spec version: 3.0.0
+ */
+
+export interface Nexthop {
+  /** Tag of an `interface` outbound used as one path of the multipath route. */
+  outbound: string;
+  /**
+     * Relative share of new flows sent through this path.
+     * @minimum 1
+     * @maximum 256
+     */
+  weight?: number;
+}
@@ -7,6 +7,7 @@
  */
 import type { CircuitBreakerConfig } from './circuitBreakerConfig';
 import type { ConntrackOnSwitch } from './conntrackOnSwitch';
+import type { Nexthop } from './nexthop';
 import type { OutboundGroup } from './outboundGroup';
 import type { OutboundStrictEnforcementAction } from './outboundStrictEnforcementAction';
 import type { OutboundType } from './outboundType';
@@ -32,6 +33,9 @@ export interface Outbound {
   strict_enforcement?: boolean;
   /** Per-outbound override of daemon.strict_enforcement_action. */
   strict_enforcement_action?: OutboundStrictEnforcementAction;
+  /** IPv6-only override of `strict_enforcement` for `interface` and `table` outbounds. `false` lets marked IPv6 traffic fall back to normal routing whenever the outbound has no IPv6 path, while IPv4 keeps the kill switch. Inherits `strict_enforcement` when omitted.
+   */
+  strict_enforcement_ipv6?: boolean;
   /** Kernel routing table ID. Required for `table` outbound type.
    */
   table?: number;
@@ -53,6 +57,9 @@ export interface Outbound {
   /** Ordered list of outbound groups. Required for `urltest` outbound type. Groups are tried in order; within a group the outbound is selected by weight.
    */
   outbound_groups?: OutboundGroup[];
+  /** Paths of a weighted ECMP route. Required for `multipath` outbound type. Each entry references an `interface` outbound; paths whose interface or gateway is unavailable are left out, and the route degrades to a single path or to an unreachable route when none remain.
+   */
+  nexthops?: Nexthop[];
   retry?: RetryConfig;
   circuit_breaker?: CircuitBreakerConfig;
 }
@@ -18,4 +18,5 @@ export const OutboundType = {
   blackhole: 'blackhole',
   ignore: 'ignore',
   urltest: 'urltest',
+  multipath: 'multipath',
 } as const;
@@ -12,6 +12,9 @@ export interface RouteRule {
   enabled?: boolean | null;
   /** List names whose traffic this rule matches. Optional when another route condition is present. */
   list?: string[];
+  /** Ingress interfaces whose packets this rule matches. Omit or leave empty for any interface. When route.inbound_interfaces is set, every entry must also be listed there.
+   */
+  inbound_interfaces?: string[];
   /** Outbound tag to route matched traffic through. */
   outbound: string;
   /** Protocol to match ("tcp", "udp", or "tcp/udp"). Omit for any. */
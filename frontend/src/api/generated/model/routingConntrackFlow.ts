@@ -0,0 +1,27 @@
+/**
+ * Generated by orval v8.6.2 🍺
+ * Do not edit manually.
+ * keen-pbr API
+ * REST API for the keen-pbr policy-based routing daemon.
+ * OpenAPI spec version: 3.0.0
+ */
+
+export interface RoutingConntrackFlow {
+  /** Layer 4 protocol name as reported by conntrack. */
+  protocol: string;
+  /** Connection tracking state. Absent for stateless protocols. */
+  state?: string | null;
+  /** Source address of the original direction. */
+  src: string;
+  /** Destination address of the original direction. */
+  dst: string;
+  /** Source port. Absent for protocols without ports. */
+  sport?: number | null;
+  /** Destination port. Absent for protocols without ports. */
+  dport?: number | null;
+  /** Connection mark (connmark) of the flow. */
+  mark: number;
+  /** Outbound tag whose fwmark matches the connection mark. Absent when the flow is unmarked or the mark belongs to no configured outbound.
+   */
+  outbound?: string | null;
+}
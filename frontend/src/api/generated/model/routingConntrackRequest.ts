@@ -0,0 +1,12 @@
+/**
+ * Generated by orval v8.6.2 🍺
+ * Do not edit manually.
+ * keen-pbr API
+ * REST API for the keen-pbr policy-based routing daemon.
+ * OpenAPI spec version: 3.0.0
+ */
+
+export interface RoutingConntrackRequest {
+  /** IPv4 or IPv6 destination address to list flows for. */
+  ip: string;
+}
@@ -0,0 +1,16 @@
+/**
+ * Generated by orval v8.6.2 🍺
+ * Do not edit manually.
+ * keen-pbr API
+ * REST API for the keen-pbr policy-based routing daemon.
+ * OpenAPI spec version: 3.0.0
+ */
+import type { RoutingConntrackFlow } from './routingConntrackFlow';
+
+export interface RoutingConntrackResponse {
+  /** The destination address that was queried. */
+  ip: string;
+  flows: RoutingConntrackFlow[];
+  /** True when conntrack printed more than 256 KiB and flows is incomplete. */
+  truncated: boolean;
+}
@@ -22,4 +22,7 @@ export interface RoutingHealthResponse {
   firewall_rules: FirewallRuleCheck[];
   route_tables: RouteTableCheck[];
   policy_rules: PolicyRuleCheck[];
+  /** Advisory findings that do not affect overall, such as an IPv6 outbound whose prefix differs from the LAN's, a list that no rule uses or a route the kernel rejected during the last apply.
+   */
+  warnings?: string[];
 }
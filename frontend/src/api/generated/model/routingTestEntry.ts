@@ -19,6 +19,10 @@ export interface RoutingTestEntry {
   /** Outbound tag found in the live kernel firewall sets. "(default)" when the IP is not present in any set. "(unknown)" when the firewall tool is unavailable.
    */
   actual_outbound: string;
-  /** true when expected_outbound equals actual_outbound. */
+  /** Interface the kernel routes the IP through when marked with the expected outbound's fwmark (ip route get). Absent when the firewall tool is unavailable, no outbound is expected, or the route has no device.
+   */
+  egress_interface?: string | null;
+  /** true when expected_outbound equals actual_outbound and, for an interface outbound, egress_interface is its interface.
+   */
   ok: boolean;
 }
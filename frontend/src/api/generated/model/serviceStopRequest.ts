@@ -0,0 +1,16 @@
+/**
+ * Generated by orval v8.6.2 🍺
+ * Do not edit manually.
+ * keen-pbr API
+ * REST API for the keen-pbr policy-based routing daemon.
+ * OpenAPI spec version: 3.0.0
+ */
+
+export interface ServiceStopRequest {
+  /**
+     * Start the routing runtime again after this many seconds, unless another start, stop, restart or config apply is requested first. Omit to stay stopped.
+     * @minimum 1
+     * @maximum 86400
+     */
+  auto_start_after_seconds?: number;
+}
@@ -8,7 +8,11 @@ import {
 import { useEffect, useMemo, useState } from "react"
 import { useTranslation } from "react-i18next"
 
-import { type DnsCheckStatus, useDnsCheck } from "@/hooks/use-dns-check"
+import {
+  DNS_CHECK_DOMAIN_SUFFIX,
+  type DnsCheckStatus,
+  useDnsCheck,
+} from "@/hooks/use-dns-check"
 import { SectionCard } from "@/components/shared/section-card"
 import { Button } from "@/components/ui/button"
 
@@ -16,14 +20,16 @@ import { DnsCheckModal } from "./dns-check-modal"
 
 export function DnsCheckWidget({
   dnsProbeEnabled,
+  domainSuffix = DNS_CHECK_DOMAIN_SUFFIX,
   onStatusChange,
 }: {
   dnsProbeEnabled: boolean
+  domainSuffix?: string
   onStatusChange?: (status: DnsCheckStatus) => void
 }) {
   const { t } = useTranslation()
   const [showPcCheckDialog, setShowPcCheckDialog] = useState(false)
-  const { status, startCheck, reset } = useDnsCheck()
+  const { status, startCheck, reset } = useDnsCheck(domainSuffix)
 
   useEffect(() => {
     onStatusChange?.(status)
@@ -102,6 +108,7 @@ export function DnsCheckWidget({
 
       <DnsCheckModal
         browserStatus={status}
+        domainSuffix={domainSuffix}
         onOpenChange={setShowPcCheckDialog}
         open={showPcCheckDialog}
       />
@@ -44,7 +44,9 @@ export function RoutingHealthCard({
     () => groupRouteTables(routeTables),
     [routeTables]
   )
+  const warnings = routingHealth.warnings ?? []
   const hasVisibleEntries =
+    warnings.length > 0 ||
     firewallRules.length > 0 ||
     groupedRoutes.length > 0 ||
     policyRules.length > 0
@@ -102,6 +104,28 @@ export function RoutingHealthCard({
         </Empty>
       ) : null}
 
+      {warnings.length > 0 ? (
+        <CompactSection
+          title={t("overview.routing.sections.warnings")}
+          items={warnings}
+          renderItem={(warning, index) => (
+            <div
+              className="rounded-md border border-border/70 bg-muted/20 px-3 py-1.5"
+              key={`warning-${index}`}
+            >
+              <div className="flex items-center justify-between gap-3">
+                <span className="min-w-0 text-xs text-muted-foreground">
+                  {warning}
+                </span>
+                <StatusBadge tone="warning">
+                  {t("overview.routing.warningBadge")}
+                </StatusBadge>
+              </div>
+            </div>
+          )}
+        />
+      ) : null}
+
       {firewallRules.length > 0 ? (
         <CompactSection
           title={t("overview.routing.sections.firewall")}
@@ -38,7 +38,9 @@ const browserCheckTimeoutMs = 5_000
 const pcCheckTimeoutMs = 300_000
 const pcWarningTimeoutMs = 30_000
 
-export function useDnsCheck(): UseDnsCheckReturn {
+export function useDnsCheck(
+  domainSuffix: string = DNS_CHECK_DOMAIN_SUFFIX
+): UseDnsCheckReturn {
   const eventSourceRef = useRef<EventSource | null>(null)
   const fetchControllerRef = useRef<AbortController | null>(null)
   const checkTimeoutRef = useRef<number | null>(null)
@@ -80,7 +82,7 @@ export function useDnsCheck(): UseDnsCheckReturn {
       cleanup()
 
       const randomString = Math.random().toString(36).slice(2, 15)
-      const domain = `${randomString}.${DNS_CHECK_DOMAIN_SUFFIX}`
+      const domain = `${randomString}.${domainSuffix}`
 
       setCheckState({
         randomString,
@@ -169,7 +171,7 @@ export function useDnsCheck(): UseDnsCheckReturn {
         performBrowserRequest ? browserCheckTimeoutMs : pcCheckTimeoutMs
       )
     },
-    [cleanup]
+    [cleanup, domainSuffix]
   )
 
   const reset = useCallback(() => {
@@ -219,7 +219,9 @@ export const enTranslation = {
         firewall: "Firewall",
         routes: "Routes",
         policies: "Policies",
+        warnings: "Warnings",
       },
+      warningBadge: "warning",
       chain: "chain",
       prerouting: "prerouting",
       defaultRoute: "default",
@@ -568,7 +570,7 @@ export const enTranslation = {
       },
       validation: {
         atLeastOneCondition:
-          "Specify at least one condition: list, DSCP, source/destination address, or source/destination port.",
+          "Specify at least one condition: list, inbound interface, DSCP, source/destination address, or source/destination port.",
         dscpRange: "DSCP must be an integer between 1 and 63.",
         outboundRequired: "Outbound tag is required.",
       },
@@ -579,6 +581,11 @@ export const enTranslation = {
           "Add one or more configured list names to match for this rule.",
         noListsSelected: "No lists selected",
         listsHint: "Choose which of your lists this rule applies to.",
+        inboundInterfaces: "Inbound interfaces",
+        inboundInterfacesPlaceholderDescription:
+          "Add interfaces to match only traffic arriving on them.",
+        inboundInterfacesHint:
+          "Match only packets arriving on these interfaces. Leave empty for any. When inbound interfaces are set in settings, pick from those.",
         proto: "Proto",
         any: "Any",
         anyLower: "any",
@@ -658,6 +665,7 @@ export const enTranslation = {
         gateway6: "gateway6={{value}}",
         table: "table={{value}}",
         urltest: "outbounds={{value}}",
+        multipath: "nexthops={{value}}",
       },
       messages: {
         missingReference:
@@ -694,6 +702,7 @@ export const enTranslation = {
           urltest: "Auto-select (urltest)",
           blackhole: "Blackhole",
           ignore: "Ignore",
+          multipath: "Multipath (ECMP)",
         },
       },
       interface: {
@@ -1011,6 +1020,8 @@ export const enTranslation = {
       fields: {
         name: "Name",
         nameHint: "Stable identifier used in rules and references.",
+        enabledHint:
+          "A disabled list is not downloaded and matches nothing, but rules may keep referencing it.",
         ttlMs: "IP cache duration (ms)",
         ttlMsHint:
           "How long to keep resolved IPs in the ipset. `0` = no timeout.",
@@ -1022,6 +1033,9 @@ export const enTranslation = {
         url: "Remote URL",
         urlHint:
           "Optional: a URL to download entries from. Combined with anything you add below.",
+        fallbackUrls: "Mirror URLs",
+        fallbackUrlsHint:
+          "Optional: backup URLs for the same list, one per line. They are tried in order when the remote URL fails.",
         file: "Absolute file path",
         fileHint:
           "Optional: a file path on the device to load entries from. Combined with other sources.",
@@ -224,7 +224,9 @@ export const ruTranslation = {
         firewall: "Firewall",
         routes: "Маршруты",
         policies: "Политики",
+        warnings: "Предупреждения",
       },
+      warningBadge: "внимание",
       chain: "chain",
       prerouting: "prerouting",
       defaultRoute: "default",
@@ -582,7 +584,7 @@ export const ruTranslation = {
       },
       validation: {
         atLeastOneCondition:
-          "Укажите хотя бы одно условие: список, DSCP, адрес источника/назначения или порт источника/назначения.",
+          "Укажите хотя бы одно условие: список, входящий интерфейс, DSCP, адрес источника/назначения или порт источника/назначения.",
         dscpRange: "DSCP должен быть целым числом от 1 до 63.",
         outboundRequired: "Тег outbound обязателен.",
       },
@@ -593,6 +595,11 @@ export const ruTranslation = {
           "Добавьте один или несколько настроенных списков для этого правила.",
         noListsSelected: "Списки не выбраны",
         listsHint: "Выберите, к каким спискам применяется это правило.",
+        inboundInterfaces: "Входящие интерфейсы",
+        inboundInterfacesPlaceholderDescription:
+          "Добавьте интерфейсы, чтобы учитывать только пришедший с них трафик.",
+        inboundInterfacesHint:
+          "Учитывать только пакеты, пришедшие с этих интерфейсов. Оставьте пустым для любого. Если входящие интерфейсы заданы в настройках, выбирайте из них.",
         proto: "Протокол",
         any: "Любой",
         anyLower: "любой",
@@ -675,6 +682,7 @@ export const ruTranslation = {
         gateway6: "gateway6={{value}}",
         table: "table={{value}}",
         urltest: "outbounds={{value}}",
+        multipath: "nexthops={{value}}",
       },
       messages: {
         missingReference:
@@ -712,6 +720,7 @@ export const ruTranslation = {
           urltest: "Автовыбор (urltest)",
           blackhole: "Blackhole",
           ignore: "Ignore",
+          multipath: "Мультипуть (ECMP)",
         },
       },
       interface: {
@@ -1042,6 +1051,8 @@ export const ruTranslation = {
       fields: {
         name: "Имя",
         nameHint: "Стабильный идентификатор для использования в правилах.",
+        enabledHint:
+          "Отключённый список не скачивается и ничего не содержит, но правила могут продолжать на него ссылаться.",
         ttlMs: "Время жизни IP-кэша (мс)",
         ttlMsHint:
           "Как долго хранить разрешённые IP в ipset. `0` = без таймаута.",
@@ -1053,6 +1064,9 @@ export const ruTranslation = {
         url: "Удалённый URL",
         urlHint:
           "Необязательно: URL для загрузки записей. Объединяется с остальным содержимым.",
+        fallbackUrls: "Зеркала URL",
+        fallbackUrlsHint:
+          "Необязательно: резервные URL того же списка, по одному на строку. Используются по порядку, если удалённый URL недоступен.",
         file: "Абсолютный путь к файлу",
         fileHint:
           "Необязательно: путь к файлу на устройстве. Объединяется с другими источниками.",
@@ -761,6 +761,13 @@ function toHex32(value: string | undefined, fallback: string) {
   }
 
   const trimmed = value.trim()
+  if (/^\d+$/.test(trimmed)) {
+    const parsed = Number.parseInt(trimmed, 10)
+    if (parsed > 0xffffffff) {
+      return fallback
+    }
+    return `0x${parsed.toString(16).padStart(8, "0")}`
+  }
   if (!/^0x[0-9a-fA-F]+$/.test(trimmed)) {
     return fallback
   }
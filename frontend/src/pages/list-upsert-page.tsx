@@ -41,6 +41,7 @@ import {
   CardHeader,
   CardTitle,
 } from "@/components/ui/card"
+import { Checkbox } from "@/components/ui/checkbox"
 import { Input } from "@/components/ui/input"
 import { Textarea } from "@/components/ui/textarea"
 import {
@@ -54,11 +55,13 @@ import { useIsMobile } from "@/hooks/use-mobile"
 
 type ListDraft = {
   name: string
+  enabled: boolean
   ttlMs: string
   detour: string
   domains: string
   ipCidrs: string
   url: string
+  fallbackUrls: string
   file: string
 }
 
@@ -69,11 +72,13 @@ const LIST_SOURCE_GROUPS: ListSourceGroup[] = ["url", "file", "inline"]
 const DEFAULT_SOURCE_GROUP: ListSourceGroup = "url"
 const LIST_FIELD_NAMES = {
   name: "name",
+  enabled: "enabled",
   ttlMs: "ttlMs",
   detour: "detour",
   domains: "domains",
   ipCidrs: "ipCidrs",
   url: "url",
+  fallbackUrls: "fallbackUrls",
   file: "file",
 } as const
 const LIST_SOURCE_GROUP_ICONS = {
@@ -82,18 +87,20 @@ const LIST_SOURCE_GROUP_ICONS = {
   inline: ScrollTextIcon,
 } satisfies Record<ListSourceGroup, typeof CloudIcon>
 const LIST_SOURCE_GROUP_FIELDS = {
-  url: [LIST_FIELD_NAMES.url],
+  url: [LIST_FIELD_NAMES.url, LIST_FIELD_NAMES.fallbackUrls],
   file: [LIST_FIELD_NAMES.file],
   inline: [LIST_FIELD_NAMES.domains, LIST_FIELD_NAMES.ipCidrs],
 } satisfies Record<ListSourceGroup, ListFieldName[]>
 
 const sampleNewList: ListDraft = {
   name: "",
+  enabled: true,
   ttlMs: "7200000",
   detour: "",
   domains: "",
   ipCidrs: "",
   url: "",
+  fallbackUrls: "",
   file: "",
 }
 
@@ -386,6 +393,33 @@ function ListForm({
               }}
             </form.Field>
 
+            <form.Field name={LIST_FIELD_NAMES.enabled}>
+              {(field) => (
+                <Field>
+                  <FieldContent>
+                    <div className="flex items-center space-x-3">
+                      <Checkbox
+                        checked={field.state.value}
+                        id="list-enabled"
+                        onCheckedChange={(checked) =>
+                          field.handleChange(checked === true)
+                        }
+                      />
+                      <FieldLabel
+                        className="cursor-pointer flex-col items-start gap-0"
+                        htmlFor="list-enabled"
+                      >
+                        {t("common.enabled")}
+                      </FieldLabel>
+                    </div>
+                    <FieldHint
+                      description={t("pages.listUpsert.fields.enabledHint")}
+                    />
+                  </FieldContent>
+                </Field>
+              )}
+            </form.Field>
+
             <form.Field
               name={LIST_FIELD_NAMES.ttlMs}
               validators={{
@@ -505,6 +539,38 @@ function ListForm({
                 )}
               </form.Field>
 
+              <form.Field name={LIST_FIELD_NAMES.fallbackUrls}>
+                {(field) => {
+                  const error = getFirstFieldError(field.state.meta.errors)
+
+                  return (
+                    <Field invalid={Boolean(error)}>
+                      <FieldLabel htmlFor="list-fallback-urls">
+                        {t("pages.listUpsert.fields.fallbackUrls")}
+                      </FieldLabel>
+                      <FieldContent>
+                        <Textarea
+                          aria-invalid={Boolean(error)}
+                          className="min-h-24"
+                          id="list-fallback-urls"
+                          onBlur={field.handleBlur}
+                          onChange={(event) =>
+                            field.handleChange(event.target.value)
+                          }
+                          value={field.state.value}
+                        />
+                        <FieldHint
+                          description={t(
+                            "pages.listUpsert.fields.fallbackUrlsHint"
+                          )}
+                          error={error}
+                        />
+                      </FieldContent>
+                    </Field>
+                  )
+                }}
+              </form.Field>
+
               <form.Field name={LIST_FIELD_NAMES.detour}>
                 {(field) => {
                   const error = getFirstFieldError(field.state.meta.errors)
@@ -730,11 +796,13 @@ function getDraftFromMapEntry(
 
   return {
     name,
+    enabled: listConfig.enabled ?? true,
     ttlMs: String(listConfig.ttl_ms ?? 0),
     detour: listConfig.detour ?? "",
     domains: (listConfig.domains ?? []).join("\n"),
     ipCidrs: (listConfig.ip_cidrs ?? []).join("\n"),
     url: listConfig.url ?? "",
+    fallbackUrls: (listConfig.fallback_urls ?? []).join("\n"),
     file: listConfig.file ?? "",
   }
 }
@@ -762,6 +830,7 @@ function buildUpdatedConfigForListUpsert(
 function getListConfigFromDraft(draft: ListDraft): ListConfig {
   const domains = splitLines(draft.domains)
   const ipCidrs = splitLines(draft.ipCidrs)
+  const fallbackUrls = splitLines(draft.fallbackUrls)
   const trimmedUrl = draft.url.trim()
   const trimmedFile = draft.file.trim()
   const trimmedDetour = draft.detour.trim()
@@ -774,6 +843,10 @@ function getListConfigFromDraft(draft: ListDraft): ListConfig {
     listConfig.url = trimmedUrl
   }
 
+  if (fallbackUrls.length > 0) {
+    listConfig.fallback_urls = fallbackUrls
+  }
+
   if (trimmedFile) {
     listConfig.file = trimmedFile
   }
@@ -790,6 +863,10 @@ function getListConfigFromDraft(draft: ListDraft): ListConfig {
     listConfig.detour = trimmedDetour
   }
 
+  if (!draft.enabled) {
+    listConfig.enabled = false
+  }
+
   return listConfig
 }
 
@@ -870,6 +947,13 @@ function resolveListFieldPath(
     return LIST_FIELD_NAMES.url
   }
 
+  if (
+    normalizedName &&
+    path.startsWith(`lists.${normalizedName}.fallback_urls`)
+  ) {
+    return LIST_FIELD_NAMES.fallbackUrls
+  }
+
   if (normalizedName && path === `lists.${normalizedName}.file`) {
     return LIST_FIELD_NAMES.file
   }
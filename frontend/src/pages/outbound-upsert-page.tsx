@@ -9,6 +9,7 @@ import { useLocation } from "wouter"
 
 import type { ApiError } from "@/api/client"
 import type { ConfigObject } from "@/api/generated/model/configObject"
+import type { Nexthop } from "@/api/generated/model/nexthop"
 import type { Outbound } from "@/api/generated/model/outbound"
 import type { RuntimeInterfaceInventoryEntry } from "@/api/generated/model/runtimeInterfaceInventoryEntry"
 import { usePostConfigMutation } from "@/api/mutations"
@@ -63,6 +64,7 @@ type OutboundDraft = {
   gateway6: string
   table: string
   outbounds: string[][]
+  nexthops: Nexthop[]
   probeUrl: string
   interval: string
   tolerance: string
@@ -106,6 +108,7 @@ const sampleNewOutbound: OutboundDraft = {
   gateway6: "",
   table: "",
   outbounds: [[]],
+  nexthops: [],
   probeUrl: "https://www.gstatic.com/generate_204",
   interval: "180000",
   tolerance: "100",
@@ -1187,6 +1190,7 @@ function mapOutboundToDraft(outbound: Outbound): OutboundDraft {
     outbounds:
       outbound.outbound_groups?.map((group) => [...group.outbounds]) ??
       sampleNewOutbound.outbounds,
+    nexthops: outbound.nexthops ?? sampleNewOutbound.nexthops,
     probeUrl: outbound.url ?? sampleNewOutbound.probeUrl,
     interval: outbound.interval_ms?.toString() ?? sampleNewOutbound.interval,
     tolerance: outbound.tolerance_ms?.toString() ?? sampleNewOutbound.tolerance,
@@ -1262,6 +1266,14 @@ function buildOutboundPayload(draft: OutboundDraft): Outbound {
     }
   }
 
+  if (draft.type === "multipath") {
+    return {
+      type: "multipath",
+      tag,
+      nexthops: draft.nexthops,
+    }
+  }
+
   return {
     type: draft.type,
     tag,
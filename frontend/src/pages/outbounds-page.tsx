@@ -587,6 +587,16 @@ function getOutboundSummary(
     })
   }
 
+  if (outbound.type === "multipath") {
+    const paths =
+      outbound.nexthops?.map(
+        (nexthop) => `${nexthop.outbound}:${nexthop.weight ?? 1}`
+      ) ?? []
+    return t("pages.outbounds.summary.multipath", {
+      value: paths.join(","),
+    })
+  }
+
   return t("common.noneShort")
 }
 
@@ -257,6 +257,7 @@ export function OverviewPage() {
 
         <DnsCheckWidget
           dnsProbeEnabled={Boolean(loadedConfig?.dns?.dns_test_server)}
+          domainSuffix={loadedConfig?.dns?.dns_test_server?.domain_suffix}
           onStatusChange={setDnsCheckStatus}
         />
       </div>
@@ -9,7 +9,7 @@ import type { ConfigObject } from "@/api/generated/model/configObject"
 import type { Outbound } from "@/api/generated/model/outbound"
 import type { RouteRule } from "@/api/generated/model/routeRule"
 import { usePostConfigMutation } from "@/api/mutations"
-import { useGetConfig } from "@/api/queries"
+import { useGetConfig, useGetRuntimeInterfaces } from "@/api/queries"
 import { selectConfig } from "@/api/selectors"
 import {
   Field,
@@ -18,6 +18,7 @@ import {
   FieldHint,
   FieldLabel,
 } from "@/components/shared/field"
+import { InterfaceMultiSelectList } from "@/components/shared/interface-picker"
 import { MultiSelectList } from "@/components/shared/multi-select-list"
 import { OutboundSelect } from "@/components/shared/outbound-select"
 import { ServerValidationAlert } from "@/components/shared/server-validation-alert"
@@ -52,6 +53,7 @@ import {
 const ROUTING_RULE_FIELD_NAMES = {
   enabled: "enabled",
   list: "list",
+  inboundInterfaces: "inbound_interfaces",
   proto: "proto",
   dscp: "dscp",
   srcPort: "src_port",
@@ -172,6 +174,12 @@ function RoutingRuleForm({
     label: option || t("pages.routingRuleUpsert.fields.anyLower"),
   }))
 
+  const runtimeInterfacesQuery = useGetRuntimeInterfaces()
+  const runtimeInterfaces =
+    runtimeInterfacesQuery.data?.status === 200
+      ? runtimeInterfacesQuery.data.data.interfaces
+      : []
+
   const postConfigMutation = usePostConfigMutation()
 
   const form = useForm({
@@ -188,6 +196,7 @@ function RoutingRuleForm({
         const nextRule = normalizeRouteRuleDraft(value)
         const hasRuleCondition =
           (nextRule.list ?? []).length > 0 ||
+          (nextRule.inbound_interfaces ?? []).length > 0 ||
           nextRule.dscp !== undefined ||
           Boolean(nextRule.src_port) ||
           Boolean(nextRule.dest_port) ||
@@ -348,6 +357,46 @@ function RoutingRuleForm({
             }}
           </form.Field>
 
+          <form.Field name={ROUTING_RULE_FIELD_NAMES.inboundInterfaces}>
+            {(field) => {
+              const error = getFirstFieldError(field.state.meta.errors)
+
+              return (
+                <Field invalid={Boolean(error)}>
+                  <FieldLabel>
+                    {t("pages.routingRuleUpsert.fields.inboundInterfaces")}
+                  </FieldLabel>
+                  <FieldContent>
+                    <InterfaceMultiSelectList
+                      addLabel={t(
+                        "pages.settings.general.inboundInterfacesAddAction"
+                      )}
+                      emptyMessage={t(
+                        "pages.settings.general.inboundInterfacesNoAvailable"
+                      )}
+                      error={error}
+                      interfaces={runtimeInterfaces}
+                      name={ROUTING_RULE_FIELD_NAMES.inboundInterfaces}
+                      onChange={field.handleChange}
+                      placeholderDescription={t(
+                        "pages.routingRuleUpsert.fields.inboundInterfacesPlaceholderDescription"
+                      )}
+                      placeholderTitle={t(
+                        "pages.settings.general.inboundInterfacesEmptyTitle"
+                      )}
+                      value={field.state.value}
+                    />
+                    <FieldHint
+                      description={t(
+                        "pages.routingRuleUpsert.fields.inboundInterfacesHint"
+                      )}
+                    />
+                  </FieldContent>
+                </Field>
+              )
+            }}
+          </form.Field>
+
           <form.Field name={ROUTING_RULE_FIELD_NAMES.proto}>
             {(field) => (
               <Field>
@@ -653,6 +702,12 @@ function resolveRoutingRuleFieldPath(
     return ROUTING_RULE_FIELD_NAMES.list
   }
 
+  if (
+    /^route\.rules(?:\[\d+\]|\.\d+)?\.inbound_interfaces(?:\[\d+\])?$/.test(path)
+  ) {
+    return ROUTING_RULE_FIELD_NAMES.inboundInterfaces
+  }
+
   if (/^route\.rules(?:\[\d+\]|\.\d+)?\.outbound$/.test(path)) {
     return ROUTING_RULE_FIELD_NAMES.outbound
   }
@@ -5,6 +5,7 @@ import { getApiErrorMessage as getSharedApiErrorMessage } from "@/lib/api-errors
 export type RouteRuleDraft = {
   enabled: boolean
   list: string[]
+  inbound_interfaces: string[]
   outbound: string
   proto: string
   dscp: string
@@ -19,6 +20,7 @@ export const protoOptions = ["", "tcp", "udp", "tcp/udp"] as const
 export const emptyRouteRuleDraft: RouteRuleDraft = {
   enabled: true,
   list: [],
+  inbound_interfaces: [],
   outbound: "",
   proto: "",
   dscp: "",
@@ -44,6 +46,7 @@ export function toRouteRuleDraft(rule: RouteRule): RouteRuleDraft {
   return {
     enabled: rule.enabled ?? true,
     list: rule.list ?? [],
+    inbound_interfaces: rule.inbound_interfaces ?? [],
     outbound: rule.outbound,
     proto: rule.proto ?? "",
     dscp: rule.dscp?.toString() ?? "",
@@ -58,6 +61,8 @@ export function normalizeRouteRuleDraft(draft: RouteRuleDraft): RouteRule {
   return {
     enabled: draft.enabled,
     list: draft.list,
+    inbound_interfaces:
+      draft.inbound_interfaces.length > 0 ? draft.inbound_interfaces : undefined,
     outbound: draft.outbound,
     proto: trimToUndefined(draft.proto),
     dscp: parseOptionalDscp(draft.dscp),
@@ -92,6 +92,7 @@ namespace api {
 
     struct ApiConfig {
         std::optional<bool> enabled;
+        std::optional<int64_t> keenetic_interface_cache_seconds;
         std::optional<int64_t> keep_alive_timeout_seconds;
         std::optional<std::string> listen;
         std::optional<int64_t> max_request_body_bytes;
@@ -99,6 +100,11 @@ namespace api {
         std::optional<int64_t> write_timeout_seconds;
     };
 
+    struct AsnSource {
+        std::optional<std::string> file;
+        std::optional<std::string> url_template;
+    };
+
     struct CacheMetadata {
         std::optional<int64_t> cidrs;
         std::optional<int64_t> domains;
@@ -123,11 +129,13 @@ namespace api {
     enum class StrictEnforcementAction : int { BLACKHOLE, UNREACHABLE };
 
     struct Daemon {
+        std::optional<std::string> bootstrap_dns;
         std::optional<std::string> cache_dir;
         std::optional<bool> clear_dynamic_sets_on_apply;
         std::optional<int64_t> exec_kill_grace_seconds;
         std::optional<int64_t> exec_timeout_seconds;
         std::optional<DaemonConfigFirewallBackend> firewall_backend;
+        std::optional<bool> firewall_self_heal;
         std::optional<int64_t> firewall_verify_max_bytes;
         std::optional<bool> ipv6_enabled;
         std::optional<int64_t> max_file_size_bytes;
@@ -140,6 +148,7 @@ namespace api {
 
     struct DnsTestServer {
         std::optional<std::string> answer_ipv4;
+        std::optional<std::string> domain_suffix;
         std::string listen;
     };
 
@@ -165,6 +174,7 @@ namespace api {
 
     struct Dns {
         std::optional<DnsTestServer> dns_test_server;
+        std::optional<int64_t> edns_packet_max;
         std::optional<std::vector<std::string>> fallback;
         std::optional<std::vector<DnsRuleElement>> rules;
         std::optional<std::vector<DnsServerElement>> servers;
@@ -176,7 +186,10 @@ namespace api {
         std::optional<std::string> start;
     };
 
+    enum class RoutePolicy : int { METRIC, REPLACE };
+
     struct Iproute {
+        std::optional<RoutePolicy> route_policy;
         std::optional<int64_t> rule_priority_start;
         std::optional<int64_t> table_start;
     };
@@ -184,19 +197,29 @@ namespace api {
     struct ListConfigValue {
         std::optional<std::string> detour;
         std::optional<std::vector<std::string>> domains;
+        std::optional<bool> enabled;
+        std::optional<std::vector<std::string>> fallback_urls;
         std::optional<std::string> file;
         std::optional<std::vector<std::string>> ip_cidrs;
+        std::optional<std::string> sha256;
         std::optional<int64_t> ttl_ms;
         std::optional<std::string> url;
+        std::optional<bool> validate_content;
     };
 
     struct ListsAutoupdate {
         std::optional<std::string> cron;
         std::optional<bool> enabled;
+        std::optional<int64_t> force_reimport_interval_hours;
     };
 
     enum class ConntrackOnSwitch : int { DELETE, PRESERVE };
 
+    struct NexthopElement {
+        std::string outbound;
+        std::optional<int64_t> weight;
+    };
+
     struct OutboundGroupElement {
         std::vector<std::string> outbounds;
         std::optional<int64_t> weight;
@@ -207,7 +230,7 @@ namespace api {
         std::optional<int64_t> interval_ms;
     };
 
-    enum class OutboundType : int { BLACKHOLE, IGNORE, INTERFACE, TABLE, URLTEST };
+    enum class OutboundType : int { BLACKHOLE, IGNORE, INTERFACE, MULTIPATH, TABLE, URLTEST };
 
     struct OutboundElement {
         std::optional<CircuitBreakerConfig> circuit_breaker;
@@ -216,11 +239,13 @@ namespace api {
         std::optional<std::string> gateway6;
         std::optional<std::string> interface;
         std::optional<int64_t> interval_ms;
+        std::optional<std::vector<NexthopElement>> nexthops;
         std::optional<std::vector<OutboundGroupElement>> outbound_groups;
         std::optional<int64_t> probe_timeout_ms;
         std::optional<Retry> retry;
         std::optional<bool> strict_enforcement;
         std::optional<StrictEnforcementAction> strict_enforcement_action;
+        std::optional<bool> strict_enforcement_ipv6;
         std::optional<int64_t> table;
         std::string tag;
         std::optional<int64_t> tolerance_ms;
@@ -233,6 +258,7 @@ namespace api {
         std::optional<std::string> dest_port;
         std::optional<int64_t> dscp;
         std::optional<bool> enabled;
+        std::optional<std::vector<std::string>> inbound_interfaces;
         std::optional<std::vector<std::string>> list;
         std::string outbound;
         std::optional<std::string> proto;
@@ -247,6 +273,7 @@ namespace api {
 
     struct ConfigObject {
         std::optional<ApiConfig> api;
+        std::optional<AsnSource> asn_source;
         std::optional<Daemon> daemon;
         std::optional<Dns> dns;
         std::optional<Fwmark> fwmark;
@@ -273,6 +300,23 @@ namespace api {
         std::optional<int64_t> apply_started_ts;
         std::string message;
         ConfigUpdateResponseStatus status;
+        std::optional<std::vector<std::string>> warnings;
+    };
+
+    struct DnsUpstreamTestRequest {
+        std::string address;
+        std::optional<std::string> domain;
+        std::optional<int64_t> timeout_ms;
+    };
+
+    struct DnsUpstreamTestResponse {
+        std::string address;
+        std::string domain;
+        std::optional<std::string> error;
+        int64_t latency_ms;
+        bool ok;
+        std::optional<std::string> rcode;
+        std::vector<std::string> resolved_ips;
     };
 
     struct ValidationErrorElement {
@@ -427,6 +471,7 @@ namespace api {
         RoutingHealthResponseOverall overall;
         std::vector<PolicyRuleCheck> policy_rules;
         std::vector<RouteTableCheck> route_tables;
+        std::optional<std::vector<std::string>> warnings;
     };
 
     struct ListMatch {
@@ -434,8 +479,30 @@ namespace api {
         std::string via;
     };
 
+    struct RoutingConntrackFlowElement {
+        std::optional<int64_t> dport;
+        std::string dst;
+        int64_t mark;
+        std::optional<std::string> outbound;
+        std::string protocol;
+        std::optional<int64_t> sport;
+        std::string src;
+        std::optional<std::string> state;
+    };
+
+    struct RoutingConntrackRequest {
+        std::string ip;
+    };
+
+    struct RoutingConntrackResponse {
+        std::vector<RoutingConntrackFlowElement> flows;
+        std::string ip;
+        bool truncated;
+    };
+
     struct RoutingTestEntry {
         std::string actual_outbound;
+        std::optional<std::string> egress_interface;
         std::string expected_outbound;
         std::string ip;
         std::optional<ListMatch> list_match;
@@ -472,6 +539,29 @@ namespace api {
         std::vector<std::string> warnings;
     };
 
+    struct DnsMatchServer {
+        ListMatch list_match;
+        int64_t rule_index;
+        std::string server;
+    };
+
+    struct DnsMatchRequest {
+        std::string domain;
+    };
+
+    struct DnsMatchRouteRuleElement {
+        ListMatch list_match;
+        std::string outbound;
+        int64_t rule_index;
+    };
+
+    struct DnsMatchResponse {
+        std::optional<DnsMatchServer> dns_server;
+        std::string domain;
+        std::string outbound;
+        std::vector<DnsMatchRouteRuleElement> route_matches;
+    };
+
     enum class RuntimeInterfaceInventoryStatusEnum : int { DOWN, UP };
 
     struct RuntimeInterfaceInventoryEntry {
@@ -513,6 +603,10 @@ namespace api {
 
     enum class StatusEventInterfacesType : int { INTERFACES };
 
+    struct ServiceStopRequest {
+        std::optional<int64_t> auto_start_after_seconds;
+    };
+
     struct StatusEventInterfaces {
         RuntimeInterfaceInventoryResponse data;
         StatusEventInterfacesType type;
@@ -547,6 +641,7 @@ namespace api {
 
     struct KeenPbrTypesEFtlCp {
         std::optional<ApiConfig> api_config;
+        std::optional<AsnSource> asn_source_config;
         std::optional<CacheMetadata> cache_metadata;
         std::optional<CheckStatus> check_status;
         std::optional<CircuitBreakerConfig> circuit_breaker_config;
@@ -556,10 +651,16 @@ namespace api {
         std::optional<ConntrackOnSwitch> conntrack_on_switch;
         std::optional<Daemon> daemon_config;
         std::optional<Dns> dns_config;
+        std::optional<DnsMatchRequest> dns_match_request;
+        std::optional<DnsMatchResponse> dns_match_response;
+        std::optional<DnsMatchRouteRuleElement> dns_match_route_rule;
+        std::optional<DnsMatchServer> dns_match_server;
         std::optional<DnsRuleElement> dns_rule;
         std::optional<DnsServerElement> dns_server;
         std::optional<SystemResolver> dns_system_resolver;
         std::optional<DnsTestServer> dns_test_server;
+        std::optional<DnsUpstreamTestRequest> dns_upstream_test_request;
+        std::optional<DnsUpstreamTestResponse> dns_upstream_test_response;
         std::optional<ErrorResponse> error_response;
         std::optional<FirewallChain> firewall_chain;
         std::optional<FirewallRuleCheck> firewall_rule_check;
@@ -574,6 +675,7 @@ namespace api {
         std::optional<ListRefreshResponse> list_refresh_response;
         std::optional<ListRefreshStateValue> list_refresh_state;
         std::optional<ListsAutoupdate> lists_autoupdate_config;
+        std::optional<NexthopElement> nexthop;
         std::optional<OutboundElement> outbound;
         std::optional<OutboundGroupElement> outbound_group;
         std::optional<PolicyRuleCheck> policy_rule_check;
@@ -584,6 +686,9 @@ namespace api {
         std::optional<Route> route_config;
         std::optional<RouteRuleElement> route_rule;
         std::optional<RouteTableCheck> route_table_check;
+        std::optional<RoutingConntrackFlowElement> routing_conntrack_flow;
+        std::optional<RoutingConntrackRequest> routing_conntrack_request;
+        std::optional<RoutingConntrackResponse> routing_conntrack_response;
         std::optional<RoutingHealthErrorResponse> routing_health_error_response;
         std::optional<RoutingHealthResponse> routing_health_response;
         std::optional<RoutingTestEntry> routing_test_entry;
@@ -600,6 +705,7 @@ namespace api {
         std::optional<RuntimeOutboundsResponse> runtime_outbounds_response;
         std::optional<RuntimeOutboundStateElement> runtime_outbound_state;
         std::optional<ResolverLiveStatus> runtime_outbound_status;
+        std::optional<ServiceStopRequest> service_stop_request;
         std::optional<StatusEventInterfaces> status_event_interfaces;
         std::optional<StatusEventOutbounds> status_event_outbounds;
         std::optional<StatusEventService> status_event_service;
@@ -614,6 +720,9 @@ namespace api {
     void from_json(const json & j, ApiConfig & x);
     void to_json(json & j, const ApiConfig & x);
 
+    void from_json(const json & j, AsnSource & x);
+    void to_json(json & j, const AsnSource & x);
+
     void from_json(const json & j, CacheMetadata & x);
     void to_json(json & j, const CacheMetadata & x);
 
@@ -650,6 +759,9 @@ namespace api {
     void from_json(const json & j, ListsAutoupdate & x);
     void to_json(json & j, const ListsAutoupdate & x);
 
+    void from_json(const json & j, NexthopElement & x);
+    void to_json(json & j, const NexthopElement & x);
+
     void from_json(const json & j, OutboundGroupElement & x);
     void to_json(json & j, const OutboundGroupElement & x);
 
@@ -677,6 +789,12 @@ namespace api {
     void from_json(const json & j, ConfigUpdateResponse & x);
     void to_json(json & j, const ConfigUpdateResponse & x);
 
+    void from_json(const json & j, DnsUpstreamTestRequest & x);
+    void to_json(json & j, const DnsUpstreamTestRequest & x);
+
+    void from_json(const json & j, DnsUpstreamTestResponse & x);
+    void to_json(json & j, const DnsUpstreamTestResponse & x);
+
     void from_json(const json & j, ValidationErrorElement & x);
     void to_json(json & j, const ValidationErrorElement & x);
 
@@ -725,6 +843,15 @@ namespace api {
     void from_json(const json & j, ListMatch & x);
     void to_json(json & j, const ListMatch & x);
 
+    void from_json(const json & j, RoutingConntrackFlowElement & x);
+    void to_json(json & j, const RoutingConntrackFlowElement & x);
+
+    void from_json(const json & j, RoutingConntrackRequest & x);
+    void to_json(json & j, const RoutingConntrackRequest & x);
+
+    void from_json(const json & j, RoutingConntrackResponse & x);
+    void to_json(json & j, const RoutingConntrackResponse & x);
+
     void from_json(const json & j, RoutingTestEntry & x);
     void to_json(json & j, const RoutingTestEntry & x);
 
@@ -740,6 +867,18 @@ namespace api {
     void from_json(const json & j, RoutingTestResponse & x);
     void to_json(json & j, const RoutingTestResponse & x);
 
+    void from_json(const json & j, DnsMatchServer & x);
+    void to_json(json & j, const DnsMatchServer & x);
+
+    void from_json(const json & j, DnsMatchRequest & x);
+    void to_json(json & j, const DnsMatchRequest & x);
+
+    void from_json(const json & j, DnsMatchRouteRuleElement & x);
+    void to_json(json & j, const DnsMatchRouteRuleElement & x);
+
+    void from_json(const json & j, DnsMatchResponse & x);
+    void to_json(json & j, const DnsMatchResponse & x);
+
     void from_json(const json & j, RuntimeInterfaceInventoryEntry & x);
     void to_json(json & j, const RuntimeInterfaceInventoryEntry & x);
 
@@ -755,6 +894,9 @@ namespace api {
     void from_json(const json & j, RuntimeOutboundsResponse & x);
     void to_json(json & j, const RuntimeOutboundsResponse & x);
 
+    void from_json(const json & j, ServiceStopRequest & x);
+    void to_json(json & j, const ServiceStopRequest & x);
+
     void from_json(const json & j, StatusEventInterfaces & x);
     void to_json(json & j, const StatusEventInterfaces & x);
 
@@ -785,6 +927,9 @@ namespace api {
     void from_json(const json & j, DnsServerType & x);
     void to_json(json & j, const DnsServerType & x);
 
+    void from_json(const json & j, RoutePolicy & x);
+    void to_json(json & j, const RoutePolicy & x);
+
     void from_json(const json & j, ConntrackOnSwitch & x);
     void to_json(json & j, const ConntrackOnSwitch & x);
 
@@ -853,6 +998,7 @@ namespace api {
 
     inline void from_json(const json & j, ApiConfig& x) {
         x.enabled = get_stack_optional<bool>(j, "enabled");
+        x.keenetic_interface_cache_seconds = get_stack_optional<int64_t>(j, "keenetic_interface_cache_seconds");
         x.keep_alive_timeout_seconds = get_stack_optional<int64_t>(j, "keep_alive_timeout_seconds");
         x.listen = get_stack_optional<std::string>(j, "listen");
         x.max_request_body_bytes = get_stack_optional<int64_t>(j, "max_request_body_bytes");
@@ -863,6 +1009,7 @@ namespace api {
     inline void to_json(json & j, const ApiConfig & x) {
         j = json::object();
         j["enabled"] = x.enabled;
+        j["keenetic_interface_cache_seconds"] = x.keenetic_interface_cache_seconds;
         j["keep_alive_timeout_seconds"] = x.keep_alive_timeout_seconds;
         j["listen"] = x.listen;
         j["max_request_body_bytes"] = x.max_request_body_bytes;
@@ -870,6 +1017,17 @@ namespace api {
         j["write_timeout_seconds"] = x.write_timeout_seconds;
     }
 
+    inline void from_json(const json & j, AsnSource& x) {
+        x.file = get_stack_optional<std::string>(j, "file");
+        x.url_template = get_stack_optional<std::string>(j, "url_template");
+    }
+
+    inline void to_json(json & j, const AsnSource & x) {
+        j = json::object();
+        j["file"] = x.file;
+        j["url_template"] = x.url_template;
+    }
+
     inline void from_json(const json & j, CacheMetadata& x) {
         x.cidrs = get_stack_optional<int64_t>(j, "cidrs");
         x.domains = get_stack_optional<int64_t>(j, "domains");
@@ -907,11 +1065,13 @@ namespace api {
     }
 
     inline void from_json(const json & j, Daemon& x) {
+        x.bootstrap_dns = get_stack_optional<std::string>(j, "bootstrap_dns");
         x.cache_dir = get_stack_optional<std::string>(j, "cache_dir");
         x.clear_dynamic_sets_on_apply = get_stack_optional<bool>(j, "clear_dynamic_sets_on_apply");
         x.exec_kill_grace_seconds = get_stack_optional<int64_t>(j, "exec_kill_grace_seconds");
         x.exec_timeout_seconds = get_stack_optional<int64_t>(j, "exec_timeout_seconds");
         x.firewall_backend = get_stack_optional<DaemonConfigFirewallBackend>(j, "firewall_backend");
+        x.firewall_self_heal = get_stack_optional<bool>(j, "firewall_self_heal");
         x.firewall_verify_max_bytes = get_stack_optional<int64_t>(j, "firewall_verify_max_bytes");
         x.ipv6_enabled = get_stack_optional<bool>(j, "ipv6_enabled");
         x.max_file_size_bytes = get_stack_optional<int64_t>(j, "max_file_size_bytes");
@@ -924,11 +1084,13 @@ namespace api {
 
     inline void to_json(json & j, const Daemon & x) {
         j = json::object();
+        j["bootstrap_dns"] = x.bootstrap_dns;
         j["cache_dir"] = x.cache_dir;
         j["clear_dynamic_sets_on_apply"] = x.clear_dynamic_sets_on_apply;
         j["exec_kill_grace_seconds"] = x.exec_kill_grace_seconds;
         j["exec_timeout_seconds"] = x.exec_timeout_seconds;
         j["firewall_backend"] = x.firewall_backend;
+        j["firewall_self_heal"] = x.firewall_self_heal;
         j["firewall_verify_max_bytes"] = x.firewall_verify_max_bytes;
         j["ipv6_enabled"] = x.ipv6_enabled;
         j["max_file_size_bytes"] = x.max_file_size_bytes;
@@ -941,12 +1103,14 @@ namespace api {
 
     inline void from_json(const json & j, DnsTestServer& x) {
         x.answer_ipv4 = get_stack_optional<std::string>(j, "answer_ipv4");
+        x.domain_suffix = get_stack_optional<std::string>(j, "domain_suffix");
         x.listen = j.at("listen").get<std::string>();
     }
 
     inline void to_json(json & j, const DnsTestServer & x) {
         j = json::object();
         j["answer_ipv4"] = x.answer_ipv4;
+        j["domain_suffix"] = x.domain_suffix;
         j["listen"] = x.listen;
     }
 
@@ -991,6 +1155,7 @@ namespace api {
 
     inline void from_json(const json & j, Dns& x) {
         x.dns_test_server = get_stack_optional<DnsTestServer>(j, "dns_test_server");
+        x.edns_packet_max = get_stack_optional<int64_t>(j, "edns_packet_max");
         x.fallback = get_stack_optional<std::vector<std::string>>(j, "fallback");
         x.rules = get_stack_optional<std::vector<DnsRuleElement>>(j, "rules");
         x.servers = get_stack_optional<std::vector<DnsServerElement>>(j, "servers");
@@ -1000,6 +1165,7 @@ namespace api {
     inline void to_json(json & j, const Dns & x) {
         j = json::object();
         j["dns_test_server"] = x.dns_test_server;
+        j["edns_packet_max"] = x.edns_packet_max;
         j["fallback"] = x.fallback;
         j["rules"] = x.rules;
         j["servers"] = x.servers;
@@ -1018,12 +1184,14 @@ namespace api {
     }
 
     inline void from_json(const json & j, Iproute& x) {
+        x.route_policy = get_stack_optional<RoutePolicy>(j, "route_policy");
         x.rule_priority_start = get_stack_optional<int64_t>(j, "rule_priority_start");
         x.table_start = get_stack_optional<int64_t>(j, "table_start");
     }
 
     inline void to_json(json & j, const Iproute & x) {
         j = json::object();
+        j["route_policy"] = x.route_policy;
         j["rule_priority_start"] = x.rule_priority_start;
         j["table_start"] = x.table_start;
     }
@@ -1031,31 +1199,52 @@ namespace api {
     inline void from_json(const json & j, ListConfigValue& x) {
         x.detour = get_stack_optional<std::string>(j, "detour");
         x.domains = get_stack_optional<std::vector<std::string>>(j, "domains");
+        x.enabled = get_stack_optional<bool>(j, "enabled");
+        x.fallback_urls = get_stack_optional<std::vector<std::string>>(j, "fallback_urls");
         x.file = get_stack_optional<std::string>(j, "file");
         x.ip_cidrs = get_stack_optional<std::vector<std::string>>(j, "ip_cidrs");
+        x.sha256 = get_stack_optional<std::string>(j, "sha256");
         x.ttl_ms = get_stack_optional<int64_t>(j, "ttl_ms");
         x.url = get_stack_optional<std::string>(j, "url");
+        x.validate_content = get_stack_optional<bool>(j, "validate_content");
     }
 
     inline void to_json(json & j, const ListConfigValue & x) {
         j = json::object();
         j["detour"] = x.detour;
         j["domains"] = x.domains;
+        j["enabled"] = x.enabled;
+        j["fallback_urls"] = x.fallback_urls;
         j["file"] = x.file;
         j["ip_cidrs"] = x.ip_cidrs;
+        j["sha256"] = x.sha256;
         j["ttl_ms"] = x.ttl_ms;
         j["url"] = x.url;
+        j["validate_content"] = x.validate_content;
     }
 
     inline void from_json(const json & j, ListsAutoupdate& x) {
         x.cron = get_stack_optional<std::string>(j, "cron");
         x.enabled = get_stack_optional<bool>(j, "enabled");
+        x.force_reimport_interval_hours = get_stack_optional<int64_t>(j, "force_reimport_interval_hours");
     }
 
     inline void to_json(json & j, const ListsAutoupdate & x) {
         j = json::object();
         j["cron"] = x.cron;
         j["enabled"] = x.enabled;
+        j["force_reimport_interval_hours"] = x.force_reimport_interval_hours;
+    }
+
+    inline void from_json(const json & j, NexthopElement& x) {
+        x.outbound = j.at("outbound").get<std::string>();
+        x.weight = get_stack_optional<int64_t>(j, "weight");
+    }
+
+    inline void to_json(json & j, const NexthopElement & x) {
+        j = json::object();
+        j["outbound"] = x.outbound;
+        j["weight"] = x.weight;
     }
 
     inline void from_json(const json & j, OutboundGroupElement& x) {
@@ -1087,11 +1276,13 @@ namespace api {
         x.gateway6 = get_stack_optional<std::string>(j, "gateway6");
         x.interface = get_stack_optional<std::string>(j, "interface");
         x.interval_ms = get_stack_optional<int64_t>(j, "interval_ms");
+        x.nexthops = get_stack_optional<std::vector<NexthopElement>>(j, "nexthops");
         x.outbound_groups = get_stack_optional<std::vector<OutboundGroupElement>>(j, "outbound_groups");
         x.probe_timeout_ms = get_stack_optional<int64_t>(j, "probe_timeout_ms");
         x.retry = get_stack_optional<Retry>(j, "retry");
         x.strict_enforcement = get_stack_optional<bool>(j, "strict_enforcement");
         x.strict_enforcement_action = get_stack_optional<StrictEnforcementAction>(j, "strict_enforcement_action");
+        x.strict_enforcement_ipv6 = get_stack_optional<bool>(j, "strict_enforcement_ipv6");
         x.table = get_stack_optional<int64_t>(j, "table");
         x.tag = j.at("tag").get<std::string>();
         x.tolerance_ms = get_stack_optional<int64_t>(j, "tolerance_ms");
@@ -1107,11 +1298,13 @@ namespace api {
         j["gateway6"] = x.gateway6;
         j["interface"] = x.interface;
         j["interval_ms"] = x.interval_ms;
+        j["nexthops"] = x.nexthops;
         j["outbound_groups"] = x.outbound_groups;
         j["probe_timeout_ms"] = x.probe_timeout_ms;
         j["retry"] = x.retry;
         j["strict_enforcement"] = x.strict_enforcement;
         j["strict_enforcement_action"] = x.strict_enforcement_action;
+        j["strict_enforcement_ipv6"] = x.strict_enforcement_ipv6;
         j["table"] = x.table;
         j["tag"] = x.tag;
         j["tolerance_ms"] = x.tolerance_ms;
@@ -1124,6 +1317,7 @@ namespace api {
         x.dest_port = get_stack_optional<std::string>(j, "dest_port");
         x.dscp = get_stack_optional<int64_t>(j, "dscp");
         x.enabled = get_stack_optional<bool>(j, "enabled");
+        x.inbound_interfaces = get_stack_optional<std::vector<std::string>>(j, "inbound_interfaces");
         x.list = get_stack_optional<std::vector<std::string>>(j, "list");
         x.outbound = j.at("outbound").get<std::string>();
         x.proto = get_stack_optional<std::string>(j, "proto");
@@ -1137,6 +1331,7 @@ namespace api {
         j["dest_port"] = x.dest_port;
         j["dscp"] = x.dscp;
         j["enabled"] = x.enabled;
+        j["inbound_interfaces"] = x.inbound_interfaces;
         j["list"] = x.list;
         j["outbound"] = x.outbound;
         j["proto"] = x.proto;
@@ -1157,6 +1352,7 @@ namespace api {
 
     inline void from_json(const json & j, ConfigObject& x) {
         x.api = get_stack_optional<ApiConfig>(j, "api");
+        x.asn_source = get_stack_optional<AsnSource>(j, "asn_source");
         x.daemon = get_stack_optional<Daemon>(j, "daemon");
         x.dns = get_stack_optional<Dns>(j, "dns");
         x.fwmark = get_stack_optional<Fwmark>(j, "fwmark");
@@ -1170,6 +1366,7 @@ namespace api {
     inline void to_json(json & j, const ConfigObject & x) {
         j = json::object();
         j["api"] = x.api;
+        j["asn_source"] = x.asn_source;
         j["daemon"] = x.daemon;
         j["dns"] = x.dns;
         j["fwmark"] = x.fwmark;
@@ -1206,6 +1403,7 @@ namespace api {
         x.apply_started_ts = get_stack_optional<int64_t>(j, "apply_started_ts");
         x.message = j.at("message").get<std::string>();
         x.status = j.at("status").get<ConfigUpdateResponseStatus>();
+        x.warnings = get_stack_optional<std::vector<std::string>>(j, "warnings");
     }
 
     inline void to_json(json & j, const ConfigUpdateResponse & x) {
@@ -1213,6 +1411,41 @@ namespace api {
         j["apply_started_ts"] = x.apply_started_ts;
         j["message"] = x.message;
         j["status"] = x.status;
+        j["warnings"] = x.warnings;
+    }
+
+    inline void from_json(const json & j, DnsUpstreamTestRequest& x) {
+        x.address = j.at("address").get<std::string>();
+        x.domain = get_stack_optional<std::string>(j, "domain");
+        x.timeout_ms = get_stack_optional<int64_t>(j, "timeout_ms");
+    }
+
+    inline void to_json(json & j, const DnsUpstreamTestRequest & x) {
+        j = json::object();
+        j["address"] = x.address;
+        j["domain"] = x.domain;
+        j["timeout_ms"] = x.timeout_ms;
+    }
+
+    inline void from_json(const json & j, DnsUpstreamTestResponse& x) {
+        x.address = j.at("address").get<std::string>();
+        x.domain = j.at("domain").get<std::string>();
+        x.error = get_stack_optional<std::string>(j, "error");
+        x.latency_ms = j.at("latency_ms").get<int64_t>();
+        x.ok = j.at("ok").get<bool>();
+        x.rcode = get_stack_optional<std::string>(j, "rcode");
+        x.resolved_ips = j.at("resolved_ips").get<std::vector<std::string>>();
+    }
+
+    inline void to_json(json & j, const DnsUpstreamTestResponse & x) {
+        j = json::object();
+        j["address"] = x.address;
+        j["domain"] = x.domain;
+        j["error"] = x.error;
+        j["latency_ms"] = x.latency_ms;
+        j["ok"] = x.ok;
+        j["rcode"] = x.rcode;
+        j["resolved_ips"] = x.resolved_ips;
     }
 
     inline void from_json(const json & j, ValidationErrorElement& x) {
@@ -1474,6 +1707,7 @@ namespace api {
         x.overall = j.at("overall").get<RoutingHealthResponseOverall>();
         x.policy_rules = j.at("policy_rules").get<std::vector<PolicyRuleCheck>>();
         x.route_tables = j.at("route_tables").get<std::vector<RouteTableCheck>>();
+        x.warnings = get_stack_optional<std::vector<std::string>>(j, "warnings");
     }
 
     inline void to_json(json & j, const RoutingHealthResponse & x) {
@@ -1484,6 +1718,7 @@ namespace api {
         j["overall"] = x.overall;
         j["policy_rules"] = x.policy_rules;
         j["route_tables"] = x.route_tables;
+        j["warnings"] = x.warnings;
     }
 
     inline void from_json(const json & j, ListMatch& x) {
@@ -1497,8 +1732,54 @@ namespace api {
         j["via"] = x.via;
     }
 
+    inline void from_json(const json & j, RoutingConntrackFlowElement& x) {
+        x.dport = get_stack_optional<int64_t>(j, "dport");
+        x.dst = j.at("dst").get<std::string>();
+        x.mark = j.at("mark").get<int64_t>();
+        x.outbound = get_stack_optional<std::string>(j, "outbound");
+        x.protocol = j.at("protocol").get<std::string>();
+        x.sport = get_stack_optional<int64_t>(j, "sport");
+        x.src = j.at("src").get<std::string>();
+        x.state = get_stack_optional<std::string>(j, "state");
+    }
+
+    inline void to_json(json & j, const RoutingConntrackFlowElement & x) {
+        j = json::object();
+        j["dport"] = x.dport;
+        j["dst"] = x.dst;
+        j["mark"] = x.mark;
+        j["outbound"] = x.outbound;
+        j["protocol"] = x.protocol;
+        j["sport"] = x.sport;
+        j["src"] = x.src;
+        j["state"] = x.state;
+    }
+
+    inline void from_json(const json & j, RoutingConntrackRequest& x) {
+        x.ip = j.at("ip").get<std::string>();
+    }
+
+    inline void to_json(json & j, const RoutingConntrackRequest & x) {
+        j = json::object();
+        j["ip"] = x.ip;
+    }
+
+    inline void from_json(const json & j, RoutingConntrackResponse& x) {
+        x.flows = j.at("flows").get<std::vector<RoutingConntrackFlowElement>>();
+        x.ip = j.at("ip").get<std::string>();
+        x.truncated = j.at("truncated").get<bool>();
+    }
+
+    inline void to_json(json & j, const RoutingConntrackResponse & x) {
+        j = json::object();
+        j["flows"] = x.flows;
+        j["ip"] = x.ip;
+        j["truncated"] = x.truncated;
+    }
+
     inline void from_json(const json & j, RoutingTestEntry& x) {
         x.actual_outbound = j.at("actual_outbound").get<std::string>();
+        x.egress_interface = get_stack_optional<std::string>(j, "egress_interface");
         x.expected_outbound = j.at("expected_outbound").get<std::string>();
         x.ip = j.at("ip").get<std::string>();
         x.list_match = get_stack_optional<ListMatch>(j, "list_match");
@@ -1508,6 +1789,7 @@ namespace api {
     inline void to_json(json & j, const RoutingTestEntry & x) {
         j = json::object();
         j["actual_outbound"] = x.actual_outbound;
+        j["egress_interface"] = x.egress_interface;
         j["expected_outbound"] = x.expected_outbound;
         j["ip"] = x.ip;
         j["list_match"] = x.list_match;
@@ -1578,6 +1860,56 @@ namespace api {
         j["warnings"] = x.warnings;
     }
 
+    inline void from_json(const json & j, DnsMatchServer& x) {
+        x.list_match = j.at("list_match").get<ListMatch>();
+        x.rule_index = j.at("rule_index").get<int64_t>();
+        x.server = j.at("server").get<std::string>();
+    }
+
+    inline void to_json(json & j, const DnsMatchServer & x) {
+        j = json::object();
+        j["list_match"] = x.list_match;
+        j["rule_index"] = x.rule_index;
+        j["server"] = x.server;
+    }
+
+    inline void from_json(const json & j, DnsMatchRequest& x) {
+        x.domain = j.at("domain").get<std::string>();
+    }
+
+    inline void to_json(json & j, const DnsMatchRequest & x) {
+        j = json::object();
+        j["domain"] = x.domain;
+    }
+
+    inline void from_json(const json & j, DnsMatchRouteRuleElement& x) {
+        x.list_match = j.at("list_match").get<ListMatch>();
+        x.outbound = j.at("outbound").get<std::string>();
+        x.rule_index = j.at("rule_index").get<int64_t>();
+    }
+
+    inline void to_json(json & j, const DnsMatchRouteRuleElement & x) {
+        j = json::object();
+        j["list_match"] = x.list_match;
+        j["outbound"] = x.outbound;
+        j["rule_index"] = x.rule_index;
+    }
+
+    inline void from_json(const json & j, DnsMatchResponse& x) {
+        x.dns_server = get_stack_optional<DnsMatchServer>(j, "dns_server");
+        x.domain = j.at("domain").get<std::string>();
+        x.outbound = j.at("outbound").get<std::string>();
+        x.route_matches = j.at("route_matches").get<std::vector<DnsMatchRouteRuleElement>>();
+    }
+
+    inline void to_json(json & j, const DnsMatchResponse & x) {
+        j = json::object();
+        j["dns_server"] = x.dns_server;
+        j["domain"] = x.domain;
+        j["outbound"] = x.outbound;
+        j["route_matches"] = x.route_matches;
+    }
+
     inline void from_json(const json & j, RuntimeInterfaceInventoryEntry& x) {
         x.admin_up = get_stack_optional<bool>(j, "admin_up");
         x.carrier = get_stack_optional<bool>(j, "carrier");
@@ -1653,6 +1985,15 @@ namespace api {
         j["outbounds"] = x.outbounds;
     }
 
+    inline void from_json(const json & j, ServiceStopRequest& x) {
+        x.auto_start_after_seconds = get_stack_optional<int64_t>(j, "auto_start_after_seconds");
+    }
+
+    inline void to_json(json & j, const ServiceStopRequest & x) {
+        j = json::object();
+        j["auto_start_after_seconds"] = x.auto_start_after_seconds;
+    }
+
     inline void from_json(const json & j, StatusEventInterfaces& x) {
         x.data = j.at("data").get<RuntimeInterfaceInventoryResponse>();
         x.type = j.at("type").get<StatusEventInterfacesType>();
@@ -1712,6 +2053,7 @@ namespace api {
 
     inline void from_json(const json & j, KeenPbrTypesEFtlCp& x) {
         x.api_config = get_stack_optional<ApiConfig>(j, "ApiConfig");
+        x.asn_source_config = get_stack_optional<AsnSource>(j, "AsnSourceConfig");
         x.cache_metadata = get_stack_optional<CacheMetadata>(j, "CacheMetadata");
         x.check_status = get_stack_optional<CheckStatus>(j, "CheckStatus");
         x.circuit_breaker_config = get_stack_optional<CircuitBreakerConfig>(j, "CircuitBreakerConfig");
@@ -1721,10 +2063,16 @@ namespace api {
         x.conntrack_on_switch = get_stack_optional<ConntrackOnSwitch>(j, "ConntrackOnSwitch");
         x.daemon_config = get_stack_optional<Daemon>(j, "DaemonConfig");
         x.dns_config = get_stack_optional<Dns>(j, "DnsConfig");
+        x.dns_match_request = get_stack_optional<DnsMatchRequest>(j, "DnsMatchRequest");
+        x.dns_match_response = get_stack_optional<DnsMatchResponse>(j, "DnsMatchResponse");
+        x.dns_match_route_rule = get_stack_optional<DnsMatchRouteRuleElement>(j, "DnsMatchRouteRule");
+        x.dns_match_server = get_stack_optional<DnsMatchServer>(j, "DnsMatchServer");
         x.dns_rule = get_stack_optional<DnsRuleElement>(j, "DnsRule");
         x.dns_server = get_stack_optional<DnsServerElement>(j, "DnsServer");
         x.dns_system_resolver = get_stack_optional<SystemResolver>(j, "DnsSystemResolver");
         x.dns_test_server = get_stack_optional<DnsTestServer>(j, "DnsTestServer");
+        x.dns_upstream_test_request = get_stack_optional<DnsUpstreamTestRequest>(j, "DnsUpstreamTestRequest");
+        x.dns_upstream_test_response = get_stack_optional<DnsUpstreamTestResponse>(j, "DnsUpstreamTestResponse");
         x.error_response = get_stack_optional<ErrorResponse>(j, "ErrorResponse");
         x.firewall_chain = get_stack_optional<FirewallChain>(j, "FirewallChain");
         x.firewall_rule_check = get_stack_optional<FirewallRuleCheck>(j, "FirewallRuleCheck");
@@ -1739,6 +2087,7 @@ namespace api {
         x.list_refresh_response = get_stack_optional<ListRefreshResponse>(j, "ListRefreshResponse");
         x.list_refresh_state = get_stack_optional<ListRefreshStateValue>(j, "ListRefreshState");
         x.lists_autoupdate_config = get_stack_optional<ListsAutoupdate>(j, "ListsAutoupdateConfig");
+        x.nexthop = get_stack_optional<NexthopElement>(j, "Nexthop");
         x.outbound = get_stack_optional<OutboundElement>(j, "Outbound");
         x.outbound_group = get_stack_optional<OutboundGroupElement>(j, "OutboundGroup");
         x.policy_rule_check = get_stack_optional<PolicyRuleCheck>(j, "PolicyRuleCheck");
@@ -1749,6 +2098,9 @@ namespace api {
         x.route_config = get_stack_optional<Route>(j, "RouteConfig");
         x.route_rule = get_stack_optional<RouteRuleElement>(j, "RouteRule");
         x.route_table_check = get_stack_optional<RouteTableCheck>(j, "RouteTableCheck");
+        x.routing_conntrack_flow = get_stack_optional<RoutingConntrackFlowElement>(j, "RoutingConntrackFlow");
+        x.routing_conntrack_request = get_stack_optional<RoutingConntrackRequest>(j, "RoutingConntrackRequest");
+        x.routing_conntrack_response = get_stack_optional<RoutingConntrackResponse>(j, "RoutingConntrackResponse");
         x.routing_health_error_response = get_stack_optional<RoutingHealthErrorResponse>(j, "RoutingHealthErrorResponse");
         x.routing_health_response = get_stack_optional<RoutingHealthResponse>(j, "RoutingHealthResponse");
         x.routing_test_entry = get_stack_optional<RoutingTestEntry>(j, "RoutingTestEntry");
@@ -1765,6 +2117,7 @@ namespace api {
         x.runtime_outbounds_response = get_stack_optional<RuntimeOutboundsResponse>(j, "RuntimeOutboundsResponse");
         x.runtime_outbound_state = get_stack_optional<RuntimeOutboundStateElement>(j, "RuntimeOutboundState");
         x.runtime_outbound_status = get_stack_optional<ResolverLiveStatus>(j, "RuntimeOutboundStatus");
+        x.service_stop_request = get_stack_optional<ServiceStopRequest>(j, "ServiceStopRequest");
         x.status_event_interfaces = get_stack_optional<StatusEventInterfaces>(j, "StatusEventInterfaces");
         x.status_event_outbounds = get_stack_optional<StatusEventOutbounds>(j, "StatusEventOutbounds");
         x.status_event_service = get_stack_optional<StatusEventService>(j, "StatusEventService");
@@ -1775,6 +2128,7 @@ namespace api {
     inline void to_json(json & j, const KeenPbrTypesEFtlCp & x) {
         j = json::object();
         j["ApiConfig"] = x.api_config;
+        j["AsnSourceConfig"] = x.asn_source_config;
         j["CacheMetadata"] = x.cache_metadata;
         j["CheckStatus"] = x.check_status;
         j["CircuitBreakerConfig"] = x.circuit_breaker_config;
@@ -1784,10 +2138,16 @@ namespace api {
         j["ConntrackOnSwitch"] = x.conntrack_on_switch;
         j["DaemonConfig"] = x.daemon_config;
         j["DnsConfig"] = x.dns_config;
+        j["DnsMatchRequest"] = x.dns_match_request;
+        j["DnsMatchResponse"] = x.dns_match_response;
+        j["DnsMatchRouteRule"] = x.dns_match_route_rule;
+        j["DnsMatchServer"] = x.dns_match_server;
         j["DnsRule"] = x.dns_rule;
         j["DnsServer"] = x.dns_server;
         j["DnsSystemResolver"] = x.dns_system_resolver;
         j["DnsTestServer"] = x.dns_test_server;
+        j["DnsUpstreamTestRequest"] = x.dns_upstream_test_request;
+        j["DnsUpstreamTestResponse"] = x.dns_upstream_test_response;
         j["ErrorResponse"] = x.error_response;
         j["FirewallChain"] = x.firewall_chain;
         j["FirewallRuleCheck"] = x.firewall_rule_check;
@@ -1802,6 +2162,7 @@ namespace api {
         j["ListRefreshResponse"] = x.list_refresh_response;
         j["ListRefreshState"] = x.list_refresh_state;
         j["ListsAutoupdateConfig"] = x.lists_autoupdate_config;
+        j["Nexthop"] = x.nexthop;
         j["Outbound"] = x.outbound;
         j["OutboundGroup"] = x.outbound_group;
         j["PolicyRuleCheck"] = x.policy_rule_check;
@@ -1812,6 +2173,9 @@ namespace api {
         j["RouteConfig"] = x.route_config;
         j["RouteRule"] = x.route_rule;
         j["RouteTableCheck"] = x.route_table_check;
+        j["RoutingConntrackFlow"] = x.routing_conntrack_flow;
+        j["RoutingConntrackRequest"] = x.routing_conntrack_request;
+        j["RoutingConntrackResponse"] = x.routing_conntrack_response;
         j["RoutingHealthErrorResponse"] = x.routing_health_error_response;
         j["RoutingHealthResponse"] = x.routing_health_response;
         j["RoutingTestEntry"] = x.routing_test_entry;
@@ -1828,6 +2192,7 @@ namespace api {
         j["RuntimeOutboundsResponse"] = x.runtime_outbounds_response;
         j["RuntimeOutboundState"] = x.runtime_outbound_state;
         j["RuntimeOutboundStatus"] = x.runtime_outbound_status;
+        j["ServiceStopRequest"] = x.service_stop_request;
         j["StatusEventInterfaces"] = x.status_event_interfaces;
         j["StatusEventOutbounds"] = x.status_event_outbounds;
         j["StatusEventService"] = x.status_event_service;
@@ -1895,6 +2260,20 @@ namespace api {
         }
     }
 
+    inline void from_json(const json & j, RoutePolicy & x) {
+        if (j == "metric") x = RoutePolicy::METRIC;
+        else if (j == "replace") x = RoutePolicy::REPLACE;
+        else { throw std::runtime_error("Cannot deserialize to enumeration \"RoutePolicy\""); }
+    }
+
+    inline void to_json(json & j, const RoutePolicy & x) {
+        switch (x) {
+            case RoutePolicy::METRIC: j = "metric"; break;
+            case RoutePolicy::REPLACE: j = "replace"; break;
+            default: throw std::runtime_error("Unexpected value in enumeration \"RoutePolicy\": " + std::to_string(static_cast<int>(x)));
+        }
+    }
+
     inline void from_json(const json & j, ConntrackOnSwitch & x) {
         if (j == "delete") x = ConntrackOnSwitch::DELETE;
         else if (j == "preserve") x = ConntrackOnSwitch::PRESERVE;
@@ -1913,6 +2292,7 @@ namespace api {
         if (j == "blackhole") x = OutboundType::BLACKHOLE;
         else if (j == "ignore") x = OutboundType::IGNORE;
         else if (j == "interface") x = OutboundType::INTERFACE;
+        else if (j == "multipath") x = OutboundType::MULTIPATH;
         else if (j == "table") x = OutboundType::TABLE;
         else if (j == "urltest") x = OutboundType::URLTEST;
         else { throw std::runtime_error("Cannot deserialize to enumeration \"OutboundType\""); }
@@ -1923,6 +2303,7 @@ namespace api {
             case OutboundType::BLACKHOLE: j = "blackhole"; break;
             case OutboundType::IGNORE: j = "ignore"; break;
             case OutboundType::INTERFACE: j = "interface"; break;
+            case OutboundType::MULTIPATH: j = "multipath"; break;
             case OutboundType::TABLE: j = "table"; break;
             case OutboundType::URLTEST: j = "urltest"; break;
             default: throw std::runtime_error("Unexpected value in enumeration \"OutboundType\": " + std::to_string(static_cast<int>(x)));
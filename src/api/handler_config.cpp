@@ -4,10 +4,14 @@
 #include "generated/api_types.hpp"
 
 #include "../config/config.hpp"
+#include "../config/config_comments.hpp"
+#include "../config/config_import.hpp"
+#include "../config/effective_config.hpp"
 #include <nlohmann/json.hpp>
 
 #include <functional>
 #include <string>
+#include <vector>
 
 namespace keen_pbr3 {
 
@@ -28,6 +32,34 @@ nlohmann::json make_validation_error_json(const ConfigValidationError& error) {
     };
 }
 
+// Run a config parse/validate step; every failure becomes a 400 response
+// listing the validation errors.
+template <typename Fn>
+auto with_config_errors_as_400(Fn&& fn) -> decltype(fn()) {
+    try {
+        return fn();
+    } catch (const ConfigValidationError& e) {
+        throw ApiError(e.what(), 400, make_validation_error_json(e).dump());
+    } catch (const ConfigError& e) {
+        nlohmann::json payload = {
+            {"error", e.what()},
+            {"validation_errors", nlohmann::json::array({
+                {{"path", "$"}, {"message", e.what()}},
+            })},
+        };
+        throw ApiError(e.what(), 400, payload.dump());
+    }
+}
+
+// Parse and validate a request body.
+Config parse_request_config(const std::string& body) {
+    return with_config_errors_as_400([&body] {
+        Config config = parse_config(body);
+        validate_config(config);
+        return config;
+    });
+}
+
 Config normalize_config_for_api_response(Config config) {
     if (!config.daemon.has_value()) {
         config.daemon = DaemonConfig{};
@@ -88,30 +120,27 @@ void register_config_handler(ApiServer& server, ApiContext& ctx) {
         return response.dump();
     });
 
+    // GET /api/config/effective - visible config with defaults resolved and secrets redacted
+    server.get("/api/config/effective", [&ctx]() -> std::string {
+        return dump_effective_config(ctx.get_visible_config());
+    });
+
     // POST /api/config - validate and stage in memory only
     server.post("/api/config", [&ctx](const std::string& body) -> std::string {
-        Config staged;
-        try {
-            staged = parse_config(body);
-            validate_config(staged);
-        } catch (const ConfigValidationError& e) {
-            throw ApiError(e.what(), 400, make_validation_error_json(e).dump());
-        } catch (const ConfigError& e) {
-            nlohmann::json payload = {
-                {"error", e.what()},
-                {"validation_errors", nlohmann::json::array({
-                    {{"path", "$"}, {"message", e.what()}},
-                })},
-            };
-            throw ApiError(e.what(), 400, payload.dump());
+        Config staged = parse_request_config(body);
+        std::vector<std::string> warnings;
+        for (const auto& warning : collect_config_warnings(staged)) {
+            warnings.push_back(warning.message);
         }
 
-        std::string formatted_config = serialize_config_pretty(staged);
+        std::string formatted_config = preserve_config_comments(
+            export_config_text(ctx.config_path), serialize_config_pretty(staged));
         ctx.stage_config(std::move(staged), std::move(formatted_config));
 
         api::ConfigUpdateResponse resp;
         resp.status = api::ConfigUpdateResponseStatus::OK;
         resp.message = "Config staged in memory";
+        if (!warnings.empty()) resp.warnings = std::move(warnings);
         return nlohmann::json(resp).dump();
     });
 
@@ -130,6 +159,31 @@ void register_config_handler(ApiServer& server, ApiContext& ctx) {
         const std::string operation_id = ctx.submit_lifecycle_operation(std::move(request));
         throw ApiAccepted(nlohmann::json{{"operation_id", operation_id}, {"status", "accepted"}}.dump());
     });
+
+    // GET /api/config/export - the config file exactly as stored on disk
+    server.get("/api/config/export", [&ctx]() -> std::string {
+        std::string text = export_config_text(ctx.config_path);
+        if (text.empty()) {
+            throw ApiError("Cannot read config file " + ctx.config_path, 500);
+        }
+        return text;
+    });
+
+    // POST /api/config/import - replace the whole config file and apply it.
+    // Invalid configs are rejected before anything is staged or written.
+    server.post("/api/config/import", [&ctx](const std::string& body) -> std::string {
+        const std::string operation_id = with_config_errors_as_400([&] {
+            return import_config(body, [&ctx](Config config, std::string text) {
+                LifecycleRequest request;
+                request.type = LifecycleOperationType::ApplyConfig;
+                request.config = std::move(config);
+                request.serialized_config = std::move(text);
+                request.backup_config = true;
+                return ctx.submit_lifecycle_operation(std::move(request));
+            });
+        });
+        throw ApiAccepted(nlohmann::json{{"operation_id", operation_id}, {"status", "accepted"}}.dump());
+    });
 }
 
 } // namespace keen_pbr3
@@ -2,8 +2,11 @@
 
 #include "handler_dns_test.hpp"
 
+#include "../dns/dns_upstream_probe.hpp"
 #include "../log/logger.hpp"
+#include "generated/api_types.hpp"
 
+#include <algorithm>
 #include <httplib.h>
 #include <nlohmann/json.hpp>
 
@@ -11,6 +14,16 @@ namespace keen_pbr3 {
 
 namespace {
 
+constexpr const char* kDefaultUpstreamTestDomain = "example.com";
+constexpr int64_t kDefaultUpstreamTestTimeoutMs = 3000;
+constexpr int64_t kMinUpstreamTestTimeoutMs = 100;
+constexpr int64_t kMaxUpstreamTestTimeoutMs = 10000;
+
+[[noreturn]] void throw_bad_request(const std::string& message) {
+    nlohmann::json payload = {{"error", message}};
+    throw ApiError(message, 400, payload.dump());
+}
+
 std::string make_sse_frame(const std::string& payload) {
     return "data: " + payload + "\n\n";
 }
@@ -71,6 +84,42 @@ void register_dns_test_handler(ApiServer& server, ApiContext& ctx) {
                 ctx.dns_test_broadcaster.unsubscribe(subscription);
             });
     });
+
+    server.post("/api/dns/test-upstream", [](const std::string& body) -> std::string {
+        api::DnsUpstreamTestRequest req;
+        try {
+            api::from_json(nlohmann::json::parse(body), req);
+        } catch (const std::exception&) {
+            throw_bad_request("Invalid request body");
+        }
+
+        if (req.address.empty()) {
+            throw_bad_request("Field 'address' must not be empty");
+        }
+        const std::string domain = req.domain.value_or(kDefaultUpstreamTestDomain);
+        if (domain.empty()) {
+            throw_bad_request("Field 'domain' must not be empty");
+        }
+        const int64_t timeout_ms = std::clamp(req.timeout_ms.value_or(kDefaultUpstreamTestTimeoutMs),
+                                              kMinUpstreamTestTimeoutMs,
+                                              kMaxUpstreamTestTimeoutMs);
+
+        const DnsUpstreamProbeResult result =
+            probe_dns_upstream(req.address, domain, std::chrono::milliseconds(timeout_ms));
+
+        api::DnsUpstreamTestResponse resp;
+        resp.address = req.address;
+        resp.domain = domain;
+        resp.ok = result.ok;
+        resp.latency_ms = result.latency_ms;
+        resp.resolved_ips = result.resolved_ips;
+        if (!result.rcode.empty()) resp.rcode = result.rcode;
+        if (!result.error.empty()) resp.error = result.error;
+
+        nlohmann::json j;
+        api::to_json(j, resp);
+        return j.dump();
+    });
 }
 
 } // namespace keen_pbr3
@@ -45,6 +45,8 @@ inline nlohmann::json outbound_to_json(const Outbound& ob, const OutboundMarkMap
     } else if (ob.type == OutboundType::URLTEST) {
         if (ob.url)         j["url"]         = *ob.url;
         if (ob.interval_ms) j["interval_ms"] = *ob.interval_ms;
+    } else if (ob.type == OutboundType::MULTIPATH) {
+        if (ob.nexthops) j["nexthops"] = *ob.nexthops;
     }
 
     auto mark_it = marks.find(ob.tag);
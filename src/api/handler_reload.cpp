@@ -2,9 +2,15 @@
 
 #include "handler_reload.hpp"
 #include "generated/api_types.hpp"
+#include "../daemon/auto_reapply.hpp"
 
 #include <nlohmann/json.hpp>
 
+#include <chrono>
+#include <cstdint>
+#include <optional>
+#include <utility>
+
 namespace keen_pbr3 {
 
 namespace {
@@ -15,20 +21,58 @@ std::string success_response(const std::string& message) {
     return nlohmann::json(resp).dump();
 }
 
-std::string start_lifecycle(ApiContext& ctx, LifecycleOperationType type) {
-    const std::string id = ctx.submit_lifecycle_operation(LifecycleRequest{.type = type});
+std::string start_lifecycle(ApiContext& ctx, LifecycleRequest request) {
+    const std::string id = ctx.submit_lifecycle_operation(std::move(request));
     throw ApiAccepted(nlohmann::json{{"operation_id", id}, {"status", "accepted"}}.dump());
 }
 
+std::string start_lifecycle(ApiContext& ctx, LifecycleOperationType type) {
+    return start_lifecycle(ctx, LifecycleRequest{.type = type});
+}
+
 } // namespace
 
+std::optional<std::chrono::seconds> parse_auto_start_after(const std::string& body) {
+    if (body.empty()) {
+        return std::nullopt;
+    }
+
+    nlohmann::json payload;
+    try {
+        payload = nlohmann::json::parse(body);
+    } catch (const nlohmann::json::exception&) {
+        throw ApiError("Invalid request body", 400);
+    }
+    if (payload.is_null()) {
+        return std::nullopt;
+    }
+    if (!payload.is_object()) {
+        throw ApiError("Invalid request body", 400);
+    }
+
+    const auto it = payload.find("auto_start_after_seconds");
+    if (it == payload.end() || it->is_null()) {
+        return std::nullopt;
+    }
+    if (!it->is_number_integer()) {
+        throw ApiError("Field 'auto_start_after_seconds' must be an integer", 400);
+    }
+    const auto seconds = it->get<std::int64_t>();
+    if (seconds < 1 || seconds > kMaxAutoReapplyAfter.count()) {
+        throw ApiError("Field 'auto_start_after_seconds' must be between 1 and 86400", 400);
+    }
+    return std::chrono::seconds(seconds);
+}
+
 void register_reload_handler(ApiServer& server, ApiContext& ctx) {
     server.post("/api/service/start", [&ctx]() -> std::string {
         return start_lifecycle(ctx, LifecycleOperationType::Start);
     });
 
-    server.post("/api/service/stop", [&ctx]() -> std::string {
-        return start_lifecycle(ctx, LifecycleOperationType::Stop);
+    server.post("/api/service/stop", [&ctx](const std::string& body) -> std::string {
+        LifecycleRequest request{.type = LifecycleOperationType::Stop};
+        request.auto_start_after = parse_auto_start_after(body);
+        return start_lifecycle(ctx, std::move(request));
     });
 
     server.post("/api/service/restart", [&ctx]() -> std::string {
@@ -5,8 +5,17 @@
 #include "handlers.hpp"
 #include "server.hpp"
 
+#include <chrono>
+#include <optional>
+#include <string>
+
 namespace keen_pbr3 {
 
+// Delay requested by a POST /api/service/stop body ({"auto_start_after_seconds":
+// N}); nullopt for an empty body or when the field is absent. Throws
+// ApiError(400) for malformed JSON or a value outside 1..86400.
+std::optional<std::chrono::seconds> parse_auto_start_after(const std::string& body);
+
 void register_reload_handler(ApiServer& server, ApiContext& ctx);
 
 } // namespace keen_pbr3
@@ -2,8 +2,11 @@
 
 #include "handler_test_routing.hpp"
 #include "../cmd/test_routing.hpp"
+#include "../runtime/conntrack_manager.hpp"
 #include "generated/api_types.hpp"
 
+#include <arpa/inet.h>
+#include <netinet/in.h>
 #include <nlohmann/json.hpp>
 
 namespace keen_pbr3 {
@@ -46,6 +49,7 @@ void register_test_routing_handler(ApiServer& server, ApiContext& ctx) {
             e.ip                = entry.ip;
             e.expected_outbound = entry.expected_outbound;
             e.actual_outbound   = entry.actual_outbound;
+            e.egress_interface  = entry.egress_interface;
             e.ok                = entry.ok;
             if (entry.list_match) {
                 api::ListMatch lm;
@@ -82,6 +86,103 @@ void register_test_routing_handler(ApiServer& server, ApiContext& ctx) {
         api::to_json(out, resp);
         return out.dump();
     });
+
+    server.post("/api/dns/match", [&ctx](const std::string& body) -> std::string {
+        api::DnsMatchRequest req;
+        try {
+            api::from_json(nlohmann::json::parse(body), req);
+        } catch (const std::exception&) {
+            nlohmann::json payload = {{"error", "Invalid request body"}};
+            throw ApiError("Invalid request body", 400, payload.dump());
+        }
+
+        if (req.domain.empty()) {
+            nlohmann::json payload = {{"error", "Field 'domain' must not be empty"}};
+            throw ApiError("Field 'domain' must not be empty", 400, payload.dump());
+        }
+
+        const auto result = ctx.compute_domain_match(req.domain);
+
+        api::DnsMatchResponse resp;
+        resp.domain = result.domain;
+        resp.outbound = result.outbound;
+        for (const auto& match : result.route_matches) {
+            api::DnsMatchRouteRuleElement rm;
+            rm.rule_index = match.rule_index;
+            rm.outbound = match.outbound;
+            rm.list_match.list = match.list_match.list_name;
+            rm.list_match.via = match.list_match.via;
+            resp.route_matches.push_back(std::move(rm));
+        }
+        if (result.dns_rule_match) {
+            api::DnsMatchServer server_match;
+            server_match.rule_index = result.dns_rule_match->rule_index;
+            server_match.server = result.dns_rule_match->server;
+            server_match.list_match.list = result.dns_rule_match->list_match.list_name;
+            server_match.list_match.via = result.dns_rule_match->list_match.via;
+            resp.dns_server = std::move(server_match);
+        }
+
+        nlohmann::json out;
+        api::to_json(out, resp);
+        return out.dump();
+    });
+
+    server.post("/api/routing/conntrack", [&ctx](const std::string& body) -> std::string {
+        api::RoutingConntrackRequest req;
+        try {
+            api::from_json(nlohmann::json::parse(body), req);
+        } catch (const std::exception&) {
+            nlohmann::json payload = {{"error", "Invalid request body"}};
+            throw ApiError("Invalid request body", 400, payload.dump());
+        }
+
+        in6_addr addr{};
+        if (inet_pton(AF_INET, req.ip.c_str(), &addr) != 1 &&
+            inet_pton(AF_INET6, req.ip.c_str(), &addr) != 1) {
+            nlohmann::json payload = {{"error", "Field 'ip' must be an IP address"}};
+            throw ApiError("Field 'ip' must be an IP address", 400, payload.dump());
+        }
+
+        const auto flows = ConntrackManager{}.list_flows_to(req.ip);
+        if (!flows.has_value()) {
+            nlohmann::json payload = {{"error", "conntrack -L failed; is conntrack installed?"}};
+            throw ApiError("conntrack -L failed", 503, payload.dump());
+        }
+
+        const Config config = ctx.get_visible_config();
+        const auto fwmark_cfg = config.fwmark.value_or(FwmarkConfig{});
+        const auto outbounds = config.outbounds.value_or(std::vector<Outbound>{});
+        const auto marks = allocate_outbound_marks(fwmark_cfg, outbounds);
+        const uint32_t mask = fwmark_mask_value(fwmark_cfg);
+
+        api::RoutingConntrackResponse resp;
+        resp.ip = req.ip;
+        resp.truncated = flows->truncated;
+        for (const auto& flow : flows->flows) {
+            api::RoutingConntrackFlowElement f;
+            f.protocol = flow.protocol;
+            if (!flow.state.empty()) f.state = flow.state;
+            f.src = flow.src;
+            f.dst = flow.dst;
+            if (flow.sport) f.sport = *flow.sport;
+            if (flow.dport) f.dport = *flow.dport;
+            f.mark = flow.mark;
+            for (const auto& outbound : outbounds) {
+                const auto mark = marks.find(outbound.tag);
+                if (mark != marks.end() && mark->second != 0 &&
+                    (flow.mark & mask) == mark->second) {
+                    f.outbound = outbound.tag;
+                    break;
+                }
+            }
+            resp.flows.push_back(std::move(f));
+        }
+
+        nlohmann::json out;
+        api::to_json(out, resp);
+        return out.dump();
+    });
 }
 
 } // namespace keen_pbr3
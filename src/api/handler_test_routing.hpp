@@ -10,6 +10,16 @@ namespace keen_pbr3 {
 // POST /api/routing/test
 // Body: { "target": "<ip-or-domain>" }
 // Returns JSON with expected/actual outbound per resolved IP.
+//
+// POST /api/dns/match
+// Body: { "domain": "<domain>" }
+// Returns the route and DNS rules whose lists contain the domain, without
+// resolving it.
+//
+// POST /api/routing/conntrack
+// Body: { "ip": "<ip>" }
+// Returns the conntrack flows to the IP with their marks and the outbound
+// each mark belongs to.
 void register_test_routing_handler(ApiServer& server, ApiContext& ctx);
 
 } // namespace keen_pbr3
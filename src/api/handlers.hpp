@@ -12,6 +12,7 @@
 #include "../runtime/lifecycle_operation.hpp"
 #include "server.hpp"
 
+#include <chrono>
 #include <cstdint>
 #include <functional>
 #include <optional>
@@ -38,6 +39,11 @@ struct LifecycleRequest {
     LifecycleOperationType type{LifecycleOperationType::Restart};
     std::optional<Config> config;
     std::string serialized_config;
+    // Copy the on-disk config to `<config_path>.bak` before replacing it.
+    bool backup_config{false};
+    // Stop only: start the runtime again after this delay unless another
+    // lifecycle operation is submitted first.
+    std::optional<std::chrono::seconds> auto_start_after;
 };
 
 struct ServiceHealthState {
@@ -85,6 +91,7 @@ struct ApiContext {
     std::function<std::map<std::string, api::ListRefreshStateValue>(const Config&)>
         get_list_refresh_state_map_fn;
     std::function<TestRoutingResult(const std::string&)> compute_test_routing_fn;
+    std::function<DomainMatchResult(const std::string&)> compute_domain_match_fn;
 
     std::function<void()> begin_save_operation_fn;
     std::function<void()> finish_config_operation_fn;
@@ -154,6 +161,10 @@ struct ApiContext {
         return compute_test_routing_fn(target);
     }
 
+    DomainMatchResult compute_domain_match(const std::string& domain) const {
+        return compute_domain_match_fn(domain);
+    }
+
     void begin_save_operation() const {
         begin_save_operation_fn();
     }
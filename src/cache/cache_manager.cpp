@@ -1,9 +1,17 @@
 #include "cache_manager.hpp"
 
+#include "../crypto/sha256.hpp"
+#include "../dns/dns_upstream_probe.hpp"
+
+#include <algorithm>
+#include <arpa/inet.h>
+#include <cctype>
 #include <chrono>
 #include <fstream>
 #include <iterator>
+#include <netinet/in.h>
 #include <nlohmann/json.hpp>
+#include <stdexcept>
 #include <string_view>
 #include <utility>
 
@@ -39,6 +47,67 @@ std::string clean_download_error_message(const std::exception& error) {
     return message;
 }
 
+constexpr std::chrono::milliseconds kBootstrapDnsTimeout{3000};
+
+struct UrlHostPort {
+    std::string host;
+    std::string port;
+};
+
+// Host (without IPv6 brackets) and port of an http(s) URL; the port defaults
+// to the scheme's. Returns nullopt for anything that does not parse.
+std::optional<UrlHostPort> url_host_port(const std::string& url) {
+    const auto scheme_end = url.find("://");
+    if (scheme_end == std::string::npos) {
+        return std::nullopt;
+    }
+    std::string scheme = url.substr(0, scheme_end);
+    std::transform(scheme.begin(), scheme.end(), scheme.begin(),
+                   [](unsigned char ch) { return static_cast<char>(std::tolower(ch)); });
+
+    const std::size_t begin = scheme_end + 3;
+    const std::size_t end = std::min(url.find_first_of("/?#", begin), url.size());
+    std::string authority = url.substr(begin, end - begin);
+    const auto at = authority.rfind('@');
+    if (at != std::string::npos) {
+        authority.erase(0, at + 1);
+    }
+
+    UrlHostPort result;
+    result.port = scheme == "https" ? "443" : "80";
+    std::string rest;
+    if (!authority.empty() && authority.front() == '[') {
+        const auto close = authority.find(']');
+        if (close == std::string::npos) {
+            return std::nullopt;
+        }
+        result.host = authority.substr(1, close - 1);
+        rest = authority.substr(close + 1);
+    } else {
+        const auto colon = authority.rfind(':');
+        result.host = authority.substr(0, colon);
+        rest = colon == std::string::npos ? std::string() : authority.substr(colon);
+    }
+    if (!rest.empty()) {
+        if (rest.front() != ':') {
+            return std::nullopt;
+        }
+        if (rest.size() > 1) {
+            result.port = rest.substr(1);
+        }
+    }
+    if (result.host.empty()) {
+        return std::nullopt;
+    }
+    return result;
+}
+
+bool is_ip_literal(const std::string& host) {
+    in6_addr addr{};
+    return inet_pton(AF_INET, host.c_str(), &addr) == 1 ||
+           inet_pton(AF_INET6, host.c_str(), &addr) == 1;
+}
+
 bool cache_contents_equal(const std::filesystem::path& path, const std::string& body) {
     std::ifstream input(path, std::ios::binary);
     if (!input) {
@@ -49,13 +118,102 @@ bool cache_contents_equal(const std::filesystem::path& path, const std::string&
     return existing == body;
 }
 
+// Why a downloaded body does not look like a plain-text list, or nullopt.
+// A missing Content-Type and application/octet-stream are accepted: many
+// mirrors serve .txt/.lst files without a text type.
+std::optional<std::string> rejected_list_content(const std::string& content_type,
+                                                 const std::string& body) {
+    std::string media_type = content_type.substr(0, content_type.find(';'));
+    const auto first = media_type.find_first_not_of(" \t");
+    media_type = first == std::string::npos
+        ? std::string()
+        : media_type.substr(first, media_type.find_last_not_of(" \t") - first + 1);
+    std::transform(media_type.begin(), media_type.end(), media_type.begin(),
+                   [](unsigned char ch) { return static_cast<char>(std::tolower(ch)); });
+    const bool text_like = media_type.empty() || media_type == "application/octet-stream" ||
+                           (media_type.rfind("text/", 0) == 0 && media_type != "text/html");
+    if (!text_like) {
+        return "unexpected Content-Type " + media_type + ", expected a text list";
+    }
+
+    std::size_t start = body.rfind("\xEF\xBB\xBF", 0) == 0 ? 3 : 0;
+    start = body.find_first_not_of(" \t\r\n", start);
+    if (start != std::string::npos && body[start] == '<') {
+        return std::string("content looks like HTML, expected a text list");
+    }
+    return std::nullopt;
+}
+
 } // namespace
 
+std::vector<std::string> resolve_via_bootstrap_dns(const std::string& server,
+                                                   const std::string& host) {
+    const auto probe = probe_dns_upstream(server, host, kBootstrapDnsTimeout);
+    if (!probe.ok) {
+        throw std::runtime_error(probe.error);
+    }
+    return probe.resolved_ips;
+}
+
+HttpRequestOptions cache_request_options(const std::string& url,
+                                         const CacheDownloadOptions& options,
+                                         const BootstrapResolver& resolver) {
+    HttpRequestOptions request_options;
+    request_options.fwmark = options.fwmark;
+    if (!options.bootstrap_dns.has_value()) {
+        return request_options;
+    }
+    const auto target = url_host_port(url);
+    if (!target.has_value() || is_ip_literal(target->host)) {
+        return request_options;
+    }
+    std::vector<std::string> addresses;
+    try {
+        addresses = resolver(*options.bootstrap_dns, target->host);
+    } catch (const std::exception& e) {
+        throw std::runtime_error("bootstrap DNS " + *options.bootstrap_dns +
+                                 " failed to resolve " + target->host + ": " + e.what());
+    }
+    if (addresses.empty()) {
+        throw std::runtime_error("bootstrap DNS " + *options.bootstrap_dns +
+                                 " returned no addresses for " + target->host);
+    }
+    std::string entry = target->host + ":" + target->port + ":";
+    for (std::size_t i = 0; i < addresses.size(); ++i) {
+        if (i > 0) entry += ",";
+        const bool ipv6 = addresses[i].find(':') != std::string::npos;
+        entry += ipv6 ? "[" + addresses[i] + "]" : addresses[i];
+    }
+    request_options.resolve.push_back(std::move(entry));
+    return request_options;
+}
+
+std::optional<std::string> rejected_download(const CacheDownloadOptions& options,
+                                             const std::string& content_type,
+                                             const std::string& body) {
+    if (options.validate_content) {
+        if (auto reason = rejected_list_content(content_type, body)) {
+            return reason;
+        }
+    }
+    if (options.expected_sha256.has_value()) {
+        std::string expected = *options.expected_sha256;
+        std::transform(expected.begin(), expected.end(), expected.begin(),
+                       [](unsigned char ch) { return static_cast<char>(std::tolower(ch)); });
+        const std::string actual = crypto::sha256_hex(body);
+        if (actual != expected) {
+            return "SHA-256 mismatch: expected " + expected + ", got " + actual;
+        }
+    }
+    return std::nullopt;
+}
+
 CacheManager::CacheManager(const std::filesystem::path& cache_dir,
                            size_t max_file_size_bytes)
     : cache_dir_(cache_dir)
     , max_file_size_bytes_(max_file_size_bytes) {
     http_client_.set_max_response_size(max_file_size_bytes);
+    bootstrap_resolver_ = resolve_via_bootstrap_dns;
 }
 
 void CacheManager::ensure_dir() {
@@ -67,18 +225,32 @@ void CacheManager::set_max_file_size(size_t bytes) {
     http_client_.set_max_response_size(bytes);
 }
 
+void CacheManager::set_bootstrap_resolver(BootstrapResolver resolver) {
+    bootstrap_resolver_ = std::move(resolver);
+}
+
 CacheDownloadResult CacheManager::download(const std::string& name,
                                            const std::string& url,
                                            const CacheDownloadOptions& options) {
     CacheMetadata existing = load_metadata(name);
+    // Validators are only meaningful to the server that issued them; a cache
+    // filled from another mirror must be fetched unconditionally.
+    const bool same_source = !existing.url.has_value() || *existing.url == url;
+
+    HttpRequestOptions request_options;
+    try {
+        request_options = cache_request_options(url, options, bootstrap_resolver_);
+    } catch (const std::exception& e) {
+        return download_failed(e.what());
+    }
 
     ConditionalDownloadResult result;
     try {
         result = http_client_.download_conditional(
             url,
-            existing.etag.value_or(""),
-            existing.last_modified.value_or(""),
-            HttpRequestOptions{options.fwmark});
+            same_source ? existing.etag.value_or("") : "",
+            same_source ? existing.last_modified.value_or("") : "",
+            request_options);
     } catch (const HttpError& e) {
         if (e.status_code() > 0) {
             return download_failed("HTTP " + std::to_string(e.status_code()), e.status_code());
@@ -94,11 +266,33 @@ CacheDownloadResult CacheManager::download(const std::string& name,
         return not_modified;
     }
 
+    if (const auto reason = rejected_download(options, result.content_type, result.body)) {
+        return download_failed(*reason);
+    }
+
+    CacheMetadata meta;
+    meta.etag = result.etag;
+    meta.last_modified = result.last_modified;
+    meta.url = url;
+    return write_cache(name, result.body, std::move(meta));
+}
+
+CacheDownloadResult CacheManager::store(const std::string& name,
+                                        const std::string& source,
+                                        const std::string& body) {
+    CacheMetadata meta;
+    meta.url = source;
+    return write_cache(name, body, std::move(meta));
+}
+
+CacheDownloadResult CacheManager::write_cache(const std::string& name,
+                                              const std::string& body,
+                                              CacheMetadata meta) {
     std::filesystem::path final_path = cache_path(name);
     // The cache version is the raw payload, not transport metadata. A server
     // may return an equivalent 200 response with a new ETag; keep both the
     // file and metadata intact so callers do not restart or reconcile.
-    if (cache_contents_equal(final_path, result.body)) {
+    if (cache_contents_equal(final_path, body)) {
         CacheDownloadResult unchanged;
         unchanged.status = CacheDownloadStatus::NotModified;
         return unchanged;
@@ -111,17 +305,13 @@ CacheDownloadResult CacheManager::download(const std::string& name,
     {
         std::ofstream ofs(tmp_path, std::ios::binary);
         if (!ofs) return download_failed("failed to open temporary cache file for writing");
-        ofs << result.body;
+        ofs << body;
         if (!ofs) {
             std::filesystem::remove(tmp_path);
             return download_failed("failed to write temporary cache file");
         }
     }
 
-    CacheMetadata meta;
-    meta.etag = result.etag;
-    meta.last_modified = result.last_modified;
-    meta.url = url;
     meta.download_time = current_time_iso();
 
     {
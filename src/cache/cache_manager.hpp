@@ -6,8 +6,10 @@
 
 #include <cstdint>
 #include <filesystem>
+#include <functional>
 #include <optional>
 #include <string>
+#include <vector>
 
 namespace keen_pbr3 {
 
@@ -16,8 +18,39 @@ using CacheMetadata = api::CacheMetadata;
 
 struct CacheDownloadOptions {
     uint32_t fwmark{0};
+    // When set, a downloaded body whose SHA-256 differs is rejected.
+    std::optional<std::string> expected_sha256;
+    // Plain DNS server ("ip", "ip:port", "[ipv6]:port") that resolves the URL
+    // host instead of the system resolver, which may be keen-pbr's own dnsmasq
+    // and not ready yet. Redirects to other hosts use the system resolver.
+    std::optional<std::string> bootstrap_dns;
+    // Reject a body served with a non-text Content-Type or that starts with
+    // '<', such as an HTML error or captive portal page.
+    bool validate_content{false};
 };
 
+// Resolve `host` through the plain DNS server `server` and return its
+// addresses. Throws std::runtime_error when nothing usable was returned.
+using BootstrapResolver =
+    std::function<std::vector<std::string>(const std::string& server, const std::string& host)>;
+
+// The default BootstrapResolver: a single A query per host.
+std::vector<std::string> resolve_via_bootstrap_dns(const std::string& server,
+                                                   const std::string& host);
+
+// HTTP options for fetching `url` under `options`: the fwmark and, when
+// bootstrap_dns is set and the URL host is a name, its addresses pinned from
+// `resolver`. Throws std::runtime_error when the bootstrap lookup fails.
+HttpRequestOptions cache_request_options(const std::string& url,
+                                         const CacheDownloadOptions& options,
+                                         const BootstrapResolver& resolver);
+
+// Why a downloaded body fails the validate_content or expected_sha256 check
+// of `options`, or nullopt when it is accepted.
+std::optional<std::string> rejected_download(const CacheDownloadOptions& options,
+                                             const std::string& content_type,
+                                             const std::string& body);
+
 enum class CacheDownloadStatus {
     Updated,
     NotModified,
@@ -55,12 +88,23 @@ public:
 
     size_t max_file_size() const noexcept { return max_file_size_bytes_; }
 
+    // Replace the resolver used for CacheDownloadOptions::bootstrap_dns
+    // (default: a single A query per host).
+    void set_bootstrap_resolver(BootstrapResolver resolver);
+
     // Download a list from URL using conditional requests (ETag/If-Modified-Since).
     // On failure, does not overwrite existing cache.
     CacheDownloadResult download(const std::string& name,
                                  const std::string& url,
                                  const CacheDownloadOptions& options = {});
 
+    // Cache `body` produced locally from `source` (recorded as the metadata
+    // url) the same way as a download: NotModified when the cached file
+    // already holds it, Failed without touching the cache on a write error.
+    CacheDownloadResult store(const std::string& name,
+                              const std::string& source,
+                              const std::string& body);
+
     // Check if a cached file exists for the given list name.
     bool has_cache(const std::string& name) const;
 
@@ -77,9 +121,14 @@ public:
     void save_metadata(const std::string& name, const CacheMetadata& meta);
 
 private:
+    CacheDownloadResult write_cache(const std::string& name,
+                                    const std::string& body,
+                                    CacheMetadata meta);
+
     std::filesystem::path cache_dir_;
     size_t max_file_size_bytes_;
     HttpClient http_client_;
+    BootstrapResolver bootstrap_resolver_;
 };
 
 } // namespace keen_pbr3
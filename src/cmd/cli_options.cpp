@@ -0,0 +1,268 @@
+#include "cli_options.hpp"
+
+#include "../daemon/auto_reapply.hpp"
+
+#include <cstdlib>
+#include <cstring>
+#include <iostream>
+
+namespace keen_pbr3 {
+
+void print_usage(const char *argv0) {
+  std::cerr << "Usage: " << argv0 << " [options] <command>\n"
+            << "\n"
+            << "Options:\n"
+            << "  --config <path>    Path to JSON config file, or - to read "
+               "it from stdin (default: "
+            << KEEN_PBR_DEFAULT_CONFIG_PATH << ")\n"
+            << "  --log-level <lvl>  Log level: error, warn, info, verbose, "
+               "debug (default: info)\n"
+            << "  --quiet, -q        Only log errors; overrides --log-level\n"
+            << "  --pid-file <path>  Override daemon.pid_file when running the "
+               "service command\n"
+            << "  --crash-report <path>  Last-crash report path (default: "
+               "/tmp/keen-pbr-crash.log)\n"
+            << "  --no-api           Disable REST API at runtime\n"
+            << "  --use-raw-prerouting  Use raw PREROUTING for IPv4 forwarded "
+               "traffic (iptables only)\n"
+            << "  --watch-config     Reload the service automatically when the "
+               "config file changes\n"
+            << "  --timeout <sec>    Abort download or apply after this many "
+               "seconds (exit code 124)\n"
+            << "  --version          Show version and exit\n"
+            << "  --help             Show this help and exit\n"
+            << "\n"
+            << "Commands:\n"
+            << "  service                            Start the routing service "
+               "(foreground)\n"
+            << "  service info                       Show the parameters the "
+               "running service uses\n"
+            << "  status [--json]                    Show routing/firewall "
+               "status and exit\n"
+            << "                                     --json prints the routing "
+               "health report and exits 1 unless all checks pass\n"
+            << "  download                           Download all configured "
+               "lists to cache and exit\n"
+            << "  download --validate-only           Check that every list URL "
+               "is reachable without downloading it\n"
+            << "  generate-resolver-config <res>     Print generated resolver "
+               "config to stdout and exit\n"
+            << "                                     Resolvers: dnsmasq "
+               "(dnsmasq-ipset and dnsmasq-nftset are deprecated)\n"
+            << "  resolver-config-hash               Print MD5 hash of "
+               "domain-to-ipset mapping and exit\n"
+            << "  test-routing <ip-or-domain>        Test expected vs actual "
+               "routing for an IP or domain\n"
+            << "  explain <ip-or-domain>             Show DNS rule, list "
+               "matches, kernel sets and outbound for a target\n"
+            << "  config dump                        Print the effective config "
+               "with defaults applied and secrets redacted\n"
+            << "  config explain                     Print the iptables rules, "
+               "ip rules and routes each rule would install\n"
+            << "  config export-script               Print a shell script that "
+               "installs the sets, rules and routes without keen-pbr\n"
+            << "  interfaces resolve <name>          Map a Keenetic interface "
+               "name or description to its Linux name (and back)\n"
+            << "  lists bench <name>                 Download and parse a URL "
+               "list and report timings without importing it\n"
+            << "  lists diff <name> [--json]         Compare the cached copy of "
+               "a URL list with a fresh download\n"
+            << "  apply --stdin --ipset <set>        Add IPs/CIDRs read from "
+               "stdin to an existing kernel set\n"
+            << "  apply --routing-only               Reapply routes, policy and "
+               "firewall rules of the running service without reloading lists\n"
+            << "  undo-routing [--auto-reapply-after <duration>]\n"
+            << "                                     Stop routing and firewall "
+               "rules of the running service, optionally starting them again "
+               "after a delay (e.g. 90s, 15m, 2h)\n"
+            << "  version [--json]                   Print version, commit and "
+               "build target details and exit\n";
+}
+
+CliOptions parse_args(int argc, char *argv[]) {
+  CliOptions opts;
+  bool quiet = false;
+  for (int i = 1; i < argc; ++i) {
+    if (std::strcmp(argv[i], "--config") == 0) {
+      if (i + 1 >= argc) {
+        std::cerr << "Error: --config requires an argument\n";
+        std::exit(1);
+      }
+      opts.config_path = argv[++i];
+    } else if (std::strcmp(argv[i], "--log-level") == 0) {
+      if (i + 1 >= argc) {
+        std::cerr << "Error: --log-level requires an argument\n";
+        std::exit(1);
+      }
+      opts.log_level = argv[++i];
+    } else if (std::strcmp(argv[i], "--quiet") == 0 ||
+               std::strcmp(argv[i], "-q") == 0) {
+      quiet = true;
+    } else if (std::strcmp(argv[i], "--pid-file") == 0) {
+      if (i + 1 >= argc) {
+        std::cerr << "Error: --pid-file requires an argument\n";
+        std::exit(1);
+      }
+      opts.pid_file_override = argv[++i];
+      opts.has_pid_file_override = true;
+    } else if (std::strcmp(argv[i], "--crash-report") == 0) {
+      if (i + 1 >= argc) {
+        std::cerr << "Error: --crash-report requires an argument\n";
+        std::exit(1);
+      }
+      opts.crash_report_path = argv[++i];
+    } else if (std::strcmp(argv[i], "--no-api") == 0) {
+      opts.no_api = true;
+    } else if (std::strcmp(argv[i], "--use-raw-prerouting") == 0) {
+      opts.use_raw_prerouting = true;
+    } else if (std::strcmp(argv[i], "--watch-config") == 0) {
+      opts.watch_config = true;
+    } else if (std::strcmp(argv[i], "--help") == 0 ||
+               std::strcmp(argv[i], "-h") == 0) {
+      opts.show_help = true;
+    } else if (std::strcmp(argv[i], "--version") == 0 ||
+               std::strcmp(argv[i], "-v") == 0) {
+      opts.show_version = true;
+    } else if (std::strcmp(argv[i], "version") == 0) {
+      opts.show_build_info = true;
+    } else if (std::strcmp(argv[i], "service") == 0) {
+      if (i + 1 < argc && std::strcmp(argv[i + 1], "info") == 0) {
+        ++i;
+        opts.run_service_info = true;
+      } else {
+        opts.run_service = true;
+      }
+    } else if (std::strcmp(argv[i], "status") == 0) {
+      opts.run_status = true;
+    } else if (std::strcmp(argv[i], "generate-resolver-config") == 0) {
+      if (i + 1 >= argc) {
+        std::cerr
+            << "Error: generate-resolver-config requires a resolver argument\n";
+        print_usage(argv[0]);
+        std::exit(1);
+      }
+      opts.resolver_type = argv[++i];
+      opts.generate_resolver_config = true;
+    } else if (std::strcmp(argv[i], "--json") == 0) {
+      opts.json_output = true;
+    } else if (std::strcmp(argv[i], "download") == 0) {
+      opts.download_lists = true;
+    } else if (std::strcmp(argv[i], "--reload") == 0) {
+      opts.download_reload = true;
+    } else if (std::strcmp(argv[i], "--validate-only") == 0) {
+      opts.download_validate_only = true;
+    } else if (std::strcmp(argv[i], "resolver-config-hash") == 0) {
+      opts.resolver_config_hash = true;
+    } else if (std::strcmp(argv[i], "test-routing") == 0) {
+      if (i + 1 >= argc) {
+        std::cerr << "Error: test-routing requires an IP address or domain "
+                     "argument\n";
+        print_usage(argv[0]);
+        std::exit(1);
+      }
+      opts.test_routing_target = argv[++i];
+      opts.run_test_routing = true;
+    } else if (std::strcmp(argv[i], "explain") == 0) {
+      if (i + 1 >= argc) {
+        std::cerr << "Error: explain requires an IP address or domain "
+                     "argument\n";
+        print_usage(argv[0]);
+        std::exit(1);
+      }
+      opts.test_routing_target = argv[++i];
+      opts.run_explain = true;
+    } else if (std::strcmp(argv[i], "config") == 0) {
+      if (i + 1 >= argc || (std::strcmp(argv[i + 1], "dump") != 0 &&
+                            std::strcmp(argv[i + 1], "explain") != 0 &&
+                            std::strcmp(argv[i + 1], "export-script") != 0)) {
+        std::cerr << "Error: config requires a subcommand: dump, explain, "
+                     "export-script\n";
+        print_usage(argv[0]);
+        std::exit(1);
+      }
+      ++i;
+      if (std::strcmp(argv[i], "dump") == 0) {
+        opts.config_dump = true;
+      } else if (std::strcmp(argv[i], "explain") == 0) {
+        opts.config_explain = true;
+      } else {
+        opts.config_export_script = true;
+      }
+    } else if (std::strcmp(argv[i], "interfaces") == 0) {
+      if (i + 2 >= argc || std::strcmp(argv[i + 1], "resolve") != 0) {
+        std::cerr << "Error: interfaces requires a subcommand: resolve <name>\n";
+        print_usage(argv[0]);
+        std::exit(1);
+      }
+      i += 2;
+      opts.interface_query = argv[i];
+      opts.interfaces_resolve = true;
+    } else if (std::strcmp(argv[i], "lists") == 0) {
+      if (i + 2 >= argc || (std::strcmp(argv[i + 1], "bench") != 0 &&
+                            std::strcmp(argv[i + 1], "diff") != 0)) {
+        std::cerr << "Error: lists requires a subcommand: bench <name> or "
+                     "diff <name>\n";
+        print_usage(argv[0]);
+        std::exit(1);
+      }
+      if (std::strcmp(argv[i + 1], "diff") == 0) {
+        opts.list_diff_name = argv[i + 2];
+        opts.list_diff = true;
+      } else {
+        opts.list_bench_name = argv[i + 2];
+        opts.list_bench = true;
+      }
+      i += 2;
+    } else if (std::strcmp(argv[i], "apply") == 0) {
+      opts.apply_entries = true;
+    } else if (std::strcmp(argv[i], "--stdin") == 0) {
+      opts.apply_stdin = true;
+    } else if (std::strcmp(argv[i], "--routing-only") == 0) {
+      opts.apply_routing_only = true;
+    } else if (std::strcmp(argv[i], "undo-routing") == 0) {
+      opts.undo_routing = true;
+    } else if (std::strcmp(argv[i], "--auto-reapply-after") == 0) {
+      if (i + 1 >= argc) {
+        std::cerr << "Error: --auto-reapply-after requires an argument\n";
+        std::exit(1);
+      }
+      const auto delay = parse_auto_reapply_after(argv[++i]);
+      if (!delay.has_value()) {
+        std::cerr << "Error: --auto-reapply-after must be a duration from 1s "
+                     "to 24h, such as 90s, 15m or 2h\n";
+        std::exit(1);
+      }
+      opts.auto_reapply_after_seconds = delay->count();
+    } else if (std::strcmp(argv[i], "--ipset") == 0) {
+      if (i + 1 >= argc) {
+        std::cerr << "Error: --ipset requires an argument\n";
+        std::exit(1);
+      }
+      opts.apply_set_name = argv[++i];
+    } else if (std::strcmp(argv[i], "--timeout") == 0) {
+      if (i + 1 >= argc) {
+        std::cerr << "Error: --timeout requires an argument\n";
+        std::exit(1);
+      }
+      char *end = nullptr;
+      const long seconds = std::strtol(argv[++i], &end, 10);
+      if (end == argv[i] || *end != '\0' || seconds < 1 || seconds > 86400) {
+        std::cerr << "Error: --timeout must be a number of seconds from 1 to 86400\n";
+        std::exit(1);
+      }
+      opts.timeout_seconds = static_cast<int>(seconds);
+    } else {
+      std::cerr << "Unknown option: " << argv[i] << "\n";
+      print_usage(argv[0]);
+      std::exit(1);
+    }
+  }
+  // --quiet wins over --log-level wherever either appears, so a cron entry
+  // stays silent even when a wrapper script appends its own --log-level.
+  if (quiet) {
+    opts.log_level = "error";
+  }
+  return opts;
+}
+
+} // namespace keen_pbr3
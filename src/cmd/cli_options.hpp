@@ -0,0 +1,62 @@
+#pragma once
+
+#include <string>
+
+#ifndef KEEN_PBR_DEFAULT_CONFIG_PATH
+#define KEEN_PBR_DEFAULT_CONFIG_PATH "/etc/keen-pbr/config.json"
+#endif
+
+namespace keen_pbr3 {
+
+struct CliOptions {
+  std::string config_path{KEEN_PBR_DEFAULT_CONFIG_PATH};
+  std::string log_level{"info"};
+  std::string pid_file_override;
+  std::string crash_report_path{"/tmp/keen-pbr-crash.log"};
+  bool no_api{false};
+  bool use_raw_prerouting{false};
+  bool watch_config{false};
+  bool has_pid_file_override{false};
+  bool run_service{false};
+  bool generate_resolver_config{false};
+  std::string resolver_type;
+  bool download_lists{false};
+  bool download_reload{false};
+  bool download_validate_only{false};
+  bool resolver_config_hash{false};
+  bool run_status{false};
+  bool json_output{false};
+  bool run_service_info{false};
+  bool run_test_routing{false};
+  bool run_explain{false};
+  std::string test_routing_target;
+  bool config_dump{false};
+  bool config_explain{false};
+  bool config_export_script{false};
+  bool interfaces_resolve{false};
+  std::string interface_query;
+  bool list_bench{false};
+  std::string list_bench_name;
+  bool list_diff{false};
+  std::string list_diff_name;
+  bool apply_entries{false};
+  bool apply_stdin{false};
+  bool apply_routing_only{false};
+  std::string apply_set_name;
+  bool undo_routing{false};
+  // undo-routing --auto-reapply-after in seconds; 0 when not requested.
+  long long auto_reapply_after_seconds{0};
+  // Hard deadline for download/apply in seconds; 0 keeps the defaults.
+  int timeout_seconds{0};
+  bool show_help{false};
+  bool show_version{false};
+  bool show_build_info{false};
+};
+
+void print_usage(const char *argv0);
+
+// Parses the command line. Prints usage and exits with status 1 on a
+// malformed argument list.
+CliOptions parse_args(int argc, char *argv[]);
+
+} // namespace keen_pbr3
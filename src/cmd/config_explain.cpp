@@ -0,0 +1,338 @@
+#include "config_explain.hpp"
+
+#include "../config/routing_state.hpp"
+#include "../firewall/iptables.hpp"
+#include "../lists/list_entry_visitor.hpp"
+#include "../lists/list_streamer.hpp"
+#include "../util/format_compat.hpp"
+
+#include <map>
+#include <optional>
+#include <set>
+#include <stdexcept>
+
+namespace keen_pbr3 {
+
+namespace {
+
+// Dry-run RouteTable/PolicyRuleManager never touch netlink.
+class NoNetlink final : public RouteNetlinkOperations, public RuleNetlinkOperations {
+public:
+    RouteAddResult add_route(const RouteSpec&) override { throw unexpected(); }
+    void delete_route(const RouteSpec&) override { throw unexpected(); }
+    RuleAddResult add_rule_for_family(const RuleSpec&, int) override { throw unexpected(); }
+    void delete_rule_for_family(const RuleSpec&, int) override { throw unexpected(); }
+
+private:
+    static std::logic_error unexpected() {
+        return std::logic_error("config explain must not modify routing");
+    }
+};
+
+bool is_ipv6_route(const RouteSpec& spec) {
+    if (spec.family != 0) return spec.family == AF_INET6;
+    return spec.destination.find(':') != std::string::npos ||
+           (spec.gateway.has_value() && spec.gateway->find(':') != std::string::npos);
+}
+
+std::map<std::string, std::string> first_urltest_children(const Config& config) {
+    std::map<std::string, std::string> selections;
+    for (const auto& outbound : config.outbounds.value_or(std::vector<Outbound>{})) {
+        if (outbound.type != OutboundType::URLTEST) continue;
+        for (const auto& group : outbound.outbound_groups.value_or(std::vector<OutboundGroup>{})) {
+            if (!group.outbounds.empty()) {
+                selections[outbound.tag] = group.outbounds.front();
+                break;
+            }
+        }
+    }
+    return selections;
+}
+
+void append_all(std::vector<std::string>& out, const std::vector<std::string>& items) {
+    out.insert(out.end(), items.begin(), items.end());
+}
+
+const char* action_label(RuleActionType action) {
+    switch (action) {
+    case RuleActionType::Mark: return "mark";
+    case RuleActionType::Drop: return "drop";
+    case RuleActionType::Pass: return "pass";
+    case RuleActionType::Skip: break;
+    }
+    return "skip";
+}
+
+void append_section(std::string& out, const char* title, const std::vector<std::string>& lines) {
+    if (lines.empty()) return;
+    out += format("  {}:\n", title);
+    for (const auto& line : lines) {
+        out += format("    {}\n", line);
+    }
+}
+
+struct SetNameParts {
+    std::string list_name;
+    bool ipv6{false};
+    bool dynamic{false};
+};
+
+// Split "kpbr4_<list>", "kpbr6d_<list>", ... into its parts.
+std::optional<SetNameParts> parse_set_name(const std::string& set_name) {
+    if (set_name.rfind("kpbr", 0) != 0 || set_name.size() < 6) return std::nullopt;
+    const auto underscore = set_name.find('_');
+    if (underscore == std::string::npos) return std::nullopt;
+    const std::string kind = set_name.substr(4, underscore - 4);
+    if (kind != "4" && kind != "6" && kind != "4d" && kind != "6d") return std::nullopt;
+    return SetNameParts{set_name.substr(underscore + 1), kind[0] == '6', kind.size() == 2};
+}
+
+
+} // namespace
+
+std::vector<std::string> format_ip_rule_commands(const RuleSpec& spec) {
+    std::string body = format("fwmark {:#x}/{:#x}", spec.fwmark, spec.fwmask);
+    switch (spec.action) {
+    case RuleAction::lookup:
+        body += format(" lookup {}", spec.table);
+        break;
+    case RuleAction::unreachable:
+        body += " unreachable";
+        break;
+    case RuleAction::blackhole:
+        body += " blackhole";
+        break;
+    }
+    body += format(" priority {}", spec.priority);
+
+    std::vector<std::string> commands;
+    if (spec.family != AF_INET6) commands.push_back("ip rule add " + body);
+    if (spec.family != AF_INET) commands.push_back("ip -6 rule add " + body);
+    return commands;
+}
+
+std::string format_ip_route_command(const RouteSpec& spec) {
+    std::string command = is_ipv6_route(spec) ? "ip -6 route add " : "ip route add ";
+    if (spec.blackhole) {
+        command += "blackhole ";
+    } else if (spec.unreachable) {
+        command += "unreachable ";
+    }
+    command += spec.destination;
+    if (!spec.nexthops.empty()) {
+        command += " " + format_route_nexthops(spec.nexthops);
+    } else {
+        if (spec.gateway.has_value()) command += " via " + *spec.gateway;
+        if (spec.interface.has_value()) command += " dev " + *spec.interface;
+    }
+    command += format(" table {}", spec.table);
+    if (spec.metric != 0) command += format(" metric {}", spec.metric);
+    return command;
+}
+
+std::vector<ExplainedRouteRule> explain_config(const Config& config, bool ipv6_enabled) {
+    const auto fwmark_cfg = config.fwmark.value_or(FwmarkConfig{});
+    const uint32_t fwmark_mask = fwmark_mask_value(fwmark_cfg);
+    const auto marks = allocate_outbound_marks(fwmark_cfg,
+                                               config.outbounds.value_or(std::vector<Outbound>{}));
+    const auto selections = first_urltest_children(config);
+
+    NoNetlink netlink;
+    RouteTable routes(netlink, true);
+    PolicyRuleManager rules(netlink, true);
+    populate_routing_state(config, marks, routes, rules, {}, &selections, ipv6_enabled);
+
+    // Same prefilter apply_runtime_firewall() installs.
+    auto prefilter = build_firewall_global_prefilter(config);
+    prefilter.restore_conntrack_mark = true;
+    prefilter.conntrack_mark_mask = fwmark_mask;
+
+    const auto route_rules =
+        config.route.value_or(RouteConfig{}).rules.value_or(std::vector<RouteRule>{});
+    const auto lists = config.lists.value_or(std::map<std::string, ListConfig>{});
+    std::vector<ExplainedRouteRule> explained_rules;
+    for (const auto& rs : build_fw_rule_states(config, marks, &selections)) {
+        ExplainedRouteRule explained;
+        explained.rule_index = rs.rule_index;
+        explained.outbound = rs.outbound_tag;
+        explained.action = rs.action_type;
+        explained.fwmark = rs.fwmark;
+        explained.list_names = rs.list_names;
+        if (rs.action_type == RuleActionType::Skip ||
+            (rs.action_type == RuleActionType::Mark && rs.fwmark == 0)) {
+            explained.action = RuleActionType::Skip;
+            explained_rules.push_back(std::move(explained));
+            continue;
+        }
+
+        const auto criteria = build_firewall_rule_criteria(route_rules.at(rs.rule_index));
+        auto describe = [&](bool ipv6, const FirewallRuleCriteria& rule_criteria) {
+            return IptablesFirewall::describe_rule_commands(
+                ipv6, rs.action_type, rs.fwmark, fwmark_mask, rule_criteria, prefilter);
+        };
+        if (rs.list_names.empty()) {
+            explained.firewall_commands = describe(false, criteria);
+            if (ipv6_enabled) append_all(explained.firewall_commands, describe(true, criteria));
+        }
+        for (const auto& set_name : rs.set_names) {
+            const bool ipv6 = set_name.rfind("kpbr6", 0) == 0;
+            if (ipv6 && !ipv6_enabled) continue;
+            FirewallRuleCriteria set_criteria = criteria;
+            set_criteria.dst_set_name = set_name;
+            ExplainedSet set{set_name, describe(ipv6, set_criteria)};
+            const auto parts = parse_set_name(set_name);
+            if (parts.has_value() && parts->dynamic) {
+                const auto list_it = lists.find(parts->list_name);
+                const int64_t ttl_ms =
+                    list_it != lists.end() ? list_it->second.ttl_ms.value_or(0) : 0;
+                if (ttl_ms >= 1000) set.timeout = static_cast<uint32_t>(ttl_ms / 1000);
+            }
+            explained.sets.push_back(std::move(set));
+        }
+
+        if (rs.action_type == RuleActionType::Mark) {
+            std::set<uint32_t> tables;
+            for (const auto& rule : rules.get_rules()) {
+                if (rule.fwmark != rs.fwmark) continue;
+                append_all(explained.ip_rules, format_ip_rule_commands(rule));
+                if (rule.action == RuleAction::lookup) tables.insert(rule.table);
+            }
+            for (const auto& route : routes.get_routes()) {
+                if (tables.count(route.table) != 0) {
+                    explained.routes.push_back(format_ip_route_command(route));
+                }
+            }
+        }
+        explained_rules.push_back(std::move(explained));
+    }
+    return explained_rules;
+}
+
+std::string format_config_explanation(const std::vector<ExplainedRouteRule>& rules) {
+    if (rules.empty()) {
+        return "No route rules configured\n";
+    }
+
+    std::string out;
+    for (const auto& rule : rules) {
+        if (!out.empty()) out += "\n";
+        out += format("Rule #{} -> {} ({}", rule.rule_index + 1, rule.outbound,
+                      action_label(rule.action));
+        if (rule.action == RuleActionType::Mark) {
+            out += format(" {:#x}", rule.fwmark);
+        }
+        out += ")\n";
+        if (!rule.list_names.empty()) {
+            std::string lists;
+            for (const auto& name : rule.list_names) {
+                if (!lists.empty()) lists += ", ";
+                lists += name;
+            }
+            out += format("  lists: {}\n", lists);
+        }
+        if (rule.action == RuleActionType::Skip) {
+            out += "  nothing is installed (rule disabled or outbound unresolved)\n";
+            continue;
+        }
+        for (const auto& set : rule.sets) {
+            append_section(out, ("ipset " + set.set_name).c_str(), set.firewall_commands);
+        }
+        append_section(out, "iptables", rule.firewall_commands);
+        append_section(out, "ip rules", rule.ip_rules);
+        append_section(out, "routes", rule.routes);
+    }
+    return out;
+}
+
+ExplainedSetEntries collect_explained_set_entries(const Config& config,
+                                                  const std::vector<ExplainedRouteRule>& rules,
+                                                  ListStreamer& list_streamer) {
+    const auto lists = config.lists.value_or(std::map<std::string, ListConfig>{});
+    ExplainedSetEntries entries;
+    for (const auto& rule : rules) {
+        for (const auto& set : rule.sets) {
+            const auto parts = parse_set_name(set.set_name);
+            if (!parts.has_value() || parts->dynamic || entries.count(set.set_name) != 0) continue;
+            const auto list_it = lists.find(parts->list_name);
+            if (list_it == lists.end()) continue;
+
+            auto& set_entries = entries[set.set_name];
+            FunctionalVisitor collector([&](EntryType type, std::string_view entry) {
+                if (type == EntryType::Domain) return;
+                const bool ipv6 = entry.find(':') != std::string_view::npos;
+                if (ipv6 == parts->ipv6) set_entries.emplace_back(entry);
+            });
+            list_streamer.stream_list(parts->list_name, list_it->second, collector);
+        }
+    }
+    return entries;
+}
+
+std::string format_config_script(const std::vector<ExplainedRouteRule>& rules,
+                                 const ExplainedSetEntries& set_entries) {
+    std::string body;
+    bool uses_ip6tables = false;
+    // Route rules sharing an outbound share its ip rules and routes.
+    std::set<std::string> emitted;
+    auto append_once = [&](const std::vector<std::string>& commands) {
+        for (const auto& command : commands) {
+            if (emitted.insert(command).second) body += command + "\n";
+        }
+    };
+    auto append_firewall = [&](const std::vector<std::string>& commands) {
+        for (const auto& command : commands) {
+            if (command.rfind("ip6tables", 0) == 0) uses_ip6tables = true;
+            body += command + "\n";
+        }
+    };
+
+    for (const auto& rule : rules) {
+        body += format("\n# Rule #{} -> {} ({}", rule.rule_index + 1, rule.outbound,
+                       action_label(rule.action));
+        if (rule.action == RuleActionType::Mark) {
+            body += format(" {:#x}", rule.fwmark);
+        }
+        body += ")\n";
+        if (rule.action == RuleActionType::Skip) {
+            body += "# nothing is installed (rule disabled or outbound unresolved)\n";
+            continue;
+        }
+        for (const auto& set : rule.sets) {
+            if (emitted.insert("ipset " + set.set_name).second) {
+                const auto parts = parse_set_name(set.set_name);
+                body += format("ipset create {} hash:net family {}", set.set_name,
+                               parts.has_value() && parts->ipv6 ? "inet6" : "inet");
+                if (set.timeout > 0) body += format(" timeout {}", set.timeout);
+                body += " -exist\n";
+                const auto entries = set_entries.find(set.set_name);
+                if (entries != set_entries.end()) {
+                    for (const auto& entry : entries->second) {
+                        body += format("ipset add {} {} -exist\n", set.set_name, entry);
+                    }
+                }
+            }
+            append_firewall(set.firewall_commands);
+        }
+        append_firewall(rule.firewall_commands);
+        append_once(rule.ip_rules);
+        append_once(rule.routes);
+    }
+
+    std::string out =
+        "#!/bin/sh\n"
+        "# Generated by `keen-pbr config export-script`: the kernel sets, firewall\n"
+        "# rules, ip rules and routes of the config, applied without keen-pbr.\n"
+        "set -e\n"
+        "\n";
+    std::vector<const char*> commands{"iptables"};
+    if (uses_ip6tables) commands.push_back("ip6tables");
+    for (const char* command : commands) {
+        out += format("{0} -t mangle -N KeenPbrTable 2>/dev/null || true\n"
+                      "{0} -t mangle -C PREROUTING -j KeenPbrTable 2>/dev/null || "
+                      "{0} -t mangle -A PREROUTING -j KeenPbrTable\n",
+                      command);
+    }
+    return out + body;
+}
+
+} // namespace keen_pbr3
@@ -0,0 +1,72 @@
+#pragma once
+
+#include "../config/config.hpp"
+#include "../routing/firewall_state.hpp"
+#include "../routing/netlink.hpp"
+
+#include <cstddef>
+#include <cstdint>
+#include <map>
+#include <string>
+#include <vector>
+
+namespace keen_pbr3 {
+
+class ListStreamer;
+
+struct ExplainedSet {
+    std::string set_name;
+    std::vector<std::string> firewall_commands;
+    // Entry timeout in seconds; set for dynamic sets of lists with ttl_ms.
+    uint32_t timeout{0};
+};
+
+// The "recipe" of one route rule: what keen-pbr would install for it, as
+// human-readable iptables / ip commands.
+struct ExplainedRouteRule {
+    size_t rule_index{0};
+    std::string outbound;
+    RuleActionType action{RuleActionType::Skip};
+    uint32_t fwmark{0};
+    std::vector<std::string> list_names;
+    // One entry per kernel set the rule matches on.
+    std::vector<ExplainedSet> sets;
+    // Rules of a route rule without lists (address/port/DSCP selectors only).
+    std::vector<std::string> firewall_commands;
+    std::vector<std::string> ip_rules;
+    std::vector<std::string> routes;
+};
+
+// Render `ip rule add ...` commands for a policy rule; family 0 yields one
+// command per family.
+std::vector<std::string> format_ip_rule_commands(const RuleSpec& spec);
+
+// Render the `ip route add ...` command for a route.
+std::string format_ip_route_command(const RouteSpec& spec);
+
+// Build the recipe for every route rule from config alone: nothing is
+// applied and no list is read, so every configured list gets its IPv4,
+// IPv6 and dynamic sets even if some would stay empty. Firewall commands
+// use the iptables backend. urltest outbounds use their first child.
+std::vector<ExplainedRouteRule> explain_config(const Config& config,
+                                               bool ipv6_enabled = true);
+
+std::string format_config_explanation(const std::vector<ExplainedRouteRule>& rules);
+
+// IPs and CIDRs of each static set, keyed by set name.
+using ExplainedSetEntries = std::map<std::string, std::vector<std::string>>;
+
+// Stream the cached, local-file and inline entries of every static set the
+// rules use. Dynamic sets are left out: dnsmasq fills them.
+ExplainedSetEntries collect_explained_set_entries(const Config& config,
+                                                  const std::vector<ExplainedRouteRule>& rules,
+                                                  ListStreamer& list_streamer);
+
+// Render the rules as a standalone POSIX shell script that creates and fills
+// the sets, hooks KeenPbrTable into mangle PREROUTING and installs the
+// firewall rules, ip rules and routes. Sets and the chain are created
+// idempotently; ip rules and routes are not, so it expects a clean system.
+std::string format_config_script(const std::vector<ExplainedRouteRule>& rules,
+                                 const ExplainedSetEntries& set_entries);
+
+} // namespace keen_pbr3
@@ -3,6 +3,7 @@
 #include "../cache/cache_manager.hpp"
 #include "../config/routing_state.hpp"
 #include "../firewall/firewall_verifier.hpp"
+#include "../health/ipv6_prefix_check.hpp"
 #include "../health/routing_health_checker.hpp"
 #include "../lists/list_streamer.hpp"
 #include "../lists/list_set_usage.hpp"
@@ -55,6 +56,7 @@ std::string outbound_type_label(OutboundType t) {
         case OutboundType::BLACKHOLE: return "blackhole";
         case OutboundType::IGNORE: return "ignore";
         case OutboundType::URLTEST: return "urltest";
+        case OutboundType::MULTIPATH: return "multipath";
     }
     return "unknown";
 }
@@ -85,6 +87,9 @@ std::string format_route_brief(const RouteTableCheck& rt) {
         desc += "unreachable " + destination;
     } else {
         desc += destination;
+        if (!rt.expected_nexthops.empty()) {
+            desc += " " + format_route_nexthops(rt.expected_nexthops);
+        }
         if (rt.expected_interface) {
             desc += keen_pbr3::format(" dev {}", *rt.expected_interface);
         }
@@ -134,7 +139,8 @@ std::map<std::string, std::string> infer_urltest_selections(const Config& config
         const bool routable =
             (ob.type == OutboundType::INTERFACE ||
              ob.type == OutboundType::TABLE ||
-             ob.type == OutboundType::URLTEST);
+             ob.type == OutboundType::URLTEST ||
+             ob.type == OutboundType::MULTIPATH);
         if (!routable) {
             continue;
         }
@@ -313,7 +319,8 @@ void print_outbound_section(const Config& config,
         const bool routable =
             (ob.type == OutboundType::INTERFACE ||
              ob.type == OutboundType::TABLE ||
-             ob.type == OutboundType::URLTEST);
+             ob.type == OutboundType::URLTEST ||
+             ob.type == OutboundType::MULTIPATH);
 
         uint32_t table_id = 0;
         uint32_t priority = 0;
@@ -343,7 +350,8 @@ void print_outbound_section(const Config& config,
         } else if (ob.type == OutboundType::TABLE) {
             std::cout << " table=" << table_id
                       << " fwmark=" << fwmark_hex(fwmark);
-        } else if (ob.type == OutboundType::URLTEST) {
+        } else if (ob.type == OutboundType::URLTEST ||
+                   ob.type == OutboundType::MULTIPATH) {
             std::cout << " fwmark=" << fwmark_hex(fwmark)
                       << " table=" << table_id;
         }
@@ -423,6 +431,16 @@ void print_firewall_section(const std::vector<DisplayFirewallRule>& firewall_rul
     }
 }
 
+void print_warnings(const RoutingHealthReport& report) {
+    if (report.warnings.empty()) {
+        return;
+    }
+    std::cout << "\nWarnings:\n";
+    for (const auto& warning : report.warnings) {
+        std::cout << "  - " << warning << "\n";
+    }
+}
+
 void print_overall_summary(const RoutingHealthReport& report,
                            const std::vector<DisplayFirewallRule>& firewall_rules) {
     const int failed = count_failed_checks(report, firewall_rules);
@@ -489,11 +507,18 @@ int run_status_command(const Config& config, const std::string& config_path) {
         routes.get_routes(),
         rules.get_rules(),
         netlink);
+    if (ipv6_decision.enabled) {
+        report.warnings = find_ipv6_prefix_mismatches(config, netlink.dump_interfaces());
+    }
+    for (const auto& warning : collect_config_warnings(config)) {
+        report.warnings.push_back(warning.message);
+    }
     const auto display_firewall_rules = build_display_firewall_rules(config, marks, report.firewall_rules);
 
     print_header(report, config_path);
     print_outbound_section(config, marks, routes, report);
     print_firewall_section(display_firewall_rules, report);
+    print_warnings(report);
     print_overall_summary(report, display_firewall_rules);
 
     return count_failed_checks(report, display_firewall_rules) == 0 ? 0 : 1;
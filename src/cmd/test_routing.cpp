@@ -7,6 +7,7 @@
 #include "../lists/kernel_set_tester.hpp"
 #include "../lists/list_entry_visitor.hpp"
 #include "../lists/list_streamer.hpp"
+#include "../routing/route_lookup.hpp"
 #include "../util/format_compat.hpp"
 #include "../util/firewall_backend_utils.hpp"
 #include "../util/string_compat.hpp"
@@ -98,12 +99,14 @@ public:
     }
 };
 
-// Pre-build lookup data for all lists referenced in route rules.
+// Pre-build lookup data for all lists referenced in route and DNS rules.
 std::map<std::string, ListLookupData> build_all_lookups(const Config& config,
                                                           const CacheManager& cache) {
     std::map<std::string, ListLookupData> result;
     const auto& route_rules =
         config.route.value_or(RouteConfig{}).rules.value_or(std::vector<RouteRule>{});
+    const auto& dns_rules =
+        config.dns.value_or(DnsConfig{}).rules.value_or(std::vector<DnsRule>{});
     const auto& lists_map =
         config.lists.value_or(std::map<std::string, ListConfig>{});
     ListStreamer streamer(cache);
@@ -117,6 +120,12 @@ std::map<std::string, ListLookupData> build_all_lookups(const Config& config,
             referenced.insert(list_name);
         }
     }
+    for (const auto& rule : dns_rules) {
+        if (!dns_rule_enabled(rule)) {
+            continue;
+        }
+        referenced.insert(rule.list.begin(), rule.list.end());
+    }
 
     for (const auto& list_name : referenced) {
         auto it = lists_map.find(list_name);
@@ -415,6 +424,33 @@ std::optional<ListMatchInfo> find_rule_match(const RouteRule& rule,
     return std::nullopt;
 }
 
+// Mirror dnsmasq server=/domain/ selection: the most specific domain wins, and
+// a list is served by the first enabled DNS rule that references it.
+std::optional<DnsRuleMatch> find_dns_rule_match(const Config& config,
+                                                const std::map<std::string, ListLookupData>& lookups,
+                                                const std::vector<std::string>& domain_cands) {
+    const auto& dns_rules =
+        config.dns.value_or(DnsConfig{}).rules.value_or(std::vector<DnsRule>{});
+
+    for (const auto& candidate : domain_cands) {
+        const std::string lower = lowercase_copy(candidate);
+        for (size_t idx = 0; idx < dns_rules.size(); ++idx) {
+            if (!dns_rule_enabled(dns_rules[idx])) {
+                continue;
+            }
+            for (const auto& list_name : dns_rules[idx].list) {
+                auto it = lookups.find(list_name);
+                if (it == lookups.end()) continue;
+                if (contains(it->second.domain_set, lower)) {
+                    return DnsRuleMatch{static_cast<int>(idx), dns_rules[idx].server,
+                                        ListMatchInfo{list_name, candidate}};
+                }
+            }
+        }
+    }
+    return std::nullopt;
+}
+
 std::string outbound_interface_name(const Config& config, const std::string& outbound_tag) {
     const auto& outbounds = config.outbounds.value_or(std::vector<Outbound>{});
     for (const auto& outbound : outbounds) {
@@ -510,6 +546,7 @@ TestRoutingResult compute_test_routing(const Config& config,
             result.dns_error = result.warnings.front();
         }
         result.resolved_ips = ips;
+        result.dns_rule_match = find_dns_rule_match(config, lookups, domain_cands);
     } else {
         ips.push_back(target);
     }
@@ -566,6 +603,15 @@ TestRoutingResult compute_test_routing(const Config& config,
             ? find_actual_outbound(*set_tester, rule_states, ip, is_ipv4_address(ip))
             : "(unknown)";
         per_ip.entry.ok = (per_ip.entry.expected_outbound == per_ip.entry.actual_outbound);
+        const auto mark = marks.find(per_ip.entry.expected_outbound);
+        if (set_tester.has_value() && mark != marks.end()) {
+            per_ip.entry.egress_interface = lookup_egress_interface(ip, mark->second);
+            const std::string interface_name =
+                outbound_interface_name(config, per_ip.entry.expected_outbound);
+            if (interface_name != "-" && per_ip.entry.egress_interface != interface_name) {
+                per_ip.entry.ok = false;
+            }
+        }
 
         per_ip.rule_ip_diagnostics.reserve(result.rule_diagnostics.size());
         for (size_t idx = 0; idx < result.rule_diagnostics.size(); ++idx) {
@@ -618,6 +664,88 @@ TestRoutingResult compute_test_routing(const Config& config,
     return result;
 }
 
+DomainMatchResult compute_domain_match(const Config& config,
+                                       const CacheManager& cache,
+                                       const std::string& domain) {
+    DomainMatchResult result;
+    result.domain = domain;
+
+    const auto lookups = build_all_lookups(config, cache);
+    const auto domain_cands = domain_candidates(lowercase_copy(domain));
+    result.dns_rule_match = find_dns_rule_match(config, lookups, domain_cands);
+
+    const auto& route_rules =
+        config.route.value_or(RouteConfig{}).rules.value_or(std::vector<RouteRule>{});
+    for (size_t idx = 0; idx < route_rules.size(); ++idx) {
+        auto match = find_rule_match(route_rules[idx], lookups, "", domain_cands);
+        if (!match) continue;
+        result.route_matches.push_back(
+            DomainRouteMatch{static_cast<int>(idx), route_rules[idx].outbound, std::move(*match)});
+    }
+    if (!result.route_matches.empty()) {
+        result.outbound = result.route_matches.front().outbound;
+    }
+
+    return result;
+}
+
+nlohmann::json explain_routing_json(const Config& config, const TestRoutingResult& result) {
+    const auto list_match_json = [](const std::optional<ListMatchInfo>& match) -> nlohmann::json {
+        if (!match) return nullptr;
+        return {{"list", match->list_name}, {"via", match->via}};
+    };
+
+    nlohmann::json dns = {{"rule_index", nullptr},
+                          {"server", nullptr},
+                          {"list_match", nullptr},
+                          {"fallback", nullptr}};
+    if (result.dns_rule_match) {
+        dns["rule_index"] = result.dns_rule_match->rule_index;
+        dns["server"] = result.dns_rule_match->server;
+        dns["list_match"] = list_match_json(result.dns_rule_match->list_match);
+    } else if (result.is_domain) {
+        dns["fallback"] = config.dns.value_or(DnsConfig{}).fallback.value_or(
+            std::vector<std::string>{});
+    }
+
+    nlohmann::json rules = nlohmann::json::array();
+    for (const auto& diag : result.rule_diagnostics) {
+        nlohmann::json ips = nlohmann::json::array();
+        for (const auto& ip_row : diag.ip_rows) {
+            nlohmann::json in_set = nullptr;
+            if (ip_row.in_ipset.has_value()) in_set = *ip_row.in_ipset;
+            ips.push_back({{"ip", ip_row.ip}, {"in_set", in_set}});
+        }
+        rules.push_back({{"rule_index", diag.rule_index},
+                         {"enabled", route_rule_enabled(diag.rule)},
+                         {"lists", route_rule_lists(diag.rule)},
+                         {"outbound", diag.outbound},
+                         {"interface", diag.interface_name},
+                         {"match", list_match_json(diag.target_match)},
+                         {"ips", std::move(ips)}});
+    }
+
+    nlohmann::json entries = nlohmann::json::array();
+    for (const auto& entry : result.entries) {
+        entries.push_back({{"ip", entry.ip},
+                           {"list_match", list_match_json(entry.list_match)},
+                           {"expected_outbound", entry.expected_outbound},
+                           {"actual_outbound", entry.actual_outbound},
+                           {"egress_interface", entry.egress_interface},
+                           {"ok", entry.ok}});
+    }
+
+    return {{"target", result.target},
+            {"is_domain", result.is_domain},
+            {"dns", std::move(dns)},
+            {"resolved_ips", result.resolved_ips},
+            {"dns_error", result.dns_error},
+            {"route_rules", std::move(rules)},
+            {"entries", std::move(entries)},
+            {"no_matching_rule", result.no_matching_rule},
+            {"warnings", result.warnings}};
+}
+
 int run_test_routing_command(const Config& config,
                               const CacheManager& cache,
                               const std::string& target) {
@@ -641,14 +769,18 @@ int run_test_routing_command(const Config& config,
     constexpr int ip_w        = 25;
     constexpr int list_w      = 35;
     constexpr int outbound_w  = 18;
+    constexpr int egress_w    = 12;
 
-    std::cout << keen_pbr3::format("{:<{}} | {:<{}} | {:<{}} | {:<{}} | {}\n",
+    std::cout << keen_pbr3::format("{:<{}} | {:<{}} | {:<{}} | {:<{}} | {:<{}} | {}\n",
                              "IP", ip_w,
                              "List Match", list_w,
                              "Expected Outbound", outbound_w,
                              "Actual Outbound", outbound_w,
+                             "Egress", egress_w,
                              "Status");
-    std::cout << std::string(ip_w + 3 + list_w + 3 + outbound_w + 3 + outbound_w + 3 + 6, '-')
+    std::cout << std::string(ip_w + 3 + list_w + 3 + outbound_w + 3 + outbound_w + 3 +
+                                 egress_w + 3 + 6,
+                             '-')
               << "\n";
 
     bool all_ok = true;
@@ -664,11 +796,12 @@ int run_test_routing_command(const Config& config,
         const std::string status = entry.ok ? "OK" : "NOK";
         if (!entry.ok) all_ok = false;
 
-        std::cout << keen_pbr3::format("{:<{}} | {:<{}} | {:<{}} | {:<{}} | {}\n",
+        std::cout << keen_pbr3::format("{:<{}} | {:<{}} | {:<{}} | {:<{}} | {:<{}} | {}\n",
                                  entry.ip, ip_w,
                                  list_str, list_w,
                                  entry.expected_outbound, outbound_w,
                                  entry.actual_outbound, outbound_w,
+                                 entry.egress_interface.value_or("-"), egress_w,
                                  status);
     }
 
@@ -3,6 +3,8 @@
 #include "../cache/cache_manager.hpp"
 #include "../config/config.hpp"
 
+#include <nlohmann/json.hpp>
+
 #include <optional>
 #include <string>
 #include <vector>
@@ -19,6 +21,9 @@ struct TestRoutingEntry {
     std::optional<ListMatchInfo> list_match;
     std::string expected_outbound; // rule outbound tag, or "(default)"
     std::string actual_outbound;   // tag, "(default)", or "(unknown)" if kernel check unavailable
+    // Interface the kernel routes the IP through with the expected outbound's
+    // fwmark; unset when not checked or the route has no device.
+    std::optional<std::string> egress_interface;
     bool ok;
 };
 
@@ -38,10 +43,18 @@ struct RuleDiagnostic {
     std::vector<RuleIpDiagnostic> ip_rows;
 };
 
+struct DnsRuleMatch {
+    int rule_index{0};
+    std::string server; // DNS server tag dnsmasq forwards the domain to
+    ListMatchInfo list_match;
+};
+
 struct TestRoutingResult {
     std::string target;
     bool is_domain{false};
     std::vector<std::string> resolved_ips;
+    // Unset when no DNS rule matches and dnsmasq uses dns.fallback.
+    std::optional<DnsRuleMatch> dns_rule_match;
     std::vector<TestRoutingEntry> entries;
     std::vector<RuleDiagnostic> rule_diagnostics;
     bool no_matching_rule{false};
@@ -49,11 +62,41 @@ struct TestRoutingResult {
     std::vector<std::string> warnings;
 };
 
-// Compute expected (config+cache) and actual (kernel ipset/nftset) routing for target.
+struct DomainRouteMatch {
+    int rule_index{0};
+    std::string outbound;
+    ListMatchInfo list_match;
+};
+
+struct DomainMatchResult {
+    std::string domain;
+    // Every enabled route rule whose lists contain the domain, in rule order.
+    std::vector<DomainRouteMatch> route_matches;
+    // Outbound of the first route match, or "(default)".
+    std::string outbound{"(default)"};
+    // Unset when no DNS rule matches and dnsmasq uses dns.fallback.
+    std::optional<DnsRuleMatch> dns_rule_match;
+};
+
+// Compute expected (config+cache) and actual (kernel ipset/nftset) routing for
+// target. With the live sets available, IPs routed to an outbound are also
+// looked up in the kernel routing tables with that outbound's fwmark; an
+// interface outbound whose egress differs from its interface is not ok.
 TestRoutingResult compute_test_routing(const Config& config,
                                         const CacheManager& cache,
                                         const std::string& target);
 
+// Match a domain against cached list data only, as test-routing does for its
+// domain candidates, but without resolving it or querying kernel sets.
+DomainMatchResult compute_domain_match(const Config& config,
+                                       const CacheManager& cache,
+                                       const std::string& domain);
+
+// Full pipeline view used by `keen-pbr explain`: DNS rule and server, resolved
+// IPs, every route rule with its list match, interface and kernel set
+// membership per IP, and the resulting per-IP verdict.
+nlohmann::json explain_routing_json(const Config& config, const TestRoutingResult& result);
+
 // Print table and return 0 if all entries match, 1 otherwise.
 int run_test_routing_command(const Config& config,
                               const CacheManager& cache,
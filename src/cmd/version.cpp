@@ -0,0 +1,76 @@
+#include "version.hpp"
+
+#include "../util/format_compat.hpp"
+
+#include <keen-pbr/version.hpp>
+
+#ifndef KEEN_PBR_TARGET_OS
+#define KEEN_PBR_TARGET_OS "linux"
+#endif
+#ifndef KEEN_PBR_TARGET_VERSION
+#define KEEN_PBR_TARGET_VERSION "unknown"
+#endif
+#ifndef KEEN_PBR_TARGET_ARCH
+#define KEEN_PBR_TARGET_ARCH "unknown"
+#endif
+#ifndef KEEN_PBR_BUILD_VARIANT
+#define KEEN_PBR_BUILD_VARIANT "full"
+#endif
+#ifndef KEEN_PBR_GIT_BRANCH
+#define KEEN_PBR_GIT_BRANCH "unknown"
+#endif
+#ifndef KEEN_PBR_GIT_COMMIT
+#define KEEN_PBR_GIT_COMMIT "unknown"
+#endif
+
+namespace keen_pbr3 {
+
+BuildInfo current_build_info() {
+    BuildInfo info;
+    info.version = KEEN_PBR3_VERSION_STRING;
+    info.build = KEEN_PBR3_VERSION_RELEASE_STRING;
+    info.commit = KEEN_PBR_GIT_COMMIT;
+    info.branch = KEEN_PBR_GIT_BRANCH;
+    info.target_os = KEEN_PBR_TARGET_OS;
+    info.target_version = KEEN_PBR_TARGET_VERSION;
+    info.architecture = KEEN_PBR_TARGET_ARCH;
+    info.variant = KEEN_PBR_BUILD_VARIANT;
+#if defined(__clang__)
+    info.compiler = "clang " __clang_version__;
+#elif defined(__GNUC__)
+    info.compiler = "gcc " __VERSION__;
+#else
+    info.compiler = "unknown";
+#endif
+    return info;
+}
+
+std::string format_build_info(const BuildInfo& info) {
+    std::string out;
+    out += format("version:        {}\n", info.version);
+    out += format("build:          {}\n", info.build);
+    out += format("commit:         {}\n", info.commit);
+    out += format("branch:         {}\n", info.branch);
+    out += format("target_os:      {}\n", info.target_os);
+    out += format("target_version: {}\n", info.target_version);
+    out += format("architecture:   {}\n", info.architecture);
+    out += format("variant:        {}\n", info.variant);
+    out += format("compiler:       {}\n", info.compiler);
+    return out;
+}
+
+nlohmann::json build_info_json(const BuildInfo& info) {
+    return {
+        {"version", info.version},
+        {"build", info.build},
+        {"commit", info.commit},
+        {"branch", info.branch},
+        {"target_os", info.target_os},
+        {"target_version", info.target_version},
+        {"architecture", info.architecture},
+        {"variant", info.variant},
+        {"compiler", info.compiler},
+    };
+}
+
+} // namespace keen_pbr3
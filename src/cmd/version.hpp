@@ -0,0 +1,31 @@
+#pragma once
+
+#include <string>
+
+#include <nlohmann/json.hpp>
+
+namespace keen_pbr3 {
+
+struct BuildInfo {
+    std::string version;
+    std::string build;
+    std::string commit;
+    std::string branch;
+    std::string target_os;
+    std::string target_version;
+    std::string architecture;
+    std::string variant;
+    std::string compiler;
+};
+
+// Identity of this binary: the version header plus the build definitions
+// CMake passes to the keen-pbr target ("unknown" when built without them).
+BuildInfo current_build_info();
+
+// Output of `keen-pbr version`: one "field: value" line per field.
+std::string format_build_info(const BuildInfo& info);
+
+// Output of `keen-pbr version --json`.
+nlohmann::json build_info_json(const BuildInfo& info);
+
+} // namespace keen_pbr3
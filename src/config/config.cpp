@@ -4,7 +4,9 @@
 #include "../util/system_info.hpp"
 
 #include <arpa/inet.h>
+#include <algorithm>
 #include <cctype>
+#include <fstream>
 #include <iomanip>
 #include <limits>
 #include <net/if.h>
@@ -14,6 +16,8 @@
 #include <nlohmann/json.hpp>
 
 #include "../dns/dns_probe_server.hpp"
+#include "../dns/dns_server.hpp"
+#include "../lists/asn_source.hpp"
 #include "../util/cron.hpp"
 
 namespace keen_pbr3 {
@@ -41,6 +45,12 @@ bool is_http_url(const std::string& url) {
     return scheme == "http" || scheme == "https";
 }
 
+bool is_sha256_hex(const std::string& value) {
+    return value.size() == 64 &&
+           std::all_of(value.begin(), value.end(),
+                       [](unsigned char ch) { return std::isxdigit(ch) != 0; });
+}
+
 void add_issue(std::vector<ConfigValidationIssue>& issues,
                std::string path,
                std::string message) {
@@ -107,11 +117,11 @@ void validate_optional_boolean_field(const json& root,
     }
 }
 
-void validate_optional_hex_string_field(const json& root,
-                                        const char* parent_key,
-                                        const char* child_key,
-                                        const std::string& path,
-                                        std::vector<ConfigValidationIssue>& issues) {
+void validate_optional_fwmark_string_field(const json& root,
+                                           const char* parent_key,
+                                           const char* child_key,
+                                           const std::string& path,
+                                           std::vector<ConfigValidationIssue>& issues) {
     const auto parent_it = root.find(parent_key);
     if (parent_it == root.end() || !parent_it->is_object()) {
         return;
@@ -123,7 +133,8 @@ void validate_optional_hex_string_field(const json& root,
     }
 
     if (!child_it->is_string()) {
-        add_issue(issues, path, path + " must be a string in hex format (e.g. 0x00010000)");
+        add_issue(issues, path,
+                  path + " must be a hex or decimal string (e.g. \"0x00010000\" or \"65536\")");
     }
 }
 
@@ -258,6 +269,50 @@ void validate_required_reference(std::vector<ConfigValidationIssue>& issues,
     }
 }
 
+void validate_multipath_nexthops(std::vector<ConfigValidationIssue>& issues,
+                                 const std::vector<Outbound>& outbounds,
+                                 const Outbound& ob) {
+    if (!ob.nexthops.has_value() || ob.nexthops->empty()) {
+        add_issue(issues, "outbounds." + ob.tag + ".nexthops",
+                  "Multipath outbound '" + ob.tag +
+                      "' 'nexthops' array must not be empty");
+        return;
+    }
+
+    std::set<std::string> seen;
+    for (size_t i = 0; i < ob.nexthops->size(); ++i) {
+        const auto& nexthop = ob.nexthops->at(i);
+        const std::string path =
+            "outbounds." + ob.tag + ".nexthops[" + std::to_string(i) + "]";
+
+        if (nexthop.weight.has_value() && (*nexthop.weight < 1 || *nexthop.weight > 256)) {
+            add_issue(issues, path + ".weight",
+                      "Multipath outbound '" + ob.tag +
+                          "' nexthop weight must be an integer from 1 through 256");
+        }
+
+        if (!seen.insert(nexthop.outbound).second) {
+            add_issue(issues, path + ".outbound",
+                      "Multipath outbound '" + ob.tag + "' lists outbound '" +
+                          nexthop.outbound + "' more than once");
+            continue;
+        }
+
+        const auto target = std::find_if(
+            outbounds.begin(), outbounds.end(),
+            [&](const Outbound& candidate) { return candidate.tag == nexthop.outbound; });
+        if (target == outbounds.end()) {
+            add_issue(issues, path + ".outbound",
+                      "Multipath outbound '" + ob.tag +
+                          "' references unknown outbound tag '" + nexthop.outbound + "'");
+        } else if (target->type != OutboundType::INTERFACE) {
+            add_issue(issues, path + ".outbound",
+                      "Multipath outbound '" + ob.tag + "' references outbound '" +
+                          nexthop.outbound + "' which is not an interface outbound");
+        }
+    }
+}
+
 void validate_rule_list_references(std::vector<ConfigValidationIssue>& issues,
                                    const std::set<std::string>& known_lists,
                                    const std::string& rule_path,
@@ -359,8 +414,8 @@ std::optional<std::string> get_optional_string_field(const json& object, const c
     return it->get<std::string>();
 }
 
-bool rule_has_list_condition(const json& rule) {
-    const auto list_it = rule.find("list");
+bool rule_has_list_condition(const json& rule, const char* key = "list") {
+    const auto list_it = rule.find(key);
     if (list_it == rule.end() || !list_it->is_array()) {
         return false;
     }
@@ -443,6 +498,7 @@ void validate_route_rule_specs(const json& root, std::vector<ConfigValidationIss
         const std::string rule_path = "route.rules[" + std::to_string(index) + "]";
         const bool has_any_condition =
             rule_has_list_condition(rule) ||
+            rule_has_list_condition(rule, "inbound_interfaces") ||
             rule_has_present_condition(rule, "dscp") ||
             rule_has_string_condition(rule, "src_port") ||
             rule_has_string_condition(rule, "dest_port") ||
@@ -452,7 +508,7 @@ void validate_route_rule_specs(const json& root, std::vector<ConfigValidationIss
         if (!has_any_condition) {
             add_issue(issues,
                       rule_path,
-                      "Route rule must include at least one condition: list, dscp, src_port, dest_port, src_addr, or dest_addr.");
+                      "Route rule must include at least one condition: list, inbound_interfaces, dscp, src_port, dest_port, src_addr, or dest_addr.");
         }
 
         validate_dscp_field(rule, rule_path, issues);
@@ -495,27 +551,23 @@ std::string route_rule_unsupported_iptables_multiport_path(size_t rule_index,
     return "route.rules[" + std::to_string(rule_index) + "].dest_port";
 }
 
-void validate_route_inbound_interfaces(const json& root, std::vector<ConfigValidationIssue>& issues) {
-    const auto route_it = root.find("route");
-    if (route_it == root.end() || !route_it->is_object()) {
-        return;
-    }
-
-    const auto inbound_it = route_it->find("inbound_interfaces");
-    if (inbound_it == route_it->end() || inbound_it->is_null()) {
+void validate_inbound_interfaces(const json& object,
+                                 const std::string& path,
+                                 std::vector<ConfigValidationIssue>& issues) {
+    const auto inbound_it = object.find("inbound_interfaces");
+    if (inbound_it == object.end() || inbound_it->is_null()) {
         return;
     }
 
     if (!inbound_it->is_array()) {
-        add_issue(issues, "route.inbound_interfaces", "route.inbound_interfaces must be an array of strings");
+        add_issue(issues, path, path + " must be an array of strings");
         return;
     }
 
     std::set<std::string> seen_interfaces;
     for (size_t index = 0; index < inbound_it->size(); ++index) {
         const auto& iface_value = inbound_it->at(index);
-        const std::string iface_path =
-            "route.inbound_interfaces[" + std::to_string(index) + "]";
+        const std::string iface_path = path + "[" + std::to_string(index) + "]";
 
         if (!iface_value.is_string()) {
             add_issue(issues, iface_path, iface_path + " must be a string");
@@ -550,6 +602,90 @@ void validate_route_inbound_interfaces(const json& root, std::vector<ConfigValid
     }
 }
 
+void validate_route_inbound_interfaces(const json& root, std::vector<ConfigValidationIssue>& issues) {
+    const auto route_it = root.find("route");
+    if (route_it == root.end() || !route_it->is_object()) {
+        return;
+    }
+
+    validate_inbound_interfaces(*route_it, "route.inbound_interfaces", issues);
+
+    const auto rules_it = route_it->find("rules");
+    if (rules_it == route_it->end() || !rules_it->is_array()) {
+        return;
+    }
+    for (size_t index = 0; index < rules_it->size(); ++index) {
+        const auto& rule = rules_it->at(index);
+        if (rule.is_object()) {
+            validate_inbound_interfaces(
+                rule, "route.rules[" + std::to_string(index) + "].inbound_interfaces", issues);
+        }
+    }
+}
+
+// nlohmann::json keeps the last of repeated object keys, so two lists with the
+// same name would silently collapse into one. Tracks the keys seen in each open
+// object while parsing and reports repeats with their dotted path.
+class DuplicateKeyDetector {
+public:
+    explicit DuplicateKeyDetector(std::vector<ConfigValidationIssue>& issues) : issues_(issues) {}
+
+    bool operator()(int /*depth*/, json::parse_event_t event, json& parsed) {
+        switch (event) {
+        case json::parse_event_t::object_start:
+        case json::parse_event_t::array_start:
+            frames_.push_back(Frame{event == json::parse_event_t::object_start, {}, {}, 0});
+            break;
+        case json::parse_event_t::key: {
+            Frame& frame = frames_.back();
+            frame.key = parsed.get<std::string>();
+            if (!frame.keys.insert(frame.key).second) {
+                add_issue(issues_, current_path(), "Duplicate key '" + frame.key + "'");
+            }
+            break;
+        }
+        case json::parse_event_t::object_end:
+        case json::parse_event_t::array_end:
+            frames_.pop_back();
+            advance_array_index();
+            break;
+        case json::parse_event_t::value:
+            advance_array_index();
+            break;
+        }
+        return true;
+    }
+
+private:
+    struct Frame {
+        bool object;
+        std::set<std::string> keys;
+        std::string key;
+        size_t index;
+    };
+
+    void advance_array_index() {
+        if (!frames_.empty() && !frames_.back().object) {
+            ++frames_.back().index;
+        }
+    }
+
+    std::string current_path() const {
+        std::string path;
+        for (const auto& frame : frames_) {
+            if (!frame.object) {
+                path += "[" + std::to_string(frame.index) + "]";
+            } else {
+                path += (path.empty() ? "" : ".") + frame.key;
+            }
+        }
+        return path;
+    }
+
+    std::vector<ConfigValidationIssue>& issues_;
+    std::vector<Frame> frames_;
+};
+
 } // namespace
 
 ConfigValidationError::ConfigValidationError(std::vector<ConfigValidationIssue> issues)
@@ -624,9 +760,10 @@ static void validate_fwmark_mask(uint32_t mask) {
     }
 }
 
-uint32_t parse_fwmark_hex_or_throw(const std::optional<std::string>& raw,
-                                   uint32_t default_value,
-                                   const std::string& path) {
+// Accepts "0x"-prefixed hex ("0x105") or plain decimal ("261").
+uint32_t parse_fwmark_value_or_throw(const std::optional<std::string>& raw,
+                                     uint32_t default_value,
+                                     const std::string& path) {
     if (!raw.has_value()) {
         return default_value;
     }
@@ -636,21 +773,24 @@ uint32_t parse_fwmark_hex_or_throw(const std::optional<std::string>& raw,
         throw ConfigError(path + " must not be empty");
     }
 
-    if (value.size() < 3 || value[0] != '0' || (value[1] != 'x' && value[1] != 'X')) {
-        throw ConfigError(path + " must be a hexadecimal string with 0x prefix");
+    const bool is_hex = value.size() >= 2 && value[0] == '0' && (value[1] == 'x' || value[1] == 'X');
+    const std::size_t digits_begin = is_hex ? 2 : 0;
+    if (value.size() == digits_begin) {
+        throw ConfigError(path + " must contain hexadecimal digits after the 0x prefix");
     }
-
-    for (size_t i = 2; i < value.size(); ++i) {
-        if (!std::isxdigit(static_cast<unsigned char>(value[i]))) {
-            throw ConfigError(path + " must contain only hexadecimal digits");
+    for (size_t i = digits_begin; i < value.size(); ++i) {
+        const auto c = static_cast<unsigned char>(value[i]);
+        if (is_hex ? !std::isxdigit(c) : !std::isdigit(c)) {
+            throw ConfigError(path + (is_hex ? " must contain only hexadecimal digits"
+                                             : " must be a decimal number or a hex value with 0x prefix"));
         }
     }
 
-    unsigned long parsed = 0;
+    unsigned long long parsed = 0;
     try {
-        parsed = std::stoul(value, nullptr, 16);
+        parsed = std::stoull(value, nullptr, is_hex ? 16 : 10);
     } catch (const std::exception&) {
-        throw ConfigError(path + " must be a valid 32-bit hexadecimal value");
+        throw ConfigError(path + " must fit into 32 bits (max 0xFFFFFFFF)");
     }
 
     if (parsed > std::numeric_limits<uint32_t>::max()) {
@@ -661,11 +801,11 @@ uint32_t parse_fwmark_hex_or_throw(const std::optional<std::string>& raw,
 }
 
 uint32_t parse_fwmark_start_or_throw(const FwmarkConfig& fwmark_cfg) {
-    return parse_fwmark_hex_or_throw(fwmark_cfg.start, 0x00010000, "fwmark.start");
+    return parse_fwmark_value_or_throw(fwmark_cfg.start, 0x00010000, "fwmark.start");
 }
 
 uint32_t parse_fwmark_mask_or_throw(const FwmarkConfig& fwmark_cfg) {
-    return parse_fwmark_hex_or_throw(fwmark_cfg.mask, 0x00FF0000, "fwmark.mask");
+    return parse_fwmark_value_or_throw(fwmark_cfg.mask, 0x00FF0000, "fwmark.mask");
 }
 
 Config parse_config(const std::string& json_str) {
@@ -674,16 +814,16 @@ Config parse_config(const std::string& json_str) {
     std::vector<ConfigValidationIssue> issues;
 
     try {
-        parsed_json = json::parse(json_str, nullptr, true, true);
+        parsed_json = json::parse(json_str, DuplicateKeyDetector(issues), true, true);
     } catch (const json::parse_error& e) {
         throw ConfigValidationError(std::vector<ConfigValidationIssue>{
             {"$", std::string("Invalid JSON: ") + e.what()}
         });
     }
 
-    validate_optional_hex_string_field(
+    validate_optional_fwmark_string_field(
         parsed_json, "fwmark", "start", "fwmark.start", issues);
-    validate_optional_hex_string_field(
+    validate_optional_fwmark_string_field(
         parsed_json, "fwmark", "mask", "fwmark.mask", issues);
     validate_optional_integer_field(
         parsed_json, "iproute", "table_start", "iproute.table_start", issues);
@@ -709,6 +849,9 @@ Config parse_config(const std::string& json_str) {
         parsed_json, "api", "write_timeout_seconds", "api.write_timeout_seconds", issues);
     validate_optional_integer_field(
         parsed_json, "api", "keep_alive_timeout_seconds", "api.keep_alive_timeout_seconds", issues);
+    validate_optional_integer_field(
+        parsed_json, "api", "keenetic_interface_cache_seconds",
+        "api.keenetic_interface_cache_seconds", issues);
     validate_optional_string_field(
         parsed_json, "daemon", "firewall_backend", "daemon.firewall_backend", issues);
     validate_optional_boolean_field(
@@ -716,6 +859,8 @@ Config parse_config(const std::string& json_str) {
     validate_optional_boolean_field(
         parsed_json, "daemon", "clear_dynamic_sets_on_apply",
         "daemon.clear_dynamic_sets_on_apply", issues);
+    validate_optional_boolean_field(
+        parsed_json, "daemon", "firewall_self_heal", "daemon.firewall_self_heal", issues);
     validate_optional_boolean_field(
         parsed_json, "daemon", "ipv6_enabled", "daemon.ipv6_enabled", issues);
     validate_route_rule_specs(parsed_json, issues);
@@ -755,6 +900,15 @@ void validate_config(const Config& cfg) {
                   "daemon.max_file_size_bytes must be greater than 0");
     }
 
+    if (cfg.daemon && cfg.daemon->bootstrap_dns.has_value()) {
+        try {
+            validate_dns_address(*cfg.daemon->bootstrap_dns);
+        } catch (const DnsError& e) {
+            add_issue(issues, "daemon.bootstrap_dns",
+                      std::string("daemon.bootstrap_dns is invalid: ") + e.what());
+        }
+    }
+
     if (cfg.daemon && cfg.daemon->exec_timeout_seconds.value_or(30) < 1) {
         add_issue(issues, "daemon.exec_timeout_seconds",
                   "daemon.exec_timeout_seconds must be >= 1");
@@ -797,6 +951,14 @@ void validate_config(const Config& cfg) {
             add_issue(issues, "api.keep_alive_timeout_seconds",
                       "api.keep_alive_timeout_seconds is too large");
         }
+        if (cfg.api->keenetic_interface_cache_seconds.value_or(120) < 0) {
+            add_issue(issues, "api.keenetic_interface_cache_seconds",
+                      "api.keenetic_interface_cache_seconds must be >= 0");
+        }
+        if (cfg.api->keenetic_interface_cache_seconds.value_or(120) > std::numeric_limits<int>::max()) {
+            add_issue(issues, "api.keenetic_interface_cache_seconds",
+                      "api.keenetic_interface_cache_seconds is too large");
+        }
     }
 
     if (cfg.lists_autoupdate) {
@@ -814,6 +976,27 @@ void validate_config(const Config& cfg) {
                           std::string("lists_autoupdate.cron: ") + e.what());
             }
         }
+        if (cfg.lists_autoupdate->force_reimport_interval_hours.value_or(0) < 0) {
+            add_issue(issues, "lists_autoupdate.force_reimport_interval_hours",
+                      "lists_autoupdate.force_reimport_interval_hours must be >= 0");
+        }
+    }
+
+    if (cfg.asn_source) {
+        const auto& url_template = cfg.asn_source->url_template;
+        const auto& file = cfg.asn_source->file;
+        if (url_template.has_value() == file.has_value()) {
+            add_issue(issues, "asn_source",
+                      "asn_source must set exactly one of: url_template, file");
+        }
+        if (url_template.has_value() &&
+            (!is_http_url(*url_template) || url_template->find("{asn}") == std::string::npos)) {
+            add_issue(issues, "asn_source.url_template",
+                      "asn_source.url_template must be an http or https URL containing {asn}");
+        }
+        if (file.has_value() && file->empty()) {
+            add_issue(issues, "asn_source.file", "asn_source.file must not be empty");
+        }
     }
 
     for (const auto& [name, list_cfg] : cfg.lists.value_or(std::map<std::string, ListConfig>{})) {
@@ -831,10 +1014,59 @@ void validate_config(const Config& cfg) {
                       "List '" + name +
                           "' must have at least one of: url, domains, ip_cidrs, file");
         }
-        if (has_url && !is_http_url(*list_cfg.url)) {
+        const bool has_asn_url = has_url && is_asn_list_url(*list_cfg.url);
+        if (has_url && !has_asn_url && !is_http_url(*list_cfg.url)) {
             add_issue(issues,
                       list_path + ".url",
-                      "List URL must use the http or https scheme");
+                      "List URL must use the http or https scheme or be as:<ASN>");
+        }
+        if (has_asn_url) {
+            if (!parse_asn_list_url(*list_cfg.url).has_value()) {
+                add_issue(issues,
+                          list_path + ".url",
+                          "List as:<ASN> url needs an AS number from 1 to 4294967295");
+            }
+            if (!cfg.asn_source.has_value()) {
+                add_issue(issues,
+                          list_path + ".url",
+                          "List as:<ASN> url requires asn_source");
+            }
+            if (list_cfg.fallback_urls.has_value()) {
+                add_issue(issues,
+                          list_path + ".fallback_urls",
+                          "List fallback_urls cannot be used with an as:<ASN> url");
+            }
+        }
+        if (list_cfg.sha256.has_value()) {
+            if (!has_url) {
+                add_issue(issues,
+                          list_path + ".sha256",
+                          "List sha256 requires url");
+            } else if (!is_sha256_hex(*list_cfg.sha256)) {
+                add_issue(issues,
+                          list_path + ".sha256",
+                          "List sha256 must be 64 hexadecimal characters");
+            }
+        }
+        if (list_cfg.validate_content.has_value() && !has_url) {
+            add_issue(issues,
+                      list_path + ".validate_content",
+                      "List validate_content requires url");
+        }
+        if (list_cfg.fallback_urls.has_value()) {
+            if (!has_url) {
+                add_issue(issues,
+                          list_path + ".fallback_urls",
+                          "List fallback_urls requires url");
+            }
+            const auto& fallback_urls = *list_cfg.fallback_urls;
+            for (size_t i = 0; i < fallback_urls.size(); ++i) {
+                if (!is_http_url(fallback_urls[i])) {
+                    add_issue(issues,
+                              list_path + ".fallback_urls[" + std::to_string(i) + "]",
+                              "List URL must use the http or https scheme");
+                }
+            }
         }
 
     }
@@ -865,6 +1097,11 @@ void validate_config(const Config& cfg) {
             }
         }
 
+        if (ob.type == OutboundType::MULTIPATH) {
+            validate_multipath_nexthops(issues, outbounds, ob);
+            continue;
+        }
+
         if (ob.type != OutboundType::URLTEST) continue;
 
         if (!ob.url.has_value() || ob.url->empty()) {
@@ -940,6 +1177,21 @@ void validate_config(const Config& cfg) {
                                     rule.outbound,
                                     "outbound tag");
         validate_rule_list_references(issues, list_names, rule_path, route_rule_lists(rule));
+
+        // The global filter already returns packets from other interfaces, so
+        // a rule-level interface outside it could never match.
+        const auto& global_interfaces =
+            cfg.route.value_or(RouteConfig{}).inbound_interfaces.value_or(std::vector<std::string>{});
+        const auto& rule_interfaces = rule.inbound_interfaces.value_or(std::vector<std::string>{});
+        for (size_t i = 0; i < rule_interfaces.size() && !global_interfaces.empty(); ++i) {
+            if (std::find(global_interfaces.begin(), global_interfaces.end(), rule_interfaces[i]) ==
+                global_interfaces.end()) {
+                add_issue(issues,
+                          rule_path + ".inbound_interfaces[" + std::to_string(i) + "]",
+                          "Interface '" + rule_interfaces[i] +
+                              "' is not listed in route.inbound_interfaces");
+            }
+        }
     }
 
     const FwmarkConfig fwmark_cfg = cfg.fwmark.value_or(FwmarkConfig{});
@@ -997,7 +1249,8 @@ void validate_config(const Config& cfg) {
             [](const Outbound& outbound) {
                 return outbound.type == OutboundType::INTERFACE ||
                        outbound.type == OutboundType::TABLE ||
-                       outbound.type == OutboundType::URLTEST;
+                       outbound.type == OutboundType::URLTEST ||
+                       outbound.type == OutboundType::MULTIPATH;
             });
         if (priority_start_valid &&
             (rule_priority_start == 0 ||
@@ -1170,12 +1423,21 @@ void validate_config(const Config& cfg) {
             }
         }
 
+        if (cfg.dns->edns_packet_max.has_value() &&
+            (*cfg.dns->edns_packet_max < 512 || *cfg.dns->edns_packet_max > 4096)) {
+            add_issue(issues, "dns.edns_packet_max",
+                      "dns.edns_packet_max must be between 512 and 4096");
+        }
+
         if (cfg.dns->dns_test_server.has_value()) {
             try {
                 const auto& test_cfg = *cfg.dns->dns_test_server;
                 const std::string* answer_ip =
                     test_cfg.answer_ipv4 ? &*test_cfg.answer_ipv4 : nullptr;
                 (void)parse_dns_probe_server_settings(test_cfg.listen, answer_ip);
+                if (test_cfg.domain_suffix.has_value()) {
+                    validate_dns_probe_domain_suffix(*test_cfg.domain_suffix);
+                }
             } catch (const std::exception& e) {
                 add_issue(issues, "dns.dns_test_server",
                           std::string("dns.dns_test_server: ") + e.what());
@@ -1206,12 +1468,87 @@ FirewallBackendPreference firewall_backend_preference(const Config& config) {
     return to_firewall_backend_preference(*config.daemon->firewall_backend);
 }
 
+std::string read_config_source(const std::string& path, std::istream& stdin_stream) {
+    std::ostringstream contents;
+    if (path == kStdinConfigPath) {
+        contents << stdin_stream.rdbuf();
+        return contents.str();
+    }
+    std::ifstream input(path);
+    if (!input.is_open()) {
+        throw ConfigError("Cannot open config file: " + path);
+    }
+    contents << input.rdbuf();
+    return contents.str();
+}
+
 Config parse_and_validate_config(const std::string& json_str) {
     Config config = parse_config(json_str);
     validate_config(config);
     return config;
 }
 
+std::vector<ConfigValidationIssue> collect_config_warnings(const Config& cfg) {
+    std::vector<ConfigValidationIssue> warnings;
+    const auto lists = cfg.lists.value_or(std::map<std::string, ListConfig>{});
+    const auto route_rules =
+        cfg.route.value_or(RouteConfig{}).rules.value_or(std::vector<RouteRule>{});
+    const auto dns_rules =
+        cfg.dns.value_or(DnsConfig{}).rules.value_or(std::vector<DnsRule>{});
+
+    std::set<std::string> referenced;
+    for (const auto& rule : route_rules) {
+        referenced.insert(route_rule_lists(rule).begin(), route_rule_lists(rule).end());
+    }
+    for (const auto& rule : dns_rules) {
+        referenced.insert(rule.list.begin(), rule.list.end());
+    }
+
+    for (const auto& [name, list] : lists) {
+        if (referenced.count(name) == 0) {
+            add_issue(warnings, "lists." + name,
+                      "List '" + name + "' is not used by any route or DNS rule");
+        }
+    }
+
+    auto only_disabled_lists = [&lists](const std::vector<std::string>& refs) {
+        if (refs.empty()) return false;
+        return std::all_of(refs.begin(), refs.end(), [&lists](const std::string& ref) {
+            const auto it = lists.find(ref);
+            return it != lists.end() && !list_enabled(it->second);
+        });
+    };
+    for (size_t i = 0; i < route_rules.size(); ++i) {
+        if (route_rule_enabled(route_rules[i]) &&
+            only_disabled_lists(route_rule_lists(route_rules[i]))) {
+            const std::string path = "route.rules[" + std::to_string(i) + "]";
+            add_issue(warnings, path, path + " only references disabled lists and matches nothing");
+        }
+    }
+    for (size_t i = 0; i < dns_rules.size(); ++i) {
+        if (dns_rule_enabled(dns_rules[i]) && only_disabled_lists(dns_rules[i].list)) {
+            const std::string path = "dns.rules[" + std::to_string(i) + "]";
+            add_issue(warnings, path, path + " only references disabled lists and matches nothing");
+        }
+    }
+
+    if (!cfg.daemon.value_or(DaemonConfig{}).ipv6_enabled.value_or(true)) {
+        for (const auto& [name, list] : lists) {
+            const auto cidrs = list.ip_cidrs.value_or(std::vector<std::string>{});
+            const bool has_ipv6 = std::any_of(cidrs.begin(), cidrs.end(), [](const std::string& cidr) {
+                return cidr.find(':') != std::string::npos;
+            });
+            if (has_ipv6) {
+                add_issue(warnings, "lists." + name + ".ip_cidrs",
+                          "List '" + name +
+                              "' contains IPv6 entries that are ignored because daemon.ipv6_enabled is false");
+            }
+        }
+    }
+
+    return warnings;
+}
+
 OutboundMarkMap allocate_outbound_marks(const FwmarkConfig& fwmark_cfg,
                                          const std::vector<Outbound>& outbounds) {
     uint32_t mask  = parse_fwmark_mask_or_throw(fwmark_cfg);
@@ -1233,7 +1570,8 @@ OutboundMarkMap allocate_outbound_marks(const FwmarkConfig& fwmark_cfg,
     for (const auto& ob : outbounds) {
         if (ob.type != OutboundType::INTERFACE &&
             ob.type != OutboundType::TABLE &&
-            ob.type != OutboundType::URLTEST) continue;
+            ob.type != OutboundType::URLTEST &&
+            ob.type != OutboundType::MULTIPATH) continue;
         routable.push_back(&ob);
     }
     std::sort(routable.begin(), routable.end(), [](const Outbound* left, const Outbound* right) {
@@ -2,6 +2,7 @@
 
 #include <cstddef>
 #include <cstdint>
+#include <iosfwd>
 #include <map>
 #include <stdexcept>
 #include <string>
@@ -56,6 +57,7 @@ using RouteConfig          = api::Route;
 using FwmarkConfig         = api::Fwmark;
 using IprouteConfig        = api::Iproute;
 using ListsAutoupdateConfig = api::ListsAutoupdate;
+using AsnSourceConfig      = api::AsnSource;
 // Note: DnsRule.list (not .lists) and RouteRule.list (not .lists) match JSON keys.
 
 constexpr std::size_t kDefaultMaxFileSizeBytes = std::size_t{8} * 1024U * 1024U; // 8 MiB
@@ -73,11 +75,30 @@ inline bool dns_rule_enabled(const DnsRule& rule) {
     return rule.enabled.value_or(true);
 }
 
+// A disabled list stays referenceable by rules but behaves as if it were empty.
+inline bool list_enabled(const ListConfig& list) {
+    return list.enabled.value_or(true);
+}
+
+inline api::RoutePolicy route_policy(const Config& config) {
+    return config.iproute.value_or(IprouteConfig{}).route_policy.value_or(api::RoutePolicy::METRIC);
+}
+
 // --- JSON deserialization and validation ---
 
+// `--config -` reads the config from stdin instead of a file.
+inline constexpr const char* kStdinConfigPath = "-";
+
+// Raw config text from `path`, or from `stdin_stream` when `path` is
+// kStdinConfigPath. Throws ConfigError if the file cannot be opened.
+std::string read_config_source(const std::string& path, std::istream& stdin_stream);
 Config parse_config(const std::string& json_str);
 void validate_config(const Config& config);
 Config parse_and_validate_config(const std::string& json_str);
+// Non-fatal advisories for a config that already passed validate_config():
+// unused lists, rules that can never match because every list they reference
+// is disabled, and IPv6 entries while IPv6 is disabled. Never throws.
+std::vector<ConfigValidationIssue> collect_config_warnings(const Config& config);
 size_t max_file_size_bytes(const Config& config);
 FirewallBackendPreference firewall_backend_preference(const Config& config);
 
@@ -0,0 +1,336 @@
+#include "config_comments.hpp"
+
+#include <nlohmann/json.hpp>
+
+#include <algorithm>
+#include <cstddef>
+#include <map>
+#include <stdexcept>
+#include <string>
+#include <vector>
+
+namespace keen_pbr3 {
+
+namespace {
+
+// Member and container positions are keyed by JSON pointer ("" is the root,
+// "/lists/work/url" a nested member, "/route/rules/0" an array element).
+struct ScannedJson {
+    std::vector<std::string> header;
+    std::vector<std::string> footer;
+    std::map<std::string, std::vector<std::string>> leading;
+    std::map<std::string, std::vector<std::string>> closing;
+    std::map<std::string, std::string> trailing;
+    // Offset of the member's key (or array element's first character).
+    std::map<std::string, std::size_t> member_start;
+    // Offset just past the member's value, including a following comma.
+    std::map<std::string, std::size_t> member_end;
+    // Offset of a container's closing bracket.
+    std::map<std::string, std::size_t> closing_start;
+    std::map<std::string, std::size_t> array_size;
+};
+
+struct Frame {
+    bool is_array{false};
+    std::string path;
+    std::size_t size{0};
+    bool expect_key{true};
+    std::string member;
+};
+
+std::string escape_pointer_token(const std::string& key) {
+    std::string escaped;
+    for (const char c : key) {
+        if (c == '~') {
+            escaped += "~0";
+        } else if (c == '/') {
+            escaped += "~1";
+        } else {
+            escaped += c;
+        }
+    }
+    return escaped;
+}
+
+// Tokenizes JSON with comments just deeply enough to know which member every
+// comment belongs to. Throws std::runtime_error on malformed input.
+ScannedJson scan_json(const std::string& text) {
+    ScannedJson result;
+    std::vector<Frame> stack;
+    std::vector<std::string> pending;
+    std::string last_member;
+    bool has_last_member = false;
+    bool newline_since_token = true;
+    bool root_seen = false;
+    std::size_t pos = 0;
+
+    const auto begin_member = [&](const std::string& path, std::size_t offset) {
+        if (!pending.empty()) {
+            result.leading[path] = std::move(pending);
+            pending.clear();
+        }
+        result.member_start[path] = offset;
+        has_last_member = false;
+    };
+
+    // Returns the path of the value that starts at `offset`.
+    const auto begin_value = [&](std::size_t offset) -> std::string {
+        if (stack.empty()) {
+            if (root_seen) throw std::runtime_error("unexpected data after the top-level value");
+            root_seen = true;
+            result.header = std::move(pending);
+            pending.clear();
+            return "";
+        }
+        Frame& top = stack.back();
+        if (top.is_array) {
+            top.member = top.path + "/" + std::to_string(top.size);
+            ++top.size;
+            begin_member(top.member, offset);
+        } else if (top.expect_key || top.member.empty()) {
+            throw std::runtime_error("object value without a key");
+        }
+        return top.member;
+    };
+
+    const auto end_value = [&](const std::string& path, std::size_t end) {
+        result.member_end[path] = end;
+        last_member = path;
+        has_last_member = !stack.empty();
+    };
+
+    while (pos < text.size()) {
+        const char c = text[pos];
+        if (c == '\n') {
+            newline_since_token = true;
+            ++pos;
+            continue;
+        }
+        if (c == ' ' || c == '\t' || c == '\r') {
+            ++pos;
+            continue;
+        }
+
+        if (c == '/') {
+            std::size_t end = std::string::npos;
+            if (pos + 1 < text.size() && text[pos + 1] == '/') {
+                end = text.find('\n', pos);
+                if (end == std::string::npos) end = text.size();
+            } else if (pos + 1 < text.size() && text[pos + 1] == '*') {
+                end = text.find("*/", pos + 2);
+                if (end == std::string::npos) throw std::runtime_error("unterminated comment");
+                end += 2;
+            } else {
+                throw std::runtime_error("unexpected '/'");
+            }
+            std::string comment = text.substr(pos, end - pos);
+            while (!comment.empty() && (comment.back() == '\r' || comment.back() == ' ')) {
+                comment.pop_back();
+            }
+            if (!newline_since_token && has_last_member) {
+                std::string& trailing = result.trailing[last_member];
+                trailing += trailing.empty() ? comment : " " + comment;
+            } else {
+                pending.push_back(std::move(comment));
+            }
+            pos = end;
+            continue;
+        }
+
+        newline_since_token = false;
+
+        if (c == '{' || c == '[') {
+            const std::string path = begin_value(pos);
+            Frame frame;
+            frame.is_array = (c == '[');
+            frame.path = path;
+            stack.push_back(std::move(frame));
+            has_last_member = false;
+            ++pos;
+            continue;
+        }
+
+        if (c == '}' || c == ']') {
+            if (stack.empty() || stack.back().is_array != (c == ']')) {
+                throw std::runtime_error("mismatched closing bracket");
+            }
+            Frame frame = std::move(stack.back());
+            stack.pop_back();
+            if (!pending.empty()) {
+                result.closing[frame.path] = std::move(pending);
+                pending.clear();
+            }
+            result.closing_start[frame.path] = pos;
+            if (frame.is_array) {
+                result.array_size[frame.path] = frame.size;
+            }
+            ++pos;
+            end_value(frame.path, pos);
+            continue;
+        }
+
+        if (c == ',') {
+            if (stack.empty()) throw std::runtime_error("unexpected ','");
+            if (has_last_member) {
+                result.member_end[last_member] = pos + 1;
+            }
+            if (!stack.back().is_array) {
+                stack.back().expect_key = true;
+            }
+            ++pos;
+            continue;
+        }
+
+        if (c == ':') {
+            ++pos;
+            continue;
+        }
+
+        if (c == '"') {
+            std::size_t end = pos + 1;
+            while (end < text.size() && text[end] != '"') {
+                end += (text[end] == '\\') ? 2 : 1;
+            }
+            if (end >= text.size()) throw std::runtime_error("unterminated string");
+            ++end;
+
+            if (!stack.empty() && !stack.back().is_array && stack.back().expect_key) {
+                Frame& top = stack.back();
+                const std::string key =
+                    nlohmann::json::parse(text.substr(pos, end - pos)).get<std::string>();
+                top.member = top.path + "/" + escape_pointer_token(key);
+                top.expect_key = false;
+                begin_member(top.member, pos);
+            } else {
+                end_value(begin_value(pos), end);
+            }
+            pos = end;
+            continue;
+        }
+
+        // Number, true, false or null.
+        std::size_t end = pos;
+        while (end < text.size() && std::string(",]}: \t\r\n/").find(text[end]) == std::string::npos) {
+            ++end;
+        }
+        end_value(begin_value(pos), end);
+        pos = end;
+    }
+
+    if (!stack.empty()) throw std::runtime_error("unterminated JSON value");
+    result.footer = std::move(pending);
+    return result;
+}
+
+struct Insertion {
+    std::size_t offset;
+    std::string text;
+};
+
+// Returns the offset of the line holding `offset` when only indentation
+// precedes it there, and sets `indent` to that indentation.
+bool line_start_of(const std::string& text, std::size_t offset, std::size_t& line_start,
+                   std::string& indent) {
+    const std::size_t newline = offset == 0 ? std::string::npos : text.rfind('\n', offset - 1);
+    line_start = newline == std::string::npos ? 0 : newline + 1;
+    indent = text.substr(line_start, offset - line_start);
+    return indent.find_first_not_of(" \t") == std::string::npos;
+}
+
+void add_comment_lines(const std::string& text,
+                       std::size_t offset,
+                       const std::vector<std::string>& comments,
+                       std::vector<Insertion>& insertions) {
+    std::size_t line_start = 0;
+    std::string indent;
+    if (!line_start_of(text, offset, line_start, indent)) {
+        return;
+    }
+    std::string block;
+    for (const auto& comment : comments) {
+        block += indent + comment + "\n";
+    }
+    insertions.push_back({line_start, std::move(block)});
+}
+
+} // namespace
+
+std::string preserve_config_comments(const std::string& original,
+                                     const std::string& formatted) {
+    ScannedJson source;
+    ScannedJson target;
+    try {
+        source = scan_json(original);
+        target = scan_json(formatted);
+    } catch (const std::exception&) {
+        return formatted;
+    }
+
+    // Element indices only identify the same item while the array keeps its
+    // length; comments below a resized array are dropped instead of moved.
+    std::vector<std::string> resized_arrays;
+    for (const auto& [path, size] : source.array_size) {
+        const auto it = target.array_size.find(path);
+        if (it == target.array_size.end() || it->second != size) {
+            resized_arrays.push_back(path + "/");
+        }
+    }
+    const auto is_stable = [&](const std::string& path) {
+        return std::none_of(resized_arrays.begin(), resized_arrays.end(),
+                            [&](const std::string& prefix) {
+                                return path.compare(0, prefix.size(), prefix) == 0;
+                            });
+    };
+
+    std::vector<Insertion> insertions;
+    if (!source.header.empty()) {
+        std::string block;
+        for (const auto& comment : source.header) block += comment + "\n";
+        insertions.push_back({0, std::move(block)});
+    }
+    for (const auto& [path, comments] : source.leading) {
+        const auto it = target.member_start.find(path);
+        if (it != target.member_start.end() && is_stable(path)) {
+            add_comment_lines(formatted, it->second, comments, insertions);
+        }
+    }
+    for (const auto& [path, comments] : source.closing) {
+        const auto it = target.closing_start.find(path);
+        if (it != target.closing_start.end() && is_stable(path)) {
+            add_comment_lines(formatted, it->second, comments, insertions);
+        }
+    }
+    for (const auto& [path, comment] : source.trailing) {
+        const auto it = target.member_end.find(path);
+        if (it == target.member_end.end() || !is_stable(path)) continue;
+        // A line comment must stay last on its line.
+        const bool at_line_end = it->second >= formatted.size() || formatted[it->second] == '\n';
+        if (comment.rfind("//", 0) == 0 && !at_line_end) continue;
+        insertions.push_back({it->second, " " + comment});
+    }
+
+    if (insertions.empty() && source.footer.empty()) {
+        return formatted;
+    }
+
+    std::stable_sort(insertions.begin(), insertions.end(),
+                     [](const Insertion& lhs, const Insertion& rhs) {
+                         return lhs.offset < rhs.offset;
+                     });
+    std::string result;
+    std::size_t copied = 0;
+    for (const auto& insertion : insertions) {
+        result.append(formatted, copied, insertion.offset - copied);
+        result += insertion.text;
+        copied = insertion.offset;
+    }
+    result.append(formatted, copied, std::string::npos);
+
+    if (!source.footer.empty()) {
+        if (!result.empty() && result.back() != '\n') result += '\n';
+        for (const auto& comment : source.footer) result += comment + "\n";
+    }
+    return result;
+}
+
+} // namespace keen_pbr3
@@ -0,0 +1,22 @@
+#pragma once
+
+#include <string>
+
+namespace keen_pbr3 {
+
+// Carry `//` and `/* */` comments from the config file on disk over to a
+// freshly serialized config (which never contains comments).
+//
+// Comments are attached to the JSON member that follows them (or, for a
+// comment on the same line, the member it trails) and re-inserted at the
+// same JSON path in `formatted`. Comments before the opening brace and after
+// the closing brace stay at the top and bottom of the file. Comments whose
+// member was removed are dropped, as are comments inside an array whose
+// length changed, because element indices no longer identify the same item.
+//
+// `formatted` must be comment-free JSON; `original` may be any text. If
+// `original` cannot be scanned, `formatted` is returned unchanged.
+std::string preserve_config_comments(const std::string& original,
+                                     const std::string& formatted);
+
+} // namespace keen_pbr3
@@ -0,0 +1,31 @@
+#include "config_import.hpp"
+
+#include "config_writer.hpp"
+
+#include <fstream>
+#include <sstream>
+
+namespace keen_pbr3 {
+
+std::string export_config_text(const std::string& config_path) {
+    std::ifstream input(config_path);
+    if (!input.is_open()) {
+        return {};
+    }
+    std::ostringstream contents;
+    contents << input.rdbuf();
+    return contents.str();
+}
+
+std::string import_config(const std::string& body, const ConfigImportSubmitFn& submit) {
+    Config config = parse_config(body);
+    validate_config(config);
+    return submit(std::move(config), body);
+}
+
+void commit_imported_config(const std::string& config_path, const std::string& text) {
+    backup_config_file(config_path);
+    write_config_atomically(config_path, text);
+}
+
+} // namespace keen_pbr3
@@ -0,0 +1,28 @@
+#pragma once
+
+#include "config.hpp"
+
+#include <functional>
+#include <string>
+
+namespace keen_pbr3 {
+
+// The config file exactly as stored on disk, comments included. Empty when
+// the file cannot be read.
+std::string export_config_text(const std::string& config_path);
+
+// Hands a validated import to the runtime. Receives the parsed config and the
+// body to be written verbatim; returns an operation id.
+using ConfigImportSubmitFn = std::function<std::string(Config config, std::string text)>;
+
+// Import `body` as the new config: parse and validate it, then pass it to
+// `submit`. An invalid body throws ConfigError (ConfigValidationError for
+// schema problems) before `submit` runs, so nothing is staged, written or
+// backed up.
+std::string import_config(const std::string& body, const ConfigImportSubmitFn& submit);
+
+// Commit an imported config once it has been applied: copy the current file
+// to `<config_path>.bak`, then write `text` verbatim.
+void commit_imported_config(const std::string& config_path, const std::string& text);
+
+} // namespace keen_pbr3
@@ -3,9 +3,11 @@
 #include <cerrno>
 #include <cstring>
 #include <filesystem>
+#include <fstream>
 #include <functional>
 #include <mutex>
 #include <fcntl.h>
+#include <sstream>
 #include <stdexcept>
 #include <string>
 #include <sys/stat.h>
@@ -122,4 +124,18 @@ void write_config_atomically(const std::string& config_path,
     }
 }
 
+void backup_config_file(const std::string& config_path) {
+    struct stat existing {};
+    if (::stat(config_path.c_str(), &existing) != 0) {
+        if (errno == ENOENT) return;
+        throw errno_error("Cannot inspect config file for backup");
+    }
+    std::ifstream input(config_path, std::ios::binary);
+    if (!input.is_open()) throw errno_error("Cannot open config file for backup");
+    std::ostringstream contents;
+    contents << input.rdbuf();
+    if (input.bad()) throw std::runtime_error("Cannot read config file for backup");
+    write_config_atomically(config_path + ".bak", contents.str());
+}
+
 } // namespace keen_pbr3
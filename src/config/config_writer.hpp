@@ -10,6 +10,10 @@ namespace keen_pbr3 {
 void write_config_atomically(const std::string& config_path,
                              const std::string& body);
 
+// Durably copy the current config file to `<config_path>.bak` before it is
+// replaced. Does nothing when the config file does not exist yet.
+void backup_config_file(const std::string& config_path);
+
 enum class ConfigWritePhase {
     BeforeTemporaryWrite,
     BeforeTemporaryFsync,
@@ -0,0 +1,286 @@
+#include "effective_config.hpp"
+
+#include <algorithm>
+#include <array>
+#include <cctype>
+#include <functional>
+#include <iomanip>
+#include <sstream>
+#include <string_view>
+
+#include "../firewall/firewall_verifier.hpp"
+#include "../health/url_tester.hpp"
+
+namespace keen_pbr3 {
+
+namespace {
+
+std::string format_fwmark_hex(uint32_t value) {
+    std::ostringstream ss;
+    ss << "0x" << std::hex << std::setfill('0') << std::setw(8) << value;
+    return ss.str();
+}
+
+std::string to_lower(std::string_view value) {
+    std::string lowered(value);
+    std::transform(lowered.begin(), lowered.end(), lowered.begin(), [](unsigned char c) {
+        return static_cast<char>(std::tolower(c));
+    });
+    return lowered;
+}
+
+bool is_secret_name(std::string_view name) {
+    static const std::array<std::string_view, 9> kSecretMarkers = {
+        "token", "password", "passwd", "secret", "auth",
+        "apikey", "api_key", "credential", "private_key",
+    };
+    const std::string lowered = to_lower(name);
+    for (const auto marker : kSecretMarkers) {
+        if (lowered.find(marker) != std::string::npos) {
+            return true;
+        }
+    }
+    return lowered == "key" || lowered == "sig" || lowered == "signature";
+}
+
+// Redacts userinfo ("user:pass@") and credential-like query parameters of a
+// URL. Strings without a scheme are returned unchanged.
+std::string redact_url(const std::string& value) {
+    const auto scheme_end = value.find("://");
+    if (scheme_end == std::string::npos) {
+        return value;
+    }
+
+    std::string result = value;
+    const std::size_t authority_begin = scheme_end + 3;
+    const std::size_t authority_end = result.find_first_of("/?#", authority_begin);
+    const std::size_t at = result.rfind(
+        '@', authority_end == std::string::npos ? std::string::npos : authority_end - 1);
+    if (at != std::string::npos && at >= authority_begin) {
+        result.replace(authority_begin, at - authority_begin, kRedactedValue);
+    }
+
+    const std::size_t query_begin = result.find('?');
+    if (query_begin == std::string::npos) {
+        return result;
+    }
+    const std::size_t fragment_begin = result.find('#', query_begin);
+    const std::size_t query_end =
+        fragment_begin == std::string::npos ? result.size() : fragment_begin;
+
+    std::string query;
+    std::size_t pos = query_begin + 1;
+    while (pos <= query_end) {
+        std::size_t next = result.find('&', pos);
+        if (next == std::string::npos || next > query_end) {
+            next = query_end;
+        }
+        const std::string param = result.substr(pos, next - pos);
+        const std::size_t eq = param.find('=');
+        if (!query.empty()) {
+            query += '&';
+        }
+        if (eq != std::string::npos && is_secret_name(param.substr(0, eq))) {
+            query += param.substr(0, eq + 1) + kRedactedValue;
+        } else {
+            query += param;
+        }
+        pos = next + 1;
+    }
+
+    return result.substr(0, query_begin + 1) + query + result.substr(query_end);
+}
+
+void prune_nulls(nlohmann::json& value) {
+    std::function<bool(nlohmann::json&)> prune = [&](nlohmann::json& node) -> bool {
+        if (node.is_object()) {
+            for (auto it = node.begin(); it != node.end();) {
+                if (prune(it.value())) {
+                    it = node.erase(it);
+                } else {
+                    ++it;
+                }
+            }
+            return false;
+        }
+        if (node.is_array()) {
+            for (auto& item : node) {
+                (void)prune(item);
+            }
+            return false;
+        }
+        return node.is_null();
+    };
+    (void)prune(value);
+}
+
+void resolve_outbound_defaults(Outbound& outbound, const DaemonConfig& daemon) {
+    if (outbound.type == OutboundType::INTERFACE || outbound.type == OutboundType::TABLE) {
+        if (!outbound.strict_enforcement.has_value()) {
+            outbound.strict_enforcement = daemon.strict_enforcement;
+        }
+        if (!outbound.strict_enforcement_action.has_value()) {
+            outbound.strict_enforcement_action = daemon.strict_enforcement_action;
+        }
+        return;
+    }
+
+    if (outbound.type == OutboundType::MULTIPATH) {
+        if (outbound.nexthops.has_value()) {
+            for (auto& nexthop : *outbound.nexthops) {
+                nexthop.weight = nexthop.weight.value_or(1);
+            }
+        }
+        return;
+    }
+
+    if (outbound.type != OutboundType::URLTEST) {
+        return;
+    }
+
+    outbound.interval_ms = outbound.interval_ms.value_or(180000);
+    outbound.tolerance_ms = outbound.tolerance_ms.value_or(100);
+    outbound.probe_timeout_ms =
+        outbound.probe_timeout_ms.value_or(kDefaultUrltestProbeTimeoutMs);
+    outbound.conntrack_on_switch =
+        outbound.conntrack_on_switch.value_or(api::ConntrackOnSwitch::PRESERVE);
+
+    RetryConfig retry = outbound.retry.value_or(RetryConfig{});
+    retry.attempts = retry.attempts.value_or(1);
+    retry.interval_ms = retry.interval_ms.value_or(1000);
+    outbound.retry = retry;
+
+    CircuitBreakerConfig breaker = outbound.circuit_breaker.value_or(CircuitBreakerConfig{});
+    breaker.failure_threshold = breaker.failure_threshold.value_or(5);
+    breaker.success_threshold = breaker.success_threshold.value_or(2);
+    breaker.timeout_ms = breaker.timeout_ms.value_or(30000);
+    breaker.half_open_max_requests = breaker.half_open_max_requests.value_or(1);
+    outbound.circuit_breaker = breaker;
+
+    if (outbound.outbound_groups.has_value()) {
+        for (auto& group : *outbound.outbound_groups) {
+            group.weight = group.weight.value_or(1);
+        }
+    }
+}
+
+} // namespace
+
+Config resolve_effective_config(Config config) {
+    DaemonConfig daemon = config.daemon.value_or(DaemonConfig{});
+    daemon.cache_dir = daemon.cache_dir.value_or("/var/cache/keen-pbr");
+    daemon.firewall_backend =
+        daemon.firewall_backend.value_or(api::DaemonConfigFirewallBackend::AUTO);
+    daemon.firewall_verify_max_bytes = daemon.firewall_verify_max_bytes.value_or(
+        static_cast<int64_t>(DEFAULT_FIREWALL_VERIFY_CAPTURE_MAX_BYTES));
+    daemon.skip_marked_packets = daemon.skip_marked_packets.value_or(true);
+    daemon.clear_dynamic_sets_on_apply = daemon.clear_dynamic_sets_on_apply.value_or(true);
+    daemon.firewall_self_heal = daemon.firewall_self_heal.value_or(false);
+    daemon.ipv6_enabled = daemon.ipv6_enabled.value_or(true);
+    daemon.strict_enforcement = daemon.strict_enforcement.value_or(false);
+    daemon.strict_enforcement_action =
+        daemon.strict_enforcement_action.value_or(api::StrictEnforcementAction::UNREACHABLE);
+    daemon.max_file_size_bytes =
+        daemon.max_file_size_bytes.value_or(static_cast<int64_t>(kDefaultMaxFileSizeBytes));
+    daemon.exec_timeout_seconds = daemon.exec_timeout_seconds.value_or(30);
+    daemon.resolver_ready_timeout_seconds = daemon.resolver_ready_timeout_seconds.value_or(120);
+    daemon.exec_kill_grace_seconds = daemon.exec_kill_grace_seconds.value_or(2);
+    config.daemon = daemon;
+
+    ApiConfig api = config.api.value_or(ApiConfig{});
+    api.enabled = api.enabled.value_or(false);
+    api.listen = api.listen.value_or("0.0.0.0:12121");
+    api.max_request_body_bytes = api.max_request_body_bytes.value_or(1024 * 1024);
+    api.read_timeout_seconds = api.read_timeout_seconds.value_or(15);
+    api.write_timeout_seconds = api.write_timeout_seconds.value_or(15);
+    api.keep_alive_timeout_seconds = api.keep_alive_timeout_seconds.value_or(20);
+    api.keenetic_interface_cache_seconds = api.keenetic_interface_cache_seconds.value_or(120);
+    config.api = api;
+
+    FwmarkConfig fwmark = config.fwmark.value_or(FwmarkConfig{});
+    fwmark.start = format_fwmark_hex(fwmark_start_value(fwmark));
+    fwmark.mask = format_fwmark_hex(fwmark_mask_value(fwmark));
+    config.fwmark = fwmark;
+
+    IprouteConfig iproute = config.iproute.value_or(IprouteConfig{});
+    iproute.table_start = iproute.table_start.value_or(150);
+    iproute.rule_priority_start = iproute.rule_priority_start.value_or(*iproute.table_start);
+    config.iproute = iproute;
+
+    ListsAutoupdateConfig autoupdate = config.lists_autoupdate.value_or(ListsAutoupdateConfig{});
+    autoupdate.enabled = autoupdate.enabled.value_or(false);
+    autoupdate.force_reimport_interval_hours =
+        autoupdate.force_reimport_interval_hours.value_or(0);
+    config.lists_autoupdate = autoupdate;
+
+    if (config.lists.has_value()) {
+        for (auto& [name, list] : *config.lists) {
+            (void)name;
+            list.ttl_ms = list.ttl_ms.value_or(0);
+        }
+    }
+
+    if (config.outbounds.has_value()) {
+        for (auto& outbound : *config.outbounds) {
+            resolve_outbound_defaults(outbound, daemon);
+        }
+    }
+
+    if (config.dns.has_value()) {
+        if (config.dns->servers.has_value()) {
+            for (auto& server : *config.dns->servers) {
+                server.type = server.type.value_or(api::DnsServerType::STATIC);
+            }
+        }
+        if (config.dns->rules.has_value()) {
+            for (auto& rule : *config.dns->rules) {
+                rule.enabled = dns_rule_enabled(rule);
+                rule.allow_domain_rebinding = rule.allow_domain_rebinding.value_or(false);
+            }
+        }
+    }
+
+    if (config.route.has_value() && config.route->rules.has_value()) {
+        for (auto& rule : *config.route->rules) {
+            rule.enabled = route_rule_enabled(rule);
+        }
+    }
+
+    return config;
+}
+
+nlohmann::json redact_config_secrets(nlohmann::json value) {
+    if (value.is_object()) {
+        for (auto it = value.begin(); it != value.end(); ++it) {
+            if (is_secret_name(it.key()) && !it.value().is_null() &&
+                !it.value().is_boolean() && !it.value().is_object() &&
+                !it.value().is_array()) {
+                it.value() = kRedactedValue;
+            } else {
+                it.value() = redact_config_secrets(std::move(it.value()));
+            }
+        }
+        return value;
+    }
+
+    if (value.is_array()) {
+        for (auto& item : value) {
+            item = redact_config_secrets(std::move(item));
+        }
+        return value;
+    }
+
+    if (value.is_string()) {
+        return redact_url(value.get<std::string>());
+    }
+
+    return value;
+}
+
+std::string dump_effective_config(const Config& config) {
+    nlohmann::json json = resolve_effective_config(config);
+    prune_nulls(json);
+    return redact_config_secrets(std::move(json)).dump(1, '\t') + "\n";
+}
+
+} // namespace keen_pbr3
@@ -0,0 +1,28 @@
+#pragma once
+
+#include <string>
+
+#include <nlohmann/json.hpp>
+
+#include "config.hpp"
+
+namespace keen_pbr3 {
+
+// Placeholder written in place of values that look like credentials.
+inline constexpr const char* kRedactedValue = "<redacted>";
+
+// Returns a copy of the config with every omitted setting replaced by the
+// default the daemon would use at runtime, so the result describes the
+// effective behaviour without relying on implicit defaults.
+Config resolve_effective_config(Config config);
+
+// Replaces secret-looking values in a serialized config: object keys that
+// name credentials (token, password, secret, auth, ...), URL userinfo and
+// credential-like URL query parameters.
+nlohmann::json redact_config_secrets(nlohmann::json value);
+
+// Resolves defaults, drops nulls, redacts secrets and pretty-prints the
+// result as JSON suitable for attaching to a support ticket.
+std::string dump_effective_config(const Config& config);
+
+} // namespace keen_pbr3
@@ -137,7 +137,21 @@ std::optional<std::string> ListParser::normalize_domain(std::string_view s) {
     return std::string(s);
 }
 
+std::optional<std::string> ListParser::normalize_tld(std::string_view s) {
+    if (s.size() < 4 || s.substr(0, 4) != "tld:") return std::nullopt;
+    s.remove_prefix(4);
+    if (!s.empty() && s.front() == '.') s.remove_prefix(1);
+    if (s.empty() || s.front() == '*') return std::nullopt;
+    auto tld = normalize_domain(s);
+    if (!tld || tld->find('.') != std::string::npos) return std::nullopt;
+    return tld;
+}
+
 bool ListParser::classify_entry(std::string_view entry, ListEntryVisitor& visitor) {
+    if (auto tld = normalize_tld(entry)) {
+        visitor.on_entry(EntryType::Domain, *tld);
+        return true;
+    }
     if (is_cidr_v4(entry) || is_cidr_v6(entry)) {
         visitor.on_entry(EntryType::Cidr, entry);
         return true;
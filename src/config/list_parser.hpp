@@ -35,6 +35,11 @@ public:
     // trailing root dot are removed from the returned value.
     static std::optional<std::string> normalize_domain(std::string_view domain);
 
+    // Validate a "tld:<label>" entry (e.g. "tld:ru" or "tld:.onion") and
+    // return the bare top-level label. It is emitted as a single-label
+    // domain, which matches every domain under that TLD.
+    static std::optional<std::string> normalize_tld(std::string_view entry);
+
 private:
     static bool is_ipv4(std::string_view s);
     static bool is_ipv6(std::string_view s);
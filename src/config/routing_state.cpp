@@ -3,6 +3,7 @@
 #include "addr_spec.hpp"
 #include "../routing/target.hpp"
 
+#include <algorithm>
 #include <arpa/inet.h>
 #include <cstring>
 #include <set>
@@ -70,6 +71,18 @@ bool strict_enforcement_enabled(const Config& cfg, const Outbound& ob) {
     return cfg.daemon.value_or(DaemonConfig{}).strict_enforcement.value_or(false);
 }
 
+// IPv6 follows strict_enforcement unless the outbound sets
+// strict_enforcement_ipv6.
+bool strict_enforcement_ipv6_enabled(const Config& cfg, const Outbound& ob) {
+    return ob.strict_enforcement_ipv6.value_or(strict_enforcement_enabled(cfg, ob));
+}
+
+// strict_enforcement_ipv6: false lets marked IPv6 traffic fall back to normal
+// routing instead of being closed when the outbound has no IPv6 path.
+bool ipv6_may_leak(const Outbound& ob) {
+    return !ob.strict_enforcement_ipv6.value_or(true);
+}
+
 RuleAction strict_enforcement_action(const Config& cfg, const Outbound& ob) {
     const auto configured = ob.strict_enforcement_action.has_value()
         ? ob.strict_enforcement_action
@@ -315,6 +328,42 @@ std::vector<const Outbound*> ordered_urltest_children(const std::vector<Outbound
     return ordered;
 }
 
+// Whether an interface outbound can carry traffic of the given family. A
+// configured gateway pins the outbound to the families it names, matching
+// make_default_routes().
+bool outbound_carries_family(const Outbound& ob, int family,
+                             const OutboundFamilyAvailabilityFn& family_available) {
+    if (ob.gateway.has_value() || ob.gateway6.has_value()) {
+        return family == AF_INET ? ob.gateway.has_value() : ob.gateway6.has_value();
+    }
+    return outbound_family_available(ob, family, family_available);
+}
+
+// Collects the usable paths of a multipath outbound for one family. Children
+// that are missing, unreachable or lack the family are left out so the route
+// only spreads flows across interfaces that can actually deliver them.
+std::vector<RouteNexthop> available_multipath_nexthops(
+    const std::vector<Outbound>& outbounds,
+    const Outbound& multipath,
+    int family,
+    const OutboundReachabilityFn& reachability_check,
+    const OutboundFamilyAvailabilityFn& family_available) {
+    std::vector<RouteNexthop> nexthops;
+    for (const auto& entry : multipath.nexthops.value_or(std::vector<api::NexthopElement>{})) {
+        const Outbound* child = find_outbound(outbounds, entry.outbound);
+        if (!child || child->type != OutboundType::INTERFACE) continue;
+        if (reachability_check && !reachability_check(*child)) continue;
+        if (!outbound_carries_family(*child, family, family_available)) continue;
+
+        RouteNexthop path;
+        path.interface = child->interface.value_or("");
+        path.gateway = family == AF_INET ? child->gateway : child->gateway6;
+        path.weight = static_cast<uint32_t>(entry.weight.value_or(1));
+        nexthops.push_back(std::move(path));
+    }
+    return nexthops;
+}
+
 // Returns the (offset)th non-reserved table ID starting from table_start.
 static uint32_t safe_table_id(uint32_t table_start, uint32_t offset) {
     uint32_t id = table_start;
@@ -345,7 +394,8 @@ void populate_routing_state(const Config& cfg,
             case OutboundType::INTERFACE: return 0;
             case OutboundType::TABLE: return 1;
             case OutboundType::URLTEST: return 2;
-            default: return 3;
+            case OutboundType::MULTIPATH: return 3;
+            default: return 4;
             }
         };
         return rank(left.type) == rank(right.type) ? left.tag < right.tag
@@ -357,15 +407,20 @@ void populate_routing_state(const Config& cfg,
     std::vector<RouteSpec> planned_routes;
     std::vector<RuleSpec> planned_rules;
 
+    // Outbound whose routes are being planned, recorded on each route so a
+    // kernel rejection can be reported against it.
+    std::string route_owner;
     auto add_route_if_enabled = [&](const RouteSpec& route) {
         if (!ipv6_enabled && route.family == AF_INET6) {
             return;
         }
         planned_routes.push_back(route);
+        planned_routes.back().outbound = route_owner;
     };
 
     const uint32_t rule_priority_start = static_cast<uint32_t>(
         cfg.iproute.value_or(IprouteConfig{}).rule_priority_start.value_or(table_start));
+    const bool single_default_route = route_policy(cfg) == api::RoutePolicy::REPLACE;
     uint32_t table_offset = 0;
     uint32_t rule_offset = 0;
     std::set<std::string> internal_detours;
@@ -377,7 +432,7 @@ void populate_routing_state(const Config& cfg,
         if (server.detour) internal_detours.insert(*server.detour);
     }
     auto add_lookup_and_guard = [&](uint32_t mark, uint32_t table, const Outbound& ob,
-                                    bool guard_required) {
+                                    bool guard4, bool guard6) {
         RuleSpec lookup;
         lookup.fwmark = mark;
         lookup.fwmask = fwmark_mask;
@@ -385,11 +440,17 @@ void populate_routing_state(const Config& cfg,
         lookup.priority = rule_priority_start + rule_offset * 2;
         if (!ipv6_enabled) lookup.family = AF_INET;
         planned_rules.push_back(lookup);
-        if (guard_required) {
+        guard6 = guard6 && ipv6_enabled;
+        if (guard4 || guard6) {
             RuleSpec guard = lookup;
             guard.table = 0;
             guard.priority += 1;
             guard.action = strict_enforcement_action(cfg, ob);
+            if (!guard6) {
+                guard.family = AF_INET;
+            } else if (!guard4) {
+                guard.family = AF_INET6;
+            }
             planned_rules.push_back(guard);
         }
         ++rule_offset;
@@ -397,10 +458,11 @@ void populate_routing_state(const Config& cfg,
     auto add_internal_detour_guard = [&](uint32_t table, const Outbound& ob) {
         const auto mark = marks.find(internal_detour_mark_key(ob.tag));
         if (mark != marks.end() && internal_detours.count(ob.tag) != 0) {
-            add_lookup_and_guard(mark->second, table, ob, true);
+            add_lookup_and_guard(mark->second, table, ob, true, true);
         }
     };
     for (const auto& ob : outbounds) {
+        route_owner = ob.tag;
         if (ob.type == OutboundType::INTERFACE) {
             auto mark_it = marks.find(ob.tag);
             if (mark_it == marks.end()) continue;
@@ -409,6 +471,7 @@ void populate_routing_state(const Config& cfg,
             ++table_offset;
 
             const bool strict = strict_enforcement_enabled(cfg, ob);
+            const bool strict6 = strict_enforcement_ipv6_enabled(cfg, ob);
             const bool reachable = !reachability_check || reachability_check(ob);
             if (reachable) {
                 for (const auto& route : make_default_routes(table_id, ob, family_available)) {
@@ -416,23 +479,27 @@ void populate_routing_state(const Config& cfg,
                 }
                 for (const auto& route : make_family_closure_routes(
                          table_id, ob, family_available)) {
+                    if (route.family == AF_INET6 && ipv6_may_leak(ob)) continue;
                     add_route_if_enabled(route);
                 }
-            } else if (strict) {
+            } else {
                 for (const auto& route : make_unreachable_routes(table_id)) {
-                    add_route_if_enabled(route);
+                    if (route.family == AF_INET6 ? strict6 : strict) {
+                        add_route_if_enabled(route);
+                    }
                 }
             }
 
-            add_lookup_and_guard(mark_it->second, table_id, ob, strict);
+            add_lookup_and_guard(mark_it->second, table_id, ob, strict, strict6);
             add_internal_detour_guard(table_id, ob);
         } else if (ob.type == OutboundType::TABLE) {
             auto mark_it = marks.find(ob.tag);
             if (mark_it == marks.end()) continue;
 
-            const bool strict = strict_enforcement_enabled(cfg, ob);
             add_lookup_and_guard(mark_it->second,
-                                 static_cast<uint32_t>(ob.table.value_or(0)), ob, strict);
+                                 static_cast<uint32_t>(ob.table.value_or(0)), ob,
+                                 strict_enforcement_enabled(cfg, ob),
+                                 strict_enforcement_ipv6_enabled(cfg, ob));
             add_internal_detour_guard(static_cast<uint32_t>(ob.table.value_or(0)), ob);
             ++table_offset;
         } else if (ob.type == OutboundType::URLTEST) {
@@ -462,8 +529,11 @@ void populate_routing_state(const Config& cfg,
                     }
                 }
 
+                // The replace policy keeps one default per family, so the
+                // metric-ordered fallbacks are left out.
                 uint32_t metric = 1;
                 for (const Outbound* child : ordered_children) {
+                    if (single_default_route) break;
                     if (child->type != OutboundType::INTERFACE) {
                         continue;
                     }
@@ -494,7 +564,39 @@ void populate_routing_state(const Config& cfg,
                 }
             }
 
-            add_lookup_and_guard(mark_it->second, table_id, ob, true);
+            add_lookup_and_guard(mark_it->second, table_id, ob, true, true);
+            add_internal_detour_guard(table_id, ob);
+        } else if (ob.type == OutboundType::MULTIPATH) {
+            auto mark_it = marks.find(ob.tag);
+            if (mark_it == marks.end()) continue;
+
+            uint32_t table_id = safe_table_id(table_start, table_offset);
+            ++table_offset;
+
+            // Spread flows across every usable path. With a single path left the
+            // route degrades to a plain default route, and with none the family
+            // is closed with an unreachable default.
+            for (int family : {AF_INET, AF_INET6}) {
+                auto nexthops = available_multipath_nexthops(
+                    outbounds, ob, family, reachability_check, family_available);
+
+                RouteSpec route;
+                route.destination = "default";
+                route.table = table_id;
+                route.family = family;
+                if (nexthops.empty()) {
+                    route.unreachable = true;
+                    route.metric = kUnreachableRouteMetric;
+                } else if (nexthops.size() == 1) {
+                    route.interface = nexthops.front().interface;
+                    route.gateway = nexthops.front().gateway;
+                } else {
+                    route.nexthops = std::move(nexthops);
+                }
+                add_route_if_enabled(route);
+            }
+
+            add_lookup_and_guard(mark_it->second, table_id, ob, true, true);
             add_internal_detour_guard(table_id, ob);
         }
         // BLACKHOLE: no routing table, no ip rule
@@ -544,7 +646,12 @@ bool is_interface_outbound_reachable(const Outbound& outbound, NetlinkManager& n
 bool interface_has_routed_ipv6(const DumpedInterface& interface) {
     return std::any_of(
         interface.ipv6_addresses.begin(), interface.ipv6_addresses.end(),
-        [](const std::string& cidr) {
+        [&interface](const std::string& cidr) {
+            if (std::find(interface.deprecated_ipv6_addresses.begin(),
+                          interface.deprecated_ipv6_addresses.end(),
+                          cidr) != interface.deprecated_ipv6_addresses.end()) {
+                return false;
+            }
             const auto slash = cidr.find('/');
             const std::string address = cidr.substr(0, slash);
             in6_addr parsed{};
@@ -603,6 +710,7 @@ FirewallRuleCriteria build_firewall_rule_criteria(const RouteRule& rule) {
         criteria.negate_dst_addr = spec.negate;
         criteria.dst_addr = std::move(spec.addrs);
     }
+    criteria.inbound_interfaces = rule.inbound_interfaces.value_or(std::vector<std::string>{});
 
     return criteria;
 }
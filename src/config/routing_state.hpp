@@ -44,6 +44,9 @@ bool is_interface_outbound_reachable(const Outbound& outbound, NetlinkManager& n
 
 // A link-local address only proves that IPv6 is enabled on the link; it does
 // not make a gatewayless tunnel capable of carrying arbitrary IPv6 traffic.
+// Deprecated addresses are ignored as well: their prefix is being phased out.
+// Temporary (privacy extension) addresses count; the kernel already prefers
+// stable addresses when it picks a source.
 bool interface_has_routed_ipv6(const DumpedInterface& interface);
 
 // Build the global firewall prefilter derived from route-level config.
@@ -0,0 +1,153 @@
+#pragma once
+
+// Public-domain SHA-256 implementation (single header, no dependencies).
+// API: keen_pbr3::crypto::sha256_hex(std::string_view) -> std::string (64 hex chars)
+
+#include <array>
+#include <cstddef>
+#include <cstdint>
+#include <cstring>
+#include <string>
+#include <string_view>
+
+namespace keen_pbr3::crypto {
+
+namespace detail {
+
+struct SHA256State {
+    uint32_t h[8] = {
+        0x6a09e667u, 0xbb67ae85u, 0x3c6ef372u, 0xa54ff53au,
+        0x510e527fu, 0x9b05688cu, 0x1f83d9abu, 0x5be0cd19u,
+    };
+    uint64_t count = 0; // bits processed so far
+    uint8_t buf[64] = {};
+    uint32_t buf_len = 0;
+
+    static constexpr uint32_t K[64] = {
+        0x428a2f98u, 0x71374491u, 0xb5c0fbcfu, 0xe9b5dba5u,
+        0x3956c25bu, 0x59f111f1u, 0x923f82a4u, 0xab1c5ed5u,
+        0xd807aa98u, 0x12835b01u, 0x243185beu, 0x550c7dc3u,
+        0x72be5d74u, 0x80deb1feu, 0x9bdc06a7u, 0xc19bf174u,
+        0xe49b69c1u, 0xefbe4786u, 0x0fc19dc6u, 0x240ca1ccu,
+        0x2de92c6fu, 0x4a7484aau, 0x5cb0a9dcu, 0x76f988dau,
+        0x983e5152u, 0xa831c66du, 0xb00327c8u, 0xbf597fc7u,
+        0xc6e00bf3u, 0xd5a79147u, 0x06ca6351u, 0x14292967u,
+        0x27b70a85u, 0x2e1b2138u, 0x4d2c6dfcu, 0x53380d13u,
+        0x650a7354u, 0x766a0abbu, 0x81c2c92eu, 0x92722c85u,
+        0xa2bfe8a1u, 0xa81a664bu, 0xc24b8b70u, 0xc76c51a3u,
+        0xd192e819u, 0xd6990624u, 0xf40e3585u, 0x106aa070u,
+        0x19a4c116u, 0x1e376c08u, 0x2748774cu, 0x34b0bcb5u,
+        0x391c0cb3u, 0x4ed8aa4au, 0x5b9cca4fu, 0x682e6ff3u,
+        0x748f82eeu, 0x78a5636fu, 0x84c87814u, 0x8cc70208u,
+        0x90befffau, 0xa4506cebu, 0xbef9a3f7u, 0xc67178f2u,
+    };
+
+    static uint32_t rotr(uint32_t x, uint32_t n) {
+        return (x >> n) | (x << (32u - n));
+    }
+
+    void process_block(const uint8_t* block) {
+        uint32_t W[64];
+        for (std::size_t i = 0; i < 16; ++i) {
+            const std::size_t offset = i * 4U;
+            W[i] = uint32_t(block[offset]) << 24
+                 | uint32_t(block[offset + 1U]) << 16
+                 | uint32_t(block[offset + 2U]) << 8
+                 | uint32_t(block[offset + 3U]);
+        }
+        for (std::size_t i = 16; i < 64U; ++i) {
+            const uint32_t s0 = rotr(W[i - 15U], 7) ^ rotr(W[i - 15U], 18) ^ (W[i - 15U] >> 3);
+            const uint32_t s1 = rotr(W[i - 2U], 17) ^ rotr(W[i - 2U], 19) ^ (W[i - 2U] >> 10);
+            W[i] = W[i - 16U] + s0 + W[i - 7U] + s1;
+        }
+
+        uint32_t a = h[0], b = h[1], c = h[2], d = h[3];
+        uint32_t e = h[4], f = h[5], g = h[6], hh = h[7];
+        for (std::size_t i = 0; i < 64U; ++i) {
+            const uint32_t S1 = rotr(e, 6) ^ rotr(e, 11) ^ rotr(e, 25);
+            const uint32_t ch = (e & f) ^ (~e & g);
+            const uint32_t t1 = hh + S1 + ch + K[i] + W[i];
+            const uint32_t S0 = rotr(a, 2) ^ rotr(a, 13) ^ rotr(a, 22);
+            const uint32_t maj = (a & b) ^ (a & c) ^ (b & c);
+            const uint32_t t2 = S0 + maj;
+            hh = g;
+            g = f;
+            f = e;
+            e = d + t1;
+            d = c;
+            c = b;
+            b = a;
+            a = t1 + t2;
+        }
+        h[0] += a; h[1] += b; h[2] += c; h[3] += d;
+        h[4] += e; h[5] += f; h[6] += g; h[7] += hh;
+    }
+
+    void update(const uint8_t* data, size_t len) {
+        count += static_cast<uint64_t>(len) * uint64_t{8};
+        while (len > 0) {
+            size_t space = 64u - buf_len;
+            size_t take  = (len < space) ? len : space;
+            std::memcpy(buf + buf_len, data, take);
+            buf_len += uint32_t(take);
+            data    += take;
+            len     -= take;
+            if (buf_len == 64) {
+                process_block(buf);
+                buf_len = 0;
+            }
+        }
+    }
+
+    std::array<uint8_t, 32> finalize() {
+        // Padding
+        uint8_t pad[64] = {};
+        pad[0] = 0x80u;
+        size_t pad_len = (buf_len < 56) ? (56u - buf_len) : (120u - buf_len);
+        const uint64_t message_bits = count;
+        update(pad, pad_len);
+
+        // Length (big-endian)
+        uint8_t len_bytes[8];
+        for (std::size_t i = 0; i < 8U; ++i) {
+            len_bytes[i] = uint8_t(message_bits >> ((7U - i) * 8U));
+        }
+        update(len_bytes, 8);
+
+        std::array<uint8_t, 32> result;
+        for (std::size_t i = 0; i < 8U; ++i) {
+            const std::size_t offset = i * 4U;
+            result[offset] = uint8_t(h[i] >> 24);
+            result[offset + 1U] = uint8_t(h[i] >> 16);
+            result[offset + 2U] = uint8_t(h[i] >> 8);
+            result[offset + 3U] = uint8_t(h[i]);
+        }
+        return result;
+    }
+
+    std::array<uint8_t, 32> digest() const {
+        SHA256State copy = *this;
+        return copy.finalize();
+    }
+};
+
+} // namespace detail
+
+inline std::string digest_to_hex(const std::array<uint8_t, 32>& digest) {
+    static constexpr char hex[] = "0123456789abcdef";
+    std::string out(64, '\0');
+    for (std::size_t i = 0; i < 32U; ++i) {
+        const std::size_t offset = i * 2U;
+        out[offset] = hex[digest[i] >> 4];
+        out[offset + 1U] = hex[digest[i] & 0xFU];
+    }
+    return out;
+}
+
+inline std::string sha256_hex(std::string_view data) {
+    detail::SHA256State state;
+    state.update(reinterpret_cast<const uint8_t*>(data.data()), data.size());
+    return digest_to_hex(state.digest());
+}
+
+} // namespace keen_pbr3::crypto
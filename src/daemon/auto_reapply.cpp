@@ -0,0 +1,78 @@
+#include "auto_reapply.hpp"
+
+#include <cerrno>
+#include <cstdint>
+#include <cstdio>
+#include <cstring>
+#include <filesystem>
+#include <fstream>
+#include <stdexcept>
+#include <utility>
+
+namespace keen_pbr3 {
+
+std::optional<std::chrono::seconds> parse_auto_reapply_after(std::string_view text) {
+    std::int64_t multiplier = 1;
+    if (!text.empty()) {
+        switch (text.back()) {
+        case 's': text.remove_suffix(1); break;
+        case 'm': multiplier = 60; text.remove_suffix(1); break;
+        case 'h': multiplier = 3600; text.remove_suffix(1); break;
+        default: break;
+        }
+    }
+    if (text.empty() || text.size() > 6) return std::nullopt;
+
+    std::int64_t value = 0;
+    for (const char c : text) {
+        if (c < '0' || c > '9') return std::nullopt;
+        value = value * 10 + (c - '0');
+    }
+    const std::chrono::seconds delay{value * multiplier};
+    if (delay.count() < 1 || delay > kMaxAutoReapplyAfter) return std::nullopt;
+    return delay;
+}
+
+AutoReapplySchedule::AutoReapplySchedule(std::string path)
+    : path_(std::move(path)) {}
+
+void AutoReapplySchedule::schedule(Clock::time_point deadline) const {
+    const std::string tmp_path = path_ + ".tmp";
+    {
+        std::ofstream out(tmp_path, std::ios::trunc);
+        out << std::chrono::duration_cast<std::chrono::seconds>(
+                   deadline.time_since_epoch()).count()
+            << '\n';
+        if (!out) {
+            throw std::runtime_error("Cannot write auto-reapply state file " + tmp_path);
+        }
+    }
+    if (std::rename(tmp_path.c_str(), path_.c_str()) != 0) {
+        const std::string error = std::strerror(errno);
+        std::remove(tmp_path.c_str());
+        throw std::runtime_error("Cannot replace auto-reapply state file " + path_ + ": " + error);
+    }
+}
+
+bool AutoReapplySchedule::cancel() const {
+    std::error_code ec;
+    return std::filesystem::remove(path_, ec);
+}
+
+std::optional<AutoReapplySchedule::Clock::time_point> AutoReapplySchedule::deadline() const {
+    std::ifstream in(path_);
+    std::int64_t seconds = 0;
+    if (!(in >> seconds) || seconds <= 0) return std::nullopt;
+    return Clock::time_point(std::chrono::seconds(seconds));
+}
+
+AutoReapplyPoll AutoReapplySchedule::poll(Clock::time_point now, const Reapply& reapply) const {
+    const auto due = deadline();
+    if (!due.has_value()) return AutoReapplyPoll::Idle;
+    if (now < *due) return AutoReapplyPoll::Pending;
+    if (!reapply()) return AutoReapplyPoll::Busy;
+    (void)cancel();
+    return AutoReapplyPoll::Reapplied;
+}
+
+} // namespace keen_pbr3
@@ -0,0 +1,56 @@
+#pragma once
+
+#include <chrono>
+#include <functional>
+#include <optional>
+#include <string>
+#include <string_view>
+
+namespace keen_pbr3 {
+
+// Longest delay accepted by `undo-routing --auto-reapply-after` and by
+// POST /api/service/stop auto_start_after_seconds.
+inline constexpr std::chrono::seconds kMaxAutoReapplyAfter{86400};
+
+// Parses an --auto-reapply-after value: a whole number of seconds with an
+// optional s, m or h suffix ("90", "90s", "15m", "2h"). Returns nullopt when
+// the text is malformed, zero or longer than kMaxAutoReapplyAfter.
+std::optional<std::chrono::seconds> parse_auto_reapply_after(std::string_view text);
+
+enum class AutoReapplyPoll {
+    Idle,      // nothing is scheduled
+    Pending,   // the deadline has not passed yet
+    Reapplied, // the reapply was accepted and the schedule cleared
+    Busy,      // the deadline passed but the reapply was refused; poll again
+};
+
+// Deadline after which the service starts routing again on its own, kept in
+// a state file (unix seconds) so it outlives the CLI call or API request that
+// set it. The service polls it on a timer; cancel() or any other lifecycle
+// operation removes it.
+class AutoReapplySchedule {
+public:
+    using Clock = std::chrono::system_clock;
+    // Runs the reapply. Returns false when the daemon is busy with another
+    // operation; the deadline is then kept so the next poll retries.
+    using Reapply = std::function<bool()>;
+
+    explicit AutoReapplySchedule(std::string path);
+
+    // Replaces any pending deadline.
+    void schedule(Clock::time_point deadline) const;
+    // Removes the pending deadline. Returns true when one was pending.
+    bool cancel() const;
+    // Pending deadline, or nullopt when none is scheduled or the state file
+    // cannot be parsed.
+    std::optional<Clock::time_point> deadline() const;
+
+    // Runs reapply once the deadline has passed. An exception from reapply
+    // propagates and leaves the deadline in place.
+    AutoReapplyPoll poll(Clock::time_point now, const Reapply& reapply) const;
+
+private:
+    std::string path_;
+};
+
+} // namespace keen_pbr3
@@ -0,0 +1,86 @@
+#include "config_watcher.hpp"
+
+#include <fstream>
+#include <sstream>
+#include <stdexcept>
+#include <sys/stat.h>
+#include <utility>
+
+namespace keen_pbr3 {
+
+bool ConfigFileWatcher::FileSignature::operator==(const FileSignature& other) const {
+    return exists == other.exists && device == other.device && inode == other.inode &&
+           size == other.size && mtime_ns == other.mtime_ns;
+}
+
+ConfigFileWatcher::ConfigFileWatcher(std::string path,
+                                     std::chrono::milliseconds debounce,
+                                     Validator validator)
+    : path_(std::move(path)),
+      debounce_(debounce),
+      validator_(std::move(validator)),
+      baseline_(read_signature()) {}
+
+ConfigFileWatcher::FileSignature ConfigFileWatcher::read_signature() const {
+    struct stat st {};
+    FileSignature signature;
+    if (::stat(path_.c_str(), &st) != 0) {
+        return signature;
+    }
+    signature.exists = true;
+    signature.device = static_cast<std::uint64_t>(st.st_dev);
+    signature.inode = static_cast<std::uint64_t>(st.st_ino);
+    signature.size = static_cast<std::uint64_t>(st.st_size);
+    signature.mtime_ns = static_cast<std::int64_t>(st.st_mtim.tv_sec) * 1000000000LL +
+                         static_cast<std::int64_t>(st.st_mtim.tv_nsec);
+    return signature;
+}
+
+ConfigWatchResult ConfigFileWatcher::poll(Clock::time_point now) {
+    const FileSignature current = read_signature();
+    if (current == baseline_) {
+        pending_.reset();
+        return {};
+    }
+    if (!pending_ || pending_->signature != current) {
+        // Still being written (or replaced): restart the debounce window.
+        pending_ = PendingChange{current, now};
+        return {};
+    }
+    if (now - pending_->first_seen < debounce_) {
+        return {};
+    }
+
+    baseline_ = current;
+    pending_.reset();
+
+    ConfigWatchResult result;
+    if (!current.exists) {
+        result.kind = ConfigWatchResult::Kind::Invalid;
+        result.error = "config file was removed";
+        return result;
+    }
+
+    std::ifstream input(path_);
+    if (!input.is_open()) {
+        result.kind = ConfigWatchResult::Kind::Invalid;
+        result.error = "cannot open config file: " + path_;
+        return result;
+    }
+    std::ostringstream contents;
+    contents << input.rdbuf();
+
+    try {
+        validator_(contents.str());
+    } catch (const std::exception& error) {
+        result.kind = ConfigWatchResult::Kind::Invalid;
+        result.error = error.what();
+        return result;
+    }
+
+    result.kind = ConfigWatchResult::Kind::Changed;
+    result.contents = contents.str();
+    return result;
+}
+
+} // namespace keen_pbr3
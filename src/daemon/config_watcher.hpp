@@ -0,0 +1,63 @@
+#pragma once
+
+#include <chrono>
+#include <cstdint>
+#include <functional>
+#include <optional>
+#include <string>
+
+namespace keen_pbr3 {
+
+struct ConfigWatchResult {
+    enum class Kind { None, Changed, Invalid };
+
+    Kind kind{Kind::None};
+    // Contents of the changed file when kind == Changed.
+    std::string contents;
+    // Validation error when kind == Invalid.
+    std::string error;
+};
+
+// Poll-based config file watcher. A change is reported only after the file
+// stat signature (inode, size, mtime) has stayed the same for the debounce
+// period, and only if the contents pass the validator. An invalid file is
+// reported once and then ignored until it is modified again.
+class ConfigFileWatcher {
+public:
+    using Clock = std::chrono::steady_clock;
+    // Throws (typically ConfigError) when the contents must not be applied.
+    using Validator = std::function<void(const std::string& contents)>;
+
+    ConfigFileWatcher(std::string path,
+                      std::chrono::milliseconds debounce,
+                      Validator validator);
+
+    ConfigWatchResult poll(Clock::time_point now);
+
+private:
+    struct FileSignature {
+        bool exists{false};
+        std::uint64_t device{0};
+        std::uint64_t inode{0};
+        std::uint64_t size{0};
+        std::int64_t mtime_ns{0};
+
+        bool operator==(const FileSignature& other) const;
+        bool operator!=(const FileSignature& other) const { return !(*this == other); }
+    };
+
+    struct PendingChange {
+        FileSignature signature;
+        Clock::time_point first_seen;
+    };
+
+    FileSignature read_signature() const;
+
+    std::string path_;
+    std::chrono::milliseconds debounce_;
+    Validator validator_;
+    FileSignature baseline_;
+    std::optional<PendingChange> pending_;
+};
+
+} // namespace keen_pbr3
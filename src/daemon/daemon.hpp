@@ -3,12 +3,14 @@
 #include "../config/config.hpp"
 #include "../dns/dns_txt_client.hpp"
 #include "../firewall/firewall.hpp"
+#include "../health/routing_health.hpp"
 #include "../health/url_tester.hpp"
 #include "../routing/firewall_state.hpp"
 #include "../routing/interface_monitor.hpp"
 #include "../routing/netlink.hpp"
 #include "../routing/policy_rule.hpp"
 #include "../routing/route_table.hpp"
+#include "../routing/routing_reconciler.hpp"
 #include "../runtime/conntrack_manager.hpp"
 #include "../runtime/lifecycle_operation.hpp"
 #include "../runtime/operation_coordinator.hpp"
@@ -40,6 +42,7 @@
 namespace keen_pbr3 {
 
 class Firewall;
+class ConfigFileWatcher;
 class Scheduler;
 class UrltestManager;
 class DnsProbeServer;
@@ -71,6 +74,8 @@ struct DaemonOptions {
   // Opt-in Keenetic workaround: classify IPv4 forwarded packets in raw
   // PREROUTING.  Local OUTPUT traffic remains in mangle.
   bool use_raw_prerouting{false};
+  // Poll the config file and reload automatically once a change settles.
+  bool watch_config{false};
 };
 
 struct ListsRefreshExecutionResult {
@@ -166,6 +171,13 @@ private:
   void handle_sigusr1();
   void schedule_sigusr1_runtime_refresh();
   void handle_sighup();
+  // Apply the config file through the config transaction. `validated` is
+  // file contents already parsed and validated by the caller (config watch);
+  // without it the file is read and validated here, once.
+  void reload_config_file(const char *source,
+                          std::optional<Config> validated = std::nullopt);
+  void start_config_watch();
+  void poll_config_watch();
   void handle_interface_monitor_events(uint32_t events);
   void reconnect_interface_monitor();
   void register_interface_monitor_fd();
@@ -189,6 +201,7 @@ private:
   void reconcile_static_routing();
   void apply_firewall(FirewallApplyMode mode = FirewallApplyMode::Destructive);
   void reconcile_lists_only(bool reload_resolver);
+  void reconcile_routing_only();
   void register_urltest_outbounds();
   void handle_urltest_selection_change(const std::string &urltest_tag,
                                        const std::string &new_child_tag);
@@ -213,6 +226,9 @@ private:
   void stop_routing_runtime();
   void restart_routing_runtime();
   bool routing_runtime_active() const;
+  // Verify the live kernel routing and firewall state against the tracked
+  // runtime state; shared by GET /api/health/routing and `status --json`.
+  RoutingHealthReport routing_health_report();
   void transition_runtime_or_throw(RuntimeState next, const char *reason);
   bool run_system_resolver_hook(std::string_view action);
   bool run_system_resolver_hook_reload();
@@ -220,6 +236,8 @@ private:
                                       std::chrono::milliseconds timeout);
   void drain_shutdown_resolver_callbacks(std::chrono::milliseconds duration);
   void schedule_lists_autoupdate();
+  void schedule_lists_force_reimport();
+  void schedule_firewall_self_heal();
   ListsRefreshExecutionResult execute_remote_list_refresh(
       const std::set<std::string> *target_lists = nullptr,
       std::string_view source = "service");
@@ -263,6 +281,10 @@ private:
   std::string submit_lifecycle_operation(LifecycleRequest request);
   void execute_lifecycle_operation(std::string operation_id,
                                    LifecycleRequest request);
+  void schedule_lifecycle_auto_start(std::chrono::seconds delay);
+  void cancel_lifecycle_auto_start();
+  void start_lifecycle_auto_start_poll();
+  void poll_lifecycle_auto_start();
   void run_runtime_control_operation_or_throw(const std::string &label,
                                               const char *operation_name,
                                               std::function<void()> task);
@@ -295,6 +317,12 @@ private:
 
   // Lists autoupdate state
   int lists_autoupdate_task_id_{-1};
+  // Hourly check for lists_autoupdate.force_reimport_interval_hours.
+  int lists_force_reimport_task_id_{-1};
+  // Last time apply_firewall() imported list entries into the kernel sets.
+  std::chrono::steady_clock::time_point last_list_import_{};
+  // Periodic firewall verification for daemon.firewall_self_heal.
+  int firewall_self_heal_task_id_{-1};
   // Periodic refresh task for cached Keenetic DNS server values.
   int keenetic_dns_refresh_task_id_{-1};
   // Periodic refresh task for the actual resolver config hash / live status.
@@ -305,6 +333,14 @@ private:
   int sigusr1_refresh_task_id_{-1};
   // Retry task for interface monitor netlink reconnect after failure.
   int interface_monitor_reconnect_task_id_{-1};
+  // Periodic config file poll enabled by --watch-config.
+  int config_watch_task_id_{-1};
+#ifdef WITH_API
+  // Periodic check of the auto-start deadline set by `undo-routing
+  // --auto-reapply-after` or POST /api/service/stop auto_start_after_seconds.
+  int lifecycle_auto_start_task_id_{-1};
+#endif
+  std::unique_ptr<ConfigFileWatcher> config_watcher_;
 
   // Epoll state
   int epoll_fd_{-1};
@@ -361,6 +397,11 @@ private:
   NetlinkManager netlink_;
   RouteTable route_table_;
   PolicyRuleManager policy_rules_;
+  // Routes the kernel refused during the last routing apply; see
+  // RoutingReconciler::rejected_routes().
+  std::vector<std::string> rejected_routes_;
+  // Foreign routes already warned about, kept across routing applies.
+  ForeignRouteReports foreign_route_reports_;
   FirewallState firewall_state_;
   ConntrackManager conntrack_manager_;
   ResolverCoordinator resolver_coordinator_;
@@ -1,5 +1,8 @@
 #include "daemon.hpp"
+#include "auto_reapply.hpp"
 #include "config_apply_transaction.hpp"
+#include "scheduler.hpp"
+#include "../config/config_import.hpp"
 #include "../config/config_writer.hpp"
 
 #ifdef WITH_API
@@ -57,6 +60,15 @@ std::vector<LifecycleOperationStage> lifecycle_stages(LifecycleOperationType typ
     return {};
 }
 
+constexpr auto LIFECYCLE_AUTO_START_POLL_INTERVAL = std::chrono::seconds{5};
+
+// Pending auto-start deadline, kept next to the control socket so that
+// `undo-routing --auto-reapply-after` and POST /api/service/stop share it.
+AutoReapplySchedule lifecycle_auto_start_schedule() {
+    return AutoReapplySchedule(
+        (std::filesystem::path(KEEN_PBR_CONTROL_SOCKET).parent_path() / "auto-reapply").string());
+}
+
 const char* config_operation_state_name(ConfigOperationState state) {
     switch (state) {
     case ConfigOperationState::Idle:
@@ -79,6 +91,7 @@ std::string Daemon::submit_lifecycle_operation(LifecycleRequest request) {
                        nlohmann::json{{"error", "A lifecycle operation is already active"},
                                       {"active_operation_id", *active}}.dump());
     }
+    cancel_lifecycle_auto_start();
 
     const std::string id = operation.id;
     if (!lifecycle_executor_.try_post(
@@ -94,6 +107,54 @@ std::string Daemon::submit_lifecycle_operation(LifecycleRequest request) {
     return id;
 }
 
+void Daemon::schedule_lifecycle_auto_start(std::chrono::seconds delay) {
+    lifecycle_auto_start_schedule().schedule(AutoReapplySchedule::Clock::now() + delay);
+    Logger::instance().info("Routing runtime will be started again in {}s", delay.count());
+}
+
+void Daemon::cancel_lifecycle_auto_start() {
+    if (lifecycle_auto_start_schedule().cancel()) {
+        Logger::instance().info("Pending routing runtime auto-start cancelled");
+    }
+}
+
+void Daemon::start_lifecycle_auto_start_poll() {
+    lifecycle_auto_start_task_id_ = scheduler_->schedule_repeating(
+        LIFECYCLE_AUTO_START_POLL_INTERVAL, [this]() { poll_lifecycle_auto_start(); },
+        "lifecycle-auto-start");
+}
+
+void Daemon::poll_lifecycle_auto_start() {
+    auto& log = Logger::instance();
+    try {
+        const auto result = lifecycle_auto_start_schedule().poll(
+            AutoReapplySchedule::Clock::now(), [this, &log] {
+                if (routing_runtime_active_) {
+                    log.info("Auto-start delay elapsed, routing runtime is already active");
+                    return true;
+                }
+                log.info("Auto-start delay elapsed, starting routing runtime");
+                try {
+                    (void)submit_lifecycle_operation(
+                        LifecycleRequest{.type = LifecycleOperationType::Start});
+                } catch (const ApiError& e) {
+                    if (e.status() != 409) throw;
+                    return false;
+                }
+                return true;
+            });
+        if (result == AutoReapplyPoll::Busy) {
+            log.info("Scheduled routing runtime start deferred: another lifecycle operation "
+                     "is active; retrying in {}s",
+                     LIFECYCLE_AUTO_START_POLL_INTERVAL.count());
+        }
+    } catch (const std::exception& e) {
+        log.error("Scheduled routing runtime start failed: {}; retrying in {}s",
+                  e.what(),
+                  LIFECYCLE_AUTO_START_POLL_INTERVAL.count());
+    }
+}
+
 void Daemon::execute_lifecycle_operation(std::string id, LifecycleRequest request) {
     std::string current_stage;
     bool runtime_mutated = false;
@@ -184,7 +245,11 @@ void Daemon::execute_lifecycle_operation(std::string id, LifecycleRequest reques
                                  true, "lifecycle:" + id + ":finalize-runtime");
 
             start_stage("commit_config");
-            write_config_atomically(config_path_, request.serialized_config);
+            if (request.backup_config) {
+                commit_imported_config(config_path_, request.serialized_config);
+            } else {
+                write_config_atomically(config_path_, request.serialized_config);
+            }
             enqueue_control_task([this, serialized = request.serialized_config] {
                 config_store_.replace_active(config_, outbound_marks_);
                 config_store_.clear_staged_if_matches(serialized);
@@ -192,6 +257,11 @@ void Daemon::execute_lifecycle_operation(std::string id, LifecycleRequest reques
             }, true, "lifecycle:" + id + ":commit-config");
             succeed_stage();
         } else if (request.type == LifecycleOperationType::Stop) {
+            // Written before anything is torn down: a stop that cannot keep
+            // its promise to start again fails without touching routing.
+            if (request.auto_start_after.has_value()) {
+                schedule_lifecycle_auto_start(*request.auto_start_after);
+            }
             runtime_mutated = true;
             run_control("stop_routing", [this] { teardown_routing_and_firewall(true); });
             start_stage("reload_fallback");
@@ -494,6 +564,8 @@ ListRefreshOperationResult Daemon::refresh_lists_via_api(std::optional<std::stri
             throw ApiError("Requested list was not found", 404);
         case RemoteListTargetSelectionError::NotRemote:
             throw ApiError("Requested list is not URL-backed", 400);
+        case RemoteListTargetSelectionError::Disabled:
+            throw ApiError("Requested list is disabled", 400);
         case RemoteListTargetSelectionError::None:
             break;
         }
@@ -672,25 +744,7 @@ void Daemon::setup_api() {
             service_health.lifecycle_operation = lifecycle_operation_store_.snapshot();
             return service_health;
         },
-        [this]() {
-            const auto runtime_snapshot = runtime_state_store_.snapshot();
-
-            if (runtime_snapshot.runtime_state == RuntimeState::starting) {
-                RoutingHealthReport report;
-                report.firewall_backend = firewall_->backend();
-                report.firewall_chain.detail =
-                    "routing runtime initialization is in progress";
-                return report;
-            }
-
-            return build_routing_health_report(
-                firewall_->backend(),
-                firewall_->uses_raw_prerouting(),
-                runtime_snapshot.firewall_state,
-                runtime_snapshot.route_specs,
-                runtime_snapshot.policy_rule_specs,
-                netlink_);
-        },
+        [this]() { return routing_health_report(); },
         [this]() {
             const Config config_snapshot = config_store_.active_config();
             const auto runtime_snapshot = runtime_state_store_.snapshot();
@@ -723,7 +777,10 @@ void Daemon::setup_api() {
         },
         [this]() {
             auto response = build_runtime_interface_inventory_response_or_empty(netlink_);
-            populate_keenetic_interface_descriptions(response);
+            const auto api_config = config_store_.active_config().api.value_or(ApiConfig{});
+            populate_keenetic_interface_descriptions(
+                response,
+                std::chrono::seconds(api_config.keenetic_interface_cache_seconds.value_or(120)));
             return response;
         },
         [this](const Config& config) {
@@ -733,6 +790,10 @@ void Daemon::setup_api() {
             const Config visible_config = config_store_.visible_config();
             return compute_test_routing(visible_config, list_service_.cache_manager(), target);
         },
+        [this](const std::string& domain) {
+            const Config visible_config = config_store_.visible_config();
+            return compute_domain_match(visible_config, list_service_.cache_manager(), domain);
+        },
         [this]() {
             begin_config_operation_or_throw(ConfigOperationState::Saving,
                                             "begin-save",
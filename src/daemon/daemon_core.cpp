@@ -1,5 +1,9 @@
 #include "daemon.hpp"
+#include "auto_reapply.hpp"
+#include "config_watcher.hpp"
 #include "disk_config_state.hpp"
+#include "runtime_info.hpp"
+#include "routing_only_apply.hpp"
 
 #include <algorithm>
 #include <arpa/inet.h>
@@ -31,6 +35,7 @@
 #include "../dns/dnsmasq_gen.hpp"
 #include "../firewall/firewall.hpp"
 #include "../firewall/firewall_verifier.hpp"
+#include "../health/routing_health_checker.hpp"
 #include "../ipc/control_protocol.hpp"
 #include "../lists/list_streamer.hpp"
 #include "../log/logger.hpp"
@@ -51,6 +56,9 @@ namespace keen_pbr3 {
 namespace {
 
 constexpr auto SIGUSR1_DEBOUNCE_DELAY = std::chrono::milliseconds{150};
+constexpr auto CONFIG_WATCH_POLL_INTERVAL = std::chrono::milliseconds{1000};
+// Editors and scp write in several steps; wait for the file to settle.
+constexpr auto CONFIG_WATCH_DEBOUNCE_DELAY = std::chrono::milliseconds{2000};
 constexpr auto INTERFACE_MONITOR_RECONNECT_RETRY_DELAY =
     std::chrono::seconds{5};
 constexpr std::size_t kResolverStreamChunkBytes =
@@ -368,10 +376,13 @@ void Daemon::handle_ipc_control_socket() {
       const bool resolver_hook_inflight =
           ipc_resolver_hook_inflight_.load(std::memory_order_acquire);
       const bool read_only_operation =
-          operation == "status" || operation == "resolver-config-hash";
+          operation == "status" || operation == "resolver-config-hash" ||
+          operation == "runtime-info" || operation == "routing-health";
       const bool startup_mutation =
           runtime_state_machine_.state() == RuntimeState::starting &&
-          (operation == "download" || operation == "test-routing");
+          (operation == "download" || operation == "test-routing" ||
+           operation == "explain" || operation == "reconcile-routing" ||
+           operation == "undo-routing");
       if (resolver_hook_inflight && operation != "generate-resolver-config" &&
           !read_only_operation) {
         response =
@@ -390,6 +401,10 @@ void Daemon::handle_ipc_control_socket() {
         }
         if (operation != "status" && operation != "resolver-config-hash" &&
             operation != "download" && operation != "test-routing" &&
+            operation != "explain" && operation != "runtime-info" &&
+            operation != "routing-health" &&
+            operation != "reconcile-routing" &&
+            operation != "undo-routing" &&
             operation != "generate-resolver-config") {
           response = ipc::make_error_response(request, "unsupported_operation",
                                               "unsupported control operation");
@@ -416,6 +431,38 @@ void Daemon::handle_ipc_control_socket() {
                         {"entries", std::move(entries)},
                         {"warnings", result.warnings},
                         {"dns_error", result.dns_error}}}};
+        } else if (operation == "explain") {
+          const std::string target = request.value("target", "");
+          if (target.empty())
+            throw ipc::ControlProtocolError("explain requires a target");
+          const Config config = config_store_.active_config();
+          const auto result = compute_test_routing(
+              config, list_service_.cache_manager(), target);
+          response = {{"protocol_version", ipc::kControlProtocolVersion},
+                      {"request_id", request.at("request_id")},
+                      {"ok", !result.dns_error.has_value()},
+                      {"result", explain_routing_json(config, result)}};
+        } else if (operation == "runtime-info") {
+          RuntimeInfoOptions options;
+          options.config_path = config_path_;
+          options.firewall_backend = firewall_backend_name(firewall_->backend());
+#ifdef WITH_API
+          options.api_running = api_server_ != nullptr;
+#endif
+          options.interface_monitor_active = interface_monitor_fd_.has_value();
+          options.watch_config = opts_.watch_config;
+          options.use_raw_prerouting = opts_.use_raw_prerouting;
+          response = {{"protocol_version", ipc::kControlProtocolVersion},
+                      {"request_id", request.at("request_id")},
+                      {"ok", true},
+                      {"result", runtime_info_json(config_store_.active_config(),
+                                                   options)}};
+        } else if (operation == "routing-health") {
+          response = {{"protocol_version", ipc::kControlProtocolVersion},
+                      {"request_id", request.at("request_id")},
+                      {"ok", true},
+                      {"result",
+                       routing_health_report_to_json(routing_health_report())}};
         } else if (operation == "generate-resolver-config") {
           const RuntimeState runtime_state = runtime_state_machine_.state();
           // The DNS configuration is a daemon-owned desired-state
@@ -521,6 +568,77 @@ void Daemon::handle_ipc_control_socket() {
           response = ipc::make_error_response(
               request, "daemon_error",
               "resolver stream executor is unavailable");
+        } else if (operation == "reconcile-routing") {
+          bool expected = false;
+          if (!ipc_mutation_inflight_.compare_exchange_strong(
+                  expected, true, std::memory_order_acq_rel)) {
+            response = ipc::make_error_response(
+                request, "busy", "another control mutation is in progress");
+            const std::string frame = ipc::encode_message(response);
+            (void)send(client, frame.data(), frame.size(), MSG_NOSIGNAL);
+            close(client);
+            continue;
+          }
+          struct MutationGate {
+            std::atomic<bool> &flag;
+            ~MutationGate() { flag.store(false, std::memory_order_release); }
+          } gate{ipc_mutation_inflight_};
+          bool config_operation_active = false;
+#ifdef WITH_API
+          config_operation_active = operation_coordinator_.busy();
+#endif
+          if (config_operation_active) {
+            response = ipc::make_error_response(
+                request, "busy", "a config operation is in progress");
+          } else if (!routing_runtime_active_) {
+            response = ipc::make_error_response(
+                request, "runtime_stopped", "routing runtime is not active");
+          } else {
+            try {
+              reconcile_routing_only();
+              response = {{"protocol_version", ipc::kControlProtocolVersion},
+                          {"request_id", request.at("request_id")},
+                          {"ok", true},
+                          {"result",
+                           {{"routes", route_table_.get_routes().size()},
+                            {"rules", policy_rules_.get_rules().size()},
+                            {"firewall_rules",
+                             firewall_state_.get_rules().size()}}}};
+            } catch (const RoutingOnlyApplyError &error) {
+              response = ipc::make_error_response(request, "missing_sets",
+                                                  error.what());
+            }
+          }
+        } else if (operation == "undo-routing") {
+#ifdef WITH_API
+          const auto seconds =
+              request.value("auto_reapply_after_seconds", std::int64_t{0});
+          if (seconds < 0 || seconds > kMaxAutoReapplyAfter.count()) {
+            throw ipc::ControlProtocolError(
+                "auto_reapply_after_seconds must be between 0 and 86400");
+          }
+          LifecycleRequest stop{.type = LifecycleOperationType::Stop};
+          if (seconds > 0) {
+            stop.auto_start_after = std::chrono::seconds(seconds);
+          }
+          try {
+            const std::string id = submit_lifecycle_operation(std::move(stop));
+            response = {{"protocol_version", ipc::kControlProtocolVersion},
+                        {"request_id", request.at("request_id")},
+                        {"ok", true},
+                        {"result",
+                         {{"operation_id", id},
+                          {"auto_reapply_after_seconds", seconds}}}};
+          } catch (const ApiError &error) {
+            response = ipc::make_error_response(
+                request, error.status() == 409 ? "busy" : "daemon_error",
+                error.what());
+          }
+#else
+          response = ipc::make_error_response(
+              request, "unsupported_operation",
+              "undo-routing requires build with WITH_API=ON");
+#endif
         } else if (operation == "download") {
           bool expected = false;
           if (!ipc_mutation_inflight_.compare_exchange_strong(
@@ -571,6 +689,7 @@ void Daemon::handle_ipc_control_socket() {
           for (const auto &list_name : relevant_lists) {
             const auto list = lists.find(list_name);
             if (list != lists.end() && list->second.url.has_value() &&
+                list_enabled(list->second) &&
                 !cache.has_cache(list_name)) {
               missing_cached_lists.push_back(list_name);
             }
@@ -860,42 +979,49 @@ void Daemon::schedule_sigusr1_runtime_refresh() {
       "sigusr1-runtime-refresh");
 }
 
-void Daemon::handle_sighup() {
+void Daemon::handle_sighup() { reload_config_file("SIGHUP"); }
+
+void Daemon::reload_config_file(const char *source,
+                                std::optional<Config> validated) {
   auto &log = Logger::instance();
 #ifndef WITH_API
-  log.warn("SIGHUP: configuration reload is unavailable in headless builds");
+  log.warn("{}: configuration reload is unavailable in headless builds",
+           source);
   return;
 #else
-  log.info("SIGHUP: full reload starting...");
+  log.info("{}: full reload starting...", source);
   if (!operation_coordinator_.try_begin("sighup-reload")) {
-    log.warn("SIGHUP: reload skipped because another config operation is in "
-             "progress");
+    log.warn("{}: reload skipped because another config operation is in "
+             "progress",
+             source);
     return;
   }
-  const bool enqueued =
-      blocking_executor_.try_post("sighup-config-transaction", [this] {
+  const bool enqueued = blocking_executor_.try_post(
+      "sighup-config-transaction",
+      [this, source, validated = std::move(validated)]() mutable {
         ConfigApplyResult result;
         try {
-          std::ifstream input(config_path_);
-          if (!input.is_open()) {
-            throw DaemonError("Cannot open config file: " + config_path_);
+          if (!validated.has_value()) {
+            std::ifstream input(config_path_);
+            if (!input.is_open()) {
+              throw DaemonError("Cannot open config file: " + config_path_);
+            }
+            std::ostringstream contents;
+            contents << input.rdbuf();
+            validated = parse_and_validate_config(contents.str());
           }
-          std::ostringstream contents;
-          contents << input.rdbuf();
-          Config candidate = parse_config(contents.str());
-          validate_config(candidate);
-          result = apply_validated_config_via_control_task(std::move(candidate),
+          result = apply_validated_config_via_control_task(std::move(*validated),
                                                            "", false);
         } catch (const std::exception &error) {
           result.error = error.what();
         }
         post_control_task(
-            [this, result = std::move(result)] {
+            [this, source, result = std::move(result)] {
               operation_coordinator_.finish();
               if (result.error.empty()) {
-                Logger::instance().info("SIGHUP: full reload complete.");
+                Logger::instance().info("{}: full reload complete.", source);
               } else {
-                Logger::instance().error("SIGHUP: reload failed: {}",
+                Logger::instance().error("{}: reload failed: {}", source,
                                          result.error);
               }
             },
@@ -903,11 +1029,68 @@ void Daemon::handle_sighup() {
       });
   if (!enqueued) {
     operation_coordinator_.finish();
-    log.error("SIGHUP: reload could not be scheduled");
+    log.error("{}: reload could not be scheduled", source);
   }
 #endif
 }
 
+void Daemon::start_config_watch() {
+  auto &log = Logger::instance();
+#ifndef WITH_API
+  log.warn("--watch-config ignored: configuration reload is unavailable in "
+           "headless builds");
+#else
+  config_watcher_ = std::make_unique<ConfigFileWatcher>(
+      config_path_, CONFIG_WATCH_DEBOUNCE_DELAY,
+      [](const std::string &contents) {
+        (void)parse_and_validate_config(contents);
+      });
+  config_watch_task_id_ = scheduler_->schedule_repeating(
+      CONFIG_WATCH_POLL_INTERVAL, [this]() { poll_config_watch(); },
+      "config-watch");
+  log.info("Watching {} for changes", config_path_);
+#endif
+}
+
+void Daemon::poll_config_watch() {
+#ifdef WITH_API
+  // Leave changes pending while an API save or reload is running; its own
+  // write to the config file is picked up (and skipped) once it completes.
+  if (!config_watcher_ || operation_coordinator_.busy()) {
+    return;
+  }
+  auto &log = Logger::instance();
+  const ConfigWatchResult change =
+      config_watcher_->poll(ConfigFileWatcher::Clock::now());
+  switch (change.kind) {
+  case ConfigWatchResult::Kind::None:
+    return;
+  case ConfigWatchResult::Kind::Invalid:
+    log.warn("Config watch: ignoring change to {}: {}", config_path_,
+             change.error);
+    return;
+  case ConfigWatchResult::Kind::Changed:
+    break;
+  }
+  // Apply exactly the contents the watcher validated; the file may have
+  // changed again since.
+  Config candidate;
+  try {
+    candidate = parse_and_validate_config(change.contents);
+  } catch (const std::exception &error) {
+    log.warn("Config watch: ignoring change to {}: {}", config_path_,
+             error.what());
+    return;
+  }
+  if (nlohmann::json(candidate) == nlohmann::json(config_)) {
+    log.debug("Config watch: {} changed but matches the active config",
+              config_path_);
+    return;
+  }
+  reload_config_file("Config watch", std::move(candidate));
+#endif
+}
+
 void Daemon::refresh_iproute_and_firewall_runtime() {
   auto &log = Logger::instance();
   try {
@@ -1316,9 +1499,13 @@ void Daemon::run() {
 
 #ifdef WITH_API
   setup_api();
+  start_lifecycle_auto_start_poll();
 #endif
 
   log.info("Daemon control plane running. PID: {}", getpid());
+  if (opts_.watch_config) {
+    start_config_watch();
+  }
   post_control_task([this] { begin_startup_runtime(); }, "startup-runtime");
 
   run_event_loop();
@@ -116,6 +116,7 @@ RuntimeStateSnapshot Daemon::build_runtime_state_snapshot() const {
     snapshot.firewall_state = firewall_state_;
     snapshot.route_specs = route_table_.get_routes();
     snapshot.policy_rule_specs = policy_rules_.get_rules();
+    snapshot.rejected_routes = rejected_routes_;
     const auto resolver_snapshot = resolver_sync_.snapshot(unix_timestamp_now_seconds());
     snapshot.resolver_config_hash = resolver_snapshot.expected_hash;
     snapshot.resolver_config_hash_actual = resolver_snapshot.actual_hash;
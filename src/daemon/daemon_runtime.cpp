@@ -11,6 +11,9 @@
 #include "../config/routing_state.hpp"
 #include "../firewall/firewall.hpp"
 #include "../firewall/firewall_runtime.hpp"
+#include "../firewall/firewall_verifier.hpp"
+#include "../health/ipv6_prefix_check.hpp"
+#include "../health/routing_health_checker.hpp"
 #include "../log/logger.hpp"
 #include "../routing/urltest_manager.hpp"
 
@@ -19,11 +22,18 @@
 #include "../util/ipv6_support.hpp"
 #include "../util/time_utils.hpp"
 #include "../util/cron.hpp"
+#include "routing_only_apply.hpp"
 #include "scheduler.hpp"
 #include "system_resolver_hook.hpp"
 
 namespace keen_pbr3 {
 
+namespace {
+
+constexpr auto kFirewallSelfHealInterval = std::chrono::seconds{60};
+
+} // namespace
+
 bool Daemon::run_system_resolver_hook(std::string_view action) {
     auto& log = Logger::instance();
 
@@ -130,6 +140,42 @@ bool Daemon::routing_runtime_active() const {
     return runtime_state_store_.snapshot().routing_runtime_active;
 }
 
+RoutingHealthReport Daemon::routing_health_report() {
+    const auto runtime_snapshot = runtime_state_store_.snapshot();
+
+    if (runtime_snapshot.runtime_state == RuntimeState::starting) {
+        RoutingHealthReport report;
+        report.firewall_backend = firewall_->backend();
+        report.firewall_chain.detail =
+            "routing runtime initialization is in progress";
+        return report;
+    }
+
+    RoutingHealthReport report = build_routing_health_report(
+        firewall_->backend(),
+        firewall_->uses_raw_prerouting(),
+        runtime_snapshot.firewall_state,
+        runtime_snapshot.route_specs,
+        runtime_snapshot.policy_rule_specs,
+        netlink_);
+
+    const Config config = config_store_.active_config();
+    if (config.daemon.value_or(DaemonConfig{}).ipv6_enabled.value_or(true)) {
+        try {
+            report.warnings = find_ipv6_prefix_mismatches(config, netlink_.dump_interfaces());
+        } catch (const std::exception& e) {
+            Logger::instance().verbose("IPv6 prefix check skipped: {}", e.what());
+        }
+    }
+    for (const auto& warning : collect_config_warnings(config)) {
+        report.warnings.push_back(warning.message);
+    }
+    report.warnings.insert(report.warnings.end(),
+                           runtime_snapshot.rejected_routes.begin(),
+                           runtime_snapshot.rejected_routes.end());
+    return report;
+}
+
 void Daemon::transition_runtime_or_throw(RuntimeState next, const char* reason) {
     std::string error;
     if (!runtime_state_machine_.transition(next, reason, error)) {
@@ -176,11 +222,20 @@ void Daemon::teardown_routing_and_firewall(bool explicit_stop) {
     }
     policy_rules_.clear();
     route_table_.clear();
+    rejected_routes_.clear();
     firewall_->cleanup();
     if (keenetic_dns_refresh_task_id_ >= 0) {
         scheduler_->cancel(keenetic_dns_refresh_task_id_);
         keenetic_dns_refresh_task_id_ = -1;
     }
+    if (lists_force_reimport_task_id_ >= 0) {
+        scheduler_->cancel(lists_force_reimport_task_id_);
+        lists_force_reimport_task_id_ = -1;
+    }
+    if (firewall_self_heal_task_id_ >= 0) {
+        scheduler_->cancel(firewall_self_heal_task_id_);
+        firewall_self_heal_task_id_ = -1;
+    }
 
     routing_runtime_active_ = false;
     transition_runtime_or_throw(explicit_stop ? RuntimeState::stopped : RuntimeState::applying,
@@ -231,6 +286,8 @@ void Daemon::complete_running_runtime(const char* reason) {
     register_urltest_outbounds();
     schedule_keenetic_dns_refresh();
     schedule_lists_autoupdate();
+    schedule_lists_force_reimport();
+    schedule_firewall_self_heal();
     refresh_resolver_config_hash_actual_async();
     transition_runtime_or_throw(RuntimeState::running, reason);
     publish_runtime_state();
@@ -292,8 +349,10 @@ void Daemon::reconcile_static_routing() {
 
     // Inspect the kernel on every apply so a restarted daemon adopts intact
     // state and only removes objects with a verifiable ownership marker.
-    RoutingReconciler(netlink_).reconcile(desired_routes.get_routes(),
-                                          desired_rules.get_rules());
+    RoutingReconciler reconciler(netlink_, foreign_route_reports_,
+                                 route_policy(config_) == api::RoutePolicy::REPLACE);
+    reconciler.reconcile(desired_routes.get_routes(), desired_rules.get_rules());
+    rejected_routes_ = reconciler.rejected_routes();
     route_table_.adopt_desired(desired_routes.get_routes());
     policy_rules_.adopt_desired(desired_rules.get_rules());
 }
@@ -307,6 +366,9 @@ void Daemon::apply_firewall(FirewallApplyMode mode) {
         list_service_.cache_manager(),
         *firewall_,
         mode));
+    if (mode != FirewallApplyMode::RulesOnly) {
+        last_list_import_ = std::chrono::steady_clock::now();
+    }
     (void)conntrack_manager_.reconcile(
         ConntrackPolicy{prefilter.skip_established_or_dnat});
 }
@@ -335,6 +397,34 @@ void Daemon::reconcile_lists_only(bool reload_resolver) {
     }
 }
 
+void Daemon::reconcile_routing_only() {
+    if (!routing_runtime_active_) {
+        throw DaemonError("routing-only reconcile requires an active routing runtime");
+    }
+
+    // Lists are not downloaded or refreshed. The sets the installed rules
+    // reference are checked first, so a missing one aborts before routes,
+    // policy rules or firewall rules change.
+    try {
+        const auto sets = make_kernel_set_writer(firewall_->backend());
+        apply_routing_only(referenced_kernel_sets(firewall_state_.get_rules()),
+                           *sets,
+                           [this] { reconcile_static_routing(); },
+                           [this](FirewallApplyMode mode) { apply_firewall(mode); });
+        publish_runtime_state();
+    } catch (const RoutingOnlyApplyError&) {
+        // Raised before any change: the runtime is still intact.
+        throw;
+    } catch (...) {
+        std::string ignored_error;
+        (void)runtime_state_machine_.transition(RuntimeState::broken,
+                                                "routing-only reconcile failed",
+                                                ignored_error);
+        publish_runtime_state();
+        throw;
+    }
+}
+
 void Daemon::handle_urltest_selection_change(const std::string& urltest_tag,
                                              const std::string& new_child_tag) {
     post_control_task([this, urltest_tag, new_child_tag]() {
@@ -444,6 +534,94 @@ void Daemon::schedule_lists_autoupdate() {
     Logger::instance().info("Lists autoupdate scheduled (next: ~{}s)", delay.count());
 }
 
+void Daemon::schedule_lists_force_reimport() {
+    if (lists_force_reimport_task_id_ >= 0) {
+        scheduler_->cancel(lists_force_reimport_task_id_);
+        lists_force_reimport_task_id_ = -1;
+    }
+
+    const std::chrono::hours interval{
+        config_.lists_autoupdate.value_or(ListsAutoupdateConfig{})
+            .force_reimport_interval_hours.value_or(0)};
+    if (interval.count() <= 0) return;
+
+    // Checked hourly against the last import, so an import done for another
+    // reason (changed list, reload) postpones the forced one.
+    lists_force_reimport_task_id_ = scheduler_->schedule_repeating(
+        std::chrono::hours{1},
+        [this, interval]() {
+            post_control_task([this, interval]() {
+                if (!should_force_list_reimport(routing_runtime_active_,
+                                                interval,
+                                                last_list_import_,
+                                                std::chrono::steady_clock::now())) {
+                    return;
+                }
+                Logger::instance().info(
+                    "Lists force re-import: no import for {}h, re-importing cached lists",
+                    interval.count());
+                try {
+                    reconcile_lists_only(false);
+                } catch (const std::exception& e) {
+                    Logger::instance().error("Lists force re-import failed: {}", e.what());
+                }
+            }, "lists-force-reimport");
+        },
+        "lists-force-reimport");
+    Logger::instance().info("Lists force re-import enabled (every {}h)", interval.count());
+}
+
+void Daemon::schedule_firewall_self_heal() {
+    if (firewall_self_heal_task_id_ >= 0) {
+        scheduler_->cancel(firewall_self_heal_task_id_);
+        firewall_self_heal_task_id_ = -1;
+    }
+    if (!config_.daemon.value_or(DaemonConfig{}).firewall_self_heal.value_or(false)) {
+        return;
+    }
+
+    firewall_self_heal_task_id_ = scheduler_->schedule_repeating(
+        kFirewallSelfHealInterval,
+        [this]() {
+            post_control_task([this]() {
+                if (!routing_runtime_active_) {
+                    return;
+                }
+#ifdef WITH_API
+                // The running operation reapplies the firewall itself.
+                if (operation_coordinator_.busy()) {
+                    return;
+                }
+#endif
+                std::vector<std::string> drift;
+                try {
+                    auto verifier = create_firewall_verifier(firewall_->backend(),
+                                                             firewall_->uses_raw_prerouting());
+                    drift = find_firewall_drift(*verifier, firewall_state_);
+                } catch (const std::exception& e) {
+                    Logger::instance().warn("Firewall self-heal check failed: {}", e.what());
+                    return;
+                }
+                if (drift.empty()) {
+                    return;
+                }
+                for (const auto& problem : drift) {
+                    Logger::instance().warn("Firewall self-heal: {}", problem);
+                }
+                Logger::instance().warn("Firewall self-heal: reinstalling firewall rules");
+                try {
+                    apply_firewall(FirewallApplyMode::PreserveSets);
+                    publish_runtime_state();
+                } catch (const std::exception& e) {
+                    Logger::instance().error("Firewall self-heal reapply failed: {}", e.what());
+                }
+            }, "firewall-self-heal");
+        },
+        "firewall-self-heal");
+    Logger::instance().info("Firewall self-heal enabled (every {}s)",
+                            kFirewallSelfHealInterval.count());
+}
+
 ListsRefreshExecutionResult Daemon::execute_remote_list_refresh(
     const std::set<std::string>* target_lists,
     std::string_view source) {
@@ -711,6 +889,14 @@ void Daemon::reconcile_prepared_runtime(PreparedRuntimeInputs prepared) {
         scheduler_->cancel(lists_autoupdate_task_id_);
         lists_autoupdate_task_id_ = -1;
     }
+    if (lists_force_reimport_task_id_ >= 0) {
+        scheduler_->cancel(lists_force_reimport_task_id_);
+        lists_force_reimport_task_id_ = -1;
+    }
+    if (firewall_self_heal_task_id_ >= 0) {
+        scheduler_->cancel(firewall_self_heal_task_id_);
+        firewall_self_heal_task_id_ = -1;
+    }
     if (keenetic_dns_refresh_task_id_ >= 0) {
         scheduler_->cancel(keenetic_dns_refresh_task_id_);
         keenetic_dns_refresh_task_id_ = -1;
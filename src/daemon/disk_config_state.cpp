@@ -18,7 +18,7 @@ DiskConfigState inspect_disk_config_state(const std::string& config_path,
     std::ostringstream contents;
     contents << input.rdbuf();
     try {
-        const auto disk_json = nlohmann::json::parse(contents.str());
+        const auto disk_json = nlohmann::json::parse(contents.str(), nullptr, true, true);
         const nlohmann::json active_json = active_config;
         DiskConfigState result;
         result.matches_active = disk_json == active_json;
@@ -1,5 +1,7 @@
 #include "list_service.hpp"
 
+#include "../lists/asn_source.hpp"
+#include "../lists/list_download.hpp"
 #include "../log/logger.hpp"
 
 #include <sstream>
@@ -47,13 +49,17 @@ RemoteListTargetSelection select_remote_list_targets(const Config& config,
             selection.error = RemoteListTargetSelectionError::NotRemote;
             return selection;
         }
+        if (!list_enabled(it->second)) {
+            selection.error = RemoteListTargetSelectionError::Disabled;
+            return selection;
+        }
 
         selection.list_names.push_back(it->first);
         return selection;
     }
 
     for (const auto& [name, list_cfg] : lists) {
-        if (list_cfg.url.has_value()) {
+        if (list_cfg.url.has_value() && list_enabled(list_cfg)) {
             selection.list_names.push_back(name);
         }
     }
@@ -113,6 +119,13 @@ bool should_reload_runtime_after_list_refresh(bool routing_runtime_active,
     return routing_runtime_active && refresh_result.any_relevant_changed();
 }
 
+bool should_force_list_reimport(bool routing_runtime_active,
+                                std::chrono::hours interval,
+                                std::chrono::steady_clock::time_point last_import,
+                                std::chrono::steady_clock::time_point now) {
+    return routing_runtime_active && interval.count() > 0 && now - last_import >= interval;
+}
+
 std::map<std::string, api::ListRefreshStateValue> build_list_refresh_state_map(const Config& config,
                                                                                const CacheManager& cache_manager) {
     std::map<std::string, api::ListRefreshStateValue> refresh_state;
@@ -144,6 +157,11 @@ const CacheManager& ListService::cache_manager() const {
     return cache_manager_;
 }
 
+void ListService::set_bootstrap_resolver(BootstrapResolver resolver) {
+    KPBR_LOCK_GUARD(mutex_);
+    cache_manager_.set_bootstrap_resolver(std::move(resolver));
+}
+
 RemoteListsRefreshResult ListService::download_uncached(
     const Config& config,
     const OutboundMarkMap& outbound_marks,
@@ -202,7 +220,7 @@ RemoteListsRefreshResult ListService::download_remote_lists(const Config& config
     RemoteListsRefreshResult result;
     try {
         for (const auto& [name, list_cfg] : config_lists(config)) {
-            if (!list_cfg.url.has_value()) {
+            if (!list_cfg.url.has_value() || !list_enabled(list_cfg)) {
                 continue;
             }
             if (target_lists && target_lists->count(name) == 0) {
@@ -215,28 +233,29 @@ RemoteListsRefreshResult ListService::download_remote_lists(const Config& config
 
             result.refreshed_lists.push_back(name);
 
-            uint32_t fwmark = 0;
-            if (list_cfg.detour.has_value()) {
-                auto it = outbound_marks.find(internal_detour_mark_key(*list_cfg.detour));
-                if (it != outbound_marks.end()) {
-                    fwmark = it->second;
-                } else {
-                    Logger::instance().warn("List '{}': detour outbound '{}' not found, "
-                                            "using default routing",
-                                            name,
-                                            *list_cfg.detour);
+            const CacheDownloadOptions download_options =
+                list_download_options(config, name, list_cfg, outbound_marks);
+
+            CacheDownloadResult download_result;
+            for (const auto& url : list_download_urls(config, list_cfg)) {
+                download_result =
+                    is_asn_list_url(url)
+                        ? cache_asn_dump_prefixes(cache_manager_, name, url,
+                                                  config.asn_source.value_or(AsnSourceConfig{})
+                                                      .file.value_or(""))
+                        : cache_manager_.download(name, url, download_options);
+                if (!download_result.failed()) {
+                    break;
                 }
-            }
-
-            const auto download_result = cache_manager_.download(name, *list_cfg.url, CacheDownloadOptions{fwmark});
-
-            if (download_result.failed()) {
-                result.failed_lists.push_back(name);
                 Logger::instance().warn("List '{}': failed to refresh {}: {}",
                                         name,
-                                        *list_cfg.url,
+                                        url,
                                         download_result.error_message.empty() ? std::string("unknown error")
                                                                               : download_result.error_message);
+            }
+
+            if (download_result.failed()) {
+                result.failed_lists.push_back(name);
                 continue;
             }
 
@@ -4,6 +4,7 @@
 #include "../config/config.hpp"
 #include "../util/traced_mutex.hpp"
 
+#include <chrono>
 #include <condition_variable>
 #include <exception>
 #include <map>
@@ -50,6 +51,7 @@ enum class RemoteListTargetSelectionError {
     None,
     NotFound,
     NotRemote,
+    Disabled,
 };
 
 struct RemoteListTargetSelection {
@@ -71,6 +73,14 @@ std::string format_list_names(const std::vector<std::string>& list_names);
 bool should_reload_runtime_after_list_refresh(bool routing_runtime_active,
                                               const RemoteListsRefreshResult& refresh_result);
 
+// lists_autoupdate.force_reimport_interval_hours: re-import cached lists when
+// the last import into the kernel sets is at least `interval` old, whether or
+// not any download changed. A zero interval disables it.
+bool should_force_list_reimport(bool routing_runtime_active,
+                                std::chrono::hours interval,
+                                std::chrono::steady_clock::time_point last_import,
+                                std::chrono::steady_clock::time_point now);
+
 std::map<std::string, api::ListRefreshStateValue> build_list_refresh_state_map(const Config& config,
                                                                                const CacheManager& cache_manager);
 
@@ -80,6 +90,7 @@ class ListService {
 
     void ensure_dir();
     const CacheManager& cache_manager() const;
+    void set_bootstrap_resolver(BootstrapResolver resolver);
 
     // Startup only: preserve cached lists and download just the missing ones.
     RemoteListsRefreshResult download_uncached(const Config& config,
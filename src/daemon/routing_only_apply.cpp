@@ -0,0 +1,41 @@
+#include "routing_only_apply.hpp"
+
+#include <algorithm>
+
+namespace keen_pbr3 {
+
+std::vector<std::string> referenced_kernel_sets(const std::vector<RuleState>& rules) {
+    std::vector<std::string> sets;
+    for (const auto& rule : rules) {
+        for (const auto& set_name : rule.set_names) {
+            if (std::find(sets.begin(), sets.end(), set_name) == sets.end()) {
+                sets.push_back(set_name);
+            }
+        }
+    }
+    return sets;
+}
+
+void apply_routing_only(const std::vector<std::string>& required_sets,
+                        const KernelSetWriter& sets,
+                        const std::function<void()>& reconcile_routing,
+                        const std::function<void(FirewallApplyMode)>& apply_firewall) {
+    std::vector<std::string> missing;
+    for (const auto& set_name : required_sets) {
+        if (!sets.exists(set_name)) missing.push_back(set_name);
+    }
+    if (!missing.empty()) {
+        std::string listed;
+        for (const auto& set_name : missing) {
+            if (!listed.empty()) listed += ", ";
+            listed += set_name;
+        }
+        throw RoutingOnlyApplyError("kernel set(s) missing: " + listed +
+                                    "; run a full apply to recreate them");
+    }
+
+    reconcile_routing();
+    apply_firewall(FirewallApplyMode::RulesOnly);
+}
+
+} // namespace keen_pbr3
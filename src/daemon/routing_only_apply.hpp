@@ -0,0 +1,33 @@
+#pragma once
+
+#include "../firewall/firewall.hpp"
+#include "../lists/kernel_set_writer.hpp"
+#include "../routing/firewall_state.hpp"
+
+#include <functional>
+#include <stdexcept>
+#include <string>
+#include <vector>
+
+namespace keen_pbr3 {
+
+class RoutingOnlyApplyError : public std::runtime_error {
+public:
+    using std::runtime_error::runtime_error;
+};
+
+// Kernel sets referenced by the installed firewall rules, in rule order and
+// without duplicates.
+std::vector<std::string> referenced_kernel_sets(const std::vector<RuleState>& rules);
+
+// `apply --routing-only`: check that every set in `required_sets` still
+// exists in the kernel, then reconcile routes and policy rules and rebuild
+// the firewall rules with FirewallApplyMode::RulesOnly. Lists are neither
+// downloaded nor imported; the sets keep their current entries.
+// A missing set throws RoutingOnlyApplyError before anything is changed.
+void apply_routing_only(const std::vector<std::string>& required_sets,
+                        const KernelSetWriter& sets,
+                        const std::function<void()>& reconcile_routing,
+                        const std::function<void(FirewallApplyMode)>& apply_firewall);
+
+} // namespace keen_pbr3
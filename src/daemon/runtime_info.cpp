@@ -0,0 +1,65 @@
+#include "runtime_info.hpp"
+
+#include "../config/effective_config.hpp"
+
+namespace keen_pbr3 {
+
+nlohmann::json runtime_info_json(const Config& active_config,
+                                 const RuntimeInfoOptions& options) {
+    const Config effective = resolve_effective_config(active_config);
+    const auto& api = *effective.api;
+    const auto& autoupdate = *effective.lists_autoupdate;
+
+    nlohmann::json dns_servers = nlohmann::json::array();
+    nlohmann::json dns_fallback = nlohmann::json::array();
+    nlohmann::json system_resolver = nullptr;
+    if (effective.dns.has_value()) {
+        for (const auto& server : effective.dns->servers.value_or(std::vector<DnsServer>{})) {
+            dns_servers.push_back({
+                {"tag", server.tag},
+                {"type", server.type},
+                {"address", server.address},
+                {"detour", server.detour},
+            });
+        }
+        dns_fallback = effective.dns->fallback.value_or(std::vector<std::string>{});
+        if (effective.dns->system_resolver.has_value()) {
+            system_resolver = effective.dns->system_resolver->address;
+        }
+    }
+
+    nlohmann::json urltests = nlohmann::json::array();
+    for (const auto& outbound : effective.outbounds.value_or(std::vector<Outbound>{})) {
+        if (outbound.type != OutboundType::URLTEST) continue;
+        urltests.push_back({
+            {"tag", outbound.tag},
+            {"url", outbound.url},
+            {"interval_ms", outbound.interval_ms},
+        });
+    }
+
+    return {
+        {"config_path", options.config_path},
+        {"firewall_backend", options.firewall_backend},
+        {"interface_monitor", options.interface_monitor_active},
+        {"watch_config", options.watch_config},
+        {"use_raw_prerouting", options.use_raw_prerouting},
+        {"api", {
+            {"running", options.api_running},
+            {"listen", options.api_running ? nlohmann::json(api.listen) : nlohmann::json(nullptr)},
+        }},
+        {"lists_autoupdate", {
+            {"enabled", autoupdate.enabled},
+            {"cron", autoupdate.cron},
+            {"force_reimport_interval_hours", autoupdate.force_reimport_interval_hours},
+        }},
+        {"dns", {
+            {"system_resolver", system_resolver},
+            {"servers", std::move(dns_servers)},
+            {"fallback", std::move(dns_fallback)},
+        }},
+        {"urltest", std::move(urltests)},
+    };
+}
+
+} // namespace keen_pbr3
@@ -0,0 +1,26 @@
+#pragma once
+
+#include "../config/config.hpp"
+
+#include <string>
+
+#include <nlohmann/json.hpp>
+
+namespace keen_pbr3 {
+
+// Daemon state that is not part of the config file.
+struct RuntimeInfoOptions {
+    std::string config_path;
+    std::string firewall_backend;
+    bool api_running{false};
+    bool interface_monitor_active{false};
+    bool watch_config{false};
+    bool use_raw_prerouting{false};
+};
+
+// Parameters the running daemon actually uses: the active config with
+// defaults resolved, plus the command-line and subsystem state in `options`.
+nlohmann::json runtime_info_json(const Config& active_config,
+                                 const RuntimeInfoOptions& options);
+
+} // namespace keen_pbr3
@@ -19,6 +19,7 @@ struct RuntimeStateSnapshot {
     FirewallState firewall_state;
     std::vector<RouteSpec> route_specs;
     std::vector<RuleSpec> policy_rule_specs;
+    std::vector<std::string> rejected_routes;
     std::map<std::string, UrltestState> urltest_states;
     std::string resolver_config_hash;
     std::string resolver_config_hash_actual;
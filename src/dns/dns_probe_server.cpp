@@ -24,12 +24,17 @@ constexpr uint16_t DNS_CLASS_IN = 1;
 constexpr uint16_t DNS_TYPE_OPT = 41;
 constexpr uint16_t DNS_FLAG_QR = 0x8000;
 constexpr uint16_t DNS_FLAG_RD = 0x0100;
+constexpr uint16_t DNS_FLAG_OPCODE = 0x7800;
+constexpr uint16_t DNS_RCODE_FORMERR = 1;
+constexpr uint16_t DNS_RCODE_SERVFAIL = 2;
 constexpr uint16_t DNS_EDNS_OPTION_ECS = 8;
 constexpr size_t kMaxTcpClients = 16;
 constexpr size_t kMaxTcpBufferSize = 16384;
 constexpr std::chrono::seconds kTcpClientIdleTimeout{15};
 constexpr std::chrono::seconds kTcpIdleSweepInterval{1};
 constexpr int kTcpWriteWaitTimeoutMs = 1000;
+// Per-query failures repeat for every client retry; log each kind once a minute.
+constexpr std::chrono::seconds kQueryWarningInterval{60};
 
 bool is_valid_ipv4(const std::string& ip) {
     struct in_addr addr {};
@@ -308,6 +313,29 @@ std::string peer_addr_to_string(int fd) {
     return socket_addr_to_string(reinterpret_cast<const sockaddr*>(&peer));
 }
 
+std::optional<std::vector<uint8_t>> build_dns_header_only_response(ByteView packet,
+                                                                   uint16_t rcode) {
+    if (packet.size() < DNS_HEADER_SIZE) {
+        return std::nullopt;
+    }
+    const uint16_t flags = static_cast<uint16_t>((packet[2] << 8) | packet[3]);
+    if ((flags & DNS_FLAG_QR) != 0) {
+        return std::nullopt;
+    }
+
+    std::vector<uint8_t> out;
+    out.reserve(DNS_HEADER_SIZE);
+    out.push_back(packet[0]); // id
+    out.push_back(packet[1]);
+    append_u16(out, static_cast<uint16_t>(DNS_FLAG_QR | (flags & (DNS_FLAG_OPCODE | DNS_FLAG_RD)) |
+                                          rcode));
+    append_u16(out, 0); // qdcount
+    append_u16(out, 0); // ancount
+    append_u16(out, 0); // nscount
+    append_u16(out, 0); // arcount
+    return out;
+}
+
 } // namespace
 
 DnsProbeListenAddress parse_dns_probe_listen_address(const std::string& listen) {
@@ -334,6 +362,31 @@ DnsProbeServerSettings parse_dns_probe_server_settings(const std::string& listen
     };
 }
 
+void validate_dns_probe_domain_suffix(const std::string& suffix) {
+    // 253 is the longest textual name; keep 64 bytes for "<label>.".
+    if (suffix.empty() || suffix.size() > 189) {
+        throw DnsError("DNS test server domain_suffix must be 1-189 characters: " + suffix);
+    }
+    size_t label_start = 0;
+    while (label_start <= suffix.size()) {
+        size_t label_end = suffix.find('.', label_start);
+        if (label_end == std::string::npos) label_end = suffix.size();
+        const size_t label_len = label_end - label_start;
+        if (label_len == 0 || label_len > 63 || suffix[label_start] == '-' ||
+            suffix[label_end - 1] == '-') {
+            throw DnsError("DNS test server domain_suffix is not a valid domain name: " + suffix);
+        }
+        for (size_t i = label_start; i < label_end; ++i) {
+            const char c = suffix[i];
+            if (!((c >= 'a' && c <= 'z') || (c >= '0' && c <= '9') || c == '-')) {
+                throw DnsError("DNS test server domain_suffix must use lowercase letters, "
+                               "digits and hyphens: " + suffix);
+            }
+        }
+        label_start = label_end + 1;
+    }
+}
+
 DnsProbeQuestion parse_dns_probe_query(ByteView packet) {
     if (packet.size() < DNS_HEADER_SIZE) {
         throw DnsError("DNS probe packet too short");
@@ -388,27 +441,41 @@ DnsProbeQuestion parse_dns_probe_query(ByteView packet) {
     question.id = read_u16(0);
     question.flags = read_u16(2);
     question.name = normalize_dns_name(labels);
+    question.question_wire = std::move(question_wire);
     pos += 4;
 
-    for (uint16_t i = 0; i < ancount + nscount; ++i) {
-        size_t name_len = read_name_length(packet, pos);
-        pos += name_len;
-        if (pos + 10 > packet.size()) {
-            throw DnsError("DNS record truncated");
-        }
-        uint16_t rdlen = static_cast<uint16_t>((packet[pos + 8] << 8) | packet[pos + 9]);
-        pos += 10;
-        if (pos + rdlen > packet.size()) {
-            throw DnsError("DNS record payload truncated");
+    // The question is all a probe answer needs: damaged records after it
+    // only cost the ECS address, and trailing bytes are ignored.
+    try {
+        for (uint16_t i = 0; i < ancount + nscount; ++i) {
+            size_t name_len = read_name_length(packet, pos);
+            pos += name_len;
+            if (pos + 10 > packet.size()) {
+                throw DnsError("DNS record truncated");
+            }
+            uint16_t rdlen = static_cast<uint16_t>((packet[pos + 8] << 8) | packet[pos + 9]);
+            pos += 10;
+            if (pos + rdlen > packet.size()) {
+                throw DnsError("DNS record payload truncated");
+            }
+            pos += rdlen;
         }
-        pos += rdlen;
-    }
 
-    question.ecs = parse_edns_client_subnet(packet, pos, arcount);
-    question.question_wire = std::move(question_wire);
+        question.ecs = parse_edns_client_subnet(packet, pos, arcount);
+    } catch (const DnsError&) {
+        question.ecs.reset();
+    }
     return question;
 }
 
+std::optional<std::vector<uint8_t>> build_dns_format_error_response(ByteView packet) {
+    return build_dns_header_only_response(packet, DNS_RCODE_FORMERR);
+}
+
+std::optional<std::vector<uint8_t>> build_dns_server_failure_response(ByteView packet) {
+    return build_dns_header_only_response(packet, DNS_RCODE_SERVFAIL);
+}
+
 std::vector<uint8_t> build_dns_probe_response(const DnsProbeQuestion& question,
                                               const std::string& answer_ipv4) {
     if (!is_valid_ipv4(answer_ipv4)) {
@@ -508,15 +575,51 @@ std::vector<int> DnsProbeServer::accept_tcp_clients() {
     return accepted;
 }
 
+std::vector<uint8_t> DnsProbeServer::build_query_response(ByteView packet,
+                                                          const std::string& source_ip,
+                                                          const QueryTransport& transport) const {
+    std::optional<DnsProbeQuestion> question;
+    try {
+        question = parse_dns_probe_query(packet);
+    } catch (const DnsError& e) {
+        auto formerr = build_dns_format_error_response(packet);
+        if (!formerr.has_value()) {
+            throw;
+        }
+        Logger::instance().warn_throttled(transport.malformed_key, kQueryWarningInterval,
+                                          "DNS test server answered malformed {} query "
+                                          "with FORMERR: {}",
+                                          transport.name, e.what());
+        return std::move(*formerr);
+    }
+
+    publish_query(*question, source_ip);
+    try {
+        return build_dns_probe_response(*question, settings_.answer_ipv4);
+    } catch (const DnsError& e) {
+        // The query was fine; the server cannot build its answer.
+        auto servfail = build_dns_server_failure_response(packet);
+        if (!servfail.has_value()) {
+            throw;
+        }
+        Logger::instance().warn_throttled(transport.servfail_key, kQueryWarningInterval,
+                                          "DNS test server answered {} query with "
+                                          "SERVFAIL: {}",
+                                          transport.name, e.what());
+        return std::move(*servfail);
+    }
+}
+
 bool DnsProbeServer::handle_udp_packet(const uint8_t* data, size_t len,
                                        const sockaddr* addr, socklen_t addrlen) {
-    DnsProbeQuestion question = parse_dns_probe_query(ByteView(data, len));
-    publish_query(question, socket_addr_to_string(addr));
+    const std::vector<uint8_t> response =
+        build_query_response(ByteView(data, len), socket_addr_to_string(addr),
+                             {"UDP", "dns-test-udp-malformed", "dns-test-udp-servfail"});
 
-    auto response = build_dns_probe_response(question, settings_.answer_ipv4);
     ssize_t sent = sendto(udp_fd_, response.data(), response.size(), 0, addr, addrlen);
     if (sent < 0) {
-        Logger::instance().warn("DNS test server UDP send failed: {}", strerror(errno));
+        Logger::instance().warn_throttled("dns-test-udp-send", kQueryWarningInterval,
+                                          "DNS test server UDP send failed: {}", strerror(errno));
         return false;
     }
     return true;
@@ -534,7 +637,9 @@ bool DnsProbeServer::handle_udp_readable() {
                 handle_udp_packet(buf, static_cast<size_t>(n),
                                   reinterpret_cast<sockaddr*>(&peer), peer_len);
             } catch (const std::exception& e) {
-                Logger::instance().warn("DNS test server dropped malformed UDP query: {}", e.what());
+                Logger::instance().warn_throttled("dns-test-udp-malformed", kQueryWarningInterval,
+                                                  "DNS test server dropped malformed UDP query: {}",
+                                                  e.what());
             }
             continue;
         }
@@ -553,10 +658,10 @@ bool DnsProbeServer::handle_udp_readable() {
 }
 
 bool DnsProbeServer::handle_tcp_packet(int fd, ByteView packet) {
-    DnsProbeQuestion question = parse_dns_probe_query(packet);
-    publish_query(question, peer_addr_to_string(fd));
+    const std::vector<uint8_t> response =
+        build_query_response(packet, peer_addr_to_string(fd),
+                             {"TCP", "dns-test-tcp-malformed", "dns-test-tcp-servfail"});
 
-    auto response = build_dns_probe_response(question, settings_.answer_ipv4);
     std::vector<uint8_t> framed;
     framed.reserve(response.size() + 2);
     append_u16(framed, static_cast<uint16_t>(response.size()));
@@ -594,7 +699,9 @@ bool DnsProbeServer::handle_tcp_client_readable(int fd) {
                     return handle_tcp_packet(fd, ByteView(
                         state.buffer.data() + 2, state.expected_size));
                 } catch (const std::exception& e) {
-                    Logger::instance().warn("DNS test server dropped malformed TCP query: {}", e.what());
+                    Logger::instance().warn_throttled("dns-test-tcp-malformed", kQueryWarningInterval,
+                                                      "DNS test server dropped malformed TCP query: {}",
+                                                      e.what());
                     return false;
                 }
             }
@@ -13,6 +13,10 @@
 
 namespace keen_pbr3 {
 
+// Zone dnsmasq forwards to the probe listener unless
+// dns.dns_test_server.domain_suffix overrides it.
+inline constexpr const char* kDefaultDnsProbeDomainSuffix = "check.keen.pbr";
+
 struct DnsProbeListenAddress {
     std::string ip;
     uint16_t port{53};
@@ -42,9 +46,19 @@ struct DnsProbeEvent {
 DnsProbeListenAddress parse_dns_probe_listen_address(const std::string& listen);
 DnsProbeServerSettings parse_dns_probe_server_settings(const std::string& listen,
                                                        const std::string* answer_ipv4);
+// Throws DnsError unless `suffix` is a lowercase domain name (letters,
+// digits and hyphens) that still leaves room for a probe label below it.
+void validate_dns_probe_domain_suffix(const std::string& suffix);
 DnsProbeQuestion parse_dns_probe_query(ByteView packet);
 std::vector<uint8_t> build_dns_probe_response(const DnsProbeQuestion& question,
                                               const std::string& answer_ipv4);
+// Header-only FORMERR reply echoing the query's id, opcode and RD bit.
+// Unset for packets shorter than a DNS header or with QR set, which get
+// no reply.
+std::optional<std::vector<uint8_t>> build_dns_format_error_response(ByteView packet);
+// Same as build_dns_format_error_response with SERVFAIL, for well-formed
+// queries the server cannot answer, such as an invalid answer_ipv4.
+std::optional<std::vector<uint8_t>> build_dns_server_failure_response(ByteView packet);
 
 class DnsProbeServer {
 public:
@@ -78,9 +92,21 @@ private:
         std::chrono::steady_clock::time_point last_activity{};
     };
 
+    // Transport label and throttle keys for per-query warnings.
+    struct QueryTransport {
+        const char* name;
+        const char* malformed_key;
+        const char* servfail_key;
+    };
+
     bool handle_udp_packet(const uint8_t* data, size_t len,
                            const struct sockaddr* addr, socklen_t addrlen);
     bool handle_tcp_packet(int fd, ByteView packet);
+    // Probe answer for `packet`, FORMERR when it does not parse, or SERVFAIL
+    // when the answer cannot be built. Throws DnsError when the packet gets
+    // no reply at all.
+    std::vector<uint8_t> build_query_response(ByteView packet, const std::string& source_ip,
+                                              const QueryTransport& transport) const;
     void publish_query(const DnsProbeQuestion& question, const std::string& source_ip) const;
     void close_fd(int& fd);
 
@@ -28,17 +28,17 @@ bool detail::dns_response_is_truncated(const unsigned char* packet, std::size_t
     return packet != nullptr && size >= NS_HFIXEDSZ && (packet[2] & 0x02U) != 0;
 }
 
-bool detail::dns_response_matches_query(const unsigned char* packet,
+bool detail::dns_response_answers_query(const unsigned char* packet,
                                         std::size_t size,
                                         std::uint16_t transaction_id,
-                                        const std::string& domain) {
+                                        const std::string& domain,
+                                        std::uint16_t query_type) {
     if (packet == nullptr || size < NS_HFIXEDSZ || size > static_cast<std::size_t>(INT_MAX)) {
         return false;
     }
     const std::uint16_t response_id =
         static_cast<std::uint16_t>((static_cast<std::uint16_t>(packet[0]) << 8U) | packet[1]);
-    if (response_id != transaction_id || (packet[2] & 0x80U) == 0 ||
-        (packet[2] & 0x78U) != 0 || (packet[3] & 0x0fU) != 0) {
+    if (response_id != transaction_id || (packet[2] & 0x80U) == 0 || (packet[2] & 0x78U) != 0) {
         return false;
     }
 
@@ -49,7 +49,7 @@ bool detail::dns_response_matches_query(const unsigned char* packet,
     }
     ns_rr question {};
     if (ns_parserr(&handle, ns_s_qd, 0, &question) < 0 ||
-        ns_rr_type(question) != ns_t_txt || ns_rr_class(question) != ns_c_in) {
+        ns_rr_type(question) != query_type || ns_rr_class(question) != ns_c_in) {
         return false;
     }
     std::string expected = domain;
@@ -59,6 +59,14 @@ bool detail::dns_response_matches_query(const unsigned char* packet,
     return strcasecmp(actual.c_str(), expected.c_str()) == 0;
 }
 
+bool detail::dns_response_matches_query(const unsigned char* packet,
+                                        std::size_t size,
+                                        std::uint16_t transaction_id,
+                                        const std::string& domain) {
+    return dns_response_answers_query(packet, size, transaction_id, domain, ns_t_txt) &&
+           (packet[3] & 0x0fU) == 0;
+}
+
 namespace {
 
 constexpr const char* kDnsTxtAnswerNotFound = "DNS TXT answer not found";
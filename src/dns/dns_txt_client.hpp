@@ -10,6 +10,14 @@ namespace keen_pbr3 {
 
 namespace detail {
 bool dns_response_is_truncated(const unsigned char* packet, std::size_t size);
+// Whether `packet` is a standard-query response to `transaction_id` with a
+// single IN question for `domain` of `query_type`. The rcode is not checked.
+bool dns_response_answers_query(const unsigned char* packet,
+                                std::size_t size,
+                                std::uint16_t transaction_id,
+                                const std::string& domain,
+                                std::uint16_t query_type);
+// dns_response_answers_query() for a TXT question, answered with NOERROR.
 bool dns_response_matches_query(const unsigned char* packet,
                                 std::size_t size,
                                 std::uint16_t transaction_id,
@@ -0,0 +1,225 @@
+#include "dns_upstream_probe.hpp"
+
+#include "../log/logger.hpp"
+
+#include <algorithm>
+#include <array>
+#include <arpa/inet.h>
+#include <arpa/nameser.h>
+#include <cerrno>
+#include <climits>
+#include <cstring>
+#include <mutex>
+#include <netinet/in.h>
+#include <poll.h>
+#include <resolv.h>
+#include <sys/socket.h>
+#include <unistd.h>
+
+#include "dns_server.hpp"
+#include "dns_txt_client.hpp"
+#include "legacy_resolver_lock.hpp"
+
+namespace keen_pbr3 {
+
+namespace {
+
+using Clock = std::chrono::steady_clock;
+
+std::string rcode_name(int rcode) {
+    switch (rcode) {
+        case ns_r_noerror: return "NOERROR";
+        case ns_r_formerr: return "FORMERR";
+        case ns_r_servfail: return "SERVFAIL";
+        case ns_r_nxdomain: return "NXDOMAIN";
+        case ns_r_notimpl: return "NOTIMP";
+        case ns_r_refused: return "REFUSED";
+        default: return "RCODE" + std::to_string(rcode);
+    }
+}
+
+std::int64_t elapsed_ms(Clock::time_point since) {
+    return std::chrono::duration_cast<std::chrono::milliseconds>(Clock::now() - since).count();
+}
+
+class SocketGuard {
+public:
+    explicit SocketGuard(int fd) : fd_(fd) {}
+    ~SocketGuard() {
+        if (fd_ >= 0) close(fd_);
+    }
+    SocketGuard(const SocketGuard&) = delete;
+    SocketGuard& operator=(const SocketGuard&) = delete;
+    int get() const { return fd_; }
+private:
+    int fd_;
+};
+
+// Fill `result` from a response that already matched the query id.
+void parse_response(const unsigned char* packet, int size, DnsUpstreamProbeResult& result) {
+    ns_msg handle {};
+    if (ns_initparse(packet, size, &handle) < 0) {
+        result.error = "Failed to parse DNS response";
+        return;
+    }
+
+    const int rcode = ns_msg_getflag(handle, ns_f_rcode);
+    result.rcode = rcode_name(rcode);
+    if (rcode != ns_r_noerror) {
+        result.error = "DNS upstream answered " + result.rcode;
+        return;
+    }
+
+    const int answer_count = ns_msg_count(handle, ns_s_an);
+    for (int i = 0; i < answer_count; ++i) {
+        ns_rr rr {};
+        if (ns_parserr(&handle, ns_s_an, i, &rr) < 0) {
+            continue;
+        }
+        if (ns_rr_type(rr) != ns_t_a || ns_rr_class(rr) != ns_c_in ||
+            ns_rr_rdlen(rr) != NS_INADDRSZ) {
+            continue;
+        }
+        char buf[INET_ADDRSTRLEN] = {};
+        if (inet_ntop(AF_INET, ns_rr_rdata(rr), buf, sizeof(buf)) != nullptr) {
+            result.resolved_ips.emplace_back(buf);
+        }
+    }
+    result.ok = true;
+}
+
+} // namespace
+
+DnsUpstreamProbeResult probe_dns_upstream(const std::string& address,
+                                          const std::string& domain,
+                                          std::chrono::milliseconds timeout) {
+    DnsUpstreamProbeResult result;
+
+    ParsedDnsAddress parsed;
+    try {
+        parsed = parse_dns_address_str(address);
+    } catch (const DnsError& e) {
+        result.error = e.what();
+        return result;
+    }
+    if (domain.empty()) {
+        result.error = "DNS test domain is empty";
+        return result;
+    }
+
+    sockaddr_storage upstream {};
+    socklen_t upstream_len = 0;
+    if (parsed.ip.find(':') != std::string::npos) {
+        auto* addr6 = reinterpret_cast<sockaddr_in6*>(&upstream);
+        addr6->sin6_family = AF_INET6;
+        addr6->sin6_port = htons(parsed.port);
+        if (inet_pton(AF_INET6, parsed.ip.c_str(), &addr6->sin6_addr) != 1) {
+            result.error = "Invalid IPv6 DNS upstream address";
+            return result;
+        }
+        upstream_len = sizeof(sockaddr_in6);
+    } else {
+        auto* addr4 = reinterpret_cast<sockaddr_in*>(&upstream);
+        addr4->sin_family = AF_INET;
+        addr4->sin_port = htons(parsed.port);
+        if (inet_pton(AF_INET, parsed.ip.c_str(), &addr4->sin_addr) != 1) {
+            result.error = "Invalid IPv4 DNS upstream address";
+            return result;
+        }
+        upstream_len = sizeof(sockaddr_in);
+    }
+
+    std::array<unsigned char, NS_PACKETSZ> query {};
+    int query_len = -1;
+    {
+        // res_mkquery() builds the packet using the global _res; serialize it.
+        std::lock_guard<std::mutex> resolver_lock(legacy_resolver_mutex());
+        query_len = res_mkquery(ns_o_query,
+                                domain.c_str(),
+                                ns_c_in,
+                                ns_t_a,
+                                nullptr,
+                                0,
+                                nullptr,
+                                query.data(),
+                                static_cast<int>(query.size()));
+    }
+    if (query_len < NS_HFIXEDSZ) {
+        result.error = "Failed to build DNS query for " + domain;
+        return result;
+    }
+    const std::uint16_t query_id = static_cast<std::uint16_t>(
+        (static_cast<std::uint16_t>(query[0]) << 8U) | query[1]);
+
+    SocketGuard socket_fd(socket(upstream.ss_family, SOCK_DGRAM | SOCK_CLOEXEC, 0));
+    if (socket_fd.get() < 0) {
+        result.error = std::string("Failed to create DNS socket: ") + std::strerror(errno);
+        return result;
+    }
+    if (connect(socket_fd.get(), reinterpret_cast<const sockaddr*>(&upstream), upstream_len) != 0) {
+        result.error = std::string("Failed to connect DNS socket: ") + std::strerror(errno);
+        return result;
+    }
+
+    const auto started_at = Clock::now();
+    const auto deadline = started_at + std::max(timeout, std::chrono::milliseconds(1));
+    if (send(socket_fd.get(), query.data(), static_cast<size_t>(query_len), 0) != query_len) {
+        result.error = std::string("Failed to send DNS query: ") + std::strerror(errno);
+        return result;
+    }
+
+    std::array<unsigned char, NS_PACKETSZ * 8> response {};
+    while (true) {
+        const auto remaining =
+            std::chrono::duration_cast<std::chrono::milliseconds>(deadline - Clock::now());
+        if (remaining.count() <= 0) {
+            result.latency_ms = elapsed_ms(started_at);
+            result.error = "DNS upstream did not answer within " +
+                           std::to_string(timeout.count()) + " ms";
+            return result;
+        }
+
+        pollfd pfd {socket_fd.get(), POLLIN, 0};
+        const int ready = poll(&pfd, 1, static_cast<int>(std::min<std::int64_t>(remaining.count(), INT_MAX)));
+        if (ready < 0) {
+            if (errno == EINTR) continue;
+            result.error = std::string("Failed to wait for DNS response: ") + std::strerror(errno);
+            return result;
+        }
+        if (ready == 0) {
+            continue;
+        }
+
+        const ssize_t received = recv(socket_fd.get(), response.data(), response.size(), 0);
+        if (received < 0) {
+            // ICMP port unreachable surfaces here on a connected UDP socket.
+            result.latency_ms = elapsed_ms(started_at);
+            result.error = std::string("DNS query failed: ") + std::strerror(errno);
+            return result;
+        }
+        // Ignore stray datagrams that do not answer our question.
+        if (!detail::dns_response_answers_query(response.data(),
+                                                static_cast<std::size_t>(received),
+                                                query_id,
+                                                domain,
+                                                ns_t_a)) {
+            continue;
+        }
+
+        result.latency_ms = elapsed_ms(started_at);
+        if (detail::dns_response_is_truncated(response.data(), static_cast<std::size_t>(received))) {
+            // The answer did not fit; without a TCP retry the test is inconclusive.
+            result.error = "DNS upstream response was truncated; the test is inconclusive";
+            return result;
+        }
+        parse_response(response.data(), static_cast<int>(received), result);
+        Logger::instance().verbose("DNS upstream test: upstream={} domain={} rcode={} latency_ms={}",
+                                   address,
+                                   domain,
+                                   result.rcode.empty() ? "-" : result.rcode,
+                                   result.latency_ms);
+        return result;
+    }
+}
+
+} // namespace keen_pbr3
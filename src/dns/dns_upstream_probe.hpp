@@ -0,0 +1,32 @@
+#pragma once
+
+#include <chrono>
+#include <cstdint>
+#include <string>
+#include <vector>
+
+namespace keen_pbr3 {
+
+struct DnsUpstreamProbeResult {
+    // true when the upstream answered the query with NOERROR.
+    bool ok{false};
+    // Time from sending the query to receiving the answer (or giving up).
+    std::int64_t latency_ms{0};
+    // A records from the answer section.
+    std::vector<std::string> resolved_ips;
+    // Response code name ("NOERROR", "SERVFAIL", ...); empty without a response.
+    std::string rcode;
+    std::string error;
+};
+
+// Send a single A query for `domain` to a plain DNS upstream ("ip",
+// "ip:port" or "[ipv6]:port") and report whether it answered in time.
+// Datagrams whose id or question do not match the query are ignored; a
+// truncated (TC) answer is reported as an error, not as a success.
+// Never throws: invalid addresses and network failures are reported in
+// DnsUpstreamProbeResult::error.
+DnsUpstreamProbeResult probe_dns_upstream(const std::string& address,
+                                          const std::string& domain,
+                                          std::chrono::milliseconds timeout);
+
+} // namespace keen_pbr3
@@ -1,4 +1,5 @@
 #include "dnsmasq_gen.hpp"
+#include "dns_probe_server.hpp"
 #include "keenetic_dns.hpp"
 #include "../crypto/md5.hpp"
 #include "../log/logger.hpp"
@@ -11,7 +12,6 @@ namespace keen_pbr3 {
 
 namespace {
 
-static constexpr const char* kDnsProbeZone = "check.keen.pbr";
 static constexpr size_t kBatchSize = 50;
 static constexpr size_t kMaxDnsmasqRowLength = 1024;
 static constexpr size_t kMaxDomainNameLength = 255;
@@ -107,15 +107,29 @@ void DnsmasqGenerator::generate_directives(
         *out << "address=/use-application-dns.net/\n\n";
     }
 
+    if (dns_config_.edns_packet_max.has_value()) {
+        if (hash_record_callback) {
+            hash_record_callback("edns-packet-max|" + std::to_string(*dns_config_.edns_packet_max));
+        }
+        if (out != nullptr) {
+            *out << "edns-packet-max=" << *dns_config_.edns_packet_max << "\n\n";
+        }
+    }
+
     if (dns_config_.dns_test_server.has_value()) {
         const auto parsed = parse_dns_address_str(dns_config_.dns_test_server->listen);
+        const auto& custom_suffix = dns_config_.dns_test_server->domain_suffix;
+        const std::string suffix = custom_suffix.value_or(kDefaultDnsProbeDomainSuffix);
         if (hash_record_callback) {
             hash_record_callback(
-                "probe-server|" + parsed.ip + "|" + std::to_string(parsed.port));
+                "probe-server|" + parsed.ip + "|" + std::to_string(parsed.port) +
+                (custom_suffix.has_value() ? "|" + suffix : std::string{}));
         }
         if (out != nullptr) {
-            *out << "rebind-domain-ok=keen.pbr\n";
-            *out << "server=/" << kDnsProbeZone << "/" << parsed.ip << "#" << parsed.port << "\n\n";
+            // The probe answers with a private address, so its zone must be
+            // exempt from dnsmasq rebind protection.
+            *out << "rebind-domain-ok=" << (custom_suffix.has_value() ? suffix : "keen.pbr") << "\n";
+            *out << "server=/" << suffix << "/" << parsed.ip << "#" << parsed.port << "\n\n";
         }
     }
 
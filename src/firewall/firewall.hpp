@@ -46,6 +46,8 @@ struct FirewallRuleCriteria {
   std::vector<std::string> src_addr; // CIDR list, empty = any source address
   std::vector<std::string>
       dst_addr;                 // CIDR list, empty = any destination address
+  std::vector<std::string>
+      inbound_interfaces;       // ingress interfaces, empty = any interface
   bool negate_src_port = false; // if true, match packets NOT from src_port
   bool negate_dst_port = false; // if true, match packets NOT to dst_port
   bool negate_src_addr = false; // if true, match packets NOT from src_addr
@@ -54,12 +56,14 @@ struct FirewallRuleCriteria {
   bool empty() const {
     return !dst_set_name.has_value() && !dscp.has_value() &&
            proto == L4Proto::Any && src_port.empty() && dst_port.empty() &&
-           src_addr.empty() && dst_addr.empty() && !apply_output;
+           src_addr.empty() && dst_addr.empty() &&
+           inbound_interfaces.empty() && !apply_output;
   }
 
   bool has_rule_selector() const {
     return dst_set_name.has_value() || dscp.has_value() || !src_port.empty() ||
-           !dst_port.empty() || !src_addr.empty() || !dst_addr.empty();
+           !dst_port.empty() || !src_addr.empty() || !dst_addr.empty() ||
+           !inbound_interfaces.empty();
   }
 };
 
@@ -108,7 +112,10 @@ enum class FirewallApplyMode : uint8_t {
   PreserveSets,
   // Refresh static list-backed elements while preserving dynamic DNS sets.
   // Backends may rebuild equivalent chains to publish the refreshed sets.
-  StaticSetsOnly
+  StaticSetsOnly,
+  // Rebuild chains and rules against the sets already in the kernel without
+  // flushing or filling them; only a missing set is created, empty.
+  RulesOnly
 };
 
 enum class FirewallSetGeneration : uint8_t { A, B };
@@ -118,7 +118,9 @@ std::vector<RuleState> apply_runtime_firewall(
                         firewall.create_ipset(set6, AF_INET6, 0);
                         rule_state.set_names.push_back(set6);
                     }
+                }
 
+                if (usage.has_static_entries && mode != FirewallApplyMode::RulesOnly) {
                     auto loader4 = firewall.create_batch_loader(set4);
                     auto loader6 = ipv6_decision.enabled
                         ? firewall.create_batch_loader(set6)
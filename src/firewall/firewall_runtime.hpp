@@ -13,7 +13,8 @@ namespace keen_pbr3 {
 
 // Materialize the runtime firewall configuration using the real backend.
 // Returns the realized rule-state snapshot that should be stored for later
-// verification and status reporting.
+// verification and status reporting. FirewallApplyMode::RulesOnly skips
+// loading list entries into the static sets.
 std::vector<RuleState> apply_runtime_firewall(
     const Config& config,
     const OutboundMarkMap& outbound_marks,
@@ -1,8 +1,10 @@
 #include "firewall_verifier.hpp"
 #include "iptables_verifier.hpp"
 #include "nftables_verifier.hpp"
+#include "../util/format_compat.hpp"
 #include "../util/safe_exec.hpp"
 
+#include <algorithm>
 #include <atomic>
 
 namespace keen_pbr3 {
@@ -31,6 +33,32 @@ CommandResult run_command_capture(const std::vector<std::string>& args) {
     };
 }
 
+std::vector<std::string> find_firewall_drift(FirewallVerifier& verifier,
+                                             const FirewallState& state) {
+    const auto& expected = state.get_rules();
+    const bool installs_rules = std::any_of(
+        expected.begin(), expected.end(),
+        [](const RuleState& rs) { return rs.action_type != RuleActionType::Skip; });
+    if (!installs_rules) return {};
+
+    verifier.set_expected_fwmark_mask(state.get_fwmark_mask());
+    std::vector<std::string> drift;
+    const auto chain = verifier.verify_chain();
+    if (!chain.chain_present || !chain.prerouting_hook_present) {
+        drift.push_back(chain.detail);
+        return drift;
+    }
+    for (const auto& check : verifier.verify_rules(expected)) {
+        if (check.status == CheckStatus::ok) continue;
+        drift.push_back(format("{} rule for {} is {}: {}",
+                               check.action,
+                               check.set_name,
+                               check.status == CheckStatus::missing ? "missing" : "mismatched",
+                               check.detail));
+    }
+    return drift;
+}
+
 std::unique_ptr<FirewallVerifier> create_firewall_verifier(
     FirewallBackend backend,
     bool use_raw_prerouting,
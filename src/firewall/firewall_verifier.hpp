@@ -57,6 +57,12 @@ protected:
     uint32_t expected_fwmark_mask_{0xFFFFFFFFu};
 };
 
+// Describe how the live firewall differs from `state`: a missing chain or
+// PREROUTING hook, or rules that are missing or carry another mark. Empty
+// when everything expected is installed, or when nothing is expected.
+std::vector<std::string> find_firewall_drift(FirewallVerifier& verifier,
+                                             const FirewallState& state);
+
 // Factory: create a verifier for the given backend.
 // runner defaults to run_command_capture; override for testing.
 std::unique_ptr<FirewallVerifier> create_firewall_verifier(
@@ -130,16 +130,17 @@ void IptablesFirewall::prepare_apply(FirewallApplyMode mode) {
   pending_elements_.clear();
   pending_rules_.clear();
 
-  target_v4_generation_ = mode == FirewallApplyMode::Destructive
-                              ? FirewallSetGeneration::A
-                          : active_v4_generation_.has_value()
-                              ? opposite_generation(*active_v4_generation_)
-                              : FirewallSetGeneration::A;
-  target_v6_generation_ = mode == FirewallApplyMode::Destructive
-                              ? FirewallSetGeneration::A
-                          : active_v6_generation_.has_value()
-                              ? opposite_generation(*active_v6_generation_)
-                              : FirewallSetGeneration::A;
+  // A rules-only apply keeps matching the filled active generation.
+  const auto next_generation =
+      [mode](const std::optional<FirewallSetGeneration> &active) {
+        if (mode == FirewallApplyMode::Destructive || !active.has_value()) {
+          return FirewallSetGeneration::A;
+        }
+        return mode == FirewallApplyMode::RulesOnly ? *active
+                                                    : opposite_generation(*active);
+      };
+  target_v4_generation_ = next_generation(active_v4_generation_);
+  target_v6_generation_ = next_generation(active_v6_generation_);
   apply_prepared_ = true;
 }
 
@@ -183,9 +184,10 @@ void IptablesFirewall::create_ipset(const std::string &set_name, int family,
   created_sets_[set_name] = family;
 }
 
-void IptablesFirewall::append_rules_for_family(
-    bool ipv6, PendingRule::Action action, uint32_t fwmark,
-    const FirewallRuleCriteria &criteria) {
+std::vector<IptablesFirewall::PendingRule>
+IptablesFirewall::expand_pending_rules(bool ipv6, PendingRule::Action action,
+                                       uint32_t fwmark, uint32_t fwmark_mask,
+                                       const FirewallRuleCriteria &criteria) {
   const std::vector<std::string> any_addr{""};
   const auto filtered_src_addrs =
       criteria.src_addr.empty()
@@ -197,9 +199,10 @@ void IptablesFirewall::append_rules_for_family(
           : filter_addrs_by_family(criteria.dst_addr, ipv6);
   if ((!criteria.src_addr.empty() && filtered_src_addrs.empty()) ||
       (!criteria.dst_addr.empty() && filtered_dst_addrs.empty())) {
-    return;
+    return {};
   }
 
+  std::vector<PendingRule> rules;
   for (const auto proto : expand_l4_protos_for_iptables(criteria)) {
     const std::vector<std::string> &src_addrs = filtered_src_addrs;
     const std::vector<std::string> &dst_addrs = filtered_dst_addrs;
@@ -209,17 +212,62 @@ void IptablesFirewall::append_rules_for_family(
         pr.ipv6 = ipv6;
         pr.action = action;
         pr.fwmark = fwmark;
-        pr.fwmark_mask = fwmark_mask();
+        pr.fwmark_mask = fwmark_mask;
         pr.criteria = criteria;
         pr.criteria.proto = proto;
         pr.criteria.src_addr = src.empty() ? std::vector<std::string>{}
                                            : std::vector<std::string>{src};
         pr.criteria.dst_addr = dst.empty() ? std::vector<std::string>{}
                                            : std::vector<std::string>{dst};
-        pending_rules_.push_back(std::move(pr));
+        rules.push_back(std::move(pr));
+      }
+    }
+  }
+  return rules;
+}
+
+void IptablesFirewall::append_rules_for_family(
+    bool ipv6, PendingRule::Action action, uint32_t fwmark,
+    const FirewallRuleCriteria &criteria) {
+  for (auto &pr :
+       expand_pending_rules(ipv6, action, fwmark, fwmark_mask(), criteria)) {
+    pending_rules_.push_back(std::move(pr));
+  }
+}
+
+std::vector<std::string> IptablesFirewall::describe_rule_commands(
+    bool ipv6, RuleActionType action, uint32_t fwmark, uint32_t fwmark_mask,
+    const FirewallRuleCriteria &criteria,
+    const FirewallGlobalPrefilter &prefilter) {
+  PendingRule::Action pending_action;
+  switch (action) {
+  case RuleActionType::Mark:
+    pending_action = PendingRule::Mark;
+    break;
+  case RuleActionType::Drop:
+    pending_action = PendingRule::Drop;
+    break;
+  case RuleActionType::Pass:
+    pending_action = PendingRule::Pass;
+    break;
+  default:
+    return {};
+  }
+
+  const std::string command =
+      ipv6 ? "ip6tables -t mangle " : "iptables -t mangle ";
+  std::vector<std::string> commands;
+  for (const auto &pr : expand_pending_rules(ipv6, pending_action, fwmark,
+                                             fwmark_mask, criteria)) {
+    for (auto &line : build_rule_lines(pr, prefilter, CHAIN_NAME,
+                                       /*allow_conntrack=*/true)) {
+      if (!line.empty() && line.back() == '\n') {
+        line.pop_back();
       }
+      commands.push_back(command + line);
     }
   }
+  return commands;
 }
 
 void IptablesFirewall::create_mark_rule(uint32_t fwmark,
@@ -397,9 +445,15 @@ std::vector<std::string> IptablesFirewall::build_rule_lines(
     const std::string &chain, bool allow_conntrack) {
   // iptables cannot express a multi-value negated -i guard in one rule, so
   // multi-interface allowlists are expanded into one positive -i match per
-  // rule.
+  // rule. Rule-level interfaces are validated to be a subset of the global
+  // allowlist and replace it for that rule.
   std::vector<std::string> iface_frags;
-  if (prefilter.has_inbound_interfaces() &&
+  if (!pr.criteria.inbound_interfaces.empty()) {
+    iface_frags.reserve(pr.criteria.inbound_interfaces.size());
+    for (const auto &iface : pr.criteria.inbound_interfaces) {
+      iface_frags.push_back(" -i " + iface);
+    }
+  } else if (prefilter.has_inbound_interfaces() &&
       prefilter.inbound_interfaces.has_value() &&
       prefilter.inbound_interfaces->size() > 1) {
     iface_frags.reserve(prefilter.inbound_interfaces->size());
@@ -607,6 +661,67 @@ std::string IptablesFirewall::build_output_script(
   return s + "COMMIT\n";
 }
 
+std::optional<std::set<std::string>> IptablesFirewall::live_set_names() {
+  const auto result =
+      safe_exec_capture({"ipset", "list", "-n"}, /*suppress_stderr=*/true);
+  if (result.exit_code != 0) {
+    return std::nullopt;
+  }
+  std::set<std::string> names;
+  std::istringstream input(result.stdout_output);
+  std::string name;
+  while (input >> name) {
+    names.insert(name);
+  }
+  return names;
+}
+
+std::string IptablesFirewall::build_ipset_script(
+    FirewallApplyMode mode, bool effective_ipv6,
+    const std::optional<std::set<std::string>> &live_sets) const {
+  std::string ipset_script;
+  std::set<std::string> disabled_ipv6_sets;
+  for (const auto &ps : pending_sets_) {
+    if (ps.family_str == "inet6" && !effective_ipv6) {
+      disabled_ipv6_sets.insert(ps.name);
+      continue;
+    }
+    if (is_dynamic_set_name(ps.name)) {
+      // dnsmasq owns these entries. A routine re-apply must neither
+      // flush them nor alter their timeout metadata, but a set destroyed
+      // outside keen-pbr is recreated so rules referencing it still load.
+      if (mode == FirewallApplyMode::Destructive) {
+        ipset_script += build_ipset_create_line(ps);
+        if (clear_dynamic_sets_on_apply()) {
+          ipset_script += keen_pbr3::format("flush {}\n", ps.name);
+        }
+      } else if (live_sets.has_value() && live_sets->count(ps.name) == 0) {
+        ipset_script += build_ipset_create_line(ps);
+      }
+      continue;
+    }
+    if (mode == FirewallApplyMode::RulesOnly) {
+      if (live_sets.has_value() && live_sets->count(ps.name) == 0) {
+        ipset_script += build_ipset_create_line(ps);
+      }
+      continue;
+    }
+    ipset_script += build_ipset_create_line(ps);
+    ipset_script += keen_pbr3::format("flush {}\n", ps.name);
+  }
+  for (const auto &[set_name, buf] : pending_elements_) {
+    if (mode == FirewallApplyMode::RulesOnly ||
+        disabled_ipv6_sets.find(set_name) != disabled_ipv6_sets.end()) {
+      continue;
+    }
+    std::string elements = buf.str();
+    if (!elements.empty()) {
+      ipset_script += elements;
+    }
+  }
+  return ipset_script;
+}
+
 void IptablesFirewall::apply(FirewallApplyMode mode) {
   if (!apply_prepared_) {
     throw FirewallError("iptables apply was not prepared");
@@ -630,37 +745,14 @@ void IptablesFirewall::apply(FirewallApplyMode mode) {
   // Phase 1: populate the inactive static generation. Reusing an A/B slot is
   // safe because every target set is flushed before entries are added; a
   // failed restore can only leave partial data in an unreachable generation.
+  // Every set exists before phase 2 installs rules that --match-set it.
   {
-    std::string ipset_script;
-    std::set<std::string> disabled_ipv6_sets;
-    for (const auto &ps : pending_sets_) {
-      if (ps.family_str == "inet6" && !effective_ipv6) {
-        disabled_ipv6_sets.insert(ps.name);
-        continue;
-      }
-      if (is_dynamic_set_name(ps.name)) {
-        // dnsmasq owns these entries. A routine re-apply must neither
-        // flush them nor alter their timeout metadata.
-        if (mode == FirewallApplyMode::Destructive) {
-          ipset_script += build_ipset_create_line(ps);
-          if (clear_dynamic_sets_on_apply()) {
-            ipset_script += keen_pbr3::format("flush {}\n", ps.name);
-          }
-        }
-        continue;
-      }
-      ipset_script += build_ipset_create_line(ps);
-      ipset_script += keen_pbr3::format("flush {}\n", ps.name);
-    }
-    for (auto &[set_name, buf] : pending_elements_) {
-      if (disabled_ipv6_sets.find(set_name) != disabled_ipv6_sets.end()) {
-        continue;
-      }
-      std::string elements = buf.str();
-      if (!elements.empty()) {
-        ipset_script += elements;
-      }
+    std::optional<std::set<std::string>> live_sets;
+    if (mode != FirewallApplyMode::Destructive) {
+      live_sets = live_set_names();
     }
+    const std::string ipset_script =
+        build_ipset_script(mode, effective_ipv6, live_sets);
     if (!ipset_script.empty()) {
       pipe_to_cmd({"ipset", "restore", "-exist"}, ipset_script);
     }
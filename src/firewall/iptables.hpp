@@ -1,10 +1,13 @@
 #pragma once
 
+#include "../routing/firewall_state.hpp"
 #include "firewall.hpp"
 
 #include <cstdint>
 #include <map>
 #include <memory>
+#include <optional>
+#include <set>
 #include <sstream>
 #include <string>
 #include <vector>
@@ -49,6 +52,15 @@ public:
     FirewallBackend backend() const override;
     bool uses_raw_prerouting() const override { return use_raw_prerouting_; }
 
+  // Render the KeenPbrTable rules a route rule expands to for one family as
+  // `iptables -t mangle ...` / `ip6tables -t mangle ...` commands, without
+  // buffering or applying anything. Returns nothing for Skip.
+  static std::vector<std::string>
+  describe_rule_commands(bool ipv6, RuleActionType action, uint32_t fwmark,
+                         uint32_t fwmark_mask,
+                         const FirewallRuleCriteria &criteria,
+                         const FirewallGlobalPrefilter &prefilter = {});
+
 private:
   static constexpr const char *CHAIN_NAME = "KeenPbrTable";
   static constexpr const char *RAW_CHAIN_NAME = "KeenPbrRaw";
@@ -80,6 +92,13 @@ private:
   // Build the 'create <name> hash:net family <f> [timeout <t>]' line.
   static std::string build_ipset_create_line(const PendingSet &ps);
   static bool is_dynamic_set_name(const std::string &set_name);
+  // Build the phase 1 'ipset restore' script. `live_sets` holds the set names
+  // present in the kernel, or nullopt when they could not be listed.
+  std::string build_ipset_script(
+      FirewallApplyMode mode, bool effective_ipv6,
+      const std::optional<std::set<std::string>> &live_sets) const;
+  // Names of all ipsets in the kernel, or nullopt when ipset cannot list them.
+  static std::optional<std::set<std::string>> live_set_names();
   // Build a complete iptables-restore script for the given protocol and rules.
   static std::string
   build_ipt_script(bool ipv6, const std::vector<PendingRule> &rules,
@@ -124,9 +143,14 @@ private:
                                           bool ipv6) const;
   static const char *output_generation_chain(FirewallSetGeneration generation);
   // Expand filter (proto, src_addr, dst_addr) into cross-product of
-  // PendingRules and append them to out.  tcp/udp is split into two entries.
-  // Multiple CIDRs in src_addr / dst_addr each become separate rules (OR
-  // semantics when combined).
+  // PendingRules.  tcp/udp is split into two entries.  Multiple CIDRs in
+  // src_addr / dst_addr each become separate rules (OR semantics when
+  // combined).
+  static std::vector<PendingRule>
+  expand_pending_rules(bool ipv6, PendingRule::Action action, uint32_t fwmark,
+                       uint32_t fwmark_mask,
+                       const FirewallRuleCriteria &criteria);
+  // Append expand_pending_rules() output to pending_rules_.
   void append_rules_for_family(bool ipv6, PendingRule::Action action,
                                uint32_t fwmark,
                                const FirewallRuleCriteria &criteria);
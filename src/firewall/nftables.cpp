@@ -460,6 +460,27 @@ nlohmann::json NftablesFirewall::build_dscp_match_exprs(const std::string& ip_pr
     return exprs;
 }
 
+nlohmann::json NftablesFirewall::build_iifname_match_exprs(
+    const std::vector<std::string>& interfaces) {
+    nlohmann::json exprs = nlohmann::json::array();
+    if (interfaces.empty()) {
+        return exprs;
+    }
+
+    nlohmann::json rhs;
+    if (interfaces.size() == 1) {
+        rhs = interfaces.front();
+    } else {
+        rhs = {{"set", interfaces}};
+    }
+    exprs.push_back({{"match", {
+        {"op", "=="},
+        {"left", {{"meta", {{"key", "iifname"}}}}},
+        {"right", rhs}
+    }}});
+    return exprs;
+}
+
 nlohmann::json NftablesFirewall::build_mark_rule_json(const PendingRule& pr) {
     std::string ip_proto = (pr.family == AF_INET6) ? "ip6" : "ip";
     nlohmann::json expr = nlohmann::json::array();
@@ -467,6 +488,9 @@ nlohmann::json NftablesFirewall::build_mark_rule_json(const PendingRule& pr) {
         // set-membership match
         expr.push_back({{"match", {{"op", "=="}, {"left", {{"payload", {{"protocol", ip_proto}, {"field", "daddr"}}}}}, {"right", "@" + *pr.criteria.dst_set_name}}}});
     }
+    for (const auto& e : build_iifname_match_exprs(pr.criteria.inbound_interfaces)) {
+        expr.push_back(e);
+    }
     for (const auto& e : build_dscp_match_exprs(ip_proto, pr.criteria.dscp)) {
         expr.push_back(e);
     }
@@ -520,6 +544,9 @@ nlohmann::json NftablesFirewall::build_drop_rule_json(const PendingRule& pr) {
     if (pr.criteria.dst_set_name.has_value()) {
         expr.push_back({{"match", {{"op", "=="}, {"left", {{"payload", {{"protocol", ip_proto}, {"field", "daddr"}}}}}, {"right", "@" + *pr.criteria.dst_set_name}}}});
     }
+    for (const auto& e : build_iifname_match_exprs(pr.criteria.inbound_interfaces)) {
+        expr.push_back(e);
+    }
     for (const auto& e : build_dscp_match_exprs(ip_proto, pr.criteria.dscp)) {
         expr.push_back(e);
     }
@@ -549,6 +576,9 @@ nlohmann::json NftablesFirewall::build_pass_rule_json(const PendingRule& pr) {
     if (pr.criteria.dst_set_name.has_value()) {
         expr.push_back({{"match", {{"op", "=="}, {"left", {{"payload", {{"protocol", ip_proto}, {"field", "daddr"}}}}}, {"right", "@" + *pr.criteria.dst_set_name}}}});
     }
+    for (const auto& e : build_iifname_match_exprs(pr.criteria.inbound_interfaces)) {
+        expr.push_back(e);
+    }
     for (const auto& e : build_dscp_match_exprs(ip_proto, pr.criteria.dscp)) {
         expr.push_back(e);
     }
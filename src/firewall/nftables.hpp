@@ -124,6 +124,8 @@ private:
     // Build nftables match expression(s) for DSCP.
     static nlohmann::json build_dscp_match_exprs(const std::string& ip_proto,
                                                   std::optional<uint8_t> dscp);
+    // Build nftables match expression(s) for ingress interface names.
+    static nlohmann::json build_iifname_match_exprs(const std::vector<std::string>& interfaces);
     // Build the JSON element-add object for bulk-loading elems into a named set.
     static nlohmann::json build_elements_json(const std::string& set_name,
                                               const nlohmann::json& elems);
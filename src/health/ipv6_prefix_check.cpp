@@ -0,0 +1,177 @@
+#include "ipv6_prefix_check.hpp"
+
+#include "../util/format_compat.hpp"
+
+#include <arpa/inet.h>
+#include <netinet/in.h>
+
+#include <algorithm>
+#include <cstring>
+#include <iterator>
+#include <set>
+
+namespace keen_pbr3 {
+
+namespace {
+
+// LAN bridges checked when route.inbound_interfaces is not configured:
+// Keenetic's Home segment and OpenWrt's default LAN.
+constexpr const char* kDefaultLanBridges[] = {"br0", "br-lan"};
+
+bool is_global_unicast(const in6_addr& address) {
+    return (address.s6_addr[0] & 0xe0) == 0x20; // 2000::/3
+}
+
+bool is_unique_local(const in6_addr& address) {
+    return (address.s6_addr[0] & 0xfe) == 0xfc; // fc00::/7
+}
+
+// "/64" prefix of an address in CIDR form when in_scope accepts it, or an
+// empty string for other scopes and anything unparsable.
+std::string ipv6_prefix64(const std::string& cidr, bool (*in_scope)(const in6_addr&)) {
+    const std::string address = cidr.substr(0, cidr.find('/'));
+    in6_addr parsed{};
+    if (inet_pton(AF_INET6, address.c_str(), &parsed) != 1) return {};
+    if (!in_scope(parsed)) return {};
+
+    std::memset(parsed.s6_addr + 8, 0, 8);
+    char buffer[INET6_ADDRSTRLEN] = {};
+    if (inet_ntop(AF_INET6, &parsed, buffer, sizeof(buffer)) == nullptr) return {};
+    return std::string(buffer) + "/64";
+}
+
+std::vector<std::string> prefixes_of(const DumpedInterface& interface,
+                                     bool (*in_scope)(const in6_addr&)) {
+    std::vector<std::string> prefixes;
+    for (const auto& cidr : interface.ipv6_addresses) {
+        if (std::find(interface.deprecated_ipv6_addresses.begin(),
+                      interface.deprecated_ipv6_addresses.end(),
+                      cidr) != interface.deprecated_ipv6_addresses.end()) {
+            continue;
+        }
+        auto prefix = ipv6_prefix64(cidr, in_scope);
+        if (!prefix.empty() &&
+            std::find(prefixes.begin(), prefixes.end(), prefix) == prefixes.end()) {
+            prefixes.push_back(std::move(prefix));
+        }
+    }
+    return prefixes;
+}
+
+const DumpedInterface* find_interface(const std::vector<DumpedInterface>& interfaces,
+                                      const std::string& name) {
+    const auto it = std::find_if(interfaces.begin(), interfaces.end(),
+                                 [&name](const DumpedInterface& interface) {
+                                     return interface.name == name;
+                                 });
+    return it == interfaces.end() ? nullptr : &*it;
+}
+
+std::string join(const std::vector<std::string>& items) {
+    std::string out;
+    for (const auto& item : items) {
+        if (!out.empty()) out += ", ";
+        out += item;
+    }
+    return out;
+}
+
+void collect_used_outbounds(const std::vector<Outbound>& outbounds,
+                            const std::string& tag,
+                            std::set<std::string>& used) {
+    if (!used.insert(tag).second) return;
+    const auto it = std::find_if(outbounds.begin(), outbounds.end(),
+                                 [&tag](const Outbound& outbound) {
+                                     return outbound.tag == tag;
+                                 });
+    if (it == outbounds.end() || it->type != OutboundType::URLTEST) return;
+    for (const auto& group : it->outbound_groups.value_or(std::vector<OutboundGroup>{})) {
+        for (const auto& member : group.outbounds) {
+            collect_used_outbounds(outbounds, member, used);
+        }
+    }
+}
+
+} // namespace
+
+std::vector<std::string> find_ipv6_prefix_mismatches(
+    const Config& config,
+    const std::vector<DumpedInterface>& interfaces) {
+    const auto route_cfg = config.route.value_or(RouteConfig{});
+    const auto rules = route_cfg.rules.value_or(std::vector<RouteRule>{});
+    const auto outbounds = config.outbounds.value_or(std::vector<Outbound>{});
+
+    std::vector<std::string> lan_names =
+        route_cfg.inbound_interfaces.value_or(std::vector<std::string>{});
+    for (const auto& rule : rules) {
+        if (!route_rule_enabled(rule)) continue;
+        for (const auto& name : rule.inbound_interfaces.value_or(std::vector<std::string>{})) {
+            if (std::find(lan_names.begin(), lan_names.end(), name) == lan_names.end()) {
+                lan_names.push_back(name);
+            }
+        }
+    }
+    if (lan_names.empty()) {
+        lan_names.assign(std::begin(kDefaultLanBridges), std::end(kDefaultLanBridges));
+    }
+
+    std::set<std::string> lan_prefixes;
+    std::vector<std::string> lan_descriptions;
+    for (const auto& name : lan_names) {
+        const DumpedInterface* lan = find_interface(interfaces, name);
+        if (lan == nullptr) continue;
+        for (const auto& prefix : prefixes_of(*lan, is_global_unicast)) {
+            lan_prefixes.insert(prefix);
+            lan_descriptions.push_back(prefix + " on " + name);
+        }
+    }
+    if (lan_prefixes.empty()) return {};
+
+    std::set<std::string> used;
+    for (const auto& rule : rules) {
+        if (route_rule_enabled(rule)) {
+            collect_used_outbounds(outbounds, rule.outbound, used);
+        }
+    }
+
+    std::vector<std::string> warnings;
+    for (const auto& outbound : outbounds) {
+        if (outbound.type != OutboundType::INTERFACE || !outbound.interface.has_value() ||
+            used.count(outbound.tag) == 0) {
+            continue;
+        }
+        const DumpedInterface* wan = find_interface(interfaces, *outbound.interface);
+        if (wan == nullptr) continue;
+        const auto prefixes = prefixes_of(*wan, is_global_unicast);
+        if (prefixes.empty()) {
+            // A tunnel with only ULA addresses can carry the LAN's global
+            // IPv6 traffic only if something on the path translates it.
+            const auto ula_prefixes = prefixes_of(*wan, is_unique_local);
+            if (ula_prefixes.empty()) continue;
+            warnings.push_back(format(
+                "Outbound '{}' sends IPv6 via {}, which only has unique local addresses ({}), "
+                "while the LAN uses {}. Without NAT66 on that path, IPv6 traffic from the LAN "
+                "is not routable.",
+                outbound.tag,
+                *outbound.interface,
+                join(ula_prefixes),
+                join(lan_descriptions)));
+            continue;
+        }
+        const bool shares_prefix = std::any_of(
+            prefixes.begin(), prefixes.end(),
+            [&lan_prefixes](const std::string& prefix) { return lan_prefixes.count(prefix) > 0; });
+        if (shares_prefix) continue;
+
+        warnings.push_back(format(
+            "Outbound '{}' sends IPv6 via {} ({}), which differs from the LAN prefix ({}). "
+            "Without NAT66/NPTv6 on that path, replies may return asymmetrically.",
+            outbound.tag,
+            *outbound.interface,
+            join(prefixes),
+            join(lan_descriptions)));
+    }
+    return warnings;
+}
+
+} // namespace keen_pbr3
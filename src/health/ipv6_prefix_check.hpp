@@ -0,0 +1,24 @@
+#pragma once
+
+#include "../config/config.hpp"
+#include "../routing/netlink.hpp"
+
+#include <string>
+#include <vector>
+
+namespace keen_pbr3 {
+
+// Compare the global IPv6 /64 prefixes of the LAN (route.inbound_interfaces,
+// or the br0/br-lan bridge when none are configured) with those of every
+// interface outbound used by an enabled route rule, directly or as an
+// urltest member. keen-pbr does no NAT66/NPTv6, so a tunnel whose prefix
+// differs from the LAN's may send replies back on a different path, and a
+// tunnel with only ULA addresses needs NAT66 to carry LAN traffic at all.
+// Returns one human-readable warning per such outbound. Deprecated
+// addresses left over from a renumbered prefix are ignored. Nothing is
+// reported when the LAN carries no global IPv6 address.
+std::vector<std::string> find_ipv6_prefix_mismatches(
+    const Config& config,
+    const std::vector<DumpedInterface>& interfaces);
+
+} // namespace keen_pbr3
@@ -1,6 +1,7 @@
 #pragma once
 
 #include "../firewall/firewall.hpp"
+#include "../routing/netlink.hpp"
 
 #include <cstdint>
 #include <optional>
@@ -36,6 +37,7 @@ struct RouteTableCheck {
     std::optional<std::string> expected_destination;
     std::optional<std::string> expected_interface;
     std::optional<std::string> expected_gateway;
+    std::vector<RouteNexthop> expected_nexthops;
     std::optional<uint32_t> expected_metric;
     std::optional<std::string> expected_route_type;
     bool table_exists{false};
@@ -65,6 +67,8 @@ struct RoutingHealthReport {
     std::vector<FirewallRuleCheck> firewall_rules;
     std::vector<RouteTableCheck> route_tables;
     std::vector<PolicyRuleCheck> policy_rules;
+    // Advisory findings that do not affect overall_ok.
+    std::vector<std::string> warnings;
     std::string error;
 };
 
@@ -31,6 +31,7 @@ bool route_matches(const RouteSpec& expected, const DumpedRoute& actual) {
            expected.gateway == actual.gateway &&
            expected.blackhole == actual.blackhole &&
            expected.unreachable == actual.unreachable &&
+           expected.nexthops == actual.nexthops &&
            (expected.metric == 0 || expected.metric == actual.metric) &&
            (expected.family == 0 || expected.family == actual.family);
 }
@@ -121,6 +122,7 @@ RoutingHealthReport build_routing_health_report(
                 extra_check.expected_destination = routes[i].destination;
                 extra_check.expected_interface = routes[i].interface;
                 extra_check.expected_gateway = routes[i].gateway;
+                extra_check.expected_nexthops = routes[i].nexthops;
                 if (routes[i].metric != 0) {
                     extra_check.expected_metric = routes[i].metric;
                 }
@@ -301,7 +303,13 @@ nlohmann::json routing_health_report_to_json(const RoutingHealthReport& r) {
         resp.policy_rules.push_back(std::move(arc));
     }
 
+    if (!r.warnings.empty()) resp.warnings = r.warnings;
+
     return nlohmann::json(resp);
 }
 
+int routing_health_exit_code(const nlohmann::json& report) {
+    return report.is_object() && report.value("overall", "") == "ok" ? 0 : 1;
+}
+
 } // namespace keen_pbr3
@@ -53,4 +53,7 @@ private:
 // - fwmark/fwmask values formatted as hex strings (e.g. "0x00010000")
 nlohmann::json routing_health_report_to_json(const RoutingHealthReport& r);
 
+// Exit status for a serialized report: 0 when "overall" is "ok", 1 otherwise.
+int routing_health_exit_code(const nlohmann::json& report);
+
 } // namespace keen_pbr3
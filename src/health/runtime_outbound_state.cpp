@@ -354,6 +354,74 @@ api::RuntimeOutboundStateElement build_urltest_outbound_state(const Config& conf
     return state;
 }
 
+bool route_carries_outbound(const DumpedRoute& route, const Outbound& outbound) {
+    if (route.nexthops.empty()) {
+        return route_matches_outbound(route, outbound);
+    }
+    if (route.destination != "default" || outbound.type != OutboundType::INTERFACE) {
+        return false;
+    }
+    const auto& gateway = route.family == AF_INET ? outbound.gateway : outbound.gateway6;
+    return std::any_of(route.nexthops.begin(), route.nexthops.end(),
+                       [&](const RouteNexthop& path) {
+                           return path.interface == outbound.interface.value_or("") &&
+                                  (!gateway.has_value() || path.gateway == gateway);
+                       });
+}
+
+api::RuntimeOutboundStateElement build_multipath_outbound_state(const Config& config,
+                                                                const Outbound& outbound,
+                                                                const OutboundMarkMap& outbound_marks,
+                                                                const std::vector<RuleSpec>& policy_rules,
+                                                                NetlinkManager& netlink) {
+    api::RuntimeOutboundStateElement state;
+    state.tag = outbound.tag;
+    state.type = outbound.type;
+
+    const auto all_outbounds = config.outbounds.value_or(std::vector<Outbound>{});
+    const auto table_id = outbound_table_id(outbound_marks, policy_rules, outbound.tag);
+    std::vector<DumpedRoute> routes;
+    if (table_id.has_value()) {
+        routes = netlink.dump_routes_in_table(*table_id);
+    }
+    const DumpedRoute* primary_route = find_primary_default_route(routes);
+
+    size_t active_count = 0;
+    std::vector<api::RuntimeInterfaceState> interfaces;
+    for (const auto& nexthop : outbound.nexthops.value_or(std::vector<api::NexthopElement>{})) {
+        const Outbound* child = find_outbound(all_outbounds, nexthop.outbound);
+        if (child == nullptr) {
+            continue;
+        }
+
+        api::RuntimeInterfaceState interface_state;
+        interface_state.outbound_tag = child->tag;
+        interface_state.interface_name = child->interface;
+        const bool active = std::any_of(routes.begin(), routes.end(), [&](const DumpedRoute& route) {
+            return !route.blackhole && !route.unreachable && route_carries_outbound(route, *child);
+        });
+        interface_state.status = active
+            ? api::RuntimeInterfaceStatusEnum::ACTIVE
+            : api::RuntimeInterfaceStatusEnum::UNAVAILABLE;
+        if (!active && !is_interface_outbound_reachable(*child, netlink)) {
+            interface_state.detail = std::string("interface is not reachable from the main routing table");
+        }
+        if (active) {
+            ++active_count;
+        }
+        interfaces.push_back(std::move(interface_state));
+    }
+
+    if (active_count > 0 && active_count < interfaces.size()) {
+        state.status = api::ResolverLiveStatus::DEGRADED;
+        state.detail = "multipath route is missing unavailable paths";
+    } else {
+        state.status = derive_overall_status(interfaces, active_count > 0, primary_route != nullptr);
+    }
+    state.interfaces = std::move(interfaces);
+    return state;
+}
+
 } // namespace
 
 api::RuntimeOutboundsResponse build_runtime_outbounds_response(
@@ -381,6 +449,11 @@ api::RuntimeOutboundsResponse build_runtime_outbounds_response(
                     build_urltest_outbound_state(config, outbound, outbound_marks,
                                                  policy_rules, netlink, urltest_state_lookup));
                 break;
+            case OutboundType::MULTIPATH:
+                response.outbounds.push_back(
+                    build_multipath_outbound_state(config, outbound, outbound_marks,
+                                                   policy_rules, netlink));
+                break;
             case OutboundType::BLACKHOLE:
             case OutboundType::IGNORE: {
                 api::RuntimeOutboundStateElement state;
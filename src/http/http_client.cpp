@@ -1,6 +1,8 @@
 #include "http_client.hpp"
 
 #include <cctype>
+#include <cerrno>
+#include <cstdlib>
 
 namespace keen_pbr3 {
 HttpError::HttpError(const std::string& message, long status_code)
@@ -15,12 +17,13 @@ std::string trim_header_value(const std::string& value) {
 }
 HttpTransportRequest request_for(const std::string& url, std::chrono::seconds timeout,
                                  const std::string& user_agent, size_t max_size,
-                                 uint32_t fwmark) {
+                                 const HttpRequestOptions& options) {
     HttpTransportRequest request;
     request.url = url;
     request.timeout_ms = static_cast<long>(timeout.count() * 1000);
     request.user_agent = user_agent;
-    request.fwmark = fwmark;
+    request.fwmark = options.fwmark;
+    request.resolve = options.resolve;
     request.max_redirects = 5;
     request.max_response_size = max_size;
     return request;
@@ -28,6 +31,18 @@ HttpTransportRequest request_for(const std::string& url, std::chrono::seconds ti
 void throw_for_status(long status) {
     if (status >= 400) throw HttpError("HTTP error " + std::to_string(status), status);
 }
+std::optional<uint64_t> parse_size(const std::string& value) {
+    if (value.empty() || !std::isdigit(static_cast<unsigned char>(value.front()))) return std::nullopt;
+    char* end = nullptr;
+    errno = 0;
+    const unsigned long long parsed = std::strtoull(value.c_str(), &end, 10);
+    if (errno != 0 || *end != '\0') return std::nullopt;
+    return static_cast<uint64_t>(parsed);
+}
+std::string header_value(const HttpTransportResponse& response, const std::string& name) {
+    const auto it = response.headers.find(name);
+    return it == response.headers.end() ? std::string() : it->second;
+}
 } // namespace
 
 void detail::capture_response_header_line(std::string_view header_view, std::string& etag,
@@ -55,7 +70,7 @@ void HttpClient::set_max_response_size(size_t bytes) { max_response_size_ = byte
 
 std::string HttpClient::download(const std::string& url, const HttpRequestOptions& options) {
     try {
-        auto response = transport_->perform(request_for(url, timeout_, user_agent_, max_response_size_, options.fwmark));
+        auto response = transport_->perform(request_for(url, timeout_, user_agent_, max_response_size_, options));
         throw_for_status(response.status_code);
         return response.body;
     } catch (const HttpTransportError& error) {
@@ -65,7 +80,7 @@ std::string HttpClient::download(const std::string& url, const HttpRequestOption
 
 std::string HttpClient::post_json(const std::string& url, const std::string& body,
                                   const HttpRequestOptions& options) {
-    auto request = request_for(url, timeout_, user_agent_, max_response_size_, options.fwmark);
+    auto request = request_for(url, timeout_, user_agent_, max_response_size_, options);
     request.method = "POST";
     request.body = body;
     request.headers.push_back("Content-Type: application/json");
@@ -81,7 +96,7 @@ std::string HttpClient::post_json(const std::string& url, const std::string& bod
 ConditionalDownloadResult HttpClient::download_conditional(
     const std::string& url, const std::string& if_none_match, const std::string& if_modified_since,
     const HttpRequestOptions& options) {
-    auto request = request_for(url, timeout_, user_agent_, max_response_size_, options.fwmark);
+    auto request = request_for(url, timeout_, user_agent_, max_response_size_, options);
     if (!if_none_match.empty()) request.headers.push_back("If-None-Match: " + if_none_match);
     if (!if_modified_since.empty()) request.headers.push_back("If-Modified-Since: " + if_modified_since);
     try {
@@ -94,6 +109,37 @@ ConditionalDownloadResult HttpClient::download_conditional(
         if (etag != response.headers.end()) result.etag = etag->second;
         const auto modified = response.headers.find("last-modified");
         if (modified != response.headers.end()) result.last_modified = modified->second;
+        result.content_type = header_value(response, "content-type");
+        return result;
+    } catch (const HttpTransportError& error) {
+        throw HttpError(error.what());
+    }
+}
+
+HttpProbeResult HttpClient::probe(const std::string& url, const HttpRequestOptions& options) {
+    auto request = request_for(url, timeout_, user_agent_, max_response_size_, options);
+    request.method = "HEAD";
+    request.discard_body = true;
+    try {
+        auto response = transport_->perform(request);
+        bool ranged = false;
+        if (response.status_code == 405 || response.status_code == 501) {
+            request.method = "GET";
+            request.headers.push_back("Range: bytes=0-0");
+            response = transport_->perform(request);
+            ranged = response.status_code == 206;
+        }
+        HttpProbeResult result;
+        result.status_code = response.status_code;
+        result.content_type = header_value(response, "content-type");
+        if (ranged) {
+            // "bytes 0-0/<total>"; the total may be "*" when unknown.
+            const std::string range = header_value(response, "content-range");
+            const auto slash = range.rfind('/');
+            if (slash != std::string::npos) result.content_length = parse_size(range.substr(slash + 1));
+        } else {
+            result.content_length = parse_size(header_value(response, "content-length"));
+        }
         return result;
     } catch (const HttpTransportError& error) {
         throw HttpError(error.what());
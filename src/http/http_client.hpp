@@ -9,6 +9,8 @@
 #include <string>
 #include <string_view>
 #include <memory>
+#include <optional>
+#include <vector>
 
 #include "http_transport.hpp"
 
@@ -22,6 +24,8 @@ void capture_response_header_line(std::string_view header,
 
 struct HttpRequestOptions {
     uint32_t fwmark{0};
+    // See HttpTransportRequest::resolve.
+    std::vector<std::string> resolve;
 };
 
 class HttpError : public std::runtime_error {
@@ -38,6 +42,13 @@ struct ConditionalDownloadResult {
     std::string body;
     std::string etag;
     std::string last_modified;
+    std::string content_type;
+};
+
+struct HttpProbeResult {
+    long status_code{0};
+    std::optional<uint64_t> content_length;
+    std::string content_type;
 };
 
 class HttpClient {
@@ -66,6 +77,12 @@ public:
         const std::string& if_modified_since = "",
         const HttpRequestOptions& options = {});
 
+    // Check a URL without downloading its body: a HEAD request, retried as a
+    // one-byte ranged GET when the server rejects HEAD. HTTP error statuses are
+    // returned, not thrown; transport failures throw HttpError.
+    HttpProbeResult probe(const std::string& url,
+                          const HttpRequestOptions& options = {});
+
 private:
     std::chrono::seconds timeout_{10};
     std::string user_agent_{"keen-pbr/" KEEN_PBR3_VERSION_STRING};
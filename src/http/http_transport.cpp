@@ -104,6 +104,8 @@ HttpTransportResponse LibcurlHttpTransport::perform(const HttpTransportRequest&
         setopt(curl.get(), CURLOPT_POSTFIELDS, request.body.c_str());
         setopt(curl.get(), CURLOPT_POSTFIELDSIZE_LARGE,
                static_cast<curl_off_t>(request.body.size()));
+    } else if (request.method == "HEAD") {
+        setopt(curl.get(), CURLOPT_NOBODY, 1L);
     } else if (request.method != "GET") {
         throw HttpTransportError("Unsupported HTTP method: " + request.method);
     }
@@ -118,6 +120,15 @@ HttpTransportResponse LibcurlHttpTransport::perform(const HttpTransportRequest&
         headers.reset(appended);
     }
     if (headers) setopt(curl.get(), CURLOPT_HTTPHEADER, headers.get());
+    HeaderList resolve;
+    for (const auto& entry : request.resolve) {
+        curl_slist* appended = curl_slist_append(resolve.get(), entry.c_str());
+        if (!appended) throw HttpTransportError("Failed to allocate resolve entry");
+        const auto previous = resolve.release();
+        (void)previous;
+        resolve.reset(appended);
+    }
+    if (resolve) setopt(curl.get(), CURLOPT_RESOLVE, resolve.get());
     const auto started = std::chrono::steady_clock::now();
     const CURLcode result = curl_easy_perform(curl.get());
     response.elapsed = std::chrono::duration_cast<std::chrono::milliseconds>(std::chrono::steady_clock::now() - started);
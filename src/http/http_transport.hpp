@@ -18,6 +18,9 @@ struct HttpTransportRequest {
     uint32_t fwmark{0};
     long max_redirects{5};
     std::vector<std::string> headers;
+    // Pinned name resolution in libcurl CURLOPT_RESOLVE form
+    // ("host:port:addr[,addr]..."); hosts not listed use the system resolver.
+    std::vector<std::string> resolve;
     std::string method{"GET"};
     std::string body;
     bool discard_body{false};
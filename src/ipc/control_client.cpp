@@ -6,7 +6,9 @@
 #include <sys/un.h>
 #include <unistd.h>
 
+#include <algorithm>
 #include <cerrno>
+#include <chrono>
 #include <cstring>
 #include <ostream>
 
@@ -15,10 +17,18 @@ namespace {
 
 constexpr std::size_t kResolverStreamChunkBytes = static_cast<std::size_t>(16) * 1024U;
 
-void wait_for(int fd, short events, int timeout_ms) {
+using Deadline = std::chrono::steady_clock::time_point;
+
+Deadline deadline_after(int timeout_ms) {
+    return std::chrono::steady_clock::now() + std::chrono::milliseconds(timeout_ms);
+}
+
+void wait_for(int fd, short events, Deadline deadline) {
+    const auto remaining = std::chrono::duration_cast<std::chrono::milliseconds>(
+        deadline - std::chrono::steady_clock::now());
     pollfd descriptor{fd, events, 0};
-    const int result = poll(&descriptor, 1, timeout_ms);
-    if (result == 0) throw ControlProtocolError("control socket timeout");
+    const int result = poll(&descriptor, 1, static_cast<int>(std::max<std::int64_t>(remaining.count(), 0)));
+    if (result == 0) throw ControlTimeoutError("control socket timeout");
     if (result < 0) throw ControlProtocolError("control socket poll failed: " + std::string(strerror(errno)));
     if ((descriptor.revents & events) == 0 &&
         (descriptor.revents & (POLLERR | POLLHUP | POLLNVAL)) != 0) {
@@ -26,21 +36,21 @@ void wait_for(int fd, short events, int timeout_ms) {
     }
 }
 
-void write_all(int fd, const std::string& data, int timeout_ms) {
+void write_all(int fd, const std::string& data, Deadline deadline) {
     std::size_t written = 0;
     while (written < data.size()) {
-        wait_for(fd, POLLOUT, timeout_ms);
+        wait_for(fd, POLLOUT, deadline);
         const ssize_t count = send(fd, data.data() + written, data.size() - written, MSG_NOSIGNAL);
         if (count <= 0) throw ControlProtocolError("control socket write failed: " + std::string(strerror(errno)));
         written += static_cast<std::size_t>(count);
     }
 }
 
-std::string read_exact(int fd, std::size_t size, int timeout_ms) {
+std::string read_exact(int fd, std::size_t size, Deadline deadline) {
     std::string result(size, '\0');
     std::size_t received = 0;
     while (received < size) {
-        wait_for(fd, POLLIN, timeout_ms);
+        wait_for(fd, POLLIN, deadline);
         const ssize_t count = recv(fd, result.data() + received, size - received, 0);
         if (count <= 0) throw ControlProtocolError("control socket read failed");
         received += static_cast<std::size_t>(count);
@@ -65,13 +75,13 @@ int connect_control_socket(const std::string& socket_path) {
     return fd;
 }
 
-nlohmann::json read_response_envelope(int fd, int timeout_ms) {
-    const std::string header = read_exact(fd, sizeof(std::uint32_t), timeout_ms);
+nlohmann::json read_response_envelope(int fd, Deadline deadline) {
+    const std::string header = read_exact(fd, sizeof(std::uint32_t), deadline);
     std::uint32_t length = 0;
     std::memcpy(&length, header.data(), sizeof(length));
     const std::size_t payload_size = ntohl(length);
     if (payload_size > kMaxControlMessageBytes) throw ControlProtocolError("control response exceeds maximum size");
-    return decode_message(header + read_exact(fd, payload_size, timeout_ms));
+    return decode_message(header + read_exact(fd, payload_size, deadline));
 }
 
 } // namespace
@@ -80,11 +90,12 @@ nlohmann::json request_control(const std::string& socket_path,
                                const nlohmann::json& request,
                                int timeout_ms) {
     validate_request_envelope(request);
+    const Deadline deadline = deadline_after(timeout_ms);
     const int fd = connect_control_socket(socket_path);
     const auto close_fd = [&]() { close(fd); };
     try {
-        write_all(fd, encode_message(request), timeout_ms);
-        return read_response_envelope(fd, timeout_ms);
+        write_all(fd, encode_message(request), deadline);
+        return read_response_envelope(fd, deadline);
     } catch (...) {
         close_fd();
         throw;
@@ -100,8 +111,8 @@ void stream_control(const std::string& socket_path,
     int fd = -1;
     try {
         fd = connect_control_socket(socket_path);
-        write_all(fd, encode_message(request), idle_timeout_ms);
-        const auto response = read_response_envelope(fd, idle_timeout_ms);
+        write_all(fd, encode_message(request), deadline_after(idle_timeout_ms));
+        const auto response = read_response_envelope(fd, deadline_after(idle_timeout_ms));
         if (!response.value("ok", false)) {
             const auto code = response.value("error", nlohmann::json::object()).value("code", "daemon_error");
             throw ControlStreamError(code, false);
@@ -111,7 +122,8 @@ void stream_control(const std::string& socket_path,
         }
 
         while (true) {
-            const std::string length_frame = read_exact(fd, sizeof(std::uint32_t), idle_timeout_ms);
+            const std::string length_frame =
+                read_exact(fd, sizeof(std::uint32_t), deadline_after(idle_timeout_ms));
             std::uint32_t length = 0;
             std::memcpy(&length, length_frame.data(), sizeof(length));
             const std::size_t chunk_size = ntohl(length);
@@ -119,7 +131,7 @@ void stream_control(const std::string& socket_path,
             if (chunk_size > kResolverStreamChunkBytes) {
                 throw ControlStreamError("protocol_error", active_bytes_streamed);
             }
-            const std::string chunk = read_exact(fd, chunk_size, idle_timeout_ms);
+            const std::string chunk = read_exact(fd, chunk_size, deadline_after(idle_timeout_ms));
             output.write(chunk.data(), static_cast<std::streamsize>(chunk.size()));
             output.flush();
             if (!output) throw ControlStreamError("stdout_error", active_bytes_streamed);
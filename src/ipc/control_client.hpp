@@ -7,7 +7,14 @@
 
 namespace keen_pbr3::ipc {
 
+// Thrown when the daemon does not answer before the deadline.
+class ControlTimeoutError : public ControlProtocolError {
+public:
+    using ControlProtocolError::ControlProtocolError;
+};
+
 // Perform one bounded request/response exchange with the running daemon.
+// timeout_ms bounds the whole exchange once the socket is connected.
 nlohmann::json request_control(const std::string& socket_path,
                                const nlohmann::json& request,
                                int timeout_ms = 5000);
@@ -20,7 +20,6 @@ namespace {
 
 constexpr const char* kInterfacesEndpoint = "http://127.0.0.1:79/rci/show/interface";
 constexpr const char* kRciEndpoint = "http://127.0.0.1:79/rci/";
-constexpr auto kCacheTtl = std::chrono::minutes(2);
 
 struct KeeneticInterface {
     std::string id;
@@ -71,8 +70,9 @@ NowFn& now_fn() {
     return value;
 }
 
-bool cache_is_fresh(const CacheState& state, std::chrono::steady_clock::time_point now) {
-    return state.descriptions.has_value() && now - state.fetched_at < kCacheTtl;
+bool cache_is_fresh(const CacheState& state, std::chrono::steady_clock::time_point now,
+                    std::chrono::seconds cache_ttl) {
+    return state.descriptions.has_value() && now - state.fetched_at < cache_ttl;
 }
 
 bool supports_system_name_endpoint(const std::string& version) {
@@ -150,6 +150,11 @@ std::vector<KeeneticInterface> parse_interfaces(const std::string& body) {
     return result;
 }
 
+std::string lowercase(std::string value) {
+    for (auto& c : value) c = static_cast<char>(std::tolower(static_cast<unsigned char>(c)));
+    return value;
+}
+
 bool is_parent_id(const std::string& id) { return id.find('/') == std::string::npos; }
 
 bool should_replace(const KeeneticInterface& existing, const KeeneticInterface& candidate) {
@@ -158,19 +163,36 @@ bool should_replace(const KeeneticInterface& existing, const KeeneticInterface&
     return !candidate.description.empty() && existing.description.empty();
 }
 
-CacheState::DescriptionMappings map_by_system_names(
-    const std::vector<KeeneticInterface>& interfaces, const std::string& response_body) {
+std::vector<std::string> parse_system_names(const std::string& response_body) {
     const auto response = nlohmann::json::parse(response_body);
     const auto show = response.find("show");
     if (show == response.end() || !show->is_object()) throw std::runtime_error("Invalid Keenetic bulk response");
     const auto items = show->find("interface");
     if (items == show->end() || !items->is_array()) throw std::runtime_error("Invalid Keenetic bulk response");
 
+    std::vector<std::string> result;
+    for (const auto& item : *items) {
+        const auto field = item.find("system-name");
+        result.push_back(field != item.end() && field->is_string() ? field->get<std::string>() : "");
+    }
+    return result;
+}
+
+std::vector<std::string> fetch_system_names(const std::vector<KeeneticInterface>& interfaces) {
+    nlohmann::json request = {{"show", {{"interface", nlohmann::json::array()}}}};
+    for (const auto& interface : interfaces) {
+        request["show"]["interface"].push_back(
+            {{"system-name", {{"name", interface.id}}}});
+    }
+    return parse_system_names(fetcher()("POST", kRciEndpoint, request.dump()));
+}
+
+CacheState::DescriptionMappings map_by_system_names(
+    const std::vector<KeeneticInterface>& interfaces, const std::vector<std::string>& system_names) {
     std::map<std::string, KeeneticInterface> selected;
-    for (size_t i = 0; i < interfaces.size() && i < items->size(); ++i) {
-        const auto field = (*items)[i].find("system-name");
-        if (field == (*items)[i].end() || !field->is_string() || interfaces[i].description.empty()) continue;
-        const std::string system_name = field->get<std::string>();
+    for (size_t i = 0; i < interfaces.size() && i < system_names.size(); ++i) {
+        if (interfaces[i].description.empty()) continue;
+        const std::string& system_name = system_names[i];
         if (system_name.empty()) continue;
         const auto existing = selected.find(system_name);
         if (existing == selected.end() || should_replace(existing->second, interfaces[i])) {
@@ -203,12 +225,7 @@ std::optional<CacheState::DescriptionMappings> fetch_descriptions() {
     if (cached_system_info().os_type != "keenetic") return std::nullopt;
     const auto interfaces = parse_interfaces(fetcher()("GET", kInterfacesEndpoint, ""));
     if (supports_system_name_endpoint(cached_system_info().os_version)) {
-        nlohmann::json request = {{"show", {{"interface", nlohmann::json::array()}}}};
-        for (const auto& interface : interfaces) {
-            request["show"]["interface"].push_back(
-                {{"system-name", {{"name", interface.id}}}});
-        }
-        return map_by_system_names(interfaces, fetcher()("POST", kRciEndpoint, request.dump()));
+        return map_by_system_names(interfaces, fetch_system_names(interfaces));
     }
     return map_by_addresses(interfaces);
 }
@@ -224,11 +241,11 @@ std::optional<CacheState::DescriptionMappings> resolve_keenetic_interface_descri
 }
 
 void populate_keenetic_interface_descriptions(
-    api::RuntimeInterfaceInventoryResponse& response) {
+    api::RuntimeInterfaceInventoryResponse& response, std::chrono::seconds cache_ttl) {
     std::lock_guard<std::mutex> lock(cache_mutex());
     CacheState& cache = cache_state();
     const auto now = now_fn()();
-    if (!cache_is_fresh(cache, now)) {
+    if (!cache_is_fresh(cache, now, cache_ttl)) {
         if (const auto descriptions = resolve_keenetic_interface_descriptions()) {
             cache.descriptions = *descriptions;
             cache.fetched_at = now;
@@ -256,6 +273,31 @@ void populate_keenetic_interface_descriptions(
     }
 }
 
+std::vector<KeeneticInterfaceName> resolve_keenetic_interface_name(const std::string& query) {
+    std::lock_guard<std::mutex> lock(cache_mutex());
+    if (cached_system_info().os_type != "keenetic") {
+        throw std::runtime_error("Interface resolution is only available on KeeneticOS");
+    }
+    if (!supports_system_name_endpoint(cached_system_info().os_version)) {
+        throw std::runtime_error("Interface resolution requires KeeneticOS 4.03 or newer");
+    }
+
+    const auto interfaces = parse_interfaces(fetcher()("GET", kInterfacesEndpoint, ""));
+    const auto system_names = fetch_system_names(interfaces);
+    const std::string needle = lowercase(query);
+    std::vector<KeeneticInterfaceName> result;
+    for (size_t i = 0; i < interfaces.size() && i < system_names.size(); ++i) {
+        const auto& interface = interfaces[i];
+        if (lowercase(interface.id) != needle &&
+            (interface.description.empty() || lowercase(interface.description) != needle) &&
+            system_names[i] != query) {
+            continue;
+        }
+        result.push_back({interface.id, interface.description, system_names[i]});
+    }
+    return result;
+}
+
 #ifdef KEEN_PBR3_TESTING
 void set_keenetic_interface_fetcher_for_tests(KeeneticInterfaceFetchFn value) {
     std::lock_guard<std::mutex> lock(cache_mutex());
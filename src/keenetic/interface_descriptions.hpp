@@ -4,15 +4,31 @@
 
 #include "../api/generated/api_types.hpp"
 
+#include <chrono>
+
 namespace keen_pbr3 {
 
 // Resolve Linux interface names to the descriptions configured in KeeneticOS.
 // Returns no value when the host is not Keenetic or RCI could not provide a
 // valid interface snapshot. Successful partial mappings are preserved.
-// Populate entries from the two-minute on-demand cache. A failed refresh
-// leaves a previously successful map available to callers.
+// Populate entries from the on-demand cache, refetched once it is older than
+// cache_ttl (api.keenetic_interface_cache_seconds). A failed refresh leaves a
+// previously successful map available to callers.
 void populate_keenetic_interface_descriptions(
-    api::RuntimeInterfaceInventoryResponse& response);
+    api::RuntimeInterfaceInventoryResponse& response,
+    std::chrono::seconds cache_ttl = std::chrono::minutes(2));
+
+struct KeeneticInterfaceName {
+    std::string id;
+    std::string description;
+    std::string system_name;
+};
+
+// Look up interfaces whose Keenetic id or description matches the query
+// (case-insensitive) or whose Linux system name equals it. Unlike the
+// description cache this always queries RCI. Throws std::runtime_error when
+// the host is not KeeneticOS 4.03+ or RCI returns an invalid response.
+std::vector<KeeneticInterfaceName> resolve_keenetic_interface_name(const std::string& query);
 
 #ifdef KEEN_PBR3_TESTING
 using KeeneticInterfaceFetchFn = std::function<std::string(
@@ -0,0 +1,168 @@
+#include "asn_source.hpp"
+
+#include <algorithm>
+#include <cctype>
+#include <fstream>
+#include <limits>
+#include <set>
+#include <sstream>
+#include <stdexcept>
+#include <string_view>
+#include <vector>
+
+namespace keen_pbr3 {
+
+namespace {
+
+constexpr std::string_view kAsnScheme = "as:";
+
+bool all_digits(std::string_view value) {
+    return !value.empty() &&
+           std::all_of(value.begin(), value.end(),
+                       [](unsigned char ch) { return std::isdigit(ch) != 0; });
+}
+
+std::optional<uint32_t> parse_asn_number(std::string_view value) {
+    if (!all_digits(value) || value.size() > 10) {
+        return std::nullopt;
+    }
+    uint64_t asn = 0;
+    for (const char ch : value) {
+        asn = asn * 10 + static_cast<uint64_t>(ch - '0');
+    }
+    if (asn == 0 || asn > std::numeric_limits<uint32_t>::max()) {
+        return std::nullopt;
+    }
+    return static_cast<uint32_t>(asn);
+}
+
+// Whether an origin field ("13335", "AS13335", "13335_4200", "13335,4200")
+// names `asn`. Returns nullopt when a part is not an AS number.
+std::optional<bool> origin_matches(std::string_view origin, uint32_t asn) {
+    bool matched = false;
+    std::size_t begin = 0;
+    while (begin <= origin.size()) {
+        const std::size_t end = std::min(origin.find_first_of("_,", begin), origin.size());
+        std::string_view part = origin.substr(begin, end - begin);
+        if (part.size() > 2 && (part[0] == 'A' || part[0] == 'a') &&
+            (part[1] == 'S' || part[1] == 's')) {
+            part.remove_prefix(2);
+        }
+        const auto parsed = parse_asn_number(part);
+        if (!parsed.has_value()) {
+            return std::nullopt;
+        }
+        matched = matched || *parsed == asn;
+        begin = end + 1;
+    }
+    return matched;
+}
+
+} // namespace
+
+bool is_asn_list_url(const std::string& url) {
+    return url.rfind(kAsnScheme, 0) == 0;
+}
+
+std::optional<uint32_t> parse_asn_list_url(const std::string& url) {
+    if (!is_asn_list_url(url)) {
+        return std::nullopt;
+    }
+    const std::string_view number = std::string_view(url).substr(kAsnScheme.size());
+    if (number.size() > 1 && number.front() == '0') {
+        return std::nullopt;
+    }
+    return parse_asn_number(number);
+}
+
+std::string expand_asn_url_template(const std::string& url_template, uint32_t asn) {
+    constexpr std::string_view placeholder = "{asn}";
+    const std::string number = std::to_string(asn);
+    std::string url = url_template;
+    for (std::size_t pos = url.find(placeholder); pos != std::string::npos;
+         pos = url.find(placeholder, pos + number.size())) {
+        url.replace(pos, placeholder.size(), number);
+    }
+    return url;
+}
+
+std::string asn_prefixes_from_dump(std::istream& dump, uint32_t asn) {
+    std::string prefixes;
+    std::set<std::string> seen;
+    std::string line;
+    std::size_t line_number = 0;
+    while (std::getline(dump, line)) {
+        ++line_number;
+        const auto comment = line.find('#');
+        if (comment != std::string::npos) {
+            line.erase(comment);
+        }
+
+        std::istringstream fields_input(line);
+        std::vector<std::string> fields;
+        for (std::string field; fields_input >> field;) {
+            fields.push_back(std::move(field));
+        }
+        if (fields.empty()) {
+            continue;
+        }
+
+        std::string prefix;
+        if (fields.size() == 2 && fields[0].find('/') != std::string::npos) {
+            prefix = fields[0];
+        } else if (fields.size() == 3 && all_digits(fields[1])) {
+            prefix = fields[0] + "/" + fields[1];
+        }
+        const auto matches = prefix.empty() ? std::nullopt : origin_matches(fields.back(), asn);
+        if (!matches.has_value()) {
+            throw std::runtime_error("malformed prefix-to-AS line " + std::to_string(line_number) +
+                                     ": expected \"<prefix>/<len> <origin>\" or "
+                                     "\"<address> <len> <origin>\"");
+        }
+        if (*matches && seen.insert(prefix).second) {
+            prefixes += prefix;
+            prefixes += '\n';
+        }
+    }
+    return prefixes;
+}
+
+std::string read_asn_dump_prefixes(const std::filesystem::path& dump_file,
+                                   const std::string& url) {
+    const auto asn = parse_asn_list_url(url);
+    if (!asn.has_value()) {
+        throw std::runtime_error("'" + url + "' is not an as:<ASN> list source");
+    }
+    std::ifstream dump(dump_file);
+    if (!dump) {
+        throw std::runtime_error("cannot read prefix-to-AS dump " + dump_file.string());
+    }
+    std::string prefixes;
+    try {
+        prefixes = asn_prefixes_from_dump(dump, *asn);
+    } catch (const std::runtime_error& e) {
+        throw std::runtime_error(dump_file.string() + ": " + e.what());
+    }
+    if (prefixes.empty()) {
+        throw std::runtime_error("no prefixes for AS" + std::to_string(*asn) + " in " +
+                                 dump_file.string());
+    }
+    return prefixes;
+}
+
+CacheDownloadResult cache_asn_dump_prefixes(CacheManager& cache,
+                                            const std::string& name,
+                                            const std::string& url,
+                                            const std::filesystem::path& dump_file) {
+    std::string prefixes;
+    try {
+        prefixes = read_asn_dump_prefixes(dump_file, url);
+    } catch (const std::runtime_error& e) {
+        CacheDownloadResult failed;
+        failed.error_message = e.what();
+        return failed;
+    }
+    return cache.store(name, url, prefixes);
+}
+
+} // namespace keen_pbr3
@@ -0,0 +1,49 @@
+#pragma once
+
+#include "../cache/cache_manager.hpp"
+
+#include <cstdint>
+#include <filesystem>
+#include <istream>
+#include <optional>
+#include <string>
+
+namespace keen_pbr3 {
+
+// A list url "as:<ASN>" stands for every prefix originated by an autonomous
+// system. It is resolved through the top-level asn_source: url_template is
+// downloaded like any list URL, file is a local BGP prefix-to-AS dump. Either
+// way the prefixes land in the list cache and go through the normal CIDR path.
+
+// Whether `url` uses the as: scheme, valid or not.
+bool is_asn_list_url(const std::string& url);
+
+// AS number of an "as:<ASN>" url: decimal 1..4294967295 without a sign or
+// leading zeros. Returns nullopt for anything else.
+std::optional<uint32_t> parse_asn_list_url(const std::string& url);
+
+// asn_source.url_template with every "{asn}" replaced by the decimal ASN.
+std::string expand_asn_url_template(const std::string& url_template, uint32_t asn);
+
+// Prefixes originated by `asn` in a prefix-to-AS dump, one per line in dump
+// order without duplicates. A line is "<prefix>/<len> <origin>" or the CAIDA
+// pfx2as "<address> <len> <origin>"; origin is an ASN, optionally prefixed
+// with "AS", or several joined by '_' (multi-origin) or ',' (AS set). Blank
+// lines and '#' comments are skipped. Throws std::runtime_error naming the
+// line for anything else.
+std::string asn_prefixes_from_dump(std::istream& dump, uint32_t asn);
+
+// asn_prefixes_from_dump() over the as: url's ASN in `dump_file`. Throws
+// std::runtime_error when the file cannot be read, is malformed, or has no
+// prefixes for the ASN.
+std::string read_asn_dump_prefixes(const std::filesystem::path& dump_file,
+                                   const std::string& url);
+
+// Cache the read_asn_dump_prefixes() result as list `name`, keeping the
+// previous copy when the dump cannot be used.
+CacheDownloadResult cache_asn_dump_prefixes(CacheManager& cache,
+                                            const std::string& name,
+                                            const std::string& url,
+                                            const std::filesystem::path& dump_file);
+
+} // namespace keen_pbr3
@@ -0,0 +1,146 @@
+#include "kernel_set_writer.hpp"
+
+#include "../config/list_parser.hpp"
+#include "../firewall/ipset_restore_pipe.hpp"
+#include "../firewall/nft_batch_pipe.hpp"
+#include "../log/logger.hpp"
+#include "../util/format_compat.hpp"
+#include "../util/safe_exec.hpp"
+
+#include <nlohmann/json.hpp>
+
+#include <sstream>
+
+namespace keen_pbr3 {
+
+namespace {
+
+constexpr const char* kNftTableName = "KeenPbrTable";
+
+EntryType entry_type(const std::string& entry) {
+    return entry.find('/') == std::string::npos ? EntryType::Ip : EntryType::Cidr;
+}
+
+class IpsetSetWriter : public KernelSetWriter {
+public:
+    bool exists(const std::string& set_name) const override {
+        return safe_exec({"ipset", "list", "-n", set_name}, /*suppress_output=*/true) == 0;
+    }
+
+    void add(const std::string& set_name, const std::vector<std::string>& entries) override {
+        std::ostringstream script;
+        IpsetRestoreVisitor visitor(script, set_name);
+        for (const auto& entry : entries) {
+            visitor.on_entry(entry_type(entry), entry);
+        }
+        Logger::instance().verbose("ipset script:\n{}", script.str());
+        const int status = safe_exec_pipe_stdin({"ipset", "restore", "-exist"}, script.str());
+        if (status != 0) {
+            throw FirewallError(keen_pbr3::format("ipset exited with status {}", status));
+        }
+    }
+};
+
+class NftSetWriter : public KernelSetWriter {
+public:
+    bool exists(const std::string& set_name) const override {
+        return safe_exec({"nft", "list", "set", "inet", kNftTableName, set_name},
+                         /*suppress_output=*/true) == 0;
+    }
+
+    void add(const std::string& set_name, const std::vector<std::string>& entries) override {
+        nlohmann::json elements = nlohmann::json::array();
+        NftBatchVisitor visitor(elements, set_name);
+        for (const auto& entry : entries) {
+            visitor.on_entry(entry_type(entry), entry);
+        }
+        const nlohmann::json batch = {{"nftables", nlohmann::json::array({
+            {{"add", {{"element", {
+                {"family", "inet"},
+                {"table", kNftTableName},
+                {"name", set_name},
+                {"elem", elements},
+            }}}}},
+        })}};
+        const std::string json_str = batch.dump();
+        Logger::instance().verbose("nft batch:\n{}", json_str);
+        const int status = safe_exec_pipe_stdin({"nft", "-j", "-f", "-"}, json_str);
+        if (status != 0) {
+            throw FirewallError(keen_pbr3::format("nft exited with status {}", status));
+        }
+    }
+};
+
+// Address family implied by the daemon's set naming, or 0 for other sets.
+int set_family(const std::string& set_name) {
+    if (set_name.rfind("kpbr4", 0) == 0) return AF_INET;
+    if (set_name.rfind("kpbr6", 0) == 0) return AF_INET6;
+    return 0;
+}
+
+} // namespace
+
+std::unique_ptr<KernelSetWriter> make_kernel_set_writer(FirewallBackend backend) {
+    if (backend == FirewallBackend::nftables) {
+        return std::make_unique<NftSetWriter>();
+    }
+    return std::make_unique<IpsetSetWriter>();
+}
+
+SetEntryImportResult import_set_entries(std::istream& input,
+                                        const std::string& set_name,
+                                        KernelSetWriter& writer) {
+    if (!writer.exists(set_name)) {
+        throw FirewallError("Set '" + set_name + "' does not exist");
+    }
+
+    SetEntryImportResult result;
+    const int family = set_family(set_name);
+    std::vector<std::string> entries;
+    FunctionalVisitor visitor([&](EntryType type, std::string_view entry) {
+        const bool is_v6 = entry.find(':') != std::string_view::npos;
+        if (type == EntryType::Domain ||
+            (family == AF_INET && is_v6) || (family == AF_INET6 && !is_v6)) {
+            ++result.skipped;
+            return;
+        }
+        entries.emplace_back(entry);
+    });
+
+    ListParser::ParseContext context;
+    std::string line;
+    std::size_t line_number = 0;
+    while (std::getline(input, line)) {
+        ++line_number;
+        ListParser::parse_line(line, visitor, "stdin", line_number, &context);
+    }
+    result.invalid = context.invalid_entry_count;
+
+    if (entries.empty()) {
+        return result;
+    }
+    try {
+        writer.add(set_name, entries);
+        result.added = entries.size();
+        return result;
+    } catch (const FirewallError& e) {
+        Logger::instance().debug("Batch add of {} entries to '{}' failed ({}), "
+                                 "retrying one by one",
+                                 entries.size(),
+                                 set_name,
+                                 e.what());
+    }
+
+    for (const auto& entry : entries) {
+        try {
+            writer.add(set_name, {entry});
+            ++result.added;
+        } catch (const FirewallError& e) {
+            ++result.failed;
+            Logger::instance().debug("Failed to add '{}' to '{}': {}", entry, set_name, e.what());
+        }
+    }
+    return result;
+}
+
+} // namespace keen_pbr3
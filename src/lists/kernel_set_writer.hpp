@@ -0,0 +1,47 @@
+#pragma once
+
+#include "../firewall/firewall.hpp"
+
+#include <cstddef>
+#include <istream>
+#include <memory>
+#include <string>
+#include <vector>
+
+namespace keen_pbr3 {
+
+// Adds entries to an already existing kernel set outside of a firewall
+// apply. Entries added this way are not tracked by the daemon and disappear
+// on the next full apply of the set.
+class KernelSetWriter {
+public:
+    virtual ~KernelSetWriter() = default;
+
+    virtual bool exists(const std::string& set_name) const = 0;
+
+    // Add IP/CIDR entries; entries already in the set are kept.
+    // Throws FirewallError when the backend command fails.
+    virtual void add(const std::string& set_name,
+                     const std::vector<std::string>& entries) = 0;
+};
+
+std::unique_ptr<KernelSetWriter> make_kernel_set_writer(FirewallBackend backend);
+
+struct SetEntryImportResult {
+    std::size_t added{0};
+    // Domains, and addresses of the wrong family for a kpbr4*/kpbr6* set.
+    std::size_t skipped{0};
+    std::size_t invalid{0};
+    // Entries the backend refused, e.g. because the set is full.
+    std::size_t failed{0};
+};
+
+// Parse one entry per line from `input` with the list parser and add every
+// IP/CIDR to `set_name`. All entries are sent in one batch; when the backend
+// rejects it, they are retried one by one so a single bad entry does not
+// discard the rest. Throws FirewallError when the set does not exist.
+SetEntryImportResult import_set_entries(std::istream& input,
+                                        const std::string& set_name,
+                                        KernelSetWriter& writer);
+
+} // namespace keen_pbr3
@@ -0,0 +1,95 @@
+#include "list_bench.hpp"
+
+#include "../config/list_parser.hpp"
+#include "../util/format_compat.hpp"
+#include "list_download.hpp"
+#include "list_entry_visitor.hpp"
+
+#include <sstream>
+#include <stdexcept>
+
+namespace keen_pbr3 {
+
+namespace {
+
+std::chrono::microseconds elapsed_between(std::chrono::steady_clock::time_point start,
+                                          std::chrono::steady_clock::time_point end) {
+    return std::chrono::duration_cast<std::chrono::microseconds>(end - start);
+}
+
+double to_milliseconds(std::chrono::microseconds value) {
+    return static_cast<double>(value.count()) / 1000.0;
+}
+
+} // namespace
+
+double ListBenchResult::megabytes_per_second() const {
+    const auto total = download_time + parse_time;
+    if (total.count() <= 0) {
+        return 0.0;
+    }
+    // bytes per microsecond is numerically equal to MB per second.
+    return static_cast<double>(bytes) / static_cast<double>(total.count());
+}
+
+ListBenchResult bench_list_download(const Config& config,
+                                    const std::string& list_name,
+                                    HttpClient& http_client,
+                                    const ListBenchClock& clock,
+                                    const BootstrapResolver& resolver) {
+    const auto& lists = config.lists.value_or(std::map<std::string, ListConfig>{});
+    const auto it = lists.find(list_name);
+    if (it == lists.end()) {
+        throw std::runtime_error("List '" + list_name + "' is not defined in the config");
+    }
+    const ListConfig& list_cfg = it->second;
+    if (!list_cfg.url.has_value()) {
+        throw std::runtime_error("List '" + list_name + "' is not URL-backed");
+    }
+
+    const auto options = list_download_options(
+        config,
+        list_name,
+        list_cfg,
+        allocate_outbound_marks(config.fwmark.value_or(FwmarkConfig{}),
+                                config.outbounds.value_or(std::vector<Outbound>{})));
+
+    ListBenchResult result;
+    result.list_name = list_name;
+    result.url = list_download_urls(config, list_cfg).front();
+
+    const auto download_start = clock();
+    const std::string body = download_list_body(config, http_client, result.url, options, resolver);
+    const auto download_end = clock();
+    result.bytes = body.size();
+    result.download_time = elapsed_between(download_start, download_end);
+
+    EntryCounter counter;
+    std::istringstream input(body);
+    const auto parse_start = clock();
+    ListParser::stream_parse(input, counter, "list '" + list_name + "'");
+    const auto parse_end = clock();
+    result.parse_time = elapsed_between(parse_start, parse_end);
+
+    result.ips = counter.ips();
+    result.cidrs = counter.cidrs();
+    result.domains = counter.domains();
+    return result;
+}
+
+std::string format_list_bench_result(const ListBenchResult& result) {
+    std::string out;
+    out += format("List:       {}\n", result.list_name);
+    out += format("URL:        {}\n", result.url);
+    out += format("Download:   {} bytes in {:.1f} ms\n", result.bytes, to_milliseconds(result.download_time));
+    out += format("Parse:      {} entries in {:.1f} ms (domains {}, ips {}, cidrs {})\n",
+                  result.entries(),
+                  to_milliseconds(result.parse_time),
+                  result.domains,
+                  result.ips,
+                  result.cidrs);
+    out += format("Throughput: {:.2f} MB/s\n", result.megabytes_per_second());
+    return out;
+}
+
+} // namespace keen_pbr3
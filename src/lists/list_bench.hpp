@@ -0,0 +1,47 @@
+#pragma once
+
+#include "../cache/cache_manager.hpp"
+#include "../config/config.hpp"
+#include "../http/http_client.hpp"
+
+#include <chrono>
+#include <cstddef>
+#include <functional>
+#include <string>
+
+namespace keen_pbr3 {
+
+struct ListBenchResult {
+    std::string list_name;
+    std::string url;
+    std::size_t bytes{0};
+    std::chrono::microseconds download_time{0};
+    std::chrono::microseconds parse_time{0};
+    std::size_t ips{0};
+    std::size_t cidrs{0};
+    std::size_t domains{0};
+
+    std::size_t entries() const { return ips + cidrs + domains; }
+
+    // Bytes per second over download and parse combined, in MB (10^6 bytes).
+    // Returns 0 when no time elapsed.
+    double megabytes_per_second() const;
+};
+
+using ListBenchClock = std::function<std::chrono::steady_clock::time_point()>;
+
+// Download a configured URL list and parse it with the regular list parser,
+// without touching the cache or any kernel set. The download uses
+// list_download_options() the same way as list refresh. Throws
+// std::runtime_error when the list does not exist or is not URL-backed, or
+// the body is rejected, and HttpError when the download fails.
+ListBenchResult bench_list_download(const Config& config,
+                                    const std::string& list_name,
+                                    HttpClient& http_client,
+                                    const ListBenchClock& clock = std::chrono::steady_clock::now,
+                                    const BootstrapResolver& resolver = resolve_via_bootstrap_dns);
+
+// Human-readable multi-line report for the CLI.
+std::string format_list_bench_result(const ListBenchResult& result);
+
+} // namespace keen_pbr3
@@ -0,0 +1,140 @@
+#include "list_diff.hpp"
+
+#include "../config/list_parser.hpp"
+#include "../log/logger.hpp"
+#include "../util/format_compat.hpp"
+#include "list_download.hpp"
+#include "list_entry_visitor.hpp"
+
+#include <algorithm>
+#include <fstream>
+#include <iterator>
+#include <set>
+#include <sstream>
+#include <stdexcept>
+
+namespace keen_pbr3 {
+
+namespace {
+
+std::set<std::string> collect_entries(std::istream& input, const std::string& source) {
+    std::set<std::string> entries;
+    FunctionalVisitor visitor([&entries](EntryType, std::string_view entry) {
+        entries.emplace(entry);
+    });
+    ListParser::stream_parse(input, visitor, source);
+    return entries;
+}
+
+} // namespace
+
+ListDiffResult diff_list_entries(std::istream& cached, std::istream& fresh) {
+    const auto old_entries = collect_entries(cached, "cached list");
+    const auto new_entries = collect_entries(fresh, "downloaded list");
+
+    ListDiffResult result;
+    std::set_difference(new_entries.begin(), new_entries.end(),
+                        old_entries.begin(), old_entries.end(),
+                        std::back_inserter(result.added));
+    std::set_difference(old_entries.begin(), old_entries.end(),
+                        new_entries.begin(), new_entries.end(),
+                        std::back_inserter(result.removed));
+    result.unchanged = new_entries.size() - result.added.size();
+    return result;
+}
+
+ListDiffResult diff_cached_list(const Config& config,
+                                const std::string& list_name,
+                                const CacheManager& cache,
+                                HttpClient& http_client,
+                                const BootstrapResolver& resolver) {
+    const auto& lists = config.lists.value_or(std::map<std::string, ListConfig>{});
+    const auto it = lists.find(list_name);
+    if (it == lists.end()) {
+        throw std::runtime_error("List '" + list_name + "' is not defined in the config");
+    }
+    const ListConfig& list_cfg = it->second;
+    if (!list_cfg.url.has_value()) {
+        throw std::runtime_error("List '" + list_name + "' is not URL-backed");
+    }
+
+    const auto options = list_download_options(
+        config,
+        list_name,
+        list_cfg,
+        allocate_outbound_marks(config.fwmark.value_or(FwmarkConfig{}),
+                                config.outbounds.value_or(std::vector<Outbound>{})));
+
+    // Same mirror order and checks as list refresh; the first accepted
+    // download wins.
+    const auto urls = list_download_urls(config, list_cfg);
+    std::string body;
+    std::string used_url;
+    for (std::size_t i = 0; i < urls.size(); ++i) {
+        try {
+            body = download_list_body(config, http_client, urls[i], options, resolver);
+            used_url = urls[i];
+            break;
+        } catch (const std::runtime_error& error) {
+            if (i + 1 == urls.size()) {
+                throw;
+            }
+            Logger::instance().warn("List '{}': failed to download {}: {}",
+                                    list_name,
+                                    urls[i],
+                                    error.what());
+        }
+    }
+    std::istringstream fresh(body);
+
+    const bool cached = cache.has_cache(list_name);
+    std::ifstream cached_file;
+    std::istringstream empty;
+    if (cached) {
+        cached_file.open(cache.cache_path(list_name));
+        if (!cached_file) {
+            throw std::runtime_error("Cannot read cached copy of list '" + list_name + "'");
+        }
+    }
+
+    ListDiffResult result = diff_list_entries(cached ? static_cast<std::istream&>(cached_file)
+                                                     : static_cast<std::istream&>(empty),
+                                              fresh);
+    result.list_name = list_name;
+    result.url = used_url;
+    result.cached = cached;
+    return result;
+}
+
+std::string format_list_diff_result(const ListDiffResult& result) {
+    std::string out;
+    out += format("List: {}\n", result.list_name);
+    out += format("URL:  {}\n", result.url);
+    if (!result.cached) {
+        out += "No cached copy; showing all downloaded entries as added\n";
+    }
+    for (const auto& entry : result.added) {
+        out += format("+ {}\n", entry);
+    }
+    for (const auto& entry : result.removed) {
+        out += format("- {}\n", entry);
+    }
+    out += format("{} added, {} removed, {} unchanged\n",
+                  result.added.size(),
+                  result.removed.size(),
+                  result.unchanged);
+    return out;
+}
+
+nlohmann::json list_diff_result_to_json(const ListDiffResult& result) {
+    return {
+        {"list", result.list_name},
+        {"url", result.url},
+        {"cached", result.cached},
+        {"added", result.added},
+        {"removed", result.removed},
+        {"unchanged", result.unchanged},
+    };
+}
+
+} // namespace keen_pbr3
@@ -0,0 +1,47 @@
+#pragma once
+
+#include "../cache/cache_manager.hpp"
+#include "../config/config.hpp"
+#include "../http/http_client.hpp"
+
+#include <nlohmann/json.hpp>
+
+#include <cstddef>
+#include <istream>
+#include <string>
+#include <vector>
+
+namespace keen_pbr3 {
+
+struct ListDiffResult {
+    std::string list_name;
+    std::string url;
+    // False when nothing was cached yet; every fetched entry is then "added".
+    bool cached{false};
+    std::vector<std::string> added;   // Sorted, in fresh but not in cached
+    std::vector<std::string> removed; // Sorted, in cached but not in fresh
+    std::size_t unchanged{0};
+};
+
+// Parse both inputs with the regular list parser and compare the resulting
+// entry sets. Comments, blank lines, duplicates and entry order are ignored.
+ListDiffResult diff_list_entries(std::istream& cached, std::istream& fresh);
+
+// Compare the cached copy of a URL-backed list against a freshly downloaded
+// one. The cache is left untouched. The download uses list_download_options()
+// and fallback_urls the same way as list refresh; url is the one that was used.
+// Throws std::runtime_error when the list does not exist or is not URL-backed,
+// or with the last URL's error (HttpError for a failed request) when every URL
+// fails.
+ListDiffResult diff_cached_list(const Config& config,
+                                const std::string& list_name,
+                                const CacheManager& cache,
+                                HttpClient& http_client,
+                                const BootstrapResolver& resolver = resolve_via_bootstrap_dns);
+
+// Human-readable report for the CLI: "+ entry" / "- entry" lines and a summary.
+std::string format_list_diff_result(const ListDiffResult& result);
+
+nlohmann::json list_diff_result_to_json(const ListDiffResult& result);
+
+} // namespace keen_pbr3
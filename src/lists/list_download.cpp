@@ -0,0 +1,66 @@
+#include "list_download.hpp"
+
+#include "../log/logger.hpp"
+#include "asn_source.hpp"
+
+#include <stdexcept>
+
+namespace keen_pbr3 {
+
+CacheDownloadOptions list_download_options(const Config& config,
+                                           const std::string& list_name,
+                                           const ListConfig& list_cfg,
+                                           const OutboundMarkMap& outbound_marks) {
+    CacheDownloadOptions options;
+    options.expected_sha256 = list_cfg.sha256;
+    options.bootstrap_dns = config.daemon.has_value() ? config.daemon->bootstrap_dns : std::nullopt;
+    options.validate_content = list_cfg.validate_content.value_or(false);
+    if (!list_cfg.detour.has_value()) {
+        return options;
+    }
+    const auto it = outbound_marks.find(internal_detour_mark_key(*list_cfg.detour));
+    if (it != outbound_marks.end()) {
+        options.fwmark = it->second;
+    } else {
+        Logger::instance().warn("List '{}': detour outbound '{}' not found, "
+                                "using default routing",
+                                list_name,
+                                *list_cfg.detour);
+    }
+    return options;
+}
+
+std::vector<std::string> list_download_urls(const Config& config, const ListConfig& list_cfg) {
+    std::vector<std::string> urls;
+    if (list_cfg.url.has_value()) {
+        const auto asn = parse_asn_list_url(*list_cfg.url);
+        const auto url_template =
+            config.asn_source.has_value() ? config.asn_source->url_template : std::nullopt;
+        urls.push_back(asn.has_value() && url_template.has_value()
+                           ? expand_asn_url_template(*url_template, *asn)
+                           : *list_cfg.url);
+    }
+    if (list_cfg.fallback_urls.has_value()) {
+        urls.insert(urls.end(), list_cfg.fallback_urls->begin(), list_cfg.fallback_urls->end());
+    }
+    return urls;
+}
+
+std::string download_list_body(const Config& config,
+                               HttpClient& http_client,
+                               const std::string& url,
+                               const CacheDownloadOptions& options,
+                               const BootstrapResolver& resolver) {
+    if (is_asn_list_url(url)) {
+        const auto& asn_source = config.asn_source.value_or(AsnSourceConfig{});
+        return read_asn_dump_prefixes(asn_source.file.value_or(""), url);
+    }
+    auto result = http_client.download_conditional(
+        url, "", "", cache_request_options(url, options, resolver));
+    if (const auto reason = rejected_download(options, result.content_type, result.body)) {
+        throw std::runtime_error(*reason);
+    }
+    return std::move(result.body);
+}
+
+} // namespace keen_pbr3
@@ -0,0 +1,36 @@
+#pragma once
+
+#include "../cache/cache_manager.hpp"
+#include "../config/config.hpp"
+#include "../http/http_client.hpp"
+
+#include <string>
+#include <vector>
+
+namespace keen_pbr3 {
+
+// Download options for a URL-backed list, shared by list refresh and the
+// lists diff/bench and download --validate-only commands: the detour fwmark,
+// daemon.bootstrap_dns, and the list's sha256 and validate_content checks.
+// An unknown detour is logged and the download uses default routing.
+CacheDownloadOptions list_download_options(const Config& config,
+                                           const std::string& list_name,
+                                           const ListConfig& list_cfg,
+                                           const OutboundMarkMap& outbound_marks);
+
+// The list url followed by its fallback_urls: mirrors are tried in this order
+// and the first successful download wins. An "as:<ASN>" url is expanded
+// through asn_source.url_template; with asn_source.file it is returned as is.
+std::vector<std::string> list_download_urls(const Config& config, const ListConfig& list_cfg);
+
+// Download `url` with `options` without touching the cache; an "as:<ASN>" url
+// is read from the asn_source.file dump instead. Throws HttpError when the
+// request fails and std::runtime_error when the bootstrap lookup fails, the
+// dump cannot be used, or the body is rejected by the content or SHA-256 check.
+std::string download_list_body(const Config& config,
+                               HttpClient& http_client,
+                               const std::string& url,
+                               const CacheDownloadOptions& options,
+                               const BootstrapResolver& resolver = resolve_via_bootstrap_dns);
+
+} // namespace keen_pbr3
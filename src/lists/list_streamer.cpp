@@ -35,6 +35,12 @@ void ListStreamer::stream_all_sources(const std::string& name,
                                       const ListConfig& config,
                                       ListEntryVisitor& visitor,
                                       bool include_cache) {
+    // A disabled list contributes nothing, but consumers still see it complete.
+    if (!list_enabled(config)) {
+        visitor.on_list_complete(name);
+        return;
+    }
+
     // 1. Cached URL file
     if (include_cache) {
         stream_file(cache_.cache_path(name), visitor, /*log_invalid_entries=*/false);
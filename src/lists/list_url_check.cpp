@@ -0,0 +1,66 @@
+#include "list_url_check.hpp"
+
+#include "../util/format_compat.hpp"
+#include "asn_source.hpp"
+#include "list_download.hpp"
+
+namespace keen_pbr3 {
+
+std::vector<ListUrlCheck> check_list_urls(const Config& config,
+                                          HttpClient& http_client,
+                                          const BootstrapResolver& resolver) {
+    std::vector<ListUrlCheck> checks;
+    if (!config.lists.has_value()) {
+        return checks;
+    }
+    const auto marks = allocate_outbound_marks(config.fwmark.value_or(FwmarkConfig{}),
+                                               config.outbounds.value_or(std::vector<Outbound>{}));
+
+    for (const auto& [list_name, list_cfg] : *config.lists) {
+        if (!list_enabled(list_cfg) || !list_cfg.url.has_value()) {
+            continue;
+        }
+
+        const auto options = list_download_options(config, list_name, list_cfg, marks);
+        for (const auto& url : list_download_urls(config, list_cfg)) {
+            if (is_asn_list_url(url)) {
+                continue;
+            }
+            ListUrlCheck check;
+            check.list_name = list_name;
+            check.url = url;
+            try {
+                const auto probe =
+                    http_client.probe(url, cache_request_options(url, options, resolver));
+                check.reachable = true;
+                check.status_code = probe.status_code;
+                check.size = probe.content_length;
+                check.content_type = probe.content_type;
+            } catch (const std::runtime_error& error) {
+                check.error = error.what();
+            }
+            checks.push_back(std::move(check));
+        }
+    }
+    return checks;
+}
+
+std::string format_list_url_checks(const std::vector<ListUrlCheck>& checks) {
+    std::string out;
+    for (const auto& check : checks) {
+        if (!check.reachable) {
+            out += format("FAIL {} {}: {}\n", check.list_name, check.url, check.error);
+            continue;
+        }
+        out += format("{} {} {}: HTTP {}, size {}, type {}\n",
+                      check.ok() ? "OK  " : "FAIL",
+                      check.list_name,
+                      check.url,
+                      check.status_code,
+                      check.size.has_value() ? std::to_string(*check.size) : std::string("unknown"),
+                      check.content_type.empty() ? std::string("unknown") : check.content_type);
+    }
+    return out;
+}
+
+} // namespace keen_pbr3
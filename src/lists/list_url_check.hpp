@@ -0,0 +1,40 @@
+#pragma once
+
+#include "../cache/cache_manager.hpp"
+#include "../config/config.hpp"
+#include "../http/http_client.hpp"
+
+#include <cstdint>
+#include <optional>
+#include <string>
+#include <vector>
+
+namespace keen_pbr3 {
+
+struct ListUrlCheck {
+    std::string list_name;
+    std::string url;
+    bool reachable{false};
+    long status_code{0};
+    std::optional<uint64_t> size;
+    std::string content_type;
+    std::string error;
+
+    // Reachable and answered with a 2xx status.
+    bool ok() const { return reachable && status_code >= 200 && status_code < 300; }
+};
+
+// Probe the url and fallback_urls of every enabled URL-backed list without
+// downloading the list bodies. Detour outbounds and daemon.bootstrap_dns are
+// honored the same way as by list refresh; a failed bootstrap lookup is
+// reported as the URL's error. An as:<ASN> list is probed at its expanded
+// asn_source.url_template; one read from asn_source.file has no URL and is
+// skipped. Checks are returned in list-name order, primary URL first.
+std::vector<ListUrlCheck> check_list_urls(const Config& config,
+                                          HttpClient& http_client,
+                                          const BootstrapResolver& resolver = resolve_via_bootstrap_dns);
+
+// One line per URL for the CLI.
+std::string format_list_url_checks(const std::vector<ListUrlCheck>& checks);
+
+} // namespace keen_pbr3
@@ -114,6 +114,37 @@ void Logger::warn(std::string_view msg) {
         );
 }
 
+void Logger::warn_throttled(std::string_view key,
+                            std::chrono::steady_clock::duration interval,
+                            std::string_view msg) {
+    if (!is_enabled(LogLevel::warn)) {
+        return;
+    }
+
+    const auto now = std::chrono::steady_clock::now();
+    std::size_t suppressed = 0;
+    {
+        std::lock_guard<std::mutex> lock(throttle_mutex_);
+        auto it = throttled_.find(key);
+        if (it == throttled_.end()) {
+            throttled_.emplace(std::string(key), ThrottleState{now, 0});
+        } else if (now - it->second.last_logged < interval) {
+            ++it->second.suppressed;
+            return;
+        } else {
+            suppressed = it->second.suppressed;
+            it->second = ThrottleState{now, 0};
+        }
+    }
+
+    if (suppressed == 0) {
+        warn(msg);
+        return;
+    }
+    warn(keen_pbr3::format("{} ({} similar message{} suppressed)",
+                           msg, suppressed, suppressed == 1 ? "" : "s"));
+}
+
 void Logger::info(std::string_view msg) {
     if (is_enabled(LogLevel::info))
         emit_line(std::string(msg),
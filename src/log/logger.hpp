@@ -2,6 +2,7 @@
 
 #include <chrono>
 #include <functional>
+#include <map>
 #include <mutex>
 #include <string>
 #include <string_view>
@@ -34,6 +35,13 @@ public:
     void debug(std::string_view msg);
     void trace(std::string_view event, std::string_view details = {});
 
+    // Warn at most once per `interval` for `key`. Messages in between are
+    // counted, and the next message logged for the key reports how many
+    // were suppressed.
+    void warn_throttled(std::string_view key,
+                        std::chrono::steady_clock::duration interval,
+                        std::string_view msg);
+
     template<typename... Args>
     void error(format_string<Args...> fmt, Args&&... args) {
         if (is_enabled(LogLevel::error))
@@ -46,6 +54,16 @@ public:
             warn(std::string_view(keen_pbr3::format(fmt, std::forward<Args>(args)...)));
     }
 
+    template<typename... Args>
+    void warn_throttled(std::string_view key,
+                        std::chrono::steady_clock::duration interval,
+                        format_string<Args...> fmt,
+                        Args&&... args) {
+        if (is_enabled(LogLevel::warn))
+            warn_throttled(key, interval,
+                           std::string_view(keen_pbr3::format(fmt, std::forward<Args>(args)...)));
+    }
+
     template<typename... Args>
     void info(format_string<Args...> fmt, Args&&... args) {
         if (is_enabled(LogLevel::info))
@@ -75,9 +93,16 @@ private:
 
     void emit_line(const std::string& line, int syslog_priority);
 
+    struct ThrottleState {
+        std::chrono::steady_clock::time_point last_logged;
+        std::size_t suppressed{0};
+    };
+
     LogLevel level_{LogLevel::info};
     std::mutex sink_mutex_;
     Sink sink_;
+    std::mutex throttle_mutex_;
+    std::map<std::string, ThrottleState, std::less<>> throttled_;
     std::chrono::steady_clock::time_point started_at_{std::chrono::steady_clock::now()};
 };
 
@@ -1,9 +1,8 @@
+#include <algorithm>
 #include <cstdlib>
 #include <cstring>
 #include <ctime>
-#include <fstream>
 #include <iostream>
-#include <sstream>
 #include <string>
 #include <string_view>
 
@@ -13,18 +12,27 @@
 
 #include <keen-pbr/version.hpp>
 
+#include "cmd/cli_options.hpp"
+#include "cmd/config_explain.hpp"
+#include "cmd/version.hpp"
 #include "config/config.hpp"
+#include "config/effective_config.hpp"
 #include "crash/crash_diagnostics.hpp"
 #include "daemon/daemon.hpp"
+#include "health/routing_health_checker.hpp"
 #include "http/curl_runtime.hpp"
+#include "keenetic/interface_descriptions.hpp"
 #include "ipc/control_client.hpp"
 #include "ipc/resolver_fallback.hpp"
+#include "lists/kernel_set_writer.hpp"
+#include "lists/list_bench.hpp"
+#include "lists/list_diff.hpp"
+#include "lists/list_streamer.hpp"
+#include "lists/list_url_check.hpp"
 #include "log/logger.hpp"
 #include "util/daemon_signals.hpp"
+#include "util/firewall_backend_utils.hpp"
 
-#ifndef KEEN_PBR_DEFAULT_CONFIG_PATH
-#define KEEN_PBR_DEFAULT_CONFIG_PATH "/etc/keen-pbr/config.json"
-#endif
 #ifndef KEEN_PBR_TARGET_OS
 #define KEEN_PBR_TARGET_OS "linux"
 #endif
@@ -49,147 +57,6 @@
 
 namespace {
 
-struct CliOptions {
-  std::string config_path{KEEN_PBR_DEFAULT_CONFIG_PATH};
-  std::string log_level{"info"};
-  std::string pid_file_override;
-  std::string crash_report_path{"/tmp/keen-pbr-crash.log"};
-  bool no_api{false};
-  bool use_raw_prerouting{false};
-  bool has_pid_file_override{false};
-  bool run_service{false};
-  bool generate_resolver_config{false};
-  std::string resolver_type;
-  bool download_lists{false};
-  bool download_reload{false};
-  bool resolver_config_hash{false};
-  bool run_status{false};
-  bool run_test_routing{false};
-  std::string test_routing_target;
-  bool show_help{false};
-  bool show_version{false};
-};
-
-void print_usage(const char *argv0) {
-  std::cerr << "Usage: " << argv0 << " [options] <command>\n"
-            << "\n"
-            << "Options:\n"
-            << "  --config <path>    Path to JSON config file (default: "
-            << KEEN_PBR_DEFAULT_CONFIG_PATH << ")\n"
-            << "  --log-level <lvl>  Log level: error, warn, info, verbose, "
-               "debug (default: info)\n"
-            << "  --pid-file <path>  Override daemon.pid_file when running the "
-               "service command\n"
-            << "  --crash-report <path>  Last-crash report path (default: "
-               "/tmp/keen-pbr-crash.log)\n"
-            << "  --no-api           Disable REST API at runtime\n"
-            << "  --use-raw-prerouting  Use raw PREROUTING for IPv4 forwarded "
-               "traffic (iptables only)\n"
-            << "  --version          Show version and exit\n"
-            << "  --help             Show this help and exit\n"
-            << "\n"
-            << "Commands:\n"
-            << "  service                            Start the routing service "
-               "(foreground)\n"
-            << "  status                             Show routing/firewall "
-               "status and exit\n"
-            << "  download                           Download all configured "
-               "lists to cache and exit\n"
-            << "  generate-resolver-config <res>     Print generated resolver "
-               "config to stdout and exit\n"
-            << "                                     Resolvers: dnsmasq "
-               "(dnsmasq-ipset and dnsmasq-nftset are deprecated)\n"
-            << "  resolver-config-hash               Print MD5 hash of "
-               "domain-to-ipset mapping and exit\n"
-            << "  test-routing <ip-or-domain>        Test expected vs actual "
-               "routing for an IP or domain\n";
-}
-
-CliOptions parse_args(int argc, char *argv[]) {
-  CliOptions opts;
-  for (int i = 1; i < argc; ++i) {
-    if (std::strcmp(argv[i], "--config") == 0) {
-      if (i + 1 >= argc) {
-        std::cerr << "Error: --config requires an argument\n";
-        std::exit(1);
-      }
-      opts.config_path = argv[++i];
-    } else if (std::strcmp(argv[i], "--log-level") == 0) {
-      if (i + 1 >= argc) {
-        std::cerr << "Error: --log-level requires an argument\n";
-        std::exit(1);
-      }
-      opts.log_level = argv[++i];
-    } else if (std::strcmp(argv[i], "--pid-file") == 0) {
-      if (i + 1 >= argc) {
-        std::cerr << "Error: --pid-file requires an argument\n";
-        std::exit(1);
-      }
-      opts.pid_file_override = argv[++i];
-      opts.has_pid_file_override = true;
-    } else if (std::strcmp(argv[i], "--crash-report") == 0) {
-      if (i + 1 >= argc) {
-        std::cerr << "Error: --crash-report requires an argument\n";
-        std::exit(1);
-      }
-      opts.crash_report_path = argv[++i];
-    } else if (std::strcmp(argv[i], "--no-api") == 0) {
-      opts.no_api = true;
-    } else if (std::strcmp(argv[i], "--use-raw-prerouting") == 0) {
-      opts.use_raw_prerouting = true;
-    } else if (std::strcmp(argv[i], "--help") == 0 ||
-               std::strcmp(argv[i], "-h") == 0) {
-      opts.show_help = true;
-    } else if (std::strcmp(argv[i], "--version") == 0 ||
-               std::strcmp(argv[i], "-v") == 0) {
-      opts.show_version = true;
-    } else if (std::strcmp(argv[i], "service") == 0) {
-      opts.run_service = true;
-    } else if (std::strcmp(argv[i], "status") == 0) {
-      opts.run_status = true;
-    } else if (std::strcmp(argv[i], "generate-resolver-config") == 0) {
-      if (i + 1 >= argc) {
-        std::cerr
-            << "Error: generate-resolver-config requires a resolver argument\n";
-        print_usage(argv[0]);
-        std::exit(1);
-      }
-      opts.resolver_type = argv[++i];
-      opts.generate_resolver_config = true;
-    } else if (std::strcmp(argv[i], "download") == 0) {
-      opts.download_lists = true;
-    } else if (std::strcmp(argv[i], "--reload") == 0) {
-      opts.download_reload = true;
-    } else if (std::strcmp(argv[i], "resolver-config-hash") == 0) {
-      opts.resolver_config_hash = true;
-    } else if (std::strcmp(argv[i], "test-routing") == 0) {
-      if (i + 1 >= argc) {
-        std::cerr << "Error: test-routing requires an IP address or domain "
-                     "argument\n";
-        print_usage(argv[0]);
-        std::exit(1);
-      }
-      opts.test_routing_target = argv[++i];
-      opts.run_test_routing = true;
-    } else {
-      std::cerr << "Unknown option: " << argv[i] << "\n";
-      print_usage(argv[0]);
-      std::exit(1);
-    }
-  }
-  return opts;
-}
-
-std::string read_file(const std::string &path) {
-  std::ifstream ifs(path);
-  if (!ifs.is_open()) {
-    throw std::runtime_error("Cannot open config file: " + path);
-  }
-  std::ostringstream ss;
-  ss << ifs.rdbuf();
-  return ss.str();
-}
-
 void set_signal_action(int signum, void (*handler)(int)) {
   struct sigaction action{};
   action.sa_handler = handler;
@@ -201,6 +68,22 @@ void set_signal_action(int signum, void (*handler)(int)) {
   }
 }
 
+// Exit code used when --timeout expires, matching coreutils timeout(1).
+constexpr int kTimeoutExitCode = 124;
+
+void handle_command_deadline(int) {
+  static const char message[] = "Error: command did not finish before --timeout\n";
+  (void)!write(STDERR_FILENO, message, sizeof(message) - 1);
+  _exit(kTimeoutExitCode);
+}
+
+// Bound commands that run in this process. Work in progress is abandoned:
+// entries already added to a kernel set stay there.
+void arm_command_deadline(int seconds) {
+  set_signal_action(SIGALRM, handle_command_deadline);
+  alarm(static_cast<unsigned int>(seconds));
+}
+
 std::optional<std::string> resolver_fallback_reason(const std::string &error) {
   // Fallback is a lifecycle decision, not a generic IPC error handler.
   // A daemon that is alive (including one in `broken`) remains the source
@@ -226,7 +109,7 @@ int main(int argc, char *argv[]) {
     keen_pbr3::set_signal_mask_for_current_thread(SIG_BLOCK,
                                                   startup_sigusr1_mask);
 
-    CliOptions opts = parse_args(argc, argv);
+    keen_pbr3::CliOptions opts = keen_pbr3::parse_args(argc, argv);
     keen_pbr3::crash_diagnostics::CrashReporterConfig crash_config;
     crash_config.report_path = opts.crash_report_path;
     crash_config.version = KEEN_PBR3_VERSION_STRING;
@@ -259,14 +142,28 @@ int main(int argc, char *argv[]) {
     }
 
     if (opts.show_help) {
-      print_usage(argv[0]);
+      keen_pbr3::print_usage(argv[0]);
+      return 0;
+    }
+
+    if (opts.show_build_info) {
+      const auto info = keen_pbr3::current_build_info();
+      if (opts.json_output) {
+        std::cout << keen_pbr3::build_info_json(info).dump(2) << '\n';
+      } else {
+        std::cout << keen_pbr3::format_build_info(info);
+      }
       return 0;
     }
 
     if (!opts.download_lists && !opts.generate_resolver_config &&
         !opts.resolver_config_hash && !opts.run_service && !opts.run_status &&
-        !opts.run_test_routing) {
-      print_usage(argv[0]);
+        !opts.run_service_info && !opts.run_test_routing && !opts.run_explain &&
+        !opts.config_dump && !opts.config_explain && !opts.config_export_script &&
+        !opts.interfaces_resolve &&
+        !opts.apply_entries && !opts.list_bench && !opts.list_diff &&
+        !opts.undo_routing) {
+      keen_pbr3::print_usage(argv[0]);
       return 0;
     }
 
@@ -274,10 +171,38 @@ int main(int argc, char *argv[]) {
     auto &logger = keen_pbr3::Logger::instance();
     logger.set_level(keen_pbr3::parse_log_level(opts.log_level));
 
+    if (opts.interfaces_resolve) {
+#ifdef WITH_API
+      const auto matches =
+          keen_pbr3::resolve_keenetic_interface_name(opts.interface_query);
+      if (matches.empty()) {
+        std::cerr << "No Keenetic interface matches '" << opts.interface_query
+                  << "'\n";
+        return 1;
+      }
+      for (const auto &match : matches) {
+        std::cout << match.id << " -> "
+                  << (match.system_name.empty() ? "(no system name)"
+                                                : match.system_name);
+        if (!match.description.empty()) {
+          std::cout << " (" << match.description << ")";
+        }
+        std::cout << '\n';
+      }
+      return 0;
+#else
+      throw std::runtime_error(
+          "interfaces resolve requires build with WITH_API=ON");
+#endif
+    }
+
     if (opts.generate_resolver_config) {
       if (opts.config_path != KEEN_PBR_DEFAULT_CONFIG_PATH) {
         throw std::runtime_error(
-            "--config is only supported with the service command");
+            "--config is only supported with the service, config dump, "
+            "config explain, config export-script, lists bench, lists diff, "
+            "download "
+            "--validate-only and apply --stdin commands");
       }
       if (opts.resolver_type != "dnsmasq" &&
           opts.resolver_type != "dnsmasq-ipset" &&
@@ -313,38 +238,194 @@ int main(int argc, char *argv[]) {
       }
     }
 
-    if (opts.run_status || opts.resolver_config_hash || opts.download_lists ||
-        opts.run_test_routing) {
+    if (opts.apply_routing_only &&
+        (!opts.apply_entries || opts.apply_stdin || !opts.apply_set_name.empty())) {
+      throw std::runtime_error(
+          "--routing-only is only supported as apply --routing-only");
+    }
+
+    if (opts.auto_reapply_after_seconds > 0 && !opts.undo_routing) {
+      throw std::runtime_error(
+          "--auto-reapply-after is only supported with undo-routing");
+    }
+
+    if (opts.download_validate_only &&
+        (!opts.download_lists || opts.download_reload)) {
+      throw std::runtime_error(
+          "--validate-only is only supported as download --validate-only");
+    }
+
+    if (opts.timeout_seconds > 0 && !opts.download_lists && !opts.apply_entries) {
+      throw std::runtime_error(
+          "--timeout is only supported with the download and apply commands");
+    }
+
+    if (opts.run_status || opts.run_service_info || opts.resolver_config_hash ||
+        (opts.download_lists && !opts.download_validate_only) || opts.run_test_routing || opts.run_explain ||
+        opts.apply_routing_only || opts.undo_routing) {
       if (opts.config_path != KEEN_PBR_DEFAULT_CONFIG_PATH) {
         throw std::runtime_error(
-            "--config is only supported with the service command");
+            "--config is only supported with the service, config dump, "
+            "config explain, config export-script, lists bench, lists diff, "
+            "download "
+            "--validate-only and apply --stdin commands");
+      }
+      if (opts.json_output && !opts.run_status) {
+        throw std::runtime_error("--json is only supported with the status command");
+      }
+      std::string operation = "test-routing";
+      if (opts.run_status && opts.json_output) {
+        operation = "routing-health";
+      } else if (opts.run_status) {
+        operation = "status";
+      } else if (opts.run_service_info) {
+        operation = "runtime-info";
+      } else if (opts.resolver_config_hash) {
+        operation = "resolver-config-hash";
+      } else if (opts.download_lists) {
+        operation = "download";
+      } else if (opts.run_explain) {
+        operation = "explain";
+      } else if (opts.apply_routing_only) {
+        operation = "reconcile-routing";
+      } else if (opts.undo_routing) {
+        operation = "undo-routing";
+      }
+      nlohmann::json response;
+      try {
+        response = keen_pbr3::ipc::request_control(
+            KEEN_PBR_CONTROL_SOCKET,
+            {{"protocol_version", keen_pbr3::ipc::kControlProtocolVersion},
+             {"request_id", "cli-" + operation},
+             {"operation", operation},
+             {"reload", opts.download_reload},
+             {"target", opts.test_routing_target},
+             {"auto_reapply_after_seconds", opts.auto_reapply_after_seconds}},
+            opts.timeout_seconds > 0 ? opts.timeout_seconds * 1000 : 5000);
+      } catch (const keen_pbr3::ipc::ControlTimeoutError &) {
+        if (opts.timeout_seconds == 0) throw;
+        keen_pbr3::Logger::instance().error("{} did not finish within {}s",
+                                            operation, opts.timeout_seconds);
+        return kTimeoutExitCode;
       }
-      const std::string operation =
-          opts.run_status
-              ? "status"
-              : (opts.resolver_config_hash
-                     ? "resolver-config-hash"
-                     : (opts.download_lists ? "download" : "test-routing"));
-      const auto response = keen_pbr3::ipc::request_control(
-          KEEN_PBR_CONTROL_SOCKET,
-          {{"protocol_version", keen_pbr3::ipc::kControlProtocolVersion},
-           {"request_id", "cli-" + operation},
-           {"operation", operation},
-           {"reload", opts.download_reload},
-           {"target", opts.test_routing_target}});
       if (opts.resolver_config_hash && response.value("ok", false)) {
         std::cout << response.at("result").value("resolver_config_hash", "")
                   << '\n';
+      } else if (opts.json_output && response.value("ok", false)) {
+        const auto &report = response.at("result");
+        std::cout << report.dump(2) << '\n';
+        return keen_pbr3::routing_health_exit_code(report);
       } else {
         std::cout << response.dump() << '\n';
       }
       return response.value("ok", false) ? 0 : 1;
     }
 
+    if (opts.json_output && !opts.list_diff) {
+      throw std::runtime_error(
+          "--json is only supported with the status, version and lists diff "
+          "commands");
+    }
+
+    if (opts.apply_entries && (!opts.apply_stdin || opts.apply_set_name.empty())) {
+      throw std::runtime_error("apply requires --stdin and --ipset <set>");
+    }
+
+    if (opts.config_path == keen_pbr3::kStdinConfigPath) {
+      if (opts.run_service) {
+        throw std::runtime_error(
+            "--config - is not supported with the service command: the "
+            "service saves and reloads its config file");
+      }
+      if (opts.apply_entries) {
+        throw std::runtime_error(
+            "--config - cannot be combined with apply --stdin, which reads "
+            "entries from stdin");
+      }
+    }
+
+    if (opts.timeout_seconds > 0) {
+      arm_command_deadline(opts.timeout_seconds);
+    }
+
     // Load and parse configuration
-    std::string json_str = read_file(opts.config_path);
+    std::string json_str =
+        keen_pbr3::read_config_source(opts.config_path, std::cin);
     keen_pbr3::Config config = keen_pbr3::parse_config(json_str);
     keen_pbr3::validate_config(config);
+    for (const auto &warning : keen_pbr3::collect_config_warnings(config)) {
+      keen_pbr3::Logger::instance().warn("Config warning: {}", warning.message);
+    }
+    if (opts.config_dump) {
+      std::cout << keen_pbr3::dump_effective_config(config);
+      return 0;
+    }
+    if (opts.config_explain) {
+      const bool ipv6_enabled = config.daemon.value_or(keen_pbr3::DaemonConfig{})
+                                    .ipv6_enabled.value_or(true);
+      std::cout << keen_pbr3::format_config_explanation(
+          keen_pbr3::explain_config(config, ipv6_enabled));
+      return 0;
+    }
+    if (opts.config_export_script) {
+      const bool ipv6_enabled = config.daemon.value_or(keen_pbr3::DaemonConfig{})
+                                    .ipv6_enabled.value_or(true);
+      const auto rules = keen_pbr3::explain_config(config, ipv6_enabled);
+      const keen_pbr3::CacheManager cache(
+          config.daemon.value_or(keen_pbr3::DaemonConfig{})
+              .cache_dir.value_or("/var/cache/keen-pbr"),
+          keen_pbr3::max_file_size_bytes(config));
+      keen_pbr3::ListStreamer list_streamer(cache);
+      std::cout << keen_pbr3::format_config_script(
+          rules, keen_pbr3::collect_explained_set_entries(config, rules,
+                                                          list_streamer));
+      return 0;
+    }
+    if (opts.list_bench) {
+      keen_pbr3::HttpClient http_client;
+      http_client.set_max_response_size(keen_pbr3::max_file_size_bytes(config));
+      const auto result = keen_pbr3::bench_list_download(
+          config, opts.list_bench_name, http_client);
+      std::cout << keen_pbr3::format_list_bench_result(result);
+      return 0;
+    }
+    if (opts.list_diff) {
+      keen_pbr3::HttpClient http_client;
+      http_client.set_max_response_size(keen_pbr3::max_file_size_bytes(config));
+      const keen_pbr3::CacheManager cache(
+          config.daemon.value_or(keen_pbr3::DaemonConfig{})
+              .cache_dir.value_or("/var/cache/keen-pbr"),
+          keen_pbr3::max_file_size_bytes(config));
+      const auto result = keen_pbr3::diff_cached_list(
+          config, opts.list_diff_name, cache, http_client);
+      if (opts.json_output) {
+        std::cout << keen_pbr3::list_diff_result_to_json(result).dump(2) << '\n';
+      } else {
+        std::cout << keen_pbr3::format_list_diff_result(result);
+      }
+      return 0;
+    }
+    if (opts.download_validate_only) {
+      keen_pbr3::HttpClient http_client;
+      const auto checks = keen_pbr3::check_list_urls(config, http_client);
+      std::cout << keen_pbr3::format_list_url_checks(checks);
+      const bool all_ok = std::all_of(
+          checks.begin(), checks.end(),
+          [](const keen_pbr3::ListUrlCheck &check) { return check.ok(); });
+      return all_ok ? 0 : 1;
+    }
+    if (opts.apply_entries) {
+      const auto backend = keen_pbr3::resolve_firewall_backend(
+          keen_pbr3::firewall_backend_preference(config));
+      const auto writer = keen_pbr3::make_kernel_set_writer(backend);
+      const auto result =
+          keen_pbr3::import_set_entries(std::cin, opts.apply_set_name, *writer);
+      std::cout << "Added " << result.added << " entries to "
+                << opts.apply_set_name << " (skipped " << result.skipped
+                << ", invalid " << result.invalid << ", failed "
+                << result.failed << ")\n";
+      return result.failed == 0 ? 0 : 1;
+    }
     if (opts.run_service && opts.has_pid_file_override) {
       if (!config.daemon.has_value()) {
         config.daemon = keen_pbr3::DaemonConfig{};
@@ -358,6 +439,7 @@ int main(int argc, char *argv[]) {
       keen_pbr3::DaemonOptions daemon_opts;
       daemon_opts.no_api = opts.no_api;
       daemon_opts.use_raw_prerouting = opts.use_raw_prerouting;
+      daemon_opts.watch_config = opts.watch_config;
 
       // Block daemon-managed signals before constructing Daemon so any
       // worker threads spawned during member initialization inherit the mask.
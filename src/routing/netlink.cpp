@@ -5,6 +5,7 @@
 
 #include <algorithm>
 #include <arpa/inet.h>
+#include <linux/if_addr.h>
 #include <map>
 #include <memory>
 #include <net/if.h>
@@ -122,6 +123,47 @@ struct RuleDeleter {
 };
 using RulePtr = std::unique_ptr<struct rtnl_rule, RuleDeleter>;
 
+// Attach one next hop per multipath path. libnl passes the weight through as
+// the raw rtnh_hops value, which the kernel interprets as weight - 1.
+void add_multipath_nexthops(struct rtnl_route* route, const RouteSpec& spec, int family) {
+    for (const auto& path : spec.nexthops) {
+        NexthopPtr nh(rtnl_route_nh_alloc());
+        if (!nh) {
+            throw NetlinkError("Failed to allocate nexthop object");
+        }
+        unsigned int ifindex = if_nametoindex(path.interface.c_str());
+        if (ifindex == 0) {
+            throw NetlinkError("Interface not found: " + path.interface);
+        }
+        rtnl_route_nh_set_ifindex(nh.get(), static_cast<int>(ifindex));
+        if (path.gateway) {
+            NlAddrPtr gw = parse_addr(*path.gateway, family);
+            rtnl_route_nh_set_gateway(nh.get(), gw.get());
+        }
+        rtnl_route_nh_set_weight(nh.get(), static_cast<uint8_t>(path.weight - 1));
+        rtnl_route_add_nexthop(route, nh.release());
+    }
+}
+
+std::optional<std::string> nexthop_interface(struct rtnl_nexthop* nh) {
+    int ifindex = rtnl_route_nh_get_ifindex(nh);
+    if (ifindex > 0) {
+        char ifname[IF_NAMESIZE];
+        if (if_indextoname(static_cast<unsigned>(ifindex), ifname)) {
+            return std::string(ifname);
+        }
+    }
+    return std::nullopt;
+}
+
+std::optional<std::string> nexthop_gateway(struct rtnl_nexthop* nh) {
+    struct nl_addr* gw = rtnl_route_nh_get_gateway(nh);
+    if (gw && nl_addr_get_len(gw) > 0) {
+        return nl_addr_to_ip_str(gw);
+    }
+    return std::nullopt;
+}
+
 } // anonymous namespace
 
 struct NetlinkManager::Impl {
@@ -200,6 +242,8 @@ RouteAddResult NetlinkManager::add_route(const RouteSpec& spec) {
         rtnl_route_set_type(route.get(), RTN_BLACKHOLE);
     } else if (spec.unreachable) {
         rtnl_route_set_type(route.get(), RTN_UNREACHABLE);
+    } else if (!spec.nexthops.empty()) {
+        add_multipath_nexthops(route.get(), spec, family);
     } else {
         // Create nexthop with interface and optional gateway
         NexthopPtr nh(rtnl_route_nh_alloc());
@@ -229,6 +273,24 @@ RouteAddResult NetlinkManager::add_route(const RouteSpec& spec) {
         if (err == -NLE_EXIST) {
             return RouteAddResult::AlreadyPresent;
         }
+        const char* hint = nullptr;
+        if (err == -NLE_NOMEM) {
+            hint = "route table full or kernel out of memory (ENOBUFS)";
+        } else if (err == -NLE_INVAL || err == -NLE_RANGE) {
+            hint = "kernel rejected the route as invalid; check the table ID, gateway and metric";
+        }
+        if (hint != nullptr) {
+            throw RouteRejectedError(keen_pbr3::format(
+                "Failed to add route: {}: {} (dst={}, table={}, iface={}, gw={}, family={}, blackhole={})",
+                nl_geterror(err),
+                hint,
+                spec.destination,
+                spec.table,
+                spec.interface.value_or("(none)"),
+                spec.gateway.value_or("(none)"),
+                family,
+                spec.blackhole));
+        }
         throw NetlinkError(keen_pbr3::format(
             "Failed to add route: {} (dst={}, table={}, iface={}, gw={}, family={}, blackhole={})",
             nl_geterror(err),
@@ -282,6 +344,8 @@ void NetlinkManager::delete_route(const RouteSpec& spec) {
         rtnl_route_set_type(route.get(), RTN_BLACKHOLE);
     } else if (spec.unreachable) {
         rtnl_route_set_type(route.get(), RTN_UNREACHABLE);
+    } else if (!spec.nexthops.empty()) {
+        add_multipath_nexthops(route.get(), spec, family);
     } else if (spec.interface) {
         NexthopPtr nh(rtnl_route_nh_alloc());
         if (!nh) {
@@ -445,24 +509,24 @@ std::vector<DumpedRoute> NetlinkManager::dump_routes_in_table(uint32_t table_id,
             }
         }
 
-        // Nexthop info (interface and gateway)
+        // Nexthop info (interface and gateway, or every path of a multipath route)
         if (!dr.blackhole && !dr.unreachable) {
             int nh_count = rtnl_route_get_nnexthops(route);
-            if (nh_count > 0) {
+            if (nh_count == 1) {
                 struct rtnl_nexthop* nh = rtnl_route_nexthop_n(route, 0);
                 if (nh) {
-                    int ifindex = rtnl_route_nh_get_ifindex(nh);
-                    if (ifindex > 0) {
-                        char ifname[IF_NAMESIZE];
-                        if (if_indextoname(static_cast<unsigned>(ifindex),
-                                           ifname)) {
-                            dr.interface = ifname;
-                        }
-                    }
-                    struct nl_addr* gw = rtnl_route_nh_get_gateway(nh);
-                    if (gw && nl_addr_get_len(gw) > 0) {
-                        dr.gateway = nl_addr_to_ip_str(gw);
-                    }
+                    dr.interface = nexthop_interface(nh);
+                    dr.gateway = nexthop_gateway(nh);
+                }
+            } else {
+                for (int i = 0; i < nh_count; ++i) {
+                    struct rtnl_nexthop* nh = rtnl_route_nexthop_n(route, i);
+                    if (!nh) continue;
+                    RouteNexthop path;
+                    path.interface = nexthop_interface(nh).value_or("");
+                    path.gateway = nexthop_gateway(nh);
+                    path.weight = static_cast<uint32_t>(rtnl_route_nh_get_weight(nh)) + 1;
+                    dr.nexthops.push_back(std::move(path));
                 }
             }
         }
@@ -596,6 +660,9 @@ std::vector<DumpedInterface> NetlinkManager::dump_interfaces() {
             break;
         case AF_INET6:
             interface_it->second.ipv6_addresses.push_back(rendered);
+            if ((rtnl_addr_get_flags(addr) & IFA_F_DEPRECATED) != 0) {
+                interface_it->second.deprecated_ipv6_addresses.push_back(rendered);
+            }
             break;
         default:
             break;
@@ -608,6 +675,7 @@ std::vector<DumpedInterface> NetlinkManager::dump_interfaces() {
         (void)ifindex;
         std::sort(dumped.ipv4_addresses.begin(), dumped.ipv4_addresses.end());
         std::sort(dumped.ipv6_addresses.begin(), dumped.ipv6_addresses.end());
+        std::sort(dumped.deprecated_ipv6_addresses.begin(), dumped.deprecated_ipv6_addresses.end());
         result.push_back(std::move(dumped));
     }
 
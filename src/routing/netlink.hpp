@@ -16,6 +16,15 @@ public:
     using std::runtime_error::runtime_error;
 };
 
+// The kernel refused a route it was asked to add: the table is full
+// (ENOBUFS/ENOMEM) or the table, gateway or metric is invalid (EINVAL,
+// ERANGE). Unlike other netlink failures this only concerns that route, so
+// callers may skip the affected outbound and carry on with the others.
+class RouteRejectedError : public NetlinkError {
+public:
+    using NetlinkError::NetlinkError;
+};
+
 enum class RouteType {
     unicast,
     blackhole,
@@ -35,6 +44,31 @@ enum class RuleAction {
 #endif
 constexpr uint8_t KEEN_PBR_GENERATED_ROUTE_PROTOCOL = KEEN_PBR_ROUTE_PROTOCOL;
 
+// One path of a multipath (ECMP) route.
+struct RouteNexthop {
+    std::string interface;
+    std::optional<std::string> gateway;
+    uint32_t weight{1};         // Relative share of flows, 1-256
+
+    bool operator==(const RouteNexthop& other) const {
+        return interface == other.interface && gateway == other.gateway &&
+               weight == other.weight;
+    }
+    bool operator!=(const RouteNexthop& other) const { return !(*this == other); }
+};
+
+// Renders next hops like `ip route`: "nexthop dev wg0 weight 1 nexthop ...".
+inline std::string format_route_nexthops(const std::vector<RouteNexthop>& nexthops) {
+    std::string out;
+    for (const auto& path : nexthops) {
+        if (!out.empty()) out += ' ';
+        out += "nexthop dev " + path.interface;
+        if (path.gateway) out += " via " + *path.gateway;
+        out += " weight " + std::to_string(path.weight);
+    }
+    return out;
+}
+
 // Represents a route to install in the kernel
 struct RouteSpec {
     std::string destination;    // IP/CIDR (e.g., "10.0.0.0/8") or "default"
@@ -46,6 +80,11 @@ struct RouteSpec {
     int family{0};              // AF_INET or AF_INET6 (0 = auto-detect)
     uint32_t metric{0};         // Route metric/priority (0 = kernel default)
     uint8_t protocol{KEEN_PBR_GENERATED_ROUTE_PROTOCOL}; // rtm_protocol ownership marker
+    // Multipath next hops; when set, interface and gateway are left empty.
+    std::vector<RouteNexthop> nexthops;
+    // Tag of the outbound the route serves. Used in error messages only and
+    // not part of route identity.
+    std::string outbound;
 };
 
 enum class RouteAddResult {
@@ -93,6 +132,7 @@ struct DumpedRoute {
     int family{0};                      // AF_INET or AF_INET6
     uint32_t metric{0};                 // Route metric/priority
     uint8_t protocol{0};                // rtm_protocol ownership marker
+    std::vector<RouteNexthop> nexthops; // Set instead of interface/gateway for multipath routes
 };
 
 // A policy rule dumped from the kernel (read-only snapshot)
@@ -113,6 +153,10 @@ struct DumpedInterface {
     std::optional<bool> carrier;       // Link carrier state when reported by kernel
     std::vector<std::string> ipv4_addresses; // IPv4 addresses in CIDR form
     std::vector<std::string> ipv6_addresses; // IPv6 addresses in CIDR form
+    // Subset of ipv6_addresses whose preferred lifetime has expired
+    // (IFA_F_DEPRECATED). They stay valid for existing connections but must
+    // not be treated as making the interface usable for new IPv6 traffic.
+    std::vector<std::string> deprecated_ipv6_addresses;
 };
 
 // The complete netlink surface required by a state reconciler.  Keeping this
@@ -0,0 +1,35 @@
+#include "route_lookup.hpp"
+
+#include "../util/format_compat.hpp"
+#include "../util/safe_exec.hpp"
+
+#include <sstream>
+
+namespace keen_pbr3 {
+
+std::optional<std::string> parse_ip_route_get_device(const std::string& output) {
+    std::istringstream line(output.substr(0, output.find('\n')));
+    std::string token;
+    if (!(line >> token)) return std::nullopt;
+    if (token == "unreachable" || token == "blackhole" || token == "prohibit") {
+        return std::nullopt;
+    }
+    do {
+        if (token == "dev") {
+            std::string device;
+            if (line >> device) return device;
+            return std::nullopt;
+        }
+    } while (line >> token);
+    return std::nullopt;
+}
+
+std::optional<std::string> lookup_egress_interface(const std::string& ip, uint32_t fwmark) {
+    const auto result = safe_exec_capture(
+        {"ip", "route", "get", ip, "mark", format("{:#x}", fwmark)},
+        /*suppress_stderr=*/true);
+    if (result.exit_code != 0) return std::nullopt;
+    return parse_ip_route_get_device(result.stdout_output);
+}
+
+} // namespace keen_pbr3
@@ -0,0 +1,19 @@
+#pragma once
+
+#include <cstdint>
+#include <optional>
+#include <string>
+
+namespace keen_pbr3 {
+
+// Output device of an `ip route get` answer, e.g. "wg0" for
+// "1.1.1.1 via 10.8.0.1 dev wg0 table 100 src 10.8.0.2 mark 0x10000 uid 0".
+// Unset for answers without a device (unreachable, blackhole, prohibit).
+std::optional<std::string> parse_ip_route_get_device(const std::string& output);
+
+// Ask the kernel which interface a packet to `ip` carrying `fwmark` leaves
+// through (`ip route get <ip> mark <fwmark>`). Unset when the lookup fails or
+// the route has no device.
+std::optional<std::string> lookup_egress_interface(const std::string& ip, uint32_t fwmark);
+
+} // namespace keen_pbr3
@@ -17,7 +17,8 @@ bool routes_equal(const RouteSpec& a, const RouteSpec& b) {
            a.unreachable == b.unreachable &&
            a.family == b.family &&
            a.metric == b.metric &&
-           a.protocol == b.protocol;
+           a.protocol == b.protocol &&
+           a.nexthops == b.nexthops;
 }
 
 } // anonymous namespace
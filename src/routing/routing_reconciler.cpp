@@ -1,8 +1,13 @@
 #include "routing_reconciler.hpp"
 
+#include "../log/logger.hpp"
+
 #include <algorithm>
+#include <functional>
 #include <netinet/in.h>
 #include <set>
+#include <string>
+#include <utility>
 
 namespace keen_pbr3 {
 namespace {
@@ -22,6 +27,7 @@ bool same_route(const RouteSpec& expected, const DumpedRoute& actual,
            expected.unreachable == actual.unreachable &&
            route_family(expected) == actual.family &&
            expected.metric == actual.metric &&
+           expected.nexthops == actual.nexthops &&
            (!include_protocol || expected.protocol == actual.protocol);
 }
 
@@ -34,13 +40,22 @@ bool same_route_lookup_key(const RouteSpec& expected, const DumpedRoute& actual)
 RouteSpec route_spec_from_dump(const DumpedRoute& route) {
     return {route.destination, route.table, route.interface,
             route.gateway, route.blackhole, route.unreachable,
-            route.family, route.metric, route.protocol};
+            route.family, route.metric, route.protocol, route.nexthops};
 }
 
 DumpedRoute dumped_route_from_spec(const RouteSpec& route) {
     return {route.destination, route.table, route.interface,
             route.gateway, route.blackhole, route.unreachable,
-            route.family, route.metric, route.protocol};
+            route.family, route.metric, route.protocol, route.nexthops};
+}
+
+std::string describe_route(const DumpedRoute& route) {
+    std::string out = route.destination;
+    if (route.blackhole) return "blackhole " + out;
+    if (route.unreachable) return "unreachable " + out;
+    if (route.gateway) out += " via " + *route.gateway;
+    if (route.interface) out += " dev " + *route.interface;
+    return out;
 }
 
 bool same_rule(const RuleSpec& expected, const DumpedRule& actual) {
@@ -60,8 +75,82 @@ bool rule_namespace_owned(const DumpedRule& actual,
 
 } // namespace
 
+bool ForeignRouteReports::changed(uint32_t table, const std::string& listed) {
+    const auto it = reported_.find(table);
+    if (listed.empty()) {
+        if (it != reported_.end()) reported_.erase(it);
+        return false;
+    }
+    if (it != reported_.end() && it->second == listed) return false;
+    reported_[table] = listed;
+    return true;
+}
+
+// Kernel mutations made by one reconcile, kept so a failure part way through
+// can put back the routes and rules that were there before it started.
+class RoutingReconciler::UndoLog {
+public:
+    explicit UndoLog(RoutingNetlinkOperations& netlink) : netlink_(netlink) {}
+
+    UndoLog(const UndoLog&) = delete;
+    UndoLog& operator=(const UndoLog&) = delete;
+
+    void route_added(const RouteSpec& route) {
+        record("delete route " + describe_route(dumped_route_from_spec(route)),
+               [this, route] { netlink_.delete_route(route); });
+    }
+    void route_deleted(const RouteSpec& route) {
+        record("restore route " + describe_route(dumped_route_from_spec(route)),
+               [this, route] { (void)netlink_.add_route(route); });
+    }
+    void rule_added(const RuleSpec& rule, int family) {
+        record("delete rule priority " + std::to_string(rule.priority),
+               [this, rule, family] { netlink_.delete_rule_for_family(rule, family); });
+    }
+    void rule_deleted(const RuleSpec& rule, int family) {
+        record("restore rule priority " + std::to_string(rule.priority),
+               [this, rule, family] { (void)netlink_.add_rule_for_family(rule, family); });
+    }
+
+    // Best effort: undo steps that fail are logged and skipped so one stuck
+    // object does not keep the others from being restored.
+    void roll_back() noexcept {
+        if (steps_.empty()) return;
+        Logger::instance().warn("Routing apply failed, rolling back {} change(s)", steps_.size());
+        for (auto it = steps_.rbegin(); it != steps_.rend(); ++it) {
+            try {
+                it->second();
+            } catch (const std::exception& e) {
+                Logger::instance().error("Rollback step '{}' failed: {}", it->first, e.what());
+            }
+        }
+        steps_.clear();
+    }
+
+private:
+    void record(std::string description, std::function<void()> undo) {
+        steps_.emplace_back(std::move(description), std::move(undo));
+    }
+
+    RoutingNetlinkOperations& netlink_;
+    std::vector<std::pair<std::string, std::function<void()>>> steps_;
+};
+
 void RoutingReconciler::reconcile(const std::vector<RouteSpec>& desired_routes,
                                   const std::vector<RuleSpec>& desired_rules) {
+    rejected_routes_.clear();
+    UndoLog undo(netlink_);
+    try {
+        reconcile_with_undo(desired_routes, desired_rules, undo);
+    } catch (...) {
+        undo.roll_back();
+        throw;
+    }
+}
+
+void RoutingReconciler::reconcile_with_undo(const std::vector<RouteSpec>& desired_routes,
+                                            const std::vector<RuleSpec>& desired_rules,
+                                            UndoLog& undo) {
     std::set<uint32_t> tables;
     for (const auto& route : desired_routes) tables.insert(route.table);
 
@@ -71,6 +160,28 @@ void RoutingReconciler::reconcile(const std::vector<RouteSpec>& desired_routes,
         actual_routes.insert(actual_routes.end(), routes.begin(), routes.end());
     }
 
+    // Under the replace policy the configured route supersedes any foreign
+    // route sharing its lookup identity, so only one default is left per table.
+    if (replace_foreign_routes_) {
+        for (auto it = actual_routes.begin(); it != actual_routes.end();) {
+            const bool superseded =
+                it->protocol != KEEN_PBR_GENERATED_ROUTE_PROTOCOL &&
+                std::any_of(desired_routes.begin(), desired_routes.end(),
+                            [&](const RouteSpec& desired) {
+                                return same_route_lookup_key(desired, *it);
+                            });
+            if (!superseded) {
+                ++it;
+                continue;
+            }
+            Logger::instance().info("Replacing foreign route in table {}: {}",
+                                    it->table, describe_route(*it));
+            netlink_.delete_route(route_spec_from_dump(*it));
+            undo.route_deleted(route_spec_from_dump(*it));
+            it = actual_routes.erase(it);
+        }
+    }
+
     // A foreign route with the same lookup identity cannot be safely replaced:
     // it would make the configured path ambiguous.  Fail before mutation.
     for (const auto& desired : desired_routes) {
@@ -85,18 +196,53 @@ void RoutingReconciler::reconcile(const std::vector<RouteSpec>& desired_routes,
         }
     }
 
+    // Non-conflicting foreign routes are left in place, but they mean another
+    // daemon or script writes into a table this configuration owns, usually
+    // because its table IDs overlap ours. Routes the replace policy deleted
+    // above are already gone from actual_routes and are not reported.
+    for (uint32_t table : tables) {
+        std::vector<std::string> foreign;
+        for (const auto& actual : actual_routes) {
+            if (actual.table == table && actual.protocol != KEEN_PBR_GENERATED_ROUTE_PROTOCOL) {
+                foreign.push_back(describe_route(actual));
+            }
+        }
+        std::sort(foreign.begin(), foreign.end());
+        std::string listed;
+        for (const auto& route : foreign) {
+            if (!listed.empty()) listed += ", ";
+            listed += route;
+        }
+        if (!foreign_route_reports_.changed(table, listed)) continue;
+        Logger::instance().warn("Route table {} contains {} route(s) keen-pbr did not install: {}. "
+                                "Another daemon may be using this table; consider a different "
+                                "iproute.table_start",
+                                table,
+                                foreign.size(),
+                                listed);
+    }
+
     // Add all new paths before pruning unrelated old ones. A generated route
     // with the same table/family/destination is the exception: some kernels
     // report EEXIST when replacing an IPv6 unreachable fallback with unicast,
     // even when their metrics differ. The terminal RPDB guard remains active
     // while this owned fallback is replaced.
+    const auto route_owner = [](const RouteSpec& route) {
+        return route.outbound.empty() ? "table " + std::to_string(route.table)
+                                      : "outbound '" + route.outbound + "'";
+    };
+    std::set<std::string> rejected_owners;
     for (const auto& desired : desired_routes) {
+        const std::string owner = route_owner(desired);
+        if (rejected_owners.count(owner) != 0) continue;
+
         auto present = std::any_of(actual_routes.begin(), actual_routes.end(),
                                    [&](const DumpedRoute& actual) {
                                        return same_route(desired, actual, true);
                                    });
         if (present) continue;
 
+        std::vector<RouteSpec> replaced;
         for (auto it = actual_routes.begin(); it != actual_routes.end();) {
             const bool wanted = std::any_of(desired_routes.begin(), desired_routes.end(),
                                             [&](const RouteSpec& candidate) {
@@ -104,15 +250,50 @@ void RoutingReconciler::reconcile(const std::vector<RouteSpec>& desired_routes,
                                             });
             if (it->protocol == KEEN_PBR_GENERATED_ROUTE_PROTOCOL &&
                 !wanted && same_route_lookup_key(desired, *it)) {
-                netlink_.delete_route(route_spec_from_dump(*it));
+                replaced.push_back(route_spec_from_dump(*it));
+                netlink_.delete_route(replaced.back());
+                undo.route_deleted(replaced.back());
                 it = actual_routes.erase(it);
             } else {
                 ++it;
             }
         }
 
-        const auto result = netlink_.add_route(desired);
+        RouteAddResult result;
+        try {
+            result = netlink_.add_route(desired);
+        } catch (const RouteRejectedError& e) {
+            // The policy rules stay, so marked traffic falls through to the
+            // strict-enforcement guard exactly as for a down interface.
+            rejected_owners.insert(owner);
+            std::string message = "Kernel rejected route " +
+                                  describe_route(dumped_route_from_spec(desired)) +
+                                  " in table " + std::to_string(desired.table);
+            if (!desired.outbound.empty()) message += " for " + owner;
+            rejected_routes_.push_back(message + ": " + e.what());
+            Logger::instance().error("{}. Skipping the remaining routes of {}; other outbounds "
+                                     "are still applied",
+                                     rejected_routes_.back(),
+                                     owner);
+            // Put back the generated route this add was about to replace, so
+            // the outbound keeps its previous path rather than none.
+            for (const auto& route : replaced) {
+                try {
+                    if (netlink_.add_route(route) == RouteAddResult::Created) {
+                        undo.route_added(route);
+                    }
+                    actual_routes.push_back(dumped_route_from_spec(route));
+                } catch (const std::exception& restore_error) {
+                    Logger::instance().error("Cannot restore route {} in table {}: {}",
+                                             describe_route(dumped_route_from_spec(route)),
+                                             route.table,
+                                             restore_error.what());
+                }
+            }
+            continue;
+        }
         if (result == RouteAddResult::Created) {
+            undo.route_added(desired);
             actual_routes.push_back(dumped_route_from_spec(desired));
             continue;
         }
@@ -134,8 +315,16 @@ void RoutingReconciler::reconcile(const std::vector<RouteSpec>& desired_routes,
                                         [&](const RouteSpec& desired) {
                                             return same_route(desired, actual, true);
                                         });
-        if (!wanted) {
+        // The previous routes of an outbound whose new routes were rejected
+        // stay until a later reconcile can replace them.
+        const bool kept = std::any_of(desired_routes.begin(), desired_routes.end(),
+                                      [&](const RouteSpec& desired) {
+                                          return rejected_owners.count(route_owner(desired)) != 0 &&
+                                                 same_route_lookup_key(desired, actual);
+                                      });
+        if (!wanted && !kept) {
             netlink_.delete_route(route_spec_from_dump(actual));
+            undo.route_deleted(route_spec_from_dump(actual));
         }
     }
 
@@ -149,7 +338,10 @@ void RoutingReconciler::reconcile(const std::vector<RouteSpec>& desired_routes,
                                                  return same_rule(desired, actual) &&
                                                         actual.family == family;
                                              });
-            if (!present) netlink_.add_rule_for_family(desired, family);
+            if (present) continue;
+            if (netlink_.add_rule_for_family(desired, family) == RuleAddResult::Created) {
+                undo.rule_added(desired, family);
+            }
         }
     }
     for (const auto& actual : actual_rules) {
@@ -162,6 +354,7 @@ void RoutingReconciler::reconcile(const std::vector<RouteSpec>& desired_routes,
             RuleSpec stale{actual.fwmark, actual.fwmask, actual.table,
                            actual.priority, actual.family, actual.action};
             netlink_.delete_rule_for_family(stale, actual.family);
+            undo.rule_deleted(stale, actual.family);
         }
     }
 }
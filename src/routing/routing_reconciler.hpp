@@ -1,24 +1,79 @@
 #pragma once
 
+#include <cstdint>
+#include <map>
+#include <string>
 #include <vector>
 
 #include "netlink.hpp"
 
 namespace keen_pbr3 {
 
+// Foreign routes last reported per table. The daemon keeps one for its whole
+// lifetime and passes it to every reconciler it builds, so an unchanged set is
+// warned about once, and again only after it changes or clears and comes back.
+// Not synchronized: the daemon applies routing from one thread at a time.
+class ForeignRouteReports {
+public:
+    // Record `listed` (the table's sorted foreign routes, joined) and return
+    // whether it differs from what was last reported for `table`. An empty
+    // listing forgets the table.
+    bool changed(uint32_t table, const std::string& listed);
+
+private:
+    std::map<uint32_t, std::string> reported_;
+};
+
 // Reconciles kernel state without relying on an in-memory manifest.  Routes
 // are owned by the generated rtm_protocol; rules are owned by an exact,
 // non-zero mark/mask pair reserved by the active configuration.
+// When a netlink step fails part way, the routes and rules the reconcile
+// already added or removed are put back (best effort) before the error
+// propagates, so a failed apply does not leave a mix of old and new state.
+// A route the kernel refuses (RouteRejectedError, e.g. a full table) is the
+// exception: it is logged, the rest of that outbound's routes are skipped
+// (its previous routes are kept) and the other outbounds are still applied.
 class RoutingReconciler {
 public:
-    explicit RoutingReconciler(RoutingNetlinkOperations& netlink)
-        : netlink_(netlink) {}
+    // With replace_foreign_routes, a foreign route that has the same table,
+    // family and destination as a desired route is deleted (`ip route
+    // replace` semantics) instead of failing the reconcile.
+    // Foreign routes are warned about only when they differ from what
+    // `foreign_route_reports` last recorded for the table.
+    RoutingReconciler(RoutingNetlinkOperations& netlink,
+                      ForeignRouteReports& foreign_route_reports,
+                      bool replace_foreign_routes = false)
+        : netlink_(netlink),
+          foreign_route_reports_(foreign_route_reports),
+          replace_foreign_routes_(replace_foreign_routes) {}
+
+    // Foreign routes are remembered only for this reconciler's lifetime.
+    explicit RoutingReconciler(RoutingNetlinkOperations& netlink,
+                               bool replace_foreign_routes = false)
+        : RoutingReconciler(netlink, own_foreign_route_reports_, replace_foreign_routes) {}
+
+    RoutingReconciler(const RoutingReconciler&) = delete;
+    RoutingReconciler& operator=(const RoutingReconciler&) = delete;
 
     void reconcile(const std::vector<RouteSpec>& desired_routes,
                    const std::vector<RuleSpec>& desired_rules);
 
+    // One message per route the kernel rejected during the last reconcile,
+    // naming the route, its table and interface, and the outbound it serves.
+    const std::vector<std::string>& rejected_routes() const { return rejected_routes_; }
+
 private:
+    class UndoLog;
+
+    void reconcile_with_undo(const std::vector<RouteSpec>& desired_routes,
+                             const std::vector<RuleSpec>& desired_rules,
+                             UndoLog& undo);
+
+    ForeignRouteReports own_foreign_route_reports_;
     RoutingNetlinkOperations& netlink_;
+    ForeignRouteReports& foreign_route_reports_;
+    bool replace_foreign_routes_;
+    std::vector<std::string> rejected_routes_;
 };
 
 } // namespace keen_pbr3
@@ -60,6 +60,9 @@ bool route_matches_expected(const RouteSpec& expected, const DumpedRoute& actual
     if (expected.blackhole || expected.unreachable) {
         return true;
     }
+    if (expected.nexthops != actual.nexthops) {
+        return false;
+    }
     if (expected.interface) {
         if (!actual.interface || *actual.interface != *expected.interface) {
             return false;
@@ -86,6 +89,7 @@ RouteTableCheck RoutingVerifier::verify_route_table(const RouteSpec& expected,
     result.expected_destination = expected.destination;
     result.expected_interface = expected.interface;
     result.expected_gateway   = expected.gateway;
+    result.expected_nexthops  = expected.nexthops;
     if (expected.metric != 0) {
         result.expected_metric = expected.metric;
     }
@@ -141,6 +145,10 @@ RouteTableCheck RoutingVerifier::verify_route_table(const RouteSpec& expected,
             } else if (!route_metric_matches(expected, *first_default)) {
                 detail << "metric mismatch: expected '" << expected.metric
                        << "', got '" << first_default->metric << "'.";
+            } else if (expected.nexthops != first_default->nexthops) {
+                detail << "multipath mismatch: expected '"
+                       << format_route_nexthops(expected.nexthops) << "', got '"
+                       << format_route_nexthops(first_default->nexthops) << "'.";
             } else {
                 if (!result.interface_matches) {
                     detail << "interface mismatch: expected '"
@@ -2,18 +2,48 @@
 
 #include "../util/safe_exec.hpp"
 
+#include <arpa/inet.h>
+#include <netinet/in.h>
+#include <sstream>
+
 namespace keen_pbr3 {
 
+namespace {
+
+// Canonical text form of an address, so "2001:DB8::1" matches conntrack's
+// "2001:db8::1". Other strings are returned unchanged.
+std::string canonical_address(const std::string& address) {
+    in6_addr addr6{};
+    char text[INET6_ADDRSTRLEN] {};
+    if (inet_pton(AF_INET6, address.c_str(), &addr6) == 1 &&
+        inet_ntop(AF_INET6, &addr6, text, sizeof(text)) != nullptr) {
+        return text;
+    }
+    return address;
+}
+
+std::optional<uint16_t> parse_port(const std::string& value) {
+    try {
+        const unsigned long port = std::stoul(value);
+        if (port <= 65535) return static_cast<uint16_t>(port);
+    } catch (const std::exception&) {
+    }
+    return std::nullopt;
+}
+
+} // namespace
+
 ConntrackManager::ConntrackManager(CommandRunner runner)
     : runner_(std::move(runner)) {
     if (!runner_) {
         runner_ = [](const std::vector<std::string>& args) {
-            constexpr size_t kMaxDiagnosticBytes = 1024;
+            // Enough for the flows of one destination in list_flows_to().
+            constexpr size_t kMaxOutputBytes = 256 * 1024;
             const auto result = safe_exec_capture(args,
                                                   /*suppress_stderr=*/false,
-                                                  kMaxDiagnosticBytes,
+                                                  kMaxOutputBytes,
                                                   /*merge_stderr=*/true);
-            return CommandResult{result.exit_code, result.stdout_output};
+            return CommandResult{result.exit_code, result.stdout_output, result.truncated};
         };
     }
 }
@@ -59,4 +89,87 @@ bool ConntrackManager::delete_mark(uint32_t mark, uint32_t owned_mask) const {
     return ipv4 && ipv6;
 }
 
+std::optional<ConntrackFlowList> ConntrackManager::list_flows_to(
+    const std::string& ip) const {
+    const std::string target = canonical_address(ip);
+    const char* family = target.find(':') != std::string::npos ? "ipv6" : "ipv4";
+    auto result = runner_({"conntrack", "-L", "-f", family, "-d", target});
+    // Hitting the output cap kills conntrack, so its exit status says nothing
+    // about the flows it already printed. Check the cap first.
+    if (!result.truncated && result.exit_code != 0) {
+        return std::nullopt;
+    }
+    if (result.truncated) {
+        // Drop the partial line the cap cut off.
+        const auto last_newline = result.output.rfind('\n');
+        result.output.resize(last_newline == std::string::npos ? 0 : last_newline + 1);
+    }
+
+    ConntrackFlowList list;
+    list.truncated = result.truncated;
+    for (auto& flow : parse_flows(result.output)) {
+        if (canonical_address(flow.dst) == target) {
+            list.flows.push_back(std::move(flow));
+        }
+    }
+    return list;
+}
+
+std::vector<ConntrackFlow> ConntrackManager::parse_flows(const std::string& output) {
+    std::vector<ConntrackFlow> flows;
+    std::istringstream lines(output);
+    std::string line;
+    while (std::getline(lines, line)) {
+        std::istringstream tokens(line);
+        std::string token;
+        ConntrackFlow flow;
+        bool has_src = false;
+        bool has_dst = false;
+        bool in_original = true;
+        size_t position = 0;
+        while (tokens >> token) {
+            const auto eq = token.find('=');
+            if (eq == std::string::npos) {
+                // "tcp 6 431999 ESTABLISHED"; the state is the first word
+                // after the protocol number and timeout.
+                if (position == 0) {
+                    flow.protocol = token;
+                } else if (position == 3 && !has_src && token.front() != '[') {
+                    flow.state = token;
+                }
+                ++position;
+                continue;
+            }
+            ++position;
+            const std::string key = token.substr(0, eq);
+            const std::string value = token.substr(eq + 1);
+            if (key == "mark") {
+                try {
+                    flow.mark = static_cast<uint32_t>(std::stoul(value, nullptr, 0));
+                } catch (const std::exception&) {
+                }
+                continue;
+            }
+            // The reply direction repeats src/dst/sport/dport.
+            if (key == "src" && has_src) in_original = false;
+            if (!in_original) continue;
+            if (key == "src") {
+                flow.src = value;
+                has_src = true;
+            } else if (key == "dst") {
+                flow.dst = value;
+                has_dst = true;
+            } else if (key == "sport") {
+                flow.sport = parse_port(value);
+            } else if (key == "dport") {
+                flow.dport = parse_port(value);
+            }
+        }
+        if (has_src && has_dst && !flow.protocol.empty()) {
+            flows.push_back(std::move(flow));
+        }
+    }
+    return flows;
+}
+
 } // namespace keen_pbr3
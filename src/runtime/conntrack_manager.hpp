@@ -2,6 +2,7 @@
 
 #include <cstdint>
 #include <functional>
+#include <optional>
 #include <string>
 #include <vector>
 
@@ -18,11 +19,30 @@ struct ConntrackPolicy {
     bool operator!=(const ConntrackPolicy& other) const { return !(*this == other); }
 };
 
+// One `conntrack -L` entry; addresses and ports are the original direction.
+struct ConntrackFlow {
+    std::string protocol;
+    std::string state; // TCP state; empty for other protocols
+    std::string src;
+    std::string dst;
+    std::optional<uint16_t> sport;
+    std::optional<uint16_t> dport;
+    uint32_t mark{0};
+};
+
+struct ConntrackFlowList {
+    std::vector<ConntrackFlow> flows;
+    // conntrack printed more than the output cap; flows is incomplete.
+    bool truncated{false};
+};
+
 class ConntrackManager {
 public:
     struct CommandResult {
         int exit_code{-1};
         std::string output;
+        // The output cap was reached and the command was killed.
+        bool truncated{false};
     };
     using CommandRunner = std::function<CommandResult(const std::vector<std::string>&)>;
 
@@ -44,6 +64,15 @@ public:
     // flushes the global conntrack table and invokes each IP family separately.
     bool delete_mark(uint32_t mark, uint32_t owned_mask) const;
 
+    // Flows whose original destination is `ip` (`conntrack -L -d <ip>`).
+    // When the output cap is hit, the flows read so far are returned with
+    // truncated set. Unset when conntrack fails or is missing.
+    std::optional<ConntrackFlowList> list_flows_to(const std::string& ip) const;
+
+    // Parse `conntrack -L` output; lines that are not flows (such as the
+    // "flow entries have been shown" summary) are skipped.
+    static std::vector<ConntrackFlow> parse_flows(const std::string& output);
+
 private:
     ConntrackPolicy active_;
     CommandRunner runner_;
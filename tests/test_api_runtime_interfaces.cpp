@@ -33,6 +33,7 @@ ApiContext make_test_api_context(
         [runtime_interfaces]() { return runtime_interfaces; },
         [](const Config&) { return std::map<std::string, api::ListRefreshStateValue>{}; },
         [](const std::string&) { return TestRoutingResult{}; },
+        [](const std::string&) { return DomainMatchResult{}; },
         []() {},
         []() {},
         [](Config, std::string) { return ConfigApplyResult{}; },
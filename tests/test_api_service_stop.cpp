@@ -0,0 +1,54 @@
+#ifdef WITH_API
+
+#include <doctest/doctest.h>
+
+#include "../src/api/handler_reload.hpp"
+
+#include <chrono>
+#include <optional>
+#include <string>
+
+namespace keen_pbr3 {
+
+namespace {
+
+int rejected_status(const std::string& body) {
+    try {
+        (void)parse_auto_start_after(body);
+    } catch (const ApiError& error) {
+        return error.status();
+    }
+    return 0;
+}
+
+} // namespace
+
+TEST_CASE("service stop: auto_start_after_seconds is optional") {
+    CHECK_FALSE(parse_auto_start_after("").has_value());
+    CHECK_FALSE(parse_auto_start_after("null").has_value());
+    CHECK_FALSE(parse_auto_start_after("{}").has_value());
+    CHECK_FALSE(parse_auto_start_after(R"({"auto_start_after_seconds":null})").has_value());
+}
+
+TEST_CASE("service stop: auto_start_after_seconds accepts 1 through 86400") {
+    CHECK(parse_auto_start_after(R"({"auto_start_after_seconds":1})") ==
+          std::optional<std::chrono::seconds>(std::chrono::seconds(1)));
+    CHECK(parse_auto_start_after(R"({"auto_start_after_seconds":900})") ==
+          std::optional<std::chrono::seconds>(std::chrono::minutes(15)));
+    CHECK(parse_auto_start_after(R"({"auto_start_after_seconds":86400})") ==
+          std::optional<std::chrono::seconds>(std::chrono::hours(24)));
+}
+
+TEST_CASE("service stop: invalid auto_start_after_seconds bodies are rejected with 400") {
+    CHECK(rejected_status(R"({"auto_start_after_seconds":0})") == 400);
+    CHECK(rejected_status(R"({"auto_start_after_seconds":86401})") == 400);
+    CHECK(rejected_status(R"({"auto_start_after_seconds":-5})") == 400);
+    CHECK(rejected_status(R"({"auto_start_after_seconds":"900"})") == 400);
+    CHECK(rejected_status(R"({"auto_start_after_seconds":1.5})") == 400);
+    CHECK(rejected_status("[900]") == 400);
+    CHECK(rejected_status("{\"auto_start_after_seconds\":") == 400);
+}
+
+} // namespace keen_pbr3
+
+#endif // WITH_API
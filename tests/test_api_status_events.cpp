@@ -35,6 +35,7 @@ ApiContext make_status_context(SseBroadcaster &broadcaster,
         return std::map<std::string, api::ListRefreshStateValue>{};
       },
       [](const std::string &) { return TestRoutingResult{}; },
+      [](const std::string &) { return DomainMatchResult{}; },
       [] {},
       [] {},
       [](Config, std::string) { return ConfigApplyResult{}; },
@@ -35,6 +35,14 @@ ApiContext make_test_api_context(SseBroadcaster& broadcaster) {
             result.target = target;
             return result;
         },
+        [](const std::string& domain) {
+            DomainMatchResult result;
+            result.domain = domain;
+            result.route_matches.push_back(
+                DomainRouteMatch{1, "vpn", ListMatchInfo{"work", "example.com"}});
+            result.outbound = "vpn";
+            return result;
+        },
         []() {},
         []() {},
         [](Config, std::string) { return ConfigApplyResult{}; },
@@ -70,6 +78,39 @@ TEST_CASE("register_test_routing_handler: rejects empty target") {
     CHECK(body["error"] == "Field 'target' must not be empty");
 }
 
+TEST_CASE("register_test_routing_handler: dns match reports rule matches") {
+    SseBroadcaster broadcaster;
+    ApiConfig api_config;
+    api_config.listen = std::string(kApiListen);
+
+    ApiServer server(api_config);
+    auto ctx = make_test_api_context(broadcaster);
+    register_test_routing_handler(server, ctx);
+
+    server.start();
+
+    httplib::Client client("127.0.0.1", 18190);
+    const auto response =
+        client.Post("/api/dns/match", R"({"domain":"www.example.com"})", "application/json");
+    const auto empty =
+        client.Post("/api/dns/match", R"({"domain":""})", "application/json");
+    server.stop();
+
+    REQUIRE(response != nullptr);
+    CHECK(response->status == 200);
+    const auto body = nlohmann::json::parse(response->body);
+    CHECK(body["domain"] == "www.example.com");
+    CHECK(body["outbound"] == "vpn");
+    REQUIRE(body["route_matches"].size() == 1);
+    CHECK(body["route_matches"][0]["rule_index"] == 1);
+    CHECK(body["route_matches"][0]["list_match"]["list"] == "work");
+    CHECK(body["route_matches"][0]["list_match"]["via"] == "example.com");
+    CHECK(body["dns_server"].is_null());
+
+    REQUIRE(empty != nullptr);
+    CHECK(empty->status == 400);
+}
+
 } // namespace keen_pbr3
 
 #endif // WITH_API
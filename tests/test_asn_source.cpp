@@ -0,0 +1,137 @@
+#include <doctest/doctest.h>
+
+#include "../src/config/list_parser.hpp"
+#include "../src/lists/asn_source.hpp"
+#include "../src/lists/list_entry_visitor.hpp"
+
+#include <filesystem>
+#include <fstream>
+#include <sstream>
+#include <stdexcept>
+#include <string>
+#include <unistd.h>
+#include <vector>
+
+using namespace keen_pbr3;
+
+namespace {
+
+// Prefix-to-AS mapping in both accepted layouts: CAIDA pfx2as
+// ("<address>\t<len>\t<origin>") and "<prefix>/<len> <origin>".
+constexpr const char* kPrefixToAsFixture =
+    "# prefix-to-AS fixture\n"
+    "1.0.0.0\t24\t13335\n"
+    "1.1.1.0\t24\t13335\n"
+    "8.8.8.0\t24\t15169\n"
+    "\n"
+    "104.16.0.0\t13\t13335_209242\n"
+    "198.51.100.0\t24\t64500,13335\n"
+    "2606:4700::/32 AS13335\n"
+    "2001:4860::/32 AS15169\n"
+    "1.1.1.0/24 13335   # duplicate of the pfx2as line\n"
+    "203.0.113.0/24 213335\n";
+
+class TempDirectory {
+public:
+    TempDirectory() {
+        char pattern[] = "/tmp/keen-pbr-asn-source-XXXXXX";
+        const char* path = ::mkdtemp(pattern);
+        if (!path) throw std::runtime_error("mkdtemp failed");
+        path_ = path;
+    }
+    ~TempDirectory() { std::filesystem::remove_all(path_); }
+    const std::filesystem::path& path() const { return path_; }
+private:
+    std::filesystem::path path_;
+};
+
+void write_file(const std::filesystem::path& path, const std::string& content) {
+    std::ofstream out(path);
+    out << content;
+}
+
+} // namespace
+
+TEST_CASE("asn source: as:<ASN> urls are validated") {
+    CHECK(parse_asn_list_url("as:13335") == std::optional<uint32_t>(13335));
+    CHECK(parse_asn_list_url("as:1") == std::optional<uint32_t>(1));
+    CHECK(parse_asn_list_url("as:4294967295") == std::optional<uint32_t>(4294967295U));
+
+    CHECK(is_asn_list_url("as:AS13335"));
+    CHECK_FALSE(parse_asn_list_url("as:AS13335").has_value());
+    CHECK_FALSE(parse_asn_list_url("as:0").has_value());
+    CHECK_FALSE(parse_asn_list_url("as:013335").has_value());
+    CHECK_FALSE(parse_asn_list_url("as:4294967296").has_value());
+    CHECK_FALSE(parse_asn_list_url("as:-1").has_value());
+    CHECK_FALSE(parse_asn_list_url("as:").has_value());
+    CHECK_FALSE(parse_asn_list_url("as:13335 ").has_value());
+    CHECK_FALSE(is_asn_list_url("https://example.com/as:13335"));
+    CHECK_FALSE(parse_asn_list_url("https://example.com/as:13335").has_value());
+}
+
+TEST_CASE("asn source: url_template placeholders are replaced by the ASN") {
+    CHECK(expand_asn_url_template("https://asn.example/{asn}/ipv4.txt?as={asn}", 13335) ==
+          "https://asn.example/13335/ipv4.txt?as=13335");
+}
+
+TEST_CASE("asn source: prefixes of one AS are taken from a prefix-to-AS fixture") {
+    std::istringstream dump(kPrefixToAsFixture);
+
+    CHECK(asn_prefixes_from_dump(dump, 13335) == "1.0.0.0/24\n"
+                                                  "1.1.1.0/24\n"
+                                                  "104.16.0.0/13\n"
+                                                  "198.51.100.0/24\n"
+                                                  "2606:4700::/32\n");
+
+    std::istringstream other(kPrefixToAsFixture);
+    CHECK(asn_prefixes_from_dump(other, 15169) == "8.8.8.0/24\n2001:4860::/32\n");
+
+    std::istringstream unknown(kPrefixToAsFixture);
+    CHECK(asn_prefixes_from_dump(unknown, 64511).empty());
+}
+
+TEST_CASE("asn source: a malformed dump line is reported with its number") {
+    std::istringstream dump("1.1.1.0/24 13335\n"
+                            "1.0.0.0 13335\n");
+
+    try {
+        (void)asn_prefixes_from_dump(dump, 13335);
+        FAIL("expected the malformed line to be rejected");
+    } catch (const std::runtime_error& error) {
+        CHECK(std::string(error.what()).find("line 2") != std::string::npos);
+    }
+
+    std::istringstream bad_origin("1.1.1.0/24 AS\n");
+    CHECK_THROWS_AS(asn_prefixes_from_dump(bad_origin, 13335), std::runtime_error);
+}
+
+TEST_CASE("asn source: dump prefixes are cached and parsed as CIDR entries") {
+    TempDirectory temp;
+    const auto dump_file = temp.path() / "pfx2as.txt";
+    write_file(dump_file, kPrefixToAsFixture);
+    CacheManager cache(temp.path() / "cache");
+    cache.ensure_dir();
+
+    const auto first = cache_asn_dump_prefixes(cache, "cloudflare", "as:13335", dump_file);
+    CHECK(first.updated());
+    CHECK(cache.load_metadata("cloudflare").url == std::optional<std::string>("as:13335"));
+    CHECK(cache_asn_dump_prefixes(cache, "cloudflare", "as:13335", dump_file).not_modified());
+
+    std::vector<std::string> cidrs;
+    FunctionalVisitor visitor([&cidrs](EntryType type, std::string_view entry) {
+        CHECK(type == EntryType::Cidr);
+        cidrs.emplace_back(entry);
+    });
+    std::ifstream cached(cache.cache_path("cloudflare"));
+    ListParser::stream_parse(cached, visitor, "list 'cloudflare'");
+    CHECK(cidrs == std::vector<std::string>{"1.0.0.0/24", "1.1.1.0/24", "104.16.0.0/13",
+                                            "198.51.100.0/24", "2606:4700::/32"});
+
+    const auto missing = cache_asn_dump_prefixes(cache, "cloudflare", "as:64511", dump_file);
+    CHECK(missing.failed());
+    CHECK(missing.error_message.find("no prefixes for AS64511") != std::string::npos);
+    const auto unreadable =
+        cache_asn_dump_prefixes(cache, "cloudflare", "as:13335", temp.path() / "missing.txt");
+    CHECK(unreadable.failed());
+    CHECK(cache.has_cache("cloudflare"));
+}
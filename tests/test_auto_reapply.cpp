@@ -0,0 +1,147 @@
+#include <doctest/doctest.h>
+
+#include "../src/daemon/auto_reapply.hpp"
+
+#include <filesystem>
+#include <fstream>
+#include <stdexcept>
+#include <string>
+#include <unistd.h>
+
+namespace keen_pbr3 {
+namespace {
+
+using namespace std::chrono_literals;
+
+class TempDir {
+public:
+    TempDir() {
+        char pattern[] = "/tmp/keen-pbr-auto-reapply-XXXXXX";
+        const char* created = ::mkdtemp(pattern);
+        REQUIRE(created != nullptr);
+        path = created;
+    }
+    ~TempDir() { std::filesystem::remove_all(path); }
+    std::filesystem::path path;
+};
+
+// Whole seconds, as stored in the state file.
+const AutoReapplySchedule::Clock::time_point kNow{1760000000s};
+
+struct CountingReapply {
+    int calls{0};
+    bool accept{true};
+
+    AutoReapplySchedule::Reapply fn() {
+        return [this] {
+            ++calls;
+            return accept;
+        };
+    }
+};
+
+} // namespace
+
+TEST_CASE("parse_auto_reapply_after accepts seconds, minutes and hours up to a day") {
+    CHECK(parse_auto_reapply_after("90") == std::optional<std::chrono::seconds>(90s));
+    CHECK(parse_auto_reapply_after("90s") == std::optional<std::chrono::seconds>(90s));
+    CHECK(parse_auto_reapply_after("15m") == std::optional<std::chrono::seconds>(900s));
+    CHECK(parse_auto_reapply_after("2h") == std::optional<std::chrono::seconds>(7200s));
+    CHECK(parse_auto_reapply_after("24h") == std::optional<std::chrono::seconds>(86400s));
+    CHECK(parse_auto_reapply_after("86400") == std::optional<std::chrono::seconds>(86400s));
+}
+
+TEST_CASE("parse_auto_reapply_after rejects malformed and out-of-range durations") {
+    for (const char* text : {"", "s", "0", "0m", "-5", "+5", "1.5h", "10d", "5 m", "m5",
+                             "25h", "1441m", "86401", "99999999999"}) {
+        CAPTURE(text);
+        CHECK_FALSE(parse_auto_reapply_after(text).has_value());
+    }
+}
+
+TEST_CASE("auto-reapply fires once the deadline passes and clears the state file") {
+    TempDir dir;
+    const AutoReapplySchedule schedule((dir.path / "auto-reapply").string());
+    CountingReapply reapply;
+
+    CHECK(schedule.poll(kNow, reapply.fn()) == AutoReapplyPoll::Idle);
+
+    schedule.schedule(kNow + 15min);
+    CHECK(schedule.deadline() == std::optional(kNow + 15min));
+    CHECK(schedule.poll(kNow + 14min, reapply.fn()) == AutoReapplyPoll::Pending);
+    CHECK(reapply.calls == 0);
+
+    CHECK(schedule.poll(kNow + 15min, reapply.fn()) == AutoReapplyPoll::Reapplied);
+    CHECK(reapply.calls == 1);
+    CHECK_FALSE(std::filesystem::exists(dir.path / "auto-reapply"));
+
+    CHECK(schedule.poll(kNow + 16min, reapply.fn()) == AutoReapplyPoll::Idle);
+    CHECK(reapply.calls == 1);
+}
+
+TEST_CASE("auto-reapply survives a new schedule object reading the same state file") {
+    TempDir dir;
+    const std::string path = (dir.path / "auto-reapply").string();
+    AutoReapplySchedule(path).schedule(kNow + 90s);
+
+    CountingReapply reapply;
+    CHECK(AutoReapplySchedule(path).poll(kNow + 90s, reapply.fn()) == AutoReapplyPoll::Reapplied);
+    CHECK(reapply.calls == 1);
+}
+
+TEST_CASE("auto-reapply keeps the deadline while the daemon is busy") {
+    TempDir dir;
+    const AutoReapplySchedule schedule((dir.path / "auto-reapply").string());
+    schedule.schedule(kNow + 1min);
+    CountingReapply reapply;
+    reapply.accept = false;
+
+    CHECK(schedule.poll(kNow + 2min, reapply.fn()) == AutoReapplyPoll::Busy);
+    CHECK(schedule.deadline().has_value());
+
+    reapply.accept = true;
+    CHECK(schedule.poll(kNow + 3min, reapply.fn()) == AutoReapplyPoll::Reapplied);
+    CHECK(reapply.calls == 2);
+    CHECK_FALSE(schedule.deadline().has_value());
+}
+
+TEST_CASE("auto-reapply keeps the deadline when the reapply throws") {
+    TempDir dir;
+    const AutoReapplySchedule schedule((dir.path / "auto-reapply").string());
+    schedule.schedule(kNow);
+
+    CHECK_THROWS_WITH(schedule.poll(kNow, [] () -> bool {
+                          throw std::runtime_error("executor unavailable");
+                      }),
+                      "executor unavailable");
+    CHECK(schedule.deadline() == std::optional(kNow));
+}
+
+TEST_CASE("a cancelled auto-reapply never fires") {
+    TempDir dir;
+    const AutoReapplySchedule schedule((dir.path / "auto-reapply").string());
+    schedule.schedule(kNow + 1h);
+    schedule.schedule(kNow + 10min);
+    CHECK(schedule.deadline() == std::optional(kNow + 10min));
+
+    CHECK(schedule.cancel());
+    CHECK_FALSE(schedule.cancel());
+
+    CountingReapply reapply;
+    CHECK(schedule.poll(kNow + 2h, reapply.fn()) == AutoReapplyPoll::Idle);
+    CHECK(reapply.calls == 0);
+}
+
+TEST_CASE("an unreadable auto-reapply state file is ignored") {
+    TempDir dir;
+    const auto path = dir.path / "auto-reapply";
+    { std::ofstream(path) << "soon\n"; }
+    const AutoReapplySchedule schedule(path.string());
+
+    CountingReapply reapply;
+    CHECK_FALSE(schedule.deadline().has_value());
+    CHECK(schedule.poll(kNow, reapply.fn()) == AutoReapplyPoll::Idle);
+    CHECK(reapply.calls == 0);
+}
+
+} // namespace keen_pbr3
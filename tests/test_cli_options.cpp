@@ -0,0 +1,52 @@
+#include <doctest/doctest.h>
+
+#include "../src/cmd/cli_options.hpp"
+
+#include <string>
+#include <vector>
+
+using namespace keen_pbr3;
+
+namespace {
+
+CliOptions parse(std::vector<std::string> args) {
+    args.insert(args.begin(), "keen-pbr");
+    std::vector<char*> argv;
+    for (auto& arg : args) {
+        argv.push_back(arg.data());
+    }
+    return parse_args(static_cast<int>(argv.size()), argv.data());
+}
+
+} // namespace
+
+TEST_CASE("cli options: log level defaults to info and follows --log-level") {
+    CHECK(parse({"download"}).log_level == "info");
+    CHECK(parse({"--log-level", "debug", "download"}).log_level == "debug");
+}
+
+TEST_CASE("cli options: --quiet wins over --log-level in either order") {
+    CHECK(parse({"--quiet", "download"}).log_level == "error");
+    CHECK(parse({"-q", "--log-level", "debug", "download"}).log_level == "error");
+    CHECK(parse({"--log-level", "verbose", "--quiet", "apply"}).log_level == "error");
+    CHECK(parse({"--log-level", "info", "download", "-q", "--log-level", "debug"})
+              .log_level == "error");
+}
+
+TEST_CASE("cli options: flags combine with subcommands") {
+    const CliOptions opts = parse({"--config", "/tmp/c.json", "-q", "apply", "--routing-only"});
+    CHECK(opts.config_path == "/tmp/c.json");
+    CHECK(opts.apply_entries);
+    CHECK(opts.apply_routing_only);
+    CHECK_FALSE(opts.download_lists);
+}
+
+TEST_CASE("cli options: undo-routing takes an auto-reapply duration") {
+    CliOptions opts = parse({"undo-routing"});
+    CHECK(opts.undo_routing);
+    CHECK(opts.auto_reapply_after_seconds == 0);
+
+    opts = parse({"undo-routing", "--auto-reapply-after", "15m"});
+    CHECK(opts.undo_routing);
+    CHECK(opts.auto_reapply_after_seconds == 900);
+}
@@ -0,0 +1,105 @@
+#include <doctest/doctest.h>
+
+#include "../src/config/config.hpp"
+#include "../src/config/config_comments.hpp"
+
+#include <nlohmann/json.hpp>
+
+#include <string>
+
+using namespace keen_pbr3;
+
+namespace {
+
+constexpr const char* kCommentedConfig = R"(// keen-pbr config for the home router
+/* maintained by hand, see docs */
+{
+  // Daemon settings
+  "daemon": {
+    "cache_dir": "/tmp/keen-pbr", // tmpfs, lists are re-downloaded on boot
+    "pid_file": "/var/run/keen-pbr.pid"
+  },
+  // Lists used by the routing rules
+  "lists": {
+    "work": {
+      // corporate networks
+      "ip_cidrs": ["10.0.0.0/8"]
+    }
+  },
+  "route": {
+    "rules": [
+      // send work traffic through the VPN
+      { "list": ["work"], "outbound": "vpn" }
+    ]
+  }
+}
+// end of config
+)";
+
+// Mimics the API save path: load, change one field, serialize without comments.
+std::string save_with_change(const std::string& original,
+                             const nlohmann::json::json_pointer& pointer,
+                             const nlohmann::json& value,
+                             nlohmann::json* saved_out = nullptr) {
+    nlohmann::json json = nlohmann::json::parse(original, nullptr, true, true);
+    json[pointer] = value;
+    if (saved_out) *saved_out = json;
+    return preserve_config_comments(original, json.dump(1, '\t') + "\n");
+}
+
+bool contains(const std::string& haystack, const std::string& needle) {
+    return haystack.find(needle) != std::string::npos;
+}
+
+} // namespace
+
+TEST_CASE("config comments survive a load, modify, save cycle") {
+    nlohmann::json expected;
+    const std::string saved = save_with_change(
+        kCommentedConfig, nlohmann::json::json_pointer("/daemon/pid_file"), "/run/keen-pbr.pid",
+        &expected);
+
+    CHECK(saved.rfind("// keen-pbr config for the home router\n"
+                      "/* maintained by hand, see docs */\n{", 0) == 0);
+    CHECK(contains(saved, "\t// Daemon settings\n\t\"daemon\": {"));
+    CHECK(contains(saved,
+                   "\"cache_dir\": \"/tmp/keen-pbr\", // tmpfs, lists are re-downloaded on boot\n"));
+    CHECK(contains(saved, "\t// Lists used by the routing rules\n\t\"lists\": {"));
+    CHECK(contains(saved, "\t\t\t// corporate networks\n\t\t\t\"ip_cidrs\": ["));
+    CHECK(contains(saved, "\t\t\t// send work traffic through the VPN\n\t\t\t{"));
+    const std::string footer = "}\n// end of config\n";
+    CHECK(saved.compare(saved.size() - footer.size(), footer.size(), footer) == 0);
+
+    CHECK(nlohmann::json::parse(saved, nullptr, true, true) == expected);
+    CHECK_NOTHROW((void)parse_config(saved));
+}
+
+TEST_CASE("config comments of removed members are dropped") {
+    nlohmann::json json = nlohmann::json::parse(kCommentedConfig, nullptr, true, true);
+    json["lists"].erase("work");
+    const std::string saved =
+        preserve_config_comments(kCommentedConfig, json.dump(1, '\t') + "\n");
+
+    CHECK_FALSE(contains(saved, "corporate networks"));
+    CHECK(contains(saved, "// Lists used by the routing rules"));
+    CHECK(nlohmann::json::parse(saved, nullptr, true, true) == json);
+}
+
+TEST_CASE("config comments inside a resized array are dropped") {
+    nlohmann::json json = nlohmann::json::parse(kCommentedConfig, nullptr, true, true);
+    json["route"]["rules"].insert(json["route"]["rules"].begin(),
+                                  {{"list", {"other"}}, {"outbound", "wan"}});
+    const std::string saved =
+        preserve_config_comments(kCommentedConfig, json.dump(1, '\t') + "\n");
+
+    CHECK_FALSE(contains(saved, "send work traffic through the VPN"));
+    CHECK(contains(saved, "// Daemon settings"));
+    CHECK(nlohmann::json::parse(saved, nullptr, true, true) == json);
+}
+
+TEST_CASE("config comments leave comment-free and unreadable originals alone") {
+    const std::string formatted = "{\n\t\"daemon\": {}\n}\n";
+    CHECK(preserve_config_comments("{\"daemon\": {}}", formatted) == formatted);
+    CHECK(preserve_config_comments("// half written\n{\"daemon\": ", formatted) == formatted);
+    CHECK(preserve_config_comments("", formatted) == formatted);
+}
@@ -0,0 +1,201 @@
+#include <doctest/doctest.h>
+
+#include "../src/cmd/config_explain.hpp"
+#include "../src/lists/list_streamer.hpp"
+
+#include <algorithm>
+#include <cstdlib>
+#include <filesystem>
+#include <fstream>
+#include <sstream>
+#include <string>
+#include <vector>
+
+using namespace keen_pbr3;
+
+namespace {
+
+const char* kSampleConfig = R"({
+    "fwmark":{"start":"0x10000","mask":"0xff0000"},
+    "iproute":{"table_start":100},
+    "lists":{"streaming":{"domains":["example.com"]}},
+    "outbounds":[
+        {"tag":"vpn","type":"interface","interface":"wg0","gateway":"10.8.0.1"},
+        {"tag":"block","type":"blackhole"}
+    ],
+    "route":{"rules":[
+        {"list":["streaming"],"outbound":"vpn"},
+        {"dest_addr":"203.0.113.0/24","proto":"tcp","dest_port":"443","outbound":"block"},
+        {"list":["streaming"],"outbound":"vpn","enabled":false}
+    ]}
+})";
+
+bool contains(const std::vector<std::string>& lines, const std::string& line) {
+    return std::find(lines.begin(), lines.end(), line) != lines.end();
+}
+
+} // namespace
+
+TEST_CASE("config explain: list rule renders set, ip rule and route commands") {
+    const auto rules = explain_config(parse_config(kSampleConfig));
+    REQUIRE(rules.size() == 3);
+
+    const auto& vpn = rules[0];
+    CHECK(vpn.outbound == "vpn");
+    CHECK(vpn.action == RuleActionType::Mark);
+    CHECK(vpn.fwmark == 0x10000);
+    REQUIRE(vpn.sets.size() == 4);
+    CHECK(vpn.sets[0].set_name == "kpbr4_streaming");
+    CHECK(vpn.sets[0].firewall_commands == std::vector<std::string>{
+        "iptables -t mangle -A KeenPbrTable -m set --match-set kpbr4_streaming dst "
+        "-j MARK --set-xmark 0x10000/0xff0000",
+        "iptables -t mangle -A KeenPbrTable -m set --match-set kpbr4_streaming dst "
+        "-j CONNMARK --save-mark --mask 0xff0000",
+        "iptables -t mangle -A KeenPbrTable -m set --match-set kpbr4_streaming dst "
+        "-j RETURN",
+    });
+    CHECK(vpn.sets[1].set_name == "kpbr6_streaming");
+    CHECK(vpn.sets[1].firewall_commands.front() ==
+          "ip6tables -t mangle -A KeenPbrTable -m set --match-set kpbr6_streaming dst "
+          "-j MARK --set-xmark 0x10000/0xff0000");
+    CHECK(vpn.sets[2].set_name == "kpbr4d_streaming");
+    CHECK(vpn.sets[3].set_name == "kpbr6d_streaming");
+    CHECK(vpn.firewall_commands.empty());
+
+    CHECK(contains(vpn.ip_rules, "ip rule add fwmark 0x10000/0xff0000 lookup 100 priority 100"));
+    CHECK(contains(vpn.ip_rules, "ip -6 rule add fwmark 0x10000/0xff0000 lookup 100 priority 100"));
+    CHECK(contains(vpn.routes, "ip route add default via 10.8.0.1 dev wg0 table 100"));
+}
+
+TEST_CASE("config explain: selector-only blackhole rule renders DROP per family") {
+    const auto rules = explain_config(parse_config(kSampleConfig));
+    REQUIRE(rules.size() == 3);
+
+    const auto& block = rules[1];
+    CHECK(block.action == RuleActionType::Drop);
+    CHECK(block.sets.empty());
+    CHECK(block.firewall_commands == std::vector<std::string>{
+        "iptables -t mangle -A KeenPbrTable -d 203.0.113.0/24 -p tcp --dport 443 -j DROP",
+    });
+    CHECK(block.ip_rules.empty());
+    CHECK(block.routes.empty());
+}
+
+TEST_CASE("config explain: disabled rules and IPv6 opt-out") {
+    const auto rules = explain_config(parse_config(kSampleConfig), false);
+    REQUIRE(rules.size() == 3);
+
+    CHECK(rules[2].action == RuleActionType::Skip);
+    CHECK(rules[2].sets.empty());
+
+    REQUIRE(rules[0].sets.size() == 2);
+    CHECK(rules[0].sets[0].set_name == "kpbr4_streaming");
+    CHECK(rules[0].sets[1].set_name == "kpbr4d_streaming");
+    CHECK(contains(rules[0].ip_rules, "ip rule add fwmark 0x10000/0xff0000 lookup 100 priority 100"));
+    CHECK_FALSE(contains(rules[0].ip_rules,
+                         "ip -6 rule add fwmark 0x10000/0xff0000 lookup 100 priority 100"));
+
+    const std::string report = format_config_explanation(rules);
+    CHECK(report.find("Rule #1 -> vpn (mark 0x10000)\n  lists: streaming\n"
+                      "  ipset kpbr4_streaming:\n") != std::string::npos);
+    CHECK(report.find("Rule #3 -> vpn (skip)\n  lists: streaming\n"
+                      "  nothing is installed") != std::string::npos);
+}
+
+TEST_CASE("config explain: ip command rendering") {
+    RuleSpec guard;
+    guard.fwmark = 0x20000;
+    guard.fwmask = 0xff0000;
+    guard.priority = 201;
+    guard.family = AF_INET;
+    guard.action = RuleAction::unreachable;
+    CHECK(format_ip_rule_commands(guard) == std::vector<std::string>{
+        "ip rule add fwmark 0x20000/0xff0000 unreachable priority 201",
+    });
+
+    RouteSpec unreachable;
+    unreachable.destination = "default";
+    unreachable.table = 101;
+    unreachable.family = AF_INET6;
+    unreachable.unreachable = true;
+    unreachable.metric = 4278198272u;
+    CHECK(format_ip_route_command(unreachable) ==
+          "ip -6 route add unreachable default table 101 metric 4278198272");
+
+    RouteSpec multipath;
+    multipath.destination = "default";
+    multipath.table = 102;
+    multipath.family = AF_INET;
+    multipath.nexthops = {RouteNexthop{"wg0", std::nullopt, 1}, RouteNexthop{"wg1", "10.9.0.1", 2}};
+    CHECK(format_ip_route_command(multipath) ==
+          "ip route add default nexthop dev wg0 weight 1 nexthop dev wg1 via 10.9.0.1 weight 2 "
+          "table 102");
+}
+
+TEST_CASE("config explain: config piped via stdin") {
+    std::istringstream stdin_stream(kSampleConfig);
+    const auto config = parse_config(read_config_source("-", stdin_stream));
+    const auto rules = explain_config(config);
+    REQUIRE(rules.size() == 3);
+    CHECK(rules[0].outbound == "vpn");
+    CHECK(stdin_stream.peek() == std::char_traits<char>::eof());
+
+    CHECK_THROWS_AS(read_config_source("/nonexistent/keen-pbr.json", stdin_stream), ConfigError);
+}
+
+TEST_CASE("config export-script: renders a runnable shell script") {
+    const auto temp_dir = std::filesystem::temp_directory_path() / "keen-pbr-export-script";
+    std::filesystem::create_directories(temp_dir);
+    const CacheManager cache(temp_dir);
+    ListStreamer streamer(cache);
+
+    auto config = parse_config(R"({
+        "fwmark":{"start":"0x10000","mask":"0xff0000"},
+        "iproute":{"table_start":100},
+        "lists":{"office":{"ip_cidrs":["10.0.0.0/8","2001:db8::/32"],"ttl_ms":600000}},
+        "outbounds":[{"tag":"vpn","type":"interface","interface":"wg0","gateway":"10.8.0.1"}],
+        "route":{"rules":[{"list":["office"],"outbound":"vpn"},{"list":["office"],"outbound":"vpn"}]}
+    })");
+    const auto rules = explain_config(config);
+    const auto entries = collect_explained_set_entries(config, rules, streamer);
+    CHECK(entries.at("kpbr4_office") == std::vector<std::string>{"10.0.0.0/8"});
+    CHECK(entries.at("kpbr6_office") == std::vector<std::string>{"2001:db8::/32"});
+    CHECK(entries.count("kpbr4d_office") == 0);
+
+    const std::string script = format_config_script(rules, entries);
+    CHECK(script.rfind("#!/bin/sh\n", 0) == 0);
+    CHECK(script.find("\nset -e\n") != std::string::npos);
+    CHECK(script.find("iptables -t mangle -C PREROUTING -j KeenPbrTable 2>/dev/null || "
+                      "iptables -t mangle -A PREROUTING -j KeenPbrTable\n") != std::string::npos);
+    CHECK(script.find("ip6tables -t mangle -N KeenPbrTable") != std::string::npos);
+    CHECK(script.find("ipset create kpbr4_office hash:net family inet -exist\n"
+                      "ipset add kpbr4_office 10.0.0.0/8 -exist\n") != std::string::npos);
+    CHECK(script.find("ipset create kpbr6_office hash:net family inet6 -exist\n"
+                      "ipset add kpbr6_office 2001:db8::/32 -exist\n") != std::string::npos);
+    CHECK(script.find("ipset create kpbr4d_office hash:net family inet timeout 600 -exist\n") !=
+          std::string::npos);
+    CHECK(script.find("iptables -t mangle -A KeenPbrTable -m set --match-set kpbr4_office dst "
+                      "-j MARK --set-xmark 0x10000/0xff0000\n") != std::string::npos);
+    CHECK(script.find("\nip route add default via 10.8.0.1 dev wg0 table 100\n") !=
+          std::string::npos);
+
+    // The second rule reuses the sets, ip rules and routes of the first.
+    const auto count = [&script](const std::string& needle) {
+        size_t n = 0;
+        for (auto pos = script.find(needle); pos != std::string::npos;
+             pos = script.find(needle, pos + 1)) {
+            ++n;
+        }
+        return n;
+    };
+    CHECK(count("ipset create kpbr4_office ") == 1);
+    CHECK(count("ip rule add fwmark 0x10000/0xff0000 lookup 100 priority 100\n") == 1);
+    CHECK(count("ip route add default via 10.8.0.1 dev wg0 table 100\n") == 1);
+    CHECK(count("-j MARK --set-xmark 0x10000/0xff0000\n") == 8);
+
+    const auto script_path = temp_dir / "export.sh";
+    std::ofstream(script_path) << script;
+    CHECK(std::system(("sh -n " + script_path.string()).c_str()) == 0);
+
+    std::filesystem::remove_all(temp_dir);
+}
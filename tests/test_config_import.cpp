@@ -0,0 +1,129 @@
+#include <doctest/doctest.h>
+
+#include "../src/config/config_import.hpp"
+
+#include <nlohmann/json.hpp>
+
+#include <filesystem>
+#include <fstream>
+#include <optional>
+#include <string>
+#include <unistd.h>
+
+namespace keen_pbr3 {
+namespace {
+
+constexpr const char* kExportedConfig = R"(// exported from the router
+{
+  "outbounds": [
+    { "tag": "vpn", "type": "interface", "interface": "wg0" } // WireGuard
+  ],
+  "lists": { "work": { "ip_cidrs": ["10.0.0.0/8"] } },
+  "route": { "rules": [{ "list": ["work"], "outbound": "vpn" }] },
+  "dns": {
+    "servers": [{ "tag": "isp", "address": "192.0.2.53" }],
+    "fallback": ["isp"],
+    "system_resolver": { "address": "127.0.0.1" }
+  }
+}
+)";
+
+class TempDir {
+public:
+    TempDir() {
+        char pattern[] = "/tmp/keen-pbr-config-import-XXXXXX";
+        const char* created = ::mkdtemp(pattern);
+        REQUIRE(created != nullptr);
+        path = created;
+    }
+    ~TempDir() { std::filesystem::remove_all(path); }
+    std::filesystem::path path;
+};
+
+void write_file(const std::filesystem::path& path, const std::string& text) {
+    std::ofstream output(path);
+    output << text;
+}
+
+std::string read_file(const std::filesystem::path& path) {
+    std::ifstream input(path);
+    return {std::istreambuf_iterator<char>(input), std::istreambuf_iterator<char>()};
+}
+
+// Stands in for the ApplyConfig lifecycle operation: records what was
+// submitted and commits it the way the daemon does after a successful apply.
+struct RecordingSubmit {
+    std::string config_path;
+    std::optional<std::string> submitted_text;
+    std::optional<Config> submitted_config;
+
+    ConfigImportSubmitFn fn() {
+        return [this](Config config, std::string text) {
+            submitted_config = std::move(config);
+            submitted_text = text;
+            commit_imported_config(config_path, text);
+            return std::string("op-1");
+        };
+    }
+};
+
+} // namespace
+
+TEST_CASE("config import: exported config round-trips byte for byte") {
+    TempDir dir;
+    const auto config_path = dir.path / "config.json";
+    write_file(config_path, kExportedConfig);
+
+    const std::string exported = export_config_text(config_path.string());
+    CHECK(exported == kExportedConfig);
+
+    RecordingSubmit submit{config_path.string()};
+    CHECK(import_config(exported, submit.fn()) == "op-1");
+
+    REQUIRE(submit.submitted_text.has_value());
+    CHECK(*submit.submitted_text == kExportedConfig);
+    REQUIRE(submit.submitted_config.has_value());
+    CHECK(submit.submitted_config->outbounds->front().tag == "vpn");
+    CHECK(read_file(config_path) == kExportedConfig);
+    CHECK(read_file(dir.path / "config.json.bak") == kExportedConfig);
+}
+
+TEST_CASE("config import: replacing a config keeps the previous file as backup") {
+    TempDir dir;
+    const auto config_path = dir.path / "config.json";
+    write_file(config_path, "// previous\n{}\n");
+
+    RecordingSubmit submit{config_path.string()};
+    import_config(kExportedConfig, submit.fn());
+
+    CHECK(read_file(config_path) == kExportedConfig);
+    CHECK(read_file(dir.path / "config.json.bak") == "// previous\n{}\n");
+}
+
+TEST_CASE("config import: an invalid body is rejected before anything is submitted") {
+    TempDir dir;
+    const auto config_path = dir.path / "config.json";
+    write_file(config_path, "// previous\n{}\n");
+    RecordingSubmit submit{config_path.string()};
+
+    SUBCASE("malformed JSON") {
+        CHECK_THROWS_AS(import_config("{ \"lists\": ", submit.fn()), ConfigError);
+    }
+    SUBCASE("schema violation") {
+        CHECK_THROWS_AS(
+            import_config(R"({"route":{"rules":[{"list":["missing"],"outbound":"vpn"}]}})",
+                          submit.fn()),
+            ConfigValidationError);
+    }
+
+    CHECK_FALSE(submit.submitted_text.has_value());
+    CHECK(read_file(config_path) == "// previous\n{}\n");
+    CHECK_FALSE(std::filesystem::exists(dir.path / "config.json.bak"));
+}
+
+TEST_CASE("config import: export of a missing file is empty") {
+    TempDir dir;
+    CHECK(export_config_text((dir.path / "absent.json").string()).empty());
+}
+
+} // namespace keen_pbr3
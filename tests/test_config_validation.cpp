@@ -153,6 +153,106 @@ TEST_CASE("list name: dot in name is rejected") {
     CHECK_THROWS_AS(parse_test_config(list_config_json("my.list")), ConfigError);
 }
 
+TEST_CASE("list sha256: requires url and a 64-character hex digest") {
+    const std::string digest =
+        "E3B0C44298FC1C149AFBF4C8996FB92427AE41E4649B934CA495991B7852B855";
+    CHECK_NOTHROW(parse_test_config(list_config_json(
+        "pinned", R"({"url":"https://example.com/list.txt","sha256":")" + digest + "\"}")));
+    CHECK_THROWS_AS(parse_test_config(list_config_json(
+                        "pinned", R"({"ip_cidrs":["10.0.0.1"],"sha256":")" + digest + "\"}")),
+                    ConfigError);
+    CHECK_THROWS_AS(parse_test_config(list_config_json(
+                        "pinned", R"({"url":"https://example.com/list.txt","sha256":"abc"})")),
+                    ConfigError);
+}
+
+TEST_CASE("list validate_content: requires url") {
+    CHECK_NOTHROW(parse_test_config(list_config_json(
+        "checked", R"({"url":"https://example.com/list.txt","validate_content":true})")));
+    CHECK_THROWS_AS(parse_test_config(list_config_json(
+                        "checked", R"({"ip_cidrs":["10.0.0.1"],"validate_content":true})")),
+                    ConfigError);
+}
+
+TEST_CASE("list fallback_urls: require url and http(s) mirrors") {
+    CHECK_NOTHROW(parse_test_config(list_config_json(
+        "mirrored",
+        R"({"url":"https://example.com/list.txt","fallback_urls":["http://mirror.example/list.txt"]})")));
+
+    const auto without_url = validate_issues(list_config_json(
+        "mirrored", R"({"ip_cidrs":["10.0.0.1"],"fallback_urls":["https://mirror.example/list.txt"]})"));
+    REQUIRE(without_url.size() == 1);
+    CHECK(without_url[0].path == "lists.mirrored.fallback_urls");
+
+    const auto bad_scheme = validate_issues(list_config_json(
+        "mirrored",
+        R"({"url":"https://example.com/list.txt","fallback_urls":["https://ok.example/a","ftp://mirror.example/b"]})"));
+    REQUIRE(bad_scheme.size() == 1);
+    CHECK(bad_scheme[0].path == "lists.mirrored.fallback_urls[1]");
+}
+
+TEST_CASE("list as:<ASN> url: needs a valid ASN and a configured asn_source") {
+    const auto with_source = [](const std::string& list_body, const std::string& source) {
+        auto config = nlohmann::json::parse(list_config_json("cloudflare", list_body));
+        config["asn_source"] = nlohmann::json::parse(source);
+        return config.dump();
+    };
+
+    CHECK(validate_issues(with_source(R"({"url":"as:13335"})",
+                                      R"({"file":"/opt/pfx2as.txt"})")).empty());
+    CHECK(validate_issues(with_source(R"({"url":"as:13335"})",
+                                      R"({"url_template":"https://asn.example/{asn}.txt"})"))
+              .empty());
+
+    const auto bad_asn = validate_issues(
+        with_source(R"({"url":"as:4294967296"})", R"({"file":"/opt/pfx2as.txt"})"));
+    REQUIRE(bad_asn.size() == 1);
+    CHECK(bad_asn[0].path == "lists.cloudflare.url");
+
+    const auto without_source = validate_issues(list_config_json("cloudflare", R"({"url":"as:13335"})"));
+    REQUIRE(without_source.size() == 1);
+    CHECK(without_source[0].message == "List as:<ASN> url requires asn_source");
+
+    const auto with_mirrors = validate_issues(with_source(
+        R"({"url":"as:13335","fallback_urls":["https://mirror.example/list.txt"]})",
+        R"({"file":"/opt/pfx2as.txt"})"));
+    REQUIRE(with_mirrors.size() == 1);
+    CHECK(with_mirrors[0].path == "lists.cloudflare.fallback_urls");
+
+    const auto both = validate_issues(with_source(
+        R"({"url":"as:13335"})",
+        R"({"file":"/opt/pfx2as.txt","url_template":"https://asn.example/{asn}.txt"})"));
+    REQUIRE(both.size() == 1);
+    CHECK(both[0].path == "asn_source");
+
+    const auto no_placeholder = validate_issues(
+        with_source(R"({"url":"as:13335"})", R"({"url_template":"https://asn.example/list.txt"})"));
+    REQUIRE(no_placeholder.size() == 1);
+    CHECK(no_placeholder[0].path == "asn_source.url_template");
+}
+
+TEST_CASE("duplicate keys: repeated list names are rejected instead of last-wins") {
+    const auto issues = parse_issues(R"({"lists":{
+        "google":{"ip_cidrs":["10.0.0.1"]},
+        "google":{"ip_cidrs":["10.0.0.2"]}}})");
+    REQUIRE(issues.size() == 1);
+    CHECK(issues[0].path == "lists.google");
+    CHECK(issues[0].message == "Duplicate key 'google'");
+}
+
+TEST_CASE("duplicate keys: nested repeats report their path") {
+    const auto issues = parse_issues(R"({
+        "outbounds":[
+            {"tag":"vpn","type":"interface","interface":"wg0"},
+            {"tag":"wan","type":"interface","interface":"eth0","interface":"eth1"}],
+        "lists":{"local":{"ip_cidrs":["10.0.0.1"],"ip_cidrs":["10.0.0.2"]}}})");
+    REQUIRE(issues.size() == 2);
+    CHECK(issues[0].path == "outbounds[1].interface");
+    CHECK(issues[1].path == "lists.local.ip_cidrs");
+
+    CHECK(parse_issues(R"({"lists":{"a":{"domains":["x.com"]},"b":{"domains":["x.com"]}}})").empty());
+}
+
 // =============================================================================
 // DNS server detour validation
 // =============================================================================
@@ -456,6 +556,21 @@ TEST_CASE("dns tag: uppercase is rejected") {
     CHECK_THROWS_AS(parse_test_config(json), ConfigError);
 }
 
+TEST_CASE("dns edns_packet_max: value within range parses") {
+    auto cfg = parse_test_config(R"({"dns":{"edns_packet_max":1232}})");
+    REQUIRE(cfg.dns.has_value());
+    CHECK(cfg.dns->edns_packet_max == 1232);
+}
+
+TEST_CASE("dns edns_packet_max: value out of range is rejected") {
+    for (const char* value : {"511", "4097"}) {
+        const auto issues =
+            validate_issues(std::string(R"({"dns":{"edns_packet_max":)") + value + "}}");
+        REQUIRE(issues.size() == 1);
+        CHECK(issues[0].path == "dns.edns_packet_max");
+    }
+}
+
 TEST_CASE("dns test server: valid listen parses") {
     std::string json = R"({"dns":{"dns_test_server":{"listen":"127.0.0.88:53"}}})";
     auto cfg = parse_test_config(json);
@@ -488,6 +603,24 @@ TEST_CASE("dns test server: invalid answer IPv4 is rejected") {
     CHECK_THROWS_AS(parse_test_config(json), ConfigError);
 }
 
+TEST_CASE("dns test server: domain suffix must be a lowercase domain name") {
+    auto cfg = parse_test_config(
+        R"({"dns":{"dns_test_server":{"listen":"127.0.0.88:53","domain_suffix":"probe.home-lab.lan"}}})");
+    REQUIRE(cfg.dns.has_value());
+    REQUIRE(cfg.dns->dns_test_server.has_value());
+    CHECK(cfg.dns->dns_test_server->domain_suffix.value_or("") == "probe.home-lab.lan");
+
+    for (const char* suffix : {"", ".probe.lan", "probe..lan", "probe.lan.", "-probe.lan",
+                               "Probe.lan", "probe_1.lan", "probe lan"}) {
+        CAPTURE(suffix);
+        const auto issues = validate_issues(
+            std::string(R"({"dns":{"dns_test_server":{"listen":"127.0.0.88:53","domain_suffix":")") +
+            suffix + "\"}}}");
+        REQUIRE(issues.size() == 1);
+        CHECK(issues[0].path == "dns.dns_test_server");
+    }
+}
+
 TEST_CASE("config validation: accepts system_resolver") {
     auto cfg = parse_test_config(R"({
         "dns": {
@@ -641,6 +774,13 @@ TEST_CASE("daemon max_file_size_bytes: zero is rejected") {
                     ConfigValidationError);
 }
 
+TEST_CASE("daemon bootstrap_dns: accepts DNS server addresses and rejects others") {
+    CHECK_NOTHROW(parse_test_config(R"({"daemon":{"bootstrap_dns":"8.8.8.8"}})"));
+    CHECK_NOTHROW(parse_test_config(R"({"daemon":{"bootstrap_dns":"[2001:4860:4860::8888]:53"}})"));
+    CHECK_THROWS_AS(parse_test_config(R"({"daemon":{"bootstrap_dns":"dns.google"}})"),
+                    ConfigValidationError);
+}
+
 TEST_CASE("inline domains allow comments and malformed entries") {
     CHECK_NOTHROW(parse_test_config(
         R"({"lists":{"domains":{"domains":["*.google.com","_dns._udp.example.com.","# package note","   ","bad/domain"]}}})"));
@@ -900,6 +1040,25 @@ TEST_CASE("route inbound_interfaces: valid future interface need not exist") {
         R"({"route":{"inbound_interfaces":["vpn_future@1"],"rules":[]}})"));
 }
 
+TEST_CASE("route rule inbound_interfaces: counts as a condition and is validated") {
+    const auto cfg = parse_test_config(
+        R"({"outbounds":[{"tag":"vpn","type":"interface","interface":"eth0"}],"route":{"rules":[{"inbound_interfaces":["br0"],"outbound":"vpn"}]}})");
+    REQUIRE(cfg.route->rules->front().inbound_interfaces.has_value());
+    CHECK(cfg.route->rules->front().inbound_interfaces->front() == "br0");
+
+    const auto issues = parse_issues(
+        R"({"route":{"rules":[{"list":["ads"],"inbound_interfaces":["br0","bad/name"],"outbound":"vpn"}]}})");
+    REQUIRE_FALSE(issues.empty());
+    CHECK(issues.front().path == "route.rules[0].inbound_interfaces[1]");
+}
+
+TEST_CASE("route rule inbound_interfaces: must be listed in route.inbound_interfaces") {
+    const auto issues = validate_issues(
+        R"({"lists":{"ads":{"domains":["example.com"]}},"outbounds":[{"tag":"vpn","type":"interface","interface":"eth0"}],"route":{"inbound_interfaces":["br0"],"rules":[{"list":["ads"],"inbound_interfaces":["br0","wg0"],"outbound":"vpn"}]}})");
+    REQUIRE(issues.size() == 1);
+    CHECK(issues[0].path == "route.rules[0].inbound_interfaces[1]");
+}
+
 // =============================================================================
 // is_reserved_table
 // =============================================================================
@@ -1064,6 +1223,23 @@ TEST_CASE("fwmark start and mask: non-string values are rejected during config p
     CHECK_THROWS_AS(parse_test_config(R"({"fwmark":{"mask":16711680}})"), ConfigValidationError);
 }
 
+TEST_CASE("fwmark start and mask: decimal and hex strings parse to the same value") {
+    const auto hex = parse_test_config(R"({"fwmark":{"start":"0x105","mask":"0x00FF0000"}})");
+    const auto dec = parse_test_config(R"({"fwmark":{"start":"261","mask":"16711680"}})");
+
+    CHECK(fwmark_start_value(hex.fwmark.value_or(FwmarkConfig{})) == 0x105);
+    CHECK(fwmark_start_value(dec.fwmark.value_or(FwmarkConfig{})) == 0x105);
+    CHECK(fwmark_mask_value(dec.fwmark.value_or(FwmarkConfig{})) == 0x00FF0000);
+}
+
+TEST_CASE("fwmark start and mask: decimal strings still go through mask validation") {
+    // 252641280 == 0x0F0F0000: non-consecutive F nibbles.
+    CHECK_THROWS_AS(parse_test_config(R"({"fwmark":{"mask":"252641280"}})"), ConfigValidationError);
+    CHECK_THROWS_AS(parse_test_config(R"({"fwmark":{"start":"4294967296"}})"), ConfigValidationError);
+    CHECK_THROWS_AS(parse_test_config(R"({"fwmark":{"start":"12ab"}})"), ConfigValidationError);
+    CHECK_THROWS_AS(parse_test_config(R"({"fwmark":{"start":"0x"}})"), ConfigValidationError);
+}
+
 TEST_CASE("config parsing returns all collected validation errors") {
     const std::string json = R"({
         "lists_autoupdate": {
@@ -1209,6 +1385,16 @@ TEST_CASE("daemon.clear_dynamic_sets_on_apply: rejects non-boolean value") {
     CHECK(issues[0].path == "daemon.clear_dynamic_sets_on_apply");
 }
 
+TEST_CASE("daemon.firewall_self_heal: accepts boolean and rejects other values") {
+    const auto cfg = parse_test_config(R"({"daemon":{"firewall_self_heal":true}})");
+    REQUIRE(cfg.daemon->firewall_self_heal.has_value());
+    CHECK(*cfg.daemon->firewall_self_heal);
+
+    const auto issues = parse_issues(R"({"daemon":{"firewall_self_heal":1}})");
+    REQUIRE(issues.size() == 1);
+    CHECK(issues[0].path == "daemon.firewall_self_heal");
+}
+
 TEST_CASE("daemon.ipv6_enabled: defaults to true behavior when absent") {
     auto cfg = parse_test_config(R"({"daemon":{}})");
     REQUIRE(cfg.daemon.has_value());
@@ -1292,12 +1478,47 @@ TEST_CASE("interface outbound: empty interface name is rejected") {
     CHECK(issues[0].path == "outbounds.wan.interface");
 }
 
+TEST_CASE("multipath outbound: nexthops must reference interface outbounds") {
+    CHECK(validate_issues(R"({"outbounds":[
+        {"tag":"wan1","type":"interface","interface":"eth0"},
+        {"tag":"wan2","type":"interface","interface":"eth1"},
+        {"tag":"ecmp","type":"multipath","nexthops":[{"outbound":"wan1","weight":3},{"outbound":"wan2"}]}
+    ]})").empty());
+
+    const auto issues = validate_issues(R"({"outbounds":[
+        {"tag":"wan1","type":"interface","interface":"eth0"},
+        {"tag":"lan","type":"table","table":200},
+        {"tag":"ecmp","type":"multipath","nexthops":[
+            {"outbound":"wan1","weight":0},{"outbound":"wan1"},{"outbound":"lan"},{"outbound":"missing"}]}
+    ]})");
+    REQUIRE(issues.size() == 4);
+    CHECK(issues[0].path == "outbounds.ecmp.nexthops[0].weight");
+    CHECK(issues[1].message.find("more than once") != std::string::npos);
+    CHECK(issues[2].message.find("not an interface outbound") != std::string::npos);
+    CHECK(issues[3].message.find("unknown outbound tag 'missing'") != std::string::npos);
+}
+
+TEST_CASE("multipath outbound: empty nexthops is rejected") {
+    const auto issues = validate_issues(R"({"outbounds":[{"tag":"ecmp","type":"multipath"}]})");
+    REQUIRE(issues.size() == 1);
+    CHECK(issues[0].path == "outbounds.ecmp.nexthops");
+}
+
 TEST_CASE("daemon execution timeout must be positive") {
     const auto issues = validate_issues(R"({"daemon":{"exec_timeout_seconds":0}})");
     REQUIRE(issues.size() == 1);
     CHECK(issues[0].path == "daemon.exec_timeout_seconds");
 }
 
+TEST_CASE("lists autoupdate force re-import interval may be zero but not negative") {
+    CHECK(validate_issues(R"({"lists_autoupdate":{"force_reimport_interval_hours":0}})").empty());
+    CHECK(validate_issues(R"({"lists_autoupdate":{"force_reimport_interval_hours":24}})").empty());
+    const auto issues =
+        validate_issues(R"({"lists_autoupdate":{"force_reimport_interval_hours":-1}})");
+    REQUIRE(issues.size() == 1);
+    CHECK(issues[0].path == "lists_autoupdate.force_reimport_interval_hours");
+}
+
 TEST_CASE("daemon execution kill grace may be zero but not negative") {
     CHECK(validate_issues(R"({"daemon":{"exec_kill_grace_seconds":0}})").empty());
     const auto issues = validate_issues(R"({"daemon":{"exec_kill_grace_seconds":-1}})");
@@ -1305,8 +1526,64 @@ TEST_CASE("daemon execution kill grace may be zero but not negative") {
     CHECK(issues[0].path == "daemon.exec_kill_grace_seconds");
 }
 
+TEST_CASE("api keenetic interface cache lifetime is bounded") {
+    CHECK(validate_issues(R"({"api":{"keenetic_interface_cache_seconds":0}})").empty());
+    CHECK(validate_issues(R"({"api":{"keenetic_interface_cache_seconds":2147483647}})").empty());
+
+    auto issues = validate_issues(R"({"api":{"keenetic_interface_cache_seconds":-1}})");
+    REQUIRE(issues.size() == 1);
+    CHECK(issues[0].path == "api.keenetic_interface_cache_seconds");
+
+    issues = validate_issues(R"({"api":{"keenetic_interface_cache_seconds":9223372036854775807}})");
+    REQUIRE(issues.size() == 1);
+    CHECK(issues[0].path == "api.keenetic_interface_cache_seconds");
+    CHECK(issues[0].message == "api.keenetic_interface_cache_seconds is too large");
+}
+
 TEST_CASE("iproute rule priority start must be positive") {
     const auto issues = validate_issues(R"({"iproute":{"rule_priority_start":0}})");
     REQUIRE(issues.size() == 1);
     CHECK(issues[0].path == "iproute.rule_priority_start");
 }
+
+TEST_CASE("config warnings are reported without failing validation") {
+    Config cfg;
+    CHECK_NOTHROW(cfg = parse_test_config(R"({
+        "daemon":{"ipv6_enabled":false},
+        "outbounds":[{"tag":"wan","type":"interface","interface":"eth0"}],
+        "lists":{
+            "used":{"domains":["example.com"]},
+            "unused":{"domains":["example.org"]},
+            "off":{"enabled":false,"ip_cidrs":["2001:db8::/32"]}
+        },
+        "route":{"rules":[
+            {"list":["used"],"outbound":"wan"},
+            {"list":["off"],"outbound":"wan"}
+        ]}
+    })"));
+
+    const auto warnings = collect_config_warnings(cfg);
+    REQUIRE(warnings.size() == 3);
+    CHECK(warnings[0].path == "lists.unused");
+    CHECK(warnings[0].message == "List 'unused' is not used by any route or DNS rule");
+    CHECK(warnings[1].path == "route.rules[1]");
+    CHECK(warnings[1].message.find("only references disabled lists") != std::string::npos);
+    CHECK(warnings[2].path == "lists.off.ip_cidrs");
+    CHECK(warnings[2].message.find("daemon.ipv6_enabled is false") != std::string::npos);
+}
+
+TEST_CASE("config warnings do not replace validation errors") {
+    const auto issues = validate_issues(R"({
+        "outbounds":[{"tag":"wan","type":"interface","interface":"eth0"}],
+        "lists":{"unused":{"domains":["example.org"]}},
+        "route":{"rules":[{"list":["missing"],"outbound":"wan"}]}
+    })");
+    REQUIRE(issues.size() == 1);
+    CHECK(issues[0].path == "route.rules[0].list[0]");
+
+    CHECK(collect_config_warnings(parse_test_config(R"({
+        "outbounds":[{"tag":"wan","type":"interface","interface":"eth0"}],
+        "lists":{"used":{"domains":["example.com"]}},
+        "route":{"rules":[{"list":["used"],"outbound":"wan"}]}
+    })")).empty());
+}
@@ -0,0 +1,123 @@
+#include <doctest/doctest.h>
+
+#include "../src/config/config.hpp"
+#include "../src/daemon/config_watcher.hpp"
+
+#include <filesystem>
+#include <fstream>
+#include <stdexcept>
+#include <string>
+#include <unistd.h>
+
+namespace keen_pbr3 {
+namespace {
+
+using namespace std::chrono_literals;
+
+class TempDirectory {
+public:
+    TempDirectory() {
+        char pattern[] = "/tmp/keen-pbr-config-watch-XXXXXX";
+        const char* path = ::mkdtemp(pattern);
+        if (!path) throw std::runtime_error("mkdtemp failed");
+        path_ = path;
+    }
+    ~TempDirectory() { std::filesystem::remove_all(path_); }
+    const std::filesystem::path& path() const { return path_; }
+private:
+    std::filesystem::path path_;
+};
+
+// Rewrites the file and bumps its mtime explicitly so the test does not
+// depend on filesystem timestamp granularity.
+void write_config(const std::filesystem::path& path, const std::string& body) {
+    static auto mtime = std::filesystem::file_time_type::clock::now();
+    { std::ofstream out(path, std::ios::trunc); out << body; }
+    mtime += 1s;
+    std::filesystem::last_write_time(path, mtime);
+}
+
+ConfigFileWatcher make_watcher(const std::filesystem::path& path) {
+    return ConfigFileWatcher(path.string(), 2000ms, [](const std::string& contents) {
+        (void)parse_and_validate_config(contents);
+    });
+}
+
+constexpr const char* kValidConfig =
+    R"({"daemon": {"cache_dir": "/tmp/a"}, "dns": {"system_resolver": {"address": "127.0.0.1"}}})";
+constexpr const char* kChangedConfig =
+    R"({"daemon": {"cache_dir": "/tmp/b"}, "dns": {"system_resolver": {"address": "127.0.0.1"}}})";
+
+} // namespace
+
+TEST_CASE("config watcher ignores an unchanged file") {
+    TempDirectory temp;
+    const auto path = temp.path() / "config.json";
+    write_config(path, kValidConfig);
+    auto watcher = make_watcher(path);
+
+    const auto start = ConfigFileWatcher::Clock::time_point{};
+    CHECK(watcher.poll(start).kind == ConfigWatchResult::Kind::None);
+    CHECK(watcher.poll(start + 10s).kind == ConfigWatchResult::Kind::None);
+}
+
+TEST_CASE("config watcher reports a change only after the debounce period") {
+    TempDirectory temp;
+    const auto path = temp.path() / "config.json";
+    write_config(path, kValidConfig);
+    auto watcher = make_watcher(path);
+    const auto start = ConfigFileWatcher::Clock::time_point{};
+
+    write_config(path, kChangedConfig);
+    CHECK(watcher.poll(start).kind == ConfigWatchResult::Kind::None);
+    CHECK(watcher.poll(start + 1s).kind == ConfigWatchResult::Kind::None);
+
+    const auto result = watcher.poll(start + 2s);
+    CHECK(result.kind == ConfigWatchResult::Kind::Changed);
+    CHECK(result.contents == kChangedConfig);
+
+    // The same change is reported exactly once.
+    CHECK(watcher.poll(start + 5s).kind == ConfigWatchResult::Kind::None);
+}
+
+TEST_CASE("config watcher skips invalid intermediate writes") {
+    TempDirectory temp;
+    const auto path = temp.path() / "config.json";
+    write_config(path, kValidConfig);
+    auto watcher = make_watcher(path);
+    const auto start = ConfigFileWatcher::Clock::time_point{};
+
+    // A half-written file seen mid-edit restarts the debounce window when the
+    // final contents land, so the invalid state is never validated.
+    write_config(path, R"({"daemon": {"cache_dir": )");
+    CHECK(watcher.poll(start).kind == ConfigWatchResult::Kind::None);
+    write_config(path, kChangedConfig);
+    CHECK(watcher.poll(start + 1500ms).kind == ConfigWatchResult::Kind::None);
+    CHECK(watcher.poll(start + 2500ms).kind == ConfigWatchResult::Kind::None);
+
+    const auto result = watcher.poll(start + 3500ms);
+    CHECK(result.kind == ConfigWatchResult::Kind::Changed);
+    CHECK(result.contents == kChangedConfig);
+}
+
+TEST_CASE("config watcher reports a settled invalid file once") {
+    TempDirectory temp;
+    const auto path = temp.path() / "config.json";
+    write_config(path, kValidConfig);
+    auto watcher = make_watcher(path);
+    const auto start = ConfigFileWatcher::Clock::time_point{};
+
+    write_config(path, R"({"outbounds": [{"tag": "Bad Tag", "type": "blackhole"}]})");
+    CHECK(watcher.poll(start).kind == ConfigWatchResult::Kind::None);
+    const auto invalid = watcher.poll(start + 2s);
+    CHECK(invalid.kind == ConfigWatchResult::Kind::Invalid);
+    CHECK_FALSE(invalid.error.empty());
+    CHECK(watcher.poll(start + 10s).kind == ConfigWatchResult::Kind::None);
+
+    // Fixing the file produces a reload again.
+    write_config(path, kChangedConfig);
+    CHECK(watcher.poll(start + 11s).kind == ConfigWatchResult::Kind::None);
+    CHECK(watcher.poll(start + 13s).kind == ConfigWatchResult::Kind::Changed);
+}
+
+} // namespace keen_pbr3
@@ -98,4 +98,26 @@ TEST_CASE("atomic config writer has committed rename before parent fsync") {
     CHECK(read_file(config) == "new");
 }
 
+TEST_CASE("config backup copies the file being replaced") {
+    TempDir dir;
+    const auto config = dir.path / "config.json";
+    const auto backup = dir.path / "config.json.bak";
+    { std::ofstream output(config); output << "// old\n{}"; }
+
+    backup_config_file(config.string());
+    write_config_atomically(config.string(), "new");
+
+    CHECK(read_file(config) == "new");
+    CHECK(read_file(backup) == "// old\n{}");
+    CHECK(file_mode(backup) == 0600);
+}
+
+TEST_CASE("config backup skips a missing config file") {
+    TempDir dir;
+    const auto config = dir.path / "config.json";
+
+    CHECK_NOTHROW(backup_config_file(config.string()));
+    CHECK_FALSE(std::filesystem::exists(dir.path / "config.json.bak"));
+}
+
 } // namespace keen_pbr3
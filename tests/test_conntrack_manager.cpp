@@ -63,4 +63,91 @@ TEST_CASE("ConntrackManager preserves foreign mark bits while restoring original
           0x5ABC00AAU);
 }
 
+TEST_CASE("ConntrackManager parses original-direction flows from conntrack -L") {
+    const auto flows = ConntrackManager::parse_flows(
+        "tcp      6 431999 ESTABLISHED src=192.168.1.10 dst=142.250.74.4 sport=51514 "
+        "dport=443 src=142.250.74.4 dst=10.8.0.2 sport=443 dport=51514 [ASSURED] "
+        "mark=65536 use=1\n"
+        "udp      17 29 src=192.168.1.11 dst=142.250.74.4 sport=40000 dport=443 [UNREPLIED] "
+        "src=142.250.74.4 dst=192.168.1.11 sport=443 dport=40000 mark=0x20000 use=1\n"
+        "icmp     1 29 src=192.168.1.12 dst=142.250.74.4 type=8 code=0 id=7 "
+        "src=142.250.74.4 dst=192.168.1.12 type=0 code=0 id=7 mark=0 use=1\n"
+        "conntrack v1.4.8 (conntrack-tools): 3 flow entries have been shown.\n");
+    REQUIRE(flows.size() == 3);
+
+    CHECK(flows[0].protocol == "tcp");
+    CHECK(flows[0].state == "ESTABLISHED");
+    CHECK(flows[0].src == "192.168.1.10");
+    CHECK(flows[0].dst == "142.250.74.4");
+    CHECK(flows[0].sport == std::optional<uint16_t>(51514));
+    CHECK(flows[0].dport == std::optional<uint16_t>(443));
+    CHECK(flows[0].mark == 0x10000);
+
+    CHECK(flows[1].protocol == "udp");
+    CHECK(flows[1].state.empty());
+    CHECK(flows[1].src == "192.168.1.11");
+    CHECK(flows[1].mark == 0x20000);
+
+    CHECK(flows[2].protocol == "icmp");
+    CHECK_FALSE(flows[2].sport.has_value());
+    CHECK_FALSE(flows[2].dport.has_value());
+    CHECK(flows[2].mark == 0);
+}
+
+TEST_CASE("ConntrackManager lists flows to one destination") {
+    std::vector<std::vector<std::string>> commands;
+    ConntrackManager manager([&commands](const std::vector<std::string>& args) {
+        commands.push_back(args);
+        return ConntrackManager::CommandResult{
+            0,
+            "tcp      6 117 TIME_WAIT src=2001:db8::10 dst=2606:4700::1111 sport=40100 "
+            "dport=443 src=2606:4700::1111 dst=2001:db8::10 sport=443 dport=40100 "
+            "[ASSURED] mark=65536 use=1\n"
+            "conntrack v1.4.8 (conntrack-tools): 1 flow entries have been shown.\n"};
+    });
+
+    const auto flows = manager.list_flows_to("2606:4700:0::1111");
+    REQUIRE(flows.has_value());
+    CHECK_FALSE(flows->truncated);
+    REQUIRE(flows->flows.size() == 1);
+    CHECK(flows->flows[0].state == "TIME_WAIT");
+    CHECK(flows->flows[0].dst == "2606:4700::1111");
+    REQUIRE(commands.size() == 1);
+    CHECK(commands[0] ==
+          std::vector<std::string>{"conntrack", "-L", "-f", "ipv6", "-d", "2606:4700::1111"});
+
+    CHECK(manager.list_flows_to("1.1.1.1")->flows.empty());
+    CHECK(commands[1] == std::vector<std::string>{"conntrack", "-L", "-f", "ipv4", "-d", "1.1.1.1"});
+}
+
+TEST_CASE("ConntrackManager reports a failed flow listing") {
+    ConntrackManager manager([](const std::vector<std::string>&) {
+        return ConntrackManager::CommandResult{1, "Operation not permitted\n"};
+    });
+    CHECK_FALSE(manager.list_flows_to("1.1.1.1").has_value());
+}
+
+TEST_CASE("ConntrackManager keeps the flows read before the output cap") {
+    // The cap kills conntrack, so the exit status is not 0 and the last
+    // line is cut off.
+    ConntrackManager manager([](const std::vector<std::string>&) {
+        return ConntrackManager::CommandResult{
+            -1,
+            "tcp      6 431999 ESTABLISHED src=192.168.1.10 dst=1.1.1.1 sport=51514 "
+            "dport=443 src=1.1.1.1 dst=192.168.1.10 sport=443 dport=51514 [ASSURED] "
+            "mark=65536 use=1\n"
+            "udp      17 29 src=192.168.1.11 dst=1.1.1.1 sport=40000 dport=53 src=1.1.1.1 "
+            "dst=192.168.1.11 sport=53 dport=40000 mark=0 use=1\n"
+            "tcp      6 431999 ESTABLISHED src=192.168.1.12 dst=1.1",
+            true};
+    });
+
+    const auto flows = manager.list_flows_to("1.1.1.1");
+    REQUIRE(flows.has_value());
+    CHECK(flows->truncated);
+    REQUIRE(flows->flows.size() == 2);
+    CHECK(flows->flows[0].src == "192.168.1.10");
+    CHECK(flows->flows[1].src == "192.168.1.11");
+}
+
 } // namespace keen_pbr3
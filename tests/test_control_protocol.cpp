@@ -9,6 +9,7 @@
 #include <unistd.h>
 
 #include <atomic>
+#include <chrono>
 #include <cstring>
 #include <thread>
 
@@ -178,3 +179,48 @@ TEST_CASE("control client reports a truncated resolver stream after active bytes
     close(listener);
     (void)unlink(path.c_str());
 }
+
+TEST_CASE("control client timeout bounds the whole exchange, not each read") {
+    const auto path = "/tmp/keen-pbr-control-timeout-" + std::to_string(getpid()) + ".sock";
+    (void)unlink(path.c_str());
+    const int listener = socket(AF_UNIX, SOCK_STREAM | SOCK_CLOEXEC, 0);
+    REQUIRE(listener >= 0);
+    sockaddr_un address{};
+    address.sun_family = AF_UNIX;
+    std::strncpy(address.sun_path, path.c_str(), sizeof(address.sun_path) - 1U);
+    REQUIRE(bind(listener, reinterpret_cast<const sockaddr*>(&address), sizeof(address)) == 0);
+    REQUIRE(listen(listener, 1) == 0);
+
+    // A slow daemon that trickles its response header one byte at a time,
+    // each byte arriving well within the timeout.
+    std::thread server([&] {
+        const int client = accept4(listener, nullptr, nullptr, SOCK_CLOEXEC);
+        if (client < 0) return;
+        std::uint32_t request_size = 0;
+        if (recv(client, &request_size, sizeof(request_size), MSG_WAITALL) != sizeof(request_size)) {
+            close(client);
+            return;
+        }
+        std::string discard(ntohl(request_size), '\0');
+        (void)recv(client, discard.data(), discard.size(), MSG_WAITALL);
+        const std::uint32_t length = htonl(64);
+        const auto* bytes = reinterpret_cast<const char*>(&length);
+        for (std::size_t i = 0; i < sizeof(length); ++i) {
+            std::this_thread::sleep_for(std::chrono::milliseconds(150));
+            if (send(client, bytes + i, 1, MSG_NOSIGNAL) != 1) break;
+        }
+        close(client);
+    });
+
+    const auto started = std::chrono::steady_clock::now();
+    CHECK_THROWS_AS(request_control(path,
+                                    {{"protocol_version", kControlProtocolVersion},
+                                     {"request_id", "client-1"},
+                                     {"operation", "download"}},
+                                    300),
+                    ControlTimeoutError);
+    CHECK(std::chrono::steady_clock::now() - started < std::chrono::milliseconds(500));
+    server.join();
+    close(listener);
+    (void)unlink(path.c_str());
+}
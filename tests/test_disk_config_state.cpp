@@ -17,6 +17,9 @@ TEST_CASE("disk config inspector compares JSON without mutating active config")
     { std::ofstream output(path); output << nlohmann::json(active).dump(); }
     CHECK(inspect_disk_config_state(path, active).matches_active);
 
+    { std::ofstream output(path); output << "// saved by the API\n" << nlohmann::json(active).dump(); }
+    CHECK(inspect_disk_config_state(path, active).matches_active);
+
     { std::ofstream output(path); output << R"({"daemon":{"pid_file":"/other.pid"}})"; }
     const auto mismatch = inspect_disk_config_state(path, active);
     CHECK_FALSE(mismatch.matches_active);
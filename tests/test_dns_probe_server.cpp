@@ -4,6 +4,10 @@
 #include "../src/dns/dns_server.hpp"
 
 #include <array>
+#include <netinet/in.h>
+#include <sys/socket.h>
+#include <sys/time.h>
+#include <unistd.h>
 
 using namespace keen_pbr3;
 
@@ -117,3 +121,126 @@ TEST_CASE("dns probe query rejects malformed packets") {
     std::vector<uint8_t> packet = {0x12, 0x34, 0x01, 0x00};
     CHECK_THROWS_AS(parse_dns_probe_query(ByteView(packet.data(), packet.size())), DnsError);
 }
+
+TEST_CASE("dns probe query tolerates damaged records after the question") {
+    auto packet = make_query_with_ecs(0x1234, "www", {192, 0, 2});
+    packet.resize(packet.size() - 5); // cut into the OPT record
+    auto query = parse_dns_probe_query(ByteView(packet.data(), packet.size()));
+    CHECK(query.name == "www.com");
+    CHECK(!query.ecs.has_value());
+
+    auto trailing = make_query(0x1234, 0x0100, "www", 1);
+    trailing.insert(trailing.end(), {0xDE, 0xAD, 0xBE, 0xEF});
+    CHECK(parse_dns_probe_query(ByteView(trailing.data(), trailing.size())).name == "www.com");
+}
+
+TEST_CASE("dns probe FORMERR response echoes id, opcode and RD") {
+    auto packet = make_query(0xBEEF, 0x0100, "www", 1);
+    packet.resize(15); // truncated qname
+    CHECK_THROWS_AS(parse_dns_probe_query(ByteView(packet.data(), packet.size())), DnsError);
+
+    auto response = build_dns_format_error_response(ByteView(packet.data(), packet.size()));
+    REQUIRE(response.has_value());
+    CHECK(*response == std::vector<uint8_t>{0xBE, 0xEF, 0x81, 0x01, 0, 0, 0, 0, 0, 0, 0, 0});
+
+    std::vector<uint8_t> too_short = {0x12, 0x34, 0x01, 0x00};
+    CHECK(!build_dns_format_error_response(ByteView(too_short.data(), too_short.size())));
+
+    auto answer = make_query(0x1234, 0x8180, "www", 1);
+    answer[4] = 0x00;
+    answer[5] = 0x02; // qdcount = 2 makes it malformed for the probe
+    CHECK(!build_dns_format_error_response(ByteView(answer.data(), answer.size())));
+}
+
+TEST_CASE("dns probe server answers a garbage UDP query with FORMERR") {
+    DnsProbeServer server(DnsProbeServerSettings{"127.0.0.1:0", "127.0.0.1", 0, "127.0.0.1"});
+    sockaddr_in server_addr{};
+    socklen_t server_len = sizeof(server_addr);
+    REQUIRE(getsockname(server.udp_fd(), reinterpret_cast<sockaddr*>(&server_addr),
+                        &server_len) == 0);
+
+    const int client = socket(AF_INET, SOCK_DGRAM, 0);
+    REQUIRE(client >= 0);
+    timeval timeout{2, 0};
+    setsockopt(client, SOL_SOCKET, SO_RCVTIMEO, &timeout, sizeof(timeout));
+
+    auto garbage = make_query(0x4242, 0x0100, "www", 1);
+    garbage[5] = 0x00; // qdcount = 0
+    REQUIRE(sendto(client, garbage.data(), garbage.size(), 0,
+                   reinterpret_cast<const sockaddr*>(&server_addr), server_len) ==
+            static_cast<ssize_t>(garbage.size()));
+    CHECK(server.handle_udp_readable());
+
+    uint8_t reply[512];
+    const ssize_t n = recv(client, reply, sizeof(reply), 0);
+    close(client);
+    REQUIRE(n == 12);
+    CHECK(reply[0] == 0x42);
+    CHECK(reply[1] == 0x42);
+    CHECK((reply[2] & 0x80) != 0);  // QR
+    CHECK((reply[3] & 0x0F) == 1);  // FORMERR
+}
+
+TEST_CASE("dns probe server answers SERVFAIL when it cannot build the answer") {
+    // A valid query, but answer_ipv4 bypassed settings validation.
+    DnsProbeServer server(DnsProbeServerSettings{"127.0.0.1:0", "127.0.0.1", 0, "not-an-ip"});
+    sockaddr_in server_addr{};
+    socklen_t server_len = sizeof(server_addr);
+    REQUIRE(getsockname(server.udp_fd(), reinterpret_cast<sockaddr*>(&server_addr),
+                        &server_len) == 0);
+
+    const int client = socket(AF_INET, SOCK_DGRAM, 0);
+    REQUIRE(client >= 0);
+    timeval timeout{2, 0};
+    setsockopt(client, SOL_SOCKET, SO_RCVTIMEO, &timeout, sizeof(timeout));
+
+    const auto query = make_query(0x5151, 0x0100, "www", 1);
+    REQUIRE(sendto(client, query.data(), query.size(), 0,
+                   reinterpret_cast<const sockaddr*>(&server_addr), server_len) ==
+            static_cast<ssize_t>(query.size()));
+    CHECK(server.handle_udp_readable());
+
+    uint8_t reply[512];
+    const ssize_t n = recv(client, reply, sizeof(reply), 0);
+    close(client);
+    REQUIRE(n == 12);
+    CHECK(reply[0] == 0x51);
+    CHECK(reply[1] == 0x51);
+    CHECK((reply[2] & 0x80) != 0);  // QR
+    CHECK((reply[3] & 0x0F) == 2);  // SERVFAIL
+}
+
+TEST_CASE("dns probe server answers SERVFAIL over TCP when it cannot build the answer") {
+    DnsProbeServer server(DnsProbeServerSettings{"127.0.0.1:0", "127.0.0.1", 0, "not-an-ip"});
+    sockaddr_in server_addr{};
+    socklen_t server_len = sizeof(server_addr);
+    REQUIRE(getsockname(server.tcp_fd(), reinterpret_cast<sockaddr*>(&server_addr),
+                        &server_len) == 0);
+
+    const int client = socket(AF_INET, SOCK_STREAM, 0);
+    REQUIRE(client >= 0);
+    timeval timeout{2, 0};
+    setsockopt(client, SOL_SOCKET, SO_RCVTIMEO, &timeout, sizeof(timeout));
+    REQUIRE(connect(client, reinterpret_cast<const sockaddr*>(&server_addr), server_len) == 0);
+    const auto accepted = server.accept_tcp_clients();
+    REQUIRE(accepted.size() == 1);
+
+    const auto query = make_query(0x6161, 0x0100, "www", 1);
+    std::vector<uint8_t> framed{0x00, static_cast<uint8_t>(query.size())};
+    framed.insert(framed.end(), query.begin(), query.end());
+    REQUIRE(send(client, framed.data(), framed.size(), 0) ==
+            static_cast<ssize_t>(framed.size()));
+    CHECK_FALSE(server.handle_tcp_client_readable(accepted.front()));
+    server.remove_tcp_client(accepted.front());
+
+    uint8_t reply[512];
+    const ssize_t n = recv(client, reply, sizeof(reply), MSG_WAITALL);
+    close(client);
+    REQUIRE(n == 14);
+    CHECK(reply[0] == 0x00);
+    CHECK(reply[1] == 12);  // frame length
+    CHECK(reply[2] == 0x61);
+    CHECK(reply[3] == 0x61);
+    CHECK((reply[4] & 0x80) != 0);  // QR
+    CHECK((reply[5] & 0x0F) == 2);  // SERVFAIL
+}
@@ -0,0 +1,249 @@
+#include <doctest/doctest.h>
+
+#include "../src/dns/dns_upstream_probe.hpp"
+
+#include <arpa/inet.h>
+#include <netinet/in.h>
+#include <sys/socket.h>
+#include <unistd.h>
+
+#include <array>
+#include <chrono>
+#include <cstdint>
+#include <functional>
+#include <stdexcept>
+#include <string>
+#include <thread>
+#include <vector>
+
+using namespace keen_pbr3;
+
+namespace {
+
+void push_u16(std::vector<uint8_t>& out, uint16_t value) {
+    out.push_back(static_cast<uint8_t>((value >> 8) & 0xFF));
+    out.push_back(static_cast<uint8_t>(value & 0xFF));
+}
+
+// Echo the question back with the given rcode and A answers.
+std::vector<uint8_t> build_a_response(const uint8_t* query,
+                                      size_t query_len,
+                                      uint8_t rcode,
+                                      const std::vector<std::array<uint8_t, 4>>& answers) {
+    std::vector<uint8_t> packet(query, query + query_len);
+    packet[2] = static_cast<uint8_t>(packet[2] | 0x80);
+    packet[3] = static_cast<uint8_t>(0x80 | rcode);
+    packet[6] = 0;
+    packet[7] = static_cast<uint8_t>(answers.size());
+    for (const auto& ip : answers) {
+        push_u16(packet, 0xC00C);
+        push_u16(packet, 0x0001);
+        push_u16(packet, 0x0001);
+        push_u16(packet, 0x0000);
+        push_u16(packet, 0x003C);
+        push_u16(packet, 0x0004);
+        packet.insert(packet.end(), ip.begin(), ip.end());
+    }
+    return packet;
+}
+
+// Turns the well-formed response into the datagrams actually sent, in order.
+using ResponseShaper = std::function<std::vector<std::vector<uint8_t>>(std::vector<uint8_t>)>;
+
+class MockDnsUpstream {
+public:
+    MockDnsUpstream(uint8_t rcode,
+                    std::vector<std::array<uint8_t, 4>> answers,
+                    ResponseShaper shape = {})
+        : rcode_(rcode), answers_(std::move(answers)), shape_(std::move(shape)) {
+        socket_fd_ = socket(AF_INET, SOCK_DGRAM, 0);
+        if (socket_fd_ < 0) {
+            throw std::runtime_error("socket() failed");
+        }
+
+        sockaddr_in addr {};
+        addr.sin_family = AF_INET;
+        addr.sin_port = htons(0);
+        addr.sin_addr.s_addr = htonl(INADDR_LOOPBACK);
+        if (bind(socket_fd_, reinterpret_cast<const sockaddr*>(&addr), sizeof(addr)) != 0) {
+            close(socket_fd_);
+            throw std::runtime_error("bind() failed");
+        }
+
+        socklen_t len = sizeof(addr);
+        if (getsockname(socket_fd_, reinterpret_cast<sockaddr*>(&addr), &len) != 0) {
+            close(socket_fd_);
+            throw std::runtime_error("getsockname() failed");
+        }
+        port_ = ntohs(addr.sin_port);
+
+        server_thread_ = std::thread([this]() { serve_once(); });
+    }
+
+    ~MockDnsUpstream() {
+        if (socket_fd_ >= 0) {
+            shutdown(socket_fd_, SHUT_RDWR);
+            close(socket_fd_);
+            socket_fd_ = -1;
+        }
+        if (server_thread_.joinable()) {
+            server_thread_.join();
+        }
+    }
+
+    std::string address() const {
+        return "127.0.0.1:" + std::to_string(port_);
+    }
+
+private:
+    void serve_once() {
+        std::array<uint8_t, 512> buffer {};
+        sockaddr_in client_addr {};
+        socklen_t client_len = sizeof(client_addr);
+        const ssize_t received = recvfrom(socket_fd_,
+                                          buffer.data(),
+                                          buffer.size(),
+                                          0,
+                                          reinterpret_cast<sockaddr*>(&client_addr),
+                                          &client_len);
+        if (received < 12) {
+            return;
+        }
+
+        auto response = build_a_response(
+            buffer.data(), static_cast<size_t>(received), rcode_, answers_);
+        const auto datagrams = shape_ ? shape_(std::move(response))
+                                      : std::vector<std::vector<uint8_t>>{std::move(response)};
+        for (const auto& datagram : datagrams) {
+            (void)sendto(socket_fd_,
+                         datagram.data(),
+                         datagram.size(),
+                         0,
+                         reinterpret_cast<const sockaddr*>(&client_addr),
+                         client_len);
+        }
+    }
+
+    int socket_fd_{-1};
+    uint16_t port_{0};
+    uint8_t rcode_{0};
+    std::vector<std::array<uint8_t, 4>> answers_;
+    ResponseShaper shape_;
+    std::thread server_thread_;
+};
+
+// Offsets in a response to an "example.com" query: the first byte of the
+// question name and the low byte of its QTYPE.
+constexpr size_t kQuestionNameOffset = 13;
+constexpr size_t kQuestionTypeOffset = 12 + 13 + 1;
+
+// Bound but never read: queries sent here go unanswered.
+class SilentDnsUpstream {
+public:
+    SilentDnsUpstream() {
+        socket_fd_ = socket(AF_INET, SOCK_DGRAM, 0);
+        sockaddr_in addr {};
+        addr.sin_family = AF_INET;
+        addr.sin_addr.s_addr = htonl(INADDR_LOOPBACK);
+        if (socket_fd_ < 0 ||
+            bind(socket_fd_, reinterpret_cast<const sockaddr*>(&addr), sizeof(addr)) != 0) {
+            throw std::runtime_error("bind() failed");
+        }
+        socklen_t len = sizeof(addr);
+        getsockname(socket_fd_, reinterpret_cast<sockaddr*>(&addr), &len);
+        port_ = ntohs(addr.sin_port);
+    }
+
+    ~SilentDnsUpstream() { close(socket_fd_); }
+
+    std::string address() const {
+        return "127.0.0.1:" + std::to_string(port_);
+    }
+
+private:
+    int socket_fd_{-1};
+    uint16_t port_{0};
+};
+
+} // namespace
+
+TEST_CASE("DNS upstream probe reports resolved IPs from a working upstream") {
+    MockDnsUpstream upstream(0, {{{93, 184, 216, 34}}, {{93, 184, 216, 35}}});
+
+    const auto result = probe_dns_upstream(
+        upstream.address(), "example.com", std::chrono::milliseconds(2000));
+
+    CHECK(result.ok);
+    CHECK(result.rcode == "NOERROR");
+    CHECK(result.error.empty());
+    CHECK(result.latency_ms >= 0);
+    CHECK(result.resolved_ips == std::vector<std::string>{"93.184.216.34", "93.184.216.35"});
+}
+
+TEST_CASE("DNS upstream probe reports error response codes") {
+    MockDnsUpstream upstream(2, {});
+
+    const auto result = probe_dns_upstream(
+        upstream.address(), "example.com", std::chrono::milliseconds(2000));
+
+    CHECK_FALSE(result.ok);
+    CHECK(result.rcode == "SERVFAIL");
+    CHECK(result.error == "DNS upstream answered SERVFAIL");
+    CHECK(result.resolved_ips.empty());
+}
+
+TEST_CASE("DNS upstream probe ignores answers to another question") {
+    MockDnsUpstream upstream(0, {{{93, 184, 216, 34}}}, [](std::vector<uint8_t> response) {
+        auto other_name = response;
+        other_name[kQuestionNameOffset] = 'x';
+        other_name.resize(other_name.size() - 4);
+        other_name.insert(other_name.end(), {192, 0, 2, 1});
+        auto other_type = other_name;
+        other_type[kQuestionNameOffset] = response[kQuestionNameOffset];
+        other_type[kQuestionTypeOffset] = 28;
+        return std::vector<std::vector<uint8_t>>{other_name, other_type, response};
+    });
+
+    const auto result = probe_dns_upstream(
+        upstream.address(), "example.com", std::chrono::milliseconds(2000));
+
+    CHECK(result.ok);
+    CHECK(result.resolved_ips == std::vector<std::string>{"93.184.216.34"});
+}
+
+TEST_CASE("DNS upstream probe treats a truncated answer as inconclusive") {
+    MockDnsUpstream upstream(0, {{{93, 184, 216, 34}}}, [](std::vector<uint8_t> response) {
+        response[2] = static_cast<uint8_t>(response[2] | 0x02);
+        return std::vector<std::vector<uint8_t>>{response};
+    });
+
+    const auto result = probe_dns_upstream(
+        upstream.address(), "example.com", std::chrono::milliseconds(2000));
+
+    CHECK_FALSE(result.ok);
+    CHECK(result.error == "DNS upstream response was truncated; the test is inconclusive");
+    CHECK(result.resolved_ips.empty());
+}
+
+TEST_CASE("DNS upstream probe enforces the timeout") {
+    SilentDnsUpstream upstream;
+
+    const auto started = std::chrono::steady_clock::now();
+    const auto result = probe_dns_upstream(
+        upstream.address(), "example.com", std::chrono::milliseconds(200));
+    const auto elapsed = std::chrono::steady_clock::now() - started;
+
+    CHECK_FALSE(result.ok);
+    CHECK(result.rcode.empty());
+    CHECK(result.error == "DNS upstream did not answer within 200 ms");
+    CHECK(elapsed < std::chrono::seconds(2));
+}
+
+TEST_CASE("DNS upstream probe rejects invalid addresses without sending") {
+    const auto result = probe_dns_upstream(
+        "not-an-ip", "example.com", std::chrono::milliseconds(200));
+
+    CHECK_FALSE(result.ok);
+    CHECK_FALSE(result.error.empty());
+    CHECK(result.latency_ms == 0);
+}
@@ -437,6 +437,54 @@ TEST_CASE("generate-resolver-config includes dns probe server directive when ena
     CHECK(output.find("server=/check.keen.pbr/127.0.0.88#53\n") != std::string::npos);
 }
 
+TEST_CASE("generate-resolver-config forwards custom dns probe domain suffix") {
+    CacheManager cache("/nonexistent/cache");
+    ListStreamer streamer(cache);
+
+    auto route_cfg = make_route_cfg("mylist");
+    auto lists = std::map<std::string, ListConfig>{{"mylist", make_list_cfg({"example.com"})}};
+
+    auto default_dns_cfg = make_empty_dns_cfg();
+    DnsTestServer default_probe;
+    default_probe.listen = "127.0.0.88:53";
+    default_dns_cfg.dns_test_server = default_probe;
+    DnsServerRegistry default_reg(default_dns_cfg);
+    DnsmasqGenerator default_gen(default_reg, streamer, route_cfg, default_dns_cfg, lists);
+
+    auto dns_cfg = make_empty_dns_cfg();
+    DnsTestServer probe_cfg = default_probe;
+    probe_cfg.domain_suffix = "probe.home.lan";
+    dns_cfg.dns_test_server = probe_cfg;
+    DnsServerRegistry reg(dns_cfg);
+    DnsmasqGenerator gen(reg, streamer, route_cfg, dns_cfg, lists);
+    const std::string output = run_generate(gen);
+
+    CHECK(output.find("rebind-domain-ok=probe.home.lan\n") != std::string::npos);
+    CHECK(output.find("server=/probe.home.lan/127.0.0.88#53\n") != std::string::npos);
+    CHECK(output.find("check.keen.pbr") == std::string::npos);
+    CHECK(gen.compute_config_hash() != default_gen.compute_config_hash());
+}
+
+TEST_CASE("generate-resolver-config emits edns-packet-max only when configured") {
+    CacheManager cache("/nonexistent/cache");
+    ListStreamer streamer(cache);
+
+    auto route_cfg = make_route_cfg("mylist");
+    auto lists = std::map<std::string, ListConfig>{{"mylist", make_list_cfg({"example.com"})}};
+
+    auto default_dns_cfg = make_empty_dns_cfg();
+    DnsServerRegistry default_reg(default_dns_cfg);
+    DnsmasqGenerator default_gen(default_reg, streamer, route_cfg, default_dns_cfg, lists);
+    CHECK(run_generate(default_gen).find("edns-packet-max=") == std::string::npos);
+
+    auto dns_cfg = make_empty_dns_cfg();
+    dns_cfg.edns_packet_max = 1232;
+    DnsServerRegistry reg(dns_cfg);
+    DnsmasqGenerator gen(reg, streamer, route_cfg, dns_cfg, lists);
+    CHECK(run_generate(gen).find("edns-packet-max=1232\n") != std::string::npos);
+    CHECK(gen.compute_config_hash() != default_gen.compute_config_hash());
+}
+
 TEST_CASE("generate-resolver-config blocks firefox doh canary domain") {
     CacheManager cache("/nonexistent/cache");
     ListStreamer streamer(cache);
@@ -660,6 +708,24 @@ TEST_CASE("generate-resolver-config ignores disabled route rules for ipset popul
     CHECK(output.find("nftset=/4#inet#KeenPbrTable#") == std::string::npos);
 }
 
+TEST_CASE("generate-resolver-config ignores domains of disabled lists") {
+    CacheManager cache("/nonexistent/cache");
+    ListStreamer streamer(cache);
+
+    const std::string list_name = "mylist";
+    auto route_cfg = make_route_cfg(list_name);
+    auto dns_cfg = make_dns_cfg(list_name, "dns1", "8.8.8.8", true);
+    auto list_cfg = make_list_cfg({"example.com"});
+    list_cfg.enabled = false;
+    auto lists = std::map<std::string, ListConfig>{{list_name, list_cfg}};
+
+    DnsServerRegistry reg(dns_cfg);
+    DnsmasqGenerator gen(reg, streamer, route_cfg, dns_cfg, lists);
+    const std::string output = run_generate(gen);
+
+    CHECK(output.find("example.com") == std::string::npos);
+}
+
 TEST_CASE("dns server registry ignores disabled dns rules during server-tag validation") {
     DnsServer fallback_server;
     fallback_server.tag = "fallback";
@@ -1192,3 +1258,35 @@ TEST_CASE("nft resolver stream keeps routed and DNS-only list directives togethe
     CHECK(output.find("rebind-domain-ok=/ts.net/\n") != std::string::npos);
     CHECK(extract_txt_hash(output) == expected_hash);
 }
+
+TEST_CASE("generate-resolver-config dnsmasq-ipset emits ipset= lines for every routed list") {
+    CacheManager cache("/nonexistent/cache");
+    ListStreamer streamer(cache);
+
+    RouteRule video_route;
+    video_route.list = std::vector<std::string>{"video"};
+    video_route.outbound = "vpn";
+    RouteRule work_route;
+    work_route.list = std::vector<std::string>{"work"};
+    work_route.outbound = "office";
+    RouteConfig route_cfg;
+    route_cfg.rules = std::vector<RouteRule>{video_route, work_route};
+
+    auto dns_cfg = make_empty_dns_cfg();
+    const auto lists = std::map<std::string, ListConfig>{
+        {"video", make_list_cfg({"youtube.com", "googlevideo.com"})},
+        {"work", make_list_cfg({"corp.example", "shared.example"})},
+    };
+
+    DnsServerRegistry registry(dns_cfg);
+    DnsmasqGenerator generator(registry, streamer, route_cfg, dns_cfg, lists,
+                               ResolverType::DNSMASQ_IPSET,
+                               KEEN_PBR3_VERSION_FULL_STRING,
+                               false);
+    const std::string output = run_generate(generator);
+
+    CHECK(output.find("ipset=/youtube.com/googlevideo.com/kpbr4d_video\n") != std::string::npos);
+    CHECK(output.find("ipset=/corp.example/shared.example/kpbr4d_work\n") != std::string::npos);
+    CHECK(output.find("kpbr6d_") == std::string::npos);
+    CHECK(output.find("nftset=") == std::string::npos);
+}
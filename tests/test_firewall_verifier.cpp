@@ -494,6 +494,76 @@ TEST_CASE("IptablesFirewallVerifier::verify_rules: versioned chain mark rule ok"
     CHECK(checks[0].status == CheckStatus::ok);
 }
 
+TEST_CASE("find_firewall_drift: rules flushed between checks are reported until reinstalled") {
+    const std::string installed_chain =
+        "-N KeenPbrTable\n"
+        "-A KeenPbrTable -m set --match-set set1 dst -j MARK --set-xmark 0x10000/0xff0000\n";
+    const std::string installed_prerouting = "-A PREROUTING -j KeenPbrTable\n";
+    std::string chain = installed_chain;
+    std::string prerouting = installed_prerouting;
+    auto runner = [&chain, &prerouting](const std::vector<std::string>& args) -> CommandResult {
+        if (matches_args(args, {"iptables", "-t", "mangle", "-S", "KeenPbrTable"})) {
+            return chain.empty() ? command_result({}, 1) : command_result(chain);
+        }
+        if (matches_args(args, {"iptables", "-t", "mangle", "-S", "PREROUTING"})) {
+            return command_result(prerouting);
+        }
+        return command_result({}, 1);
+    };
+
+    FirewallState state;
+    state.set_fwmark_mask(0x00ff0000u);
+    RuleState rule;
+    rule.set_names = {"set1"};
+    rule.action_type = RuleActionType::Mark;
+    rule.fwmark = 0x10000u;
+    state.set_rules({rule});
+
+    int reinstalls = 0;
+    // One self-heal tick: a fresh verifier, as the daemon creates per check.
+    auto tick = [&]() {
+        IptablesFirewallVerifier verifier(runner);
+        const auto drift = find_firewall_drift(verifier, state);
+        if (!drift.empty()) {
+            ++reinstalls;
+            chain = installed_chain;
+            prerouting = installed_prerouting;
+        }
+        return drift;
+    };
+
+    CHECK(tick().empty());
+    CHECK(reinstalls == 0);
+
+    // `/etc/init.d/firewall restart` flushes the mangle table.
+    chain.clear();
+    prerouting = "-P PREROUTING ACCEPT\n";
+    const auto flushed = tick();
+    REQUIRE(flushed.size() == 1);
+    CHECK(flushed[0].find("KeenPbrTable") != std::string::npos);
+    CHECK(reinstalls == 1);
+    CHECK(tick().empty());
+
+    // Only the marking rule is deleted; the chain and hook survive.
+    chain = "-N KeenPbrTable\n";
+    const auto missing_rule = tick();
+    REQUIRE(missing_rule.size() == 1);
+    CHECK(missing_rule[0].rfind("mark rule for set1 is missing", 0) == 0);
+    CHECK(reinstalls == 2);
+    CHECK(tick().empty());
+    CHECK(reinstalls == 2);
+}
+
+TEST_CASE("find_firewall_drift: nothing expected means nothing to heal") {
+    auto runner = [](const std::vector<std::string>&) { return command_result({}, 1); };
+    IptablesFirewallVerifier verifier(runner);
+    FirewallState state;
+    RuleState skipped;
+    skipped.action_type = RuleActionType::Skip;
+    state.set_rules({skipped});
+    CHECK(find_firewall_drift(verifier, state).empty());
+}
+
 TEST_CASE("IptablesFirewallVerifier::verify_rules: mark rule missing") {
     const std::string prerouting =
         "-P PREROUTING ACCEPT\n"
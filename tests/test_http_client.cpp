@@ -136,3 +136,24 @@ TEST_CASE("url tester uses discard transport probes and retry policy") {
     CHECK(transport->request.fwmark == 77);
     CHECK(transport->request.max_redirects == 3);
 }
+
+TEST_CASE("http client probes with HEAD and reports size and content type") {
+    auto transport = std::make_shared<FakeTransport>();
+    transport->response = {200, {}, {{"content-length", "1234"}, {"content-type", "text/plain"}},
+                           std::chrono::milliseconds(1)};
+    keen_pbr3::HttpClient client(transport);
+
+    const auto result = client.probe("https://example.test/list.txt", {9});
+    CHECK(transport->calls == 1);
+    CHECK(transport->request.method == "HEAD");
+    CHECK(transport->request.discard_body);
+    CHECK(transport->request.fwmark == 9);
+    CHECK(result.status_code == 200);
+    CHECK(result.content_length == std::optional<uint64_t>(1234));
+    CHECK(result.content_type == "text/plain");
+
+    transport->response = {404, {}, {}, std::chrono::milliseconds(1)};
+    CHECK(client.probe("https://example.test/missing.txt").status_code == 404);
+    transport->fail = true;
+    CHECK_THROWS_AS(client.probe("https://example.test/list.txt"), keen_pbr3::HttpError);
+}
@@ -167,6 +167,18 @@ public:
     return firewall.pending_sets_.size();
   }
 
+  static std::string
+  build_ipset_script(FirewallApplyMode mode,
+                     const std::optional<std::set<std::string>> &live_sets) {
+    IptablesFirewall firewall;
+    firewall.create_ipset("kpbr4_static", AF_INET);
+    firewall.create_ipset("kpbr4d_domains", AF_INET, 3600);
+    auto loader = firewall.create_batch_loader("kpbr4_static");
+    loader->on_entry(EntryType::Ip, "192.0.2.1");
+    return firewall.build_ipset_script(mode, /*effective_ipv6=*/false,
+                                       live_sets);
+  }
+
   static bool conflicting_duplicate_create_throws() {
     IptablesFirewall firewall;
     firewall.create_ipset("kpbr4_shared", AF_INET);
@@ -190,6 +202,48 @@ TEST_CASE("IptablesFirewall deduplicates repeated static ipset declarations") {
   CHECK(T::conflicting_duplicate_create_throws());
 }
 
+TEST_CASE("ipset script creates static sets before adding their entries") {
+  const std::string script =
+      T::build_ipset_script(FirewallApplyMode::Destructive, std::nullopt);
+  const auto create = script.find("create kpbr4_static hash:net");
+  const auto add = script.find("add kpbr4_static 192.0.2.1");
+  REQUIRE(create != std::string::npos);
+  REQUIRE(add != std::string::npos);
+  CHECK(create < add);
+  CHECK(script.find("create kpbr4d_domains hash:net family inet timeout 3600") !=
+        std::string::npos);
+}
+
+TEST_CASE("ipset script recreates only missing dynamic sets on routine apply") {
+  const std::set<std::string> live{"kpbr4_static", "kpbr4d_domains"};
+  const std::string present =
+      T::build_ipset_script(FirewallApplyMode::PreserveSets, live);
+  CHECK(present.find("kpbr4d_domains") == std::string::npos);
+  CHECK(present.find("flush kpbr4_static") != std::string::npos);
+
+  const std::string missing = T::build_ipset_script(
+      FirewallApplyMode::PreserveSets, std::set<std::string>{"kpbr4_static"});
+  CHECK(missing.find("create kpbr4d_domains") != std::string::npos);
+  CHECK(missing.find("flush kpbr4d_domains") == std::string::npos);
+
+  const std::string unknown =
+      T::build_ipset_script(FirewallApplyMode::PreserveSets, std::nullopt);
+  CHECK(unknown.find("kpbr4d_domains") == std::string::npos);
+}
+
+TEST_CASE("ipset script leaves set contents alone on a rules-only apply") {
+  const std::string present = T::build_ipset_script(
+      FirewallApplyMode::RulesOnly,
+      std::set<std::string>{"kpbr4_static", "kpbr4d_domains"});
+  CHECK(present.empty());
+
+  const std::string missing = T::build_ipset_script(
+      FirewallApplyMode::RulesOnly, std::set<std::string>{"kpbr4d_domains"});
+  CHECK(missing.find("create kpbr4_static") != std::string::npos);
+  CHECK(missing.find("flush") == std::string::npos);
+  CHECK(missing.find("add ") == std::string::npos);
+}
+
 TEST_CASE("raw prerouting rules use an isolated raw chain without conntrack") {
   Rule rule{"kpbr4s_minecraft", false, false, Rule::Mark, 0x100, {}};
   FirewallGlobalPrefilter prefilter;
@@ -373,6 +427,13 @@ TEST_CASE(
   CHECK(T::prepared_static_set_name(FirewallSetGeneration::B,
                                     FirewallApplyMode::StaticSetsOnly) ==
         "kpbr4s_sample");
+  // Rules-only applies keep matching the filled active generation.
+  CHECK(T::prepared_static_set_name(FirewallSetGeneration::B,
+                                    FirewallApplyMode::RulesOnly) ==
+        "kpbr4S_sample");
+  CHECK(T::prepared_static_set_name(std::nullopt,
+                                    FirewallApplyMode::RulesOnly) ==
+        "kpbr4s_sample");
 }
 
 // =============================================================================
@@ -557,6 +618,21 @@ TEST_CASE("build_ipt_script: multi-interface prefilter expands route rules "
                "RETURN\n") != std::string::npos);
 }
 
+TEST_CASE("build_ipt_script: rule-level inbound interfaces add -i per "
+          "interface") {
+  ProtoPortFilter filter;
+  filter.inbound_interfaces = {"br0", "br1"};
+  auto s = T::build_ipt_script(
+      false, {mark_rule("kpbr4_local", false, 0x100, filter)},
+      prefilter_with_interfaces({"br0", "br1", "wg0"}, false));
+
+  CHECK(s.find("-A KeenPbrTable -m set --match-set kpbr4_local dst -i br0 -j "
+               "MARK --set-xmark 0x100/0xffffffff\n") != std::string::npos);
+  CHECK(s.find("-A KeenPbrTable -m set --match-set kpbr4_local dst -i br1 -j "
+               "MARK --set-xmark 0x100/0xffffffff\n") != std::string::npos);
+  CHECK(s.find("-i wg0") == std::string::npos);
+}
+
 TEST_CASE("build_ipt_script: config-derived prefilter keeps route rule body "
           "unchanged") {
   auto cfg = parse_valid_config(R"({
@@ -98,6 +98,58 @@ TEST_CASE("Keenetic descriptions: stale refresh failure retains successful cache
     CHECK(*second.interfaces.front().description == "Internet");
 }
 
+TEST_CASE("Keenetic descriptions: cache is served within the TTL and refetched after it") {
+    KeeneticInterfaceTestState state;
+    set_system_info_for_tests(SystemInfo{"keenetic", "4.02.C.1", "keenetic"});
+    auto now = std::chrono::steady_clock::time_point{};
+    set_keenetic_interface_now_fn_for_tests([&now] { return now; });
+    int calls = 0;
+    set_keenetic_interface_fetcher_for_tests([&calls](const std::string&,
+                                                       const std::string&,
+                                                       const std::string&) {
+        ++calls;
+        return R"({"Wireguard0":{"id":"Wireguard0","description":"Office VPN","address":"10.20.30.1","mask":"255.255.255.0"}})";
+    });
+
+    const auto populate = [] {
+        auto response = inventory("wg0", {"10.20.30.1/24"});
+        populate_keenetic_interface_descriptions(response, std::chrono::seconds(10));
+        return response;
+    };
+
+    CHECK(populate().interfaces.front().description == std::optional<std::string>("Office VPN"));
+    CHECK(calls == 1);
+
+    now += std::chrono::seconds(9);
+    CHECK(populate().interfaces.front().description == std::optional<std::string>("Office VPN"));
+    CHECK(calls == 1);
+
+    now += std::chrono::seconds(1);
+    CHECK(populate().interfaces.front().description == std::optional<std::string>("Office VPN"));
+    CHECK(calls == 2);
+}
+
+TEST_CASE("Keenetic descriptions: zero TTL fetches on every request") {
+    KeeneticInterfaceTestState state;
+    set_system_info_for_tests(SystemInfo{"keenetic", "4.02.C.1", "keenetic"});
+    auto now = std::chrono::steady_clock::time_point{};
+    set_keenetic_interface_now_fn_for_tests([&now] { return now; });
+    int calls = 0;
+    set_keenetic_interface_fetcher_for_tests([&calls](const std::string&,
+                                                       const std::string&,
+                                                       const std::string&) {
+        ++calls;
+        return R"({})";
+    });
+
+    auto first = inventory("eth0");
+    populate_keenetic_interface_descriptions(first, std::chrono::seconds(0));
+    auto second = inventory("eth0");
+    populate_keenetic_interface_descriptions(second, std::chrono::seconds(0));
+
+    CHECK(calls == 2);
+}
+
 TEST_CASE("Keenetic descriptions: non-Keenetic hosts do not query RCI") {
     KeeneticInterfaceTestState state;
     set_system_info_for_tests(SystemInfo{"openwrt", "24.10", "openwrt"});
@@ -114,6 +166,61 @@ TEST_CASE("Keenetic descriptions: non-Keenetic hosts do not query RCI") {
     CHECK(calls == 0);
 }
 
+TEST_CASE("Keenetic interface resolve: maps names and descriptions in both directions") {
+    KeeneticInterfaceTestState state;
+    set_system_info_for_tests(SystemInfo{"keenetic", "4.03.C.1", "keenetic"});
+    set_keenetic_interface_fetcher_for_tests([](const std::string& method,
+                                                 const std::string&,
+                                                 const std::string&) {
+        if (method == "GET") {
+            return R"({"Wireguard0":{"id":"Wireguard0","description":"Office VPN"},"GigabitEthernet1":{"id":"GigabitEthernet1"}})";
+        }
+        return R"({"show":{"interface":[{"system-name":"eth3"},{"system-name":"nwg0"}]}})";
+    });
+
+    const auto by_id = resolve_keenetic_interface_name("wireguard0");
+    REQUIRE(by_id.size() == 1);
+    CHECK(by_id.front().system_name == "nwg0");
+    CHECK(by_id.front().description == "Office VPN");
+
+    const auto by_description = resolve_keenetic_interface_name("office vpn");
+    REQUIRE(by_description.size() == 1);
+    CHECK(by_description.front().system_name == "nwg0");
+
+    const auto by_system_name = resolve_keenetic_interface_name("eth3");
+    REQUIRE(by_system_name.size() == 1);
+    CHECK(by_system_name.front().id == "GigabitEthernet1");
+    CHECK(by_system_name.front().description.empty());
+}
+
+TEST_CASE("Keenetic interface resolve: unknown names yield no match") {
+    KeeneticInterfaceTestState state;
+    set_system_info_for_tests(SystemInfo{"keenetic", "4.03.C.1", "keenetic"});
+    set_keenetic_interface_fetcher_for_tests([](const std::string& method,
+                                                 const std::string&,
+                                                 const std::string&) {
+        if (method == "GET") {
+            return R"({"Wireguard0":{"id":"Wireguard0","description":"Office VPN"}})";
+        }
+        return R"({"show":{"interface":[{"system-name":"nwg0"}]}})";
+    });
+
+    CHECK(resolve_keenetic_interface_name("Wireguard1").empty());
+}
+
+TEST_CASE("Keenetic interface resolve: legacy firmware and other hosts are rejected") {
+    KeeneticInterfaceTestState state;
+    set_keenetic_interface_fetcher_for_tests([](const std::string&, const std::string&, const std::string&) {
+        return std::string{};
+    });
+
+    set_system_info_for_tests(SystemInfo{"keenetic", "4.02.C.1", "keenetic"});
+    CHECK_THROWS_AS(resolve_keenetic_interface_name("Wireguard0"), std::runtime_error);
+
+    set_system_info_for_tests(SystemInfo{"openwrt", "24.10", "openwrt"});
+    CHECK_THROWS_AS(resolve_keenetic_interface_name("eth0"), std::runtime_error);
+}
+
 } // namespace keen_pbr3
 
 #endif // WITH_API
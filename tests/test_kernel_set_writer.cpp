@@ -0,0 +1,128 @@
+#include <doctest/doctest.h>
+
+#include "../src/lists/kernel_set_writer.hpp"
+
+#include <algorithm>
+#include <map>
+#include <set>
+#include <sstream>
+#include <string>
+#include <vector>
+
+using namespace keen_pbr3;
+
+namespace {
+
+class FakeKernelSetWriter : public KernelSetWriter {
+public:
+    std::map<std::string, std::vector<std::string>> sets;
+    // Entries the fake backend refuses; a batch containing one fails whole.
+    std::set<std::string> rejected;
+    int add_calls{0};
+
+    bool exists(const std::string& set_name) const override {
+        return sets.count(set_name) != 0;
+    }
+
+    void add(const std::string& set_name, const std::vector<std::string>& entries) override {
+        ++add_calls;
+        const bool has_rejected =
+            std::any_of(entries.begin(), entries.end(), [this](const std::string& entry) {
+                return rejected.count(entry) != 0;
+            });
+        if (has_rejected) {
+            throw FirewallError("ipset exited with status 1");
+        }
+        auto& set = sets.at(set_name);
+        set.insert(set.end(), entries.begin(), entries.end());
+    }
+};
+
+} // namespace
+
+TEST_CASE("set import adds parsed IPs and CIDRs from stdin") {
+    FakeKernelSetWriter writer;
+    writer.sets["myset"] = {};
+    std::istringstream input("1.2.3.4\n"
+                             "# comment\n"
+                             "\n"
+                             "  10.0.0.0/8  \n"
+                             "2001:db8::/32\n"
+                             "example.com\n"
+                             "not an entry\n");
+
+    const auto result = import_set_entries(input, "myset", writer);
+
+    CHECK(result.added == 3);
+    CHECK(result.skipped == 1);
+    CHECK(result.invalid == 1);
+    CHECK(writer.add_calls == 1);
+    CHECK(writer.sets["myset"] ==
+          std::vector<std::string>{"1.2.3.4", "10.0.0.0/8", "2001:db8::/32"});
+}
+
+TEST_CASE("set import skips entries of the wrong family for daemon sets") {
+    FakeKernelSetWriter writer;
+    writer.sets["kpbr4_vpn"] = {};
+    writer.sets["kpbr6_vpn"] = {};
+    const std::string entries = "192.0.2.1\n2001:db8::1\n";
+
+    std::istringstream v4_input(entries);
+    const auto v4 = import_set_entries(v4_input, "kpbr4_vpn", writer);
+    CHECK(v4.added == 1);
+    CHECK(v4.skipped == 1);
+    CHECK(writer.sets["kpbr4_vpn"] == std::vector<std::string>{"192.0.2.1"});
+
+    std::istringstream v6_input(entries);
+    const auto v6 = import_set_entries(v6_input, "kpbr6_vpn", writer);
+    CHECK(v6.added == 1);
+    CHECK(writer.sets["kpbr6_vpn"] == std::vector<std::string>{"2001:db8::1"});
+}
+
+TEST_CASE("set import rejects a missing set before reading input") {
+    FakeKernelSetWriter writer;
+    std::istringstream input("1.2.3.4\n");
+
+    CHECK_THROWS_AS(import_set_entries(input, "missing", writer), FirewallError);
+    CHECK(writer.add_calls == 0);
+    CHECK(input.tellg() == 0);
+}
+
+TEST_CASE("set import does not call the backend without entries") {
+    FakeKernelSetWriter writer;
+    writer.sets["myset"] = {};
+    std::istringstream input("# nothing here\nexample.org\n");
+
+    const auto result = import_set_entries(input, "myset", writer);
+
+    CHECK(result.added == 0);
+    CHECK(result.skipped == 1);
+    CHECK(writer.add_calls == 0);
+}
+
+TEST_CASE("set import retries entries one by one when the batch is rejected") {
+    FakeKernelSetWriter writer;
+    writer.sets["myset"] = {};
+    writer.rejected = {"10.0.0.2"};
+    std::istringstream input("10.0.0.1\n10.0.0.2\n10.0.0.3\n");
+
+    const auto result = import_set_entries(input, "myset", writer);
+
+    CHECK(result.added == 2);
+    CHECK(result.failed == 1);
+    CHECK(writer.add_calls == 4);
+    CHECK(writer.sets["myset"] == std::vector<std::string>{"10.0.0.1", "10.0.0.3"});
+}
+
+TEST_CASE("set import reports every entry as failed when the backend rejects all") {
+    FakeKernelSetWriter writer;
+    writer.sets["myset"] = {};
+    writer.rejected = {"10.0.0.1", "10.0.0.2"};
+    std::istringstream input("10.0.0.1\n10.0.0.2\n");
+
+    const auto result = import_set_entries(input, "myset", writer);
+
+    CHECK(result.added == 0);
+    CHECK(result.failed == 2);
+    CHECK(writer.sets["myset"].empty());
+}
@@ -0,0 +1,122 @@
+#include <doctest/doctest.h>
+
+#include "../src/lists/list_bench.hpp"
+
+#include <chrono>
+#include <memory>
+#include <stdexcept>
+#include <string>
+#include <vector>
+
+using namespace keen_pbr3;
+
+namespace {
+
+class FixedBodyTransport final : public HttpTransport {
+public:
+    explicit FixedBodyTransport(std::string body) : body_(std::move(body)) {}
+
+    HttpTransportResponse perform(const HttpTransportRequest& request) override {
+        last_request = request;
+        ++calls;
+        HttpTransportResponse response;
+        response.status_code = 200;
+        response.body = body_;
+        return response;
+    }
+
+    HttpTransportRequest last_request;
+    int calls{0};
+
+private:
+    std::string body_;
+};
+
+// Returns the given offsets (in milliseconds) from a fixed epoch, one per call.
+ListBenchClock scripted_clock(std::vector<int> offsets_ms) {
+    auto calls = std::make_shared<std::size_t>(0);
+    return [offsets_ms = std::move(offsets_ms), calls]() {
+        const auto epoch = std::chrono::steady_clock::time_point{};
+        const int offset = offsets_ms.at((*calls)++);
+        return epoch + std::chrono::milliseconds(offset);
+    };
+}
+
+Config config_with_list(const ListConfig& list) {
+    Config config;
+    config.lists = std::map<std::string, ListConfig>{{"remote", list}};
+    return config;
+}
+
+} // namespace
+
+TEST_CASE("list bench: reports bytes, entries, timings and throughput") {
+    std::string body = "example.com\nexample.org\n# comment\n192.0.2.1\n198.51.100.0/24\n";
+    body += "#" + std::string(1000000 - body.size() - 2, 'x') + "\n";
+    REQUIRE(body.size() == 1000000);
+    auto transport = std::make_shared<FixedBodyTransport>(body);
+    HttpClient client(transport);
+
+    ListConfig list;
+    list.url = "https://example.com/list.txt";
+    const auto result = bench_list_download(
+        config_with_list(list), "remote", client, scripted_clock({0, 400, 400, 500}));
+
+    CHECK(transport->calls == 1);
+    CHECK(transport->last_request.url == "https://example.com/list.txt");
+    CHECK(result.list_name == "remote");
+    CHECK(result.url == "https://example.com/list.txt");
+    CHECK(result.bytes == 1000000);
+    CHECK(result.download_time == std::chrono::milliseconds(400));
+    CHECK(result.parse_time == std::chrono::milliseconds(100));
+    CHECK(result.domains == 2);
+    CHECK(result.ips == 1);
+    CHECK(result.cidrs == 1);
+    CHECK(result.entries() == 4);
+    CHECK(result.megabytes_per_second() == 2.0);
+
+    const std::string report = format_list_bench_result(result);
+    CHECK(report.find("1000000 bytes in 400.0 ms") != std::string::npos);
+    CHECK(report.find("4 entries in 100.0 ms") != std::string::npos);
+    CHECK(report.find("2.00 MB/s") != std::string::npos);
+}
+
+TEST_CASE("list bench: throughput is zero when no time elapsed") {
+    ListBenchResult result;
+    result.bytes = 1024;
+    CHECK(result.megabytes_per_second() == 0.0);
+}
+
+TEST_CASE("list bench: detour outbound marks the download request") {
+    auto transport = std::make_shared<FixedBodyTransport>("example.com\n");
+    HttpClient client(transport);
+
+    Outbound vpn;
+    vpn.tag = "vpn";
+    vpn.type = OutboundType::INTERFACE;
+    vpn.interface = "wg0";
+
+    ListConfig list;
+    list.url = "https://example.com/list.txt";
+    list.detour = "vpn";
+    Config config = config_with_list(list);
+    config.outbounds = std::vector<Outbound>{vpn};
+
+    (void)bench_list_download(config, "remote", client, scripted_clock({0, 1, 1, 2}));
+
+    const auto marks = allocate_outbound_marks(FwmarkConfig{}, *config.outbounds);
+    CHECK(transport->last_request.fwmark == marks.at(internal_detour_mark_key("vpn")));
+}
+
+TEST_CASE("list bench: unknown and non-URL lists are rejected") {
+    auto transport = std::make_shared<FixedBodyTransport>("");
+    HttpClient client(transport);
+
+    ListConfig local;
+    local.domains = std::vector<std::string>{"example.com"};
+    const Config config = config_with_list(local);
+
+    CHECK_THROWS_AS(bench_list_download(config, "missing", client), std::runtime_error);
+    CHECK_THROWS_AS(bench_list_download(config, "remote", client), std::runtime_error);
+    CHECK(transport->calls == 0);
+}
@@ -0,0 +1,220 @@
+#include <doctest/doctest.h>
+
+#include "../src/lists/list_diff.hpp"
+
+#include <filesystem>
+#include <fstream>
+#include <memory>
+#include <set>
+#include <sstream>
+#include <stdexcept>
+#include <string>
+#include <unistd.h>
+#include <vector>
+
+using namespace keen_pbr3;
+
+namespace {
+
+class FixedBodyTransport final : public HttpTransport {
+public:
+    explicit FixedBodyTransport(std::string body) : body_(std::move(body)) {}
+
+    HttpTransportResponse perform(const HttpTransportRequest& request) override {
+        last_request = request;
+        ++calls;
+        HttpTransportResponse response;
+        response.status_code = 200;
+        response.body = body_;
+        return response;
+    }
+
+    HttpTransportRequest last_request;
+    int calls{0};
+
+private:
+    std::string body_;
+};
+
+// Fails every URL listed in `down` with a 503 and serves `body` otherwise.
+class MirrorTransport final : public HttpTransport {
+public:
+    HttpTransportResponse perform(const HttpTransportRequest& request) override {
+        urls.push_back(request.url);
+        HttpTransportResponse response;
+        if (down.count(request.url) != 0) {
+            response.status_code = 503;
+            return response;
+        }
+        response.status_code = 200;
+        response.body = body;
+        return response;
+    }
+
+    std::set<std::string> down;
+    std::string body;
+    std::vector<std::string> urls;
+};
+
+class TempDirectory {
+public:
+    TempDirectory() {
+        char pattern[] = "/tmp/keen-pbr-list-diff-XXXXXX";
+        const char* path = ::mkdtemp(pattern);
+        if (!path) throw std::runtime_error("mkdtemp failed");
+        path_ = path;
+    }
+    ~TempDirectory() { std::filesystem::remove_all(path_); }
+    const std::filesystem::path& path() const { return path_; }
+private:
+    std::filesystem::path path_;
+};
+
+Config config_with_url_list() {
+    ListConfig list;
+    list.url = "https://example.com/list.txt";
+    Config config;
+    config.lists = std::map<std::string, ListConfig>{{"remote", list}};
+    return config;
+}
+
+} // namespace
+
+TEST_CASE("list diff: reports added and removed entries between two versions") {
+    std::istringstream cached("example.com\n"
+                              "# old comment\n"
+                              "192.0.2.1\n"
+                              "198.51.100.0/24\n");
+    std::istringstream fresh("198.51.100.0/24\n"
+                             "example.com\n"
+                             "\n"
+                             "example.org\n"
+                             "example.org\n"
+                             "203.0.113.0/24\n");
+
+    const auto result = diff_list_entries(cached, fresh);
+
+    CHECK(result.added == std::vector<std::string>{"203.0.113.0/24", "example.org"});
+    CHECK(result.removed == std::vector<std::string>{"192.0.2.1"});
+    CHECK(result.unchanged == 2);
+}
+
+TEST_CASE("list diff: compares the cached file with a download without overwriting it") {
+    TempDirectory temp;
+    CacheManager cache(temp.path());
+    {
+        std::ofstream out(cache.cache_path("remote"));
+        out << "old.example\nshared.example\n";
+    }
+    auto transport = std::make_shared<FixedBodyTransport>("shared.example\nnew.example\n");
+    HttpClient client(transport);
+
+    const auto result = diff_cached_list(config_with_url_list(), "remote", cache, client);
+
+    CHECK(transport->calls == 1);
+    CHECK(transport->last_request.url == "https://example.com/list.txt");
+    CHECK(result.cached);
+    CHECK(result.added == std::vector<std::string>{"new.example"});
+    CHECK(result.removed == std::vector<std::string>{"old.example"});
+
+    std::ifstream in(cache.cache_path("remote"));
+    const std::string cached_body((std::istreambuf_iterator<char>(in)), std::istreambuf_iterator<char>());
+    CHECK(cached_body == "old.example\nshared.example\n");
+
+    const std::string report = format_list_diff_result(result);
+    CHECK(report.find("+ new.example\n") != std::string::npos);
+    CHECK(report.find("- old.example\n") != std::string::npos);
+    CHECK(report.find("1 added, 1 removed, 1 unchanged") != std::string::npos);
+
+    const auto json = list_diff_result_to_json(result);
+    CHECK(json.at("added") == nlohmann::json::array({"new.example"}));
+    CHECK(json.at("removed") == nlohmann::json::array({"old.example"}));
+    CHECK(json.at("unchanged") == 1);
+}
+
+TEST_CASE("list diff: without a cached copy every entry is added") {
+    TempDirectory temp;
+    CacheManager cache(temp.path());
+    auto transport = std::make_shared<FixedBodyTransport>("a.example\nb.example\n");
+    HttpClient client(transport);
+
+    const auto result = diff_cached_list(config_with_url_list(), "remote", cache, client);
+
+    CHECK_FALSE(result.cached);
+    CHECK(result.added == std::vector<std::string>{"a.example", "b.example"});
+    CHECK(result.removed.empty());
+    CHECK_FALSE(cache.has_cache("remote"));
+}
+
+TEST_CASE("list diff: unknown and non-URL lists are rejected") {
+    TempDirectory temp;
+    CacheManager cache(temp.path());
+    auto transport = std::make_shared<FixedBodyTransport>("");
+    HttpClient client(transport);
+
+    ListConfig local;
+    local.domains = std::vector<std::string>{"example.com"};
+    Config config;
+    config.lists = std::map<std::string, ListConfig>{{"local", local}};
+
+    CHECK_THROWS_AS(diff_cached_list(config, "missing", cache, client), std::runtime_error);
+    CHECK_THROWS_AS(diff_cached_list(config, "local", cache, client), std::runtime_error);
+    CHECK(transport->calls == 0);
+}
+
+TEST_CASE("list diff: falls back to mirrors in the same order as list refresh") {
+    TempDirectory temp;
+    CacheManager cache(temp.path());
+    auto transport = std::make_shared<MirrorTransport>();
+    transport->down = {"https://example.com/list.txt", "https://mirror-a.example/list.txt"};
+    transport->body = "a.example\n";
+    HttpClient client(transport);
+
+    Config config = config_with_url_list();
+    config.lists->at("remote").fallback_urls = std::vector<std::string>{
+        "https://mirror-a.example/list.txt", "https://mirror-b.example/list.txt"};
+
+    const auto result = diff_cached_list(config, "remote", cache, client);
+
+    CHECK(transport->urls == std::vector<std::string>{"https://example.com/list.txt",
+                                                      "https://mirror-a.example/list.txt",
+                                                      "https://mirror-b.example/list.txt"});
+    CHECK(result.url == "https://mirror-b.example/list.txt");
+    CHECK(result.added == std::vector<std::string>{"a.example"});
+
+    transport->down.insert("https://mirror-b.example/list.txt");
+    CHECK_THROWS_AS(diff_cached_list(config, "remote", cache, client), HttpError);
+}
+
+TEST_CASE("list diff: applies bootstrap DNS and the sha256 check like list refresh") {
+    TempDirectory temp;
+    CacheManager cache(temp.path());
+    auto transport = std::make_shared<MirrorTransport>();
+    transport->body = "a.example\n";
+    HttpClient client(transport);
+
+    Config config = config_with_url_list();
+    config.daemon = DaemonConfig{};
+    config.daemon->bootstrap_dns = "192.0.2.53";
+    config.lists->at("remote").fallback_urls =
+        std::vector<std::string>{"https://mirror.example/list.txt"};
+    config.lists->at("remote").sha256 =
+        "0000000000000000000000000000000000000000000000000000000000000000";
+
+    std::vector<std::string> resolved_hosts;
+    const BootstrapResolver resolver = [&resolved_hosts](const std::string& server,
+                                                         const std::string& host) {
+        CHECK(server == "192.0.2.53");
+        resolved_hosts.push_back(host);
+        return std::vector<std::string>{"198.51.100.7"};
+    };
+
+    try {
+        (void)diff_cached_list(config, "remote", cache, client, resolver);
+        FAIL("expected the SHA-256 mismatch to be reported");
+    } catch (const std::runtime_error& error) {
+        CHECK(std::string(error.what()).find("SHA-256 mismatch") != std::string::npos);
+    }
+    CHECK(resolved_hosts == std::vector<std::string>{"example.com", "mirror.example"});
+    CHECK(transport->urls.size() == 2);
+}
@@ -0,0 +1,79 @@
+#include <doctest/doctest.h>
+
+#include "../src/lists/list_download.hpp"
+#include "../src/log/logger.hpp"
+
+#include <optional>
+#include <string>
+#include <vector>
+
+using namespace keen_pbr3;
+
+TEST_CASE("list download: detour outbound mark is used for the request") {
+    ListConfig list;
+    list.url = "https://example.com/list.txt";
+    list.detour = "vpn";
+    const OutboundMarkMap marks{{internal_detour_mark_key("vpn"), 0x20000}};
+
+    CHECK(list_download_options(Config{}, "remote", list, marks).fwmark == 0x20000);
+}
+
+TEST_CASE("list download: unknown detour falls back to default routing with a warning") {
+    ListConfig list;
+    list.url = "https://example.com/list.txt";
+    list.detour = "missing";
+
+    std::vector<std::string> lines;
+    const LogLevel previous_level = Logger::instance().level();
+    Logger::instance().set_level(LogLevel::warn);
+    Logger::instance().set_sink([&lines](const std::string& line) { lines.push_back(line); });
+    const auto options = list_download_options(Config{}, "remote", list, OutboundMarkMap{});
+    Logger::instance().clear_sink();
+    Logger::instance().set_level(previous_level);
+
+    CHECK(options.fwmark == 0);
+    REQUIRE(lines.size() == 1);
+    CHECK(lines.front().find("detour outbound 'missing' not found") != std::string::npos);
+}
+
+TEST_CASE("list download: options carry bootstrap DNS and the list content checks") {
+    ListConfig list;
+    list.url = "https://example.com/list.txt";
+    list.sha256 = "ABC123";
+    list.validate_content = true;
+    Config config;
+    config.daemon = DaemonConfig{};
+    config.daemon->bootstrap_dns = "192.0.2.53";
+
+    const auto options = list_download_options(config, "remote", list, OutboundMarkMap{});
+
+    CHECK(options.bootstrap_dns == std::optional<std::string>("192.0.2.53"));
+    CHECK(options.expected_sha256 == std::optional<std::string>("ABC123"));
+    CHECK(options.validate_content);
+}
+
+TEST_CASE("list download: url comes before fallback_urls") {
+    ListConfig list;
+    list.url = "https://example.com/list.txt";
+    list.fallback_urls = std::vector<std::string>{"https://a.example/list.txt",
+                                                  "https://b.example/list.txt"};
+
+    CHECK(list_download_urls(Config{}, list) == std::vector<std::string>{"https://example.com/list.txt",
+                                                               "https://a.example/list.txt",
+                                                               "https://b.example/list.txt"});
+}
+
+TEST_CASE("list download: an as:<ASN> url is expanded through asn_source.url_template") {
+    ListConfig list;
+    list.url = "as:13335";
+    Config config;
+    config.asn_source = AsnSourceConfig{};
+    config.asn_source->url_template = "https://asn.example/as/{asn}/aggregated.txt";
+
+    CHECK(list_download_urls(config, list) ==
+          std::vector<std::string>{"https://asn.example/as/13335/aggregated.txt"});
+
+    config.asn_source->url_template.reset();
+    config.asn_source->file = "/opt/pfx2as.txt";
+    CHECK(list_download_urls(config, list) == std::vector<std::string>{"as:13335"});
+}
@@ -30,6 +30,22 @@ TEST_CASE("ListParser normalizes wildcard and root-dot domains") {
     CHECK(visitor.entries[1].second == "_dns._udp.example.com");
 }
 
+TEST_CASE("ListParser accepts tld: entries as single-label domains") {
+    RecordingVisitor visitor;
+    CHECK(ListParser::classify_entry("tld:ru", visitor));
+    CHECK(ListParser::classify_entry("tld:.ONION", visitor));
+    REQUIRE(visitor.entries.size() == 2);
+    CHECK(visitor.entries[0].first == EntryType::Domain);
+    CHECK(visitor.entries[0].second == "ru");
+    CHECK(visitor.entries[1].second == "ONION");
+
+    for (const std::string value : {"tld:", "tld:co.uk", "tld:*.ru", "tld:-ru", "tld:r u"}) {
+        CAPTURE(value);
+        CHECK_FALSE(ListParser::classify_entry(value, visitor));
+    }
+    CHECK(visitor.entries.size() == 2);
+}
+
 TEST_CASE("ListParser applies identical domain rules to streamed sources") {
     std::istringstream input(
         "*.google.com\n"
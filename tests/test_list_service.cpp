@@ -10,6 +10,8 @@
 #include <cstdlib>
 #include <cstring>
 #include <filesystem>
+#include <fstream>
+#include <iterator>
 #include <map>
 #include <mutex>
 #include <netinet/in.h>
@@ -258,6 +260,26 @@ TEST_CASE("select_remote_list_targets: refresh-all selects only URL-backed lists
     CHECK(selection.list_names.front() == "remote");
 }
 
+TEST_CASE("select_remote_list_targets: refresh-all skips disabled lists") {
+    Config config;
+
+    ListConfig remote;
+    remote.url = "https://example.com/remote.txt";
+
+    ListConfig disabled = remote;
+    disabled.enabled = false;
+
+    config.lists = std::map<std::string, ListConfig>{
+        {"disabled", disabled},
+        {"remote", remote},
+    };
+
+    const auto selection = select_remote_list_targets(config, std::nullopt);
+
+    CHECK(selection.ok());
+    CHECK(selection.list_names == std::vector<std::string>{"remote"});
+}
+
 TEST_CASE("select_remote_list_targets: single URL-backed list is accepted") {
     Config config;
 
@@ -297,6 +319,20 @@ TEST_CASE("select_remote_list_targets: non-URL-backed list returns not remote")
     CHECK(selection.list_names.empty());
 }
 
+TEST_CASE("select_remote_list_targets: disabled list returns disabled") {
+    Config config;
+
+    ListConfig remote;
+    remote.url = "https://example.com/remote.txt";
+    remote.enabled = false;
+    config.lists = std::map<std::string, ListConfig>{{"remote", remote}};
+
+    const auto selection = select_remote_list_targets(config, std::string("remote"));
+
+    CHECK(selection.error == RemoteListTargetSelectionError::Disabled);
+    CHECK(selection.list_names.empty());
+}
+
 TEST_CASE("should_reload_runtime_after_list_refresh: only relevant changes "
           "reload active runtime") {
     RemoteListsRefreshResult refresh_result;
@@ -310,6 +346,28 @@ TEST_CASE("should_reload_runtime_after_list_refresh: only relevant changes "
     CHECK_FALSE(should_reload_runtime_after_list_refresh(true, refresh_result));
 }
 
+TEST_CASE("should_force_list_reimport: unchanged lists are re-imported once the "
+          "interval has passed") {
+    RemoteListsRefreshResult refresh_result;
+    refresh_result.unchanged_lists = {"remote"};
+    CHECK_FALSE(should_reload_runtime_after_list_refresh(true, refresh_result));
+
+    const auto last_import = std::chrono::steady_clock::time_point{} + std::chrono::hours{100};
+    const std::chrono::hours interval{24};
+
+    CHECK_FALSE(should_force_list_reimport(true, interval, last_import,
+                                           last_import + std::chrono::hours{23}));
+    CHECK(should_force_list_reimport(true, interval, last_import,
+                                     last_import + std::chrono::hours{24}));
+    CHECK(should_force_list_reimport(true, interval, last_import,
+                                     last_import + std::chrono::hours{30}));
+
+    CHECK_FALSE(should_force_list_reimport(false, interval, last_import,
+                                           last_import + std::chrono::hours{30}));
+    CHECK_FALSE(should_force_list_reimport(true, std::chrono::hours{0}, last_import,
+                                           last_import + std::chrono::hours{30}));
+}
+
 TEST_CASE("build_list_refresh_state_map: URL-backed lists expose last_updated "
           "metadata only") {
     const auto temp_dir = make_temp_dir();
@@ -503,6 +561,222 @@ TEST_CASE("refresh_remote_lists: raw-identical 200 response does not change cach
     std::filesystem::remove_all(temp_dir);
 }
 
+TEST_CASE("refresh_remote_lists: sha256 pin accepts matching and rejects mismatched content") {
+    CurlGlobalGuard curl_guard;
+    TestHttpServer server({
+        {"/pinned.txt", HttpResponse{200, "OK", "example.com\n"}},
+        {"/tampered.txt", HttpResponse{200, "OK", "evil.example\n"}},
+    });
+    LoggerCapture logs;
+
+    const auto temp_dir = make_temp_dir();
+    ListService service(temp_dir);
+    service.ensure_dir();
+
+    // sha256 of "example.com\n"
+    const std::string digest = "391196688aa55d3321deffa736f8d103b4813470952b748e9c2c9deb17fa60f5";
+    ListConfig remote;
+    remote.url = server.url("/pinned.txt");
+    remote.sha256 = digest;
+    Config config;
+    config.lists = std::map<std::string, ListConfig>{{"remote", remote}};
+
+    const auto accepted = service.refresh_remote_lists(config, OutboundMarkMap{});
+    CHECK(accepted.changed_lists == std::vector<std::string>{"remote"});
+    REQUIRE(service.cache_manager().has_cache("remote"));
+
+    remote.url = server.url("/tampered.txt");
+    config.lists = std::map<std::string, ListConfig>{{"remote", remote}};
+    const auto rejected = service.refresh_remote_lists(config, OutboundMarkMap{});
+    CHECK(rejected.failed_lists == std::vector<std::string>{"remote"});
+    CHECK(rejected.changed_lists.empty());
+    CHECK(logs.contains("SHA-256 mismatch: expected " + digest));
+
+    std::ifstream cached(service.cache_manager().cache_path("remote"));
+    const std::string cached_body((std::istreambuf_iterator<char>(cached)),
+                                  std::istreambuf_iterator<char>());
+    CHECK(cached_body == "example.com\n");
+
+    std::filesystem::remove_all(temp_dir);
+}
+
+TEST_CASE("refresh_remote_lists: validate_content rejects an HTML error page") {
+    CurlGlobalGuard curl_guard;
+    TestHttpServer server({
+        {"/list.txt", HttpResponse{200, "OK", "example.com\n", {"Content-Type: text/plain; charset=utf-8"}}},
+        {"/portal.txt",
+         HttpResponse{200, "OK", "<!DOCTYPE html>\n<html><body>Not Found</body></html>\n",
+                      {"Content-Type: text/plain"}}},
+        {"/error.txt", HttpResponse{200, "OK", "example.org\n", {"Content-Type: text/html"}}},
+    });
+    LoggerCapture logs;
+
+    const auto temp_dir = make_temp_dir();
+    ListService service(temp_dir);
+    service.ensure_dir();
+
+    ListConfig remote;
+    remote.url = server.url("/list.txt");
+    remote.validate_content = true;
+    Config config;
+    config.lists = std::map<std::string, ListConfig>{{"remote", remote}};
+
+    const auto accepted = service.refresh_remote_lists(config, OutboundMarkMap{});
+    CHECK(accepted.changed_lists == std::vector<std::string>{"remote"});
+    REQUIRE(service.cache_manager().has_cache("remote"));
+
+    remote.url = server.url("/portal.txt");
+    config.lists = std::map<std::string, ListConfig>{{"remote", remote}};
+    const auto html_body = service.refresh_remote_lists(config, OutboundMarkMap{});
+    CHECK(html_body.failed_lists == std::vector<std::string>{"remote"});
+    CHECK(logs.contains("content looks like HTML"));
+
+    remote.url = server.url("/error.txt");
+    config.lists = std::map<std::string, ListConfig>{{"remote", remote}};
+    const auto html_type = service.refresh_remote_lists(config, OutboundMarkMap{});
+    CHECK(html_type.failed_lists == std::vector<std::string>{"remote"});
+    CHECK(logs.contains("unexpected Content-Type text/html"));
+
+    std::ifstream cached(service.cache_manager().cache_path("remote"));
+    const std::string cached_body((std::istreambuf_iterator<char>(cached)),
+                                  std::istreambuf_iterator<char>());
+    CHECK(cached_body == "example.com\n");
+
+    // Without validate_content the page is cached like any other list.
+    remote.url = server.url("/portal.txt");
+    remote.validate_content.reset();
+    config.lists = std::map<std::string, ListConfig>{{"remote", remote}};
+    const auto unchecked = service.refresh_remote_lists(config, OutboundMarkMap{});
+    CHECK(unchecked.changed_lists == std::vector<std::string>{"remote"});
+
+    std::filesystem::remove_all(temp_dir);
+}
+
+TEST_CASE("refresh_remote_lists: fallback URL is used when the primary fails") {
+    CurlGlobalGuard curl_guard;
+    TestHttpServer server({
+        {"/primary.txt", HttpResponse{503, "Service Unavailable", ""}},
+        {"/mirror.txt", HttpResponse{200, "OK", "mirror.example\n"}},
+    });
+    LoggerCapture logs;
+
+    const auto temp_dir = make_temp_dir();
+    ListService service(temp_dir);
+    service.ensure_dir();
+
+    ListConfig remote;
+    remote.url = server.url("/primary.txt");
+    remote.fallback_urls = std::vector<std::string>{server.url("/mirror.txt")};
+    Config config;
+    config.lists = std::map<std::string, ListConfig>{{"remote", remote}};
+
+    const auto result = service.refresh_remote_lists(config, OutboundMarkMap{});
+
+    CHECK(result.failed_lists.empty());
+    CHECK(result.changed_lists == std::vector<std::string>{"remote"});
+    CHECK(logs.contains("List 'remote': failed to refresh " + *remote.url + ": HTTP 503"));
+    CHECK(service.cache_manager().load_metadata("remote").url == server.url("/mirror.txt"));
+
+    std::ifstream cached(service.cache_manager().cache_path("remote"));
+    const std::string cached_body((std::istreambuf_iterator<char>(cached)),
+                                  std::istreambuf_iterator<char>());
+    CHECK(cached_body == "mirror.example\n");
+
+    std::filesystem::remove_all(temp_dir);
+}
+
+TEST_CASE("refresh_remote_lists: list fails only when every URL fails") {
+    CurlGlobalGuard curl_guard;
+    TestHttpServer server({});
+    LoggerCapture logs;
+
+    const auto temp_dir = make_temp_dir();
+    ListService service(temp_dir);
+    service.ensure_dir();
+
+    ListConfig remote;
+    remote.url = server.url("/primary.txt");
+    remote.fallback_urls = std::vector<std::string>{server.url("/mirror.txt")};
+    Config config;
+    config.lists = std::map<std::string, ListConfig>{{"remote", remote}};
+
+    const auto result = service.refresh_remote_lists(config, OutboundMarkMap{});
+
+    CHECK(result.failed_lists == std::vector<std::string>{"remote"});
+    CHECK(logs.contains("failed to refresh " + server.url("/primary.txt") + ": HTTP 404"));
+    CHECK(logs.contains("failed to refresh " + server.url("/mirror.txt") + ": HTTP 404"));
+    CHECK_FALSE(service.cache_manager().has_cache("remote"));
+
+    std::filesystem::remove_all(temp_dir);
+}
+
+TEST_CASE("refresh_remote_lists: bootstrap DNS resolves the list host") {
+    CurlGlobalGuard curl_guard;
+    TestHttpServer server({
+        {"/list.txt", HttpResponse{200, "OK", "bootstrap.example\n"}},
+    });
+
+    const auto temp_dir = make_temp_dir();
+    ListService service(temp_dir);
+    service.ensure_dir();
+    std::vector<std::pair<std::string, std::string>> queries;
+    service.set_bootstrap_resolver([&queries](const std::string& dns_server, const std::string& host) {
+        queries.emplace_back(dns_server, host);
+        return std::vector<std::string>{"127.0.0.1"};
+    });
+
+    std::string url = server.url("/list.txt");
+    url.replace(url.find("127.0.0.1"), std::strlen("127.0.0.1"), "lists.invalid");
+    ListConfig remote;
+    remote.url = url;
+    Config config;
+    config.daemon = DaemonConfig{};
+    config.daemon->bootstrap_dns = "192.0.2.53";
+    config.lists = std::map<std::string, ListConfig>{{"remote", remote}};
+
+    const auto result = service.refresh_remote_lists(config, OutboundMarkMap{});
+
+    CHECK(result.failed_lists.empty());
+    CHECK(result.changed_lists == std::vector<std::string>{"remote"});
+    REQUIRE(queries.size() == 1);
+    CHECK(queries[0].first == "192.0.2.53");
+    CHECK(queries[0].second == "lists.invalid");
+
+    std::ifstream cached(service.cache_manager().cache_path("remote"));
+    const std::string cached_body((std::istreambuf_iterator<char>(cached)),
+                                  std::istreambuf_iterator<char>());
+    CHECK(cached_body == "bootstrap.example\n");
+
+    std::filesystem::remove_all(temp_dir);
+}
+
+TEST_CASE("refresh_remote_lists: bootstrap DNS failure fails the list") {
+    CurlGlobalGuard curl_guard;
+    LoggerCapture logs;
+
+    const auto temp_dir = make_temp_dir();
+    ListService service(temp_dir);
+    service.ensure_dir();
+    service.set_bootstrap_resolver([](const std::string&, const std::string&) -> std::vector<std::string> {
+        throw std::runtime_error("DNS upstream answered NXDOMAIN");
+    });
+
+    ListConfig remote;
+    remote.url = "http://lists.invalid/list.txt";
+    Config config;
+    config.daemon = DaemonConfig{};
+    config.daemon->bootstrap_dns = "192.0.2.53";
+    config.lists = std::map<std::string, ListConfig>{{"remote", remote}};
+
+    const auto result = service.refresh_remote_lists(config, OutboundMarkMap{});
+
+    CHECK(result.failed_lists == std::vector<std::string>{"remote"});
+    CHECK(logs.contains("bootstrap DNS 192.0.2.53 failed to resolve lists.invalid: "
+                        "DNS upstream answered NXDOMAIN"));
+
+    std::filesystem::remove_all(temp_dir);
+}
+
 TEST_CASE("collect_relevant_list_names: ignores disabled route and dns rules") {
     Config config;
 
@@ -553,3 +827,43 @@ TEST_CASE("collect_relevant_list_names: ignores disabled route and dns rules") {
     CHECK(dns_relevant_lists.count("dns_disabled") == 0);
     CHECK(dns_relevant_lists.count("dns_enabled") == 1);
 }
+
+TEST_CASE("refresh_remote_lists: as:<ASN> lists are cached from asn_source") {
+    CurlGlobalGuard curl_guard;
+    TestHttpServer server({
+        {"/as/13335.txt", HttpResponse{200, "OK", "1.1.1.0/24\n"}},
+    });
+
+    const auto temp_dir = make_temp_dir();
+    ListService service(temp_dir / "cache");
+    service.ensure_dir();
+    {
+        std::ofstream dump(temp_dir / "pfx2as.txt");
+        dump << "1.0.0.0\t24\t13335\n8.8.8.0\t24\t15169\n";
+    }
+
+    ListConfig asn_list;
+    asn_list.url = "as:13335";
+    Config config;
+    config.lists = std::map<std::string, ListConfig>{{"cloudflare", asn_list}};
+    config.asn_source = AsnSourceConfig{};
+    config.asn_source->file = (temp_dir / "pfx2as.txt").string();
+
+    const auto from_dump = service.refresh_remote_lists(config, OutboundMarkMap{});
+    CHECK(from_dump.changed_lists == std::vector<std::string>{"cloudflare"});
+    {
+        std::ifstream cached(service.cache_manager().cache_path("cloudflare"));
+        const std::string body((std::istreambuf_iterator<char>(cached)),
+                               std::istreambuf_iterator<char>());
+        CHECK(body == "1.0.0.0/24\n");
+    }
+
+    config.asn_source = AsnSourceConfig{};
+    config.asn_source->url_template = server.url("/as/{asn}.txt");
+    const auto from_url = service.refresh_remote_lists(config, OutboundMarkMap{});
+    CHECK(from_url.changed_lists == std::vector<std::string>{"cloudflare"});
+    CHECK(service.cache_manager().load_metadata("cloudflare").url ==
+          std::optional<std::string>(server.url("/as/13335.txt")));
+
+    std::filesystem::remove_all(temp_dir);
+}
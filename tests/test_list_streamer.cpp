@@ -29,7 +29,9 @@ private:
 class CountingVisitor : public ListEntryVisitor {
 public:
     void on_entry(EntryType, std::string_view) override { ++count; }
+    void on_list_complete(const std::string&) override { ++completed; }
     std::size_t count{0};
+    std::size_t completed{0};
 };
 
 void stream_local(const std::filesystem::path& path, std::size_t max_size) {
@@ -82,4 +84,28 @@ TEST_CASE("ListStreamer rejects files and lines over configured bounds") {
     CHECK_THROWS(stream_local(long_line, 8192));
 }
 
+TEST_CASE("ListStreamer treats a disabled list as empty") {
+    TempDirectory temp;
+    const auto path = temp.path() / "list.txt";
+    { std::ofstream out(path); out << "example.com\n192.0.2.0/24\n"; }
+
+    CacheManager cache(temp.path() / "cache", 1024);
+    ListStreamer streamer(cache);
+    ListConfig config;
+    config.file = path.string();
+    config.ip_cidrs = std::vector<std::string>{"198.51.100.0/24"};
+    config.domains = std::vector<std::string>{"example.org"};
+    config.enabled = false;
+
+    CountingVisitor visitor;
+    streamer.stream_list("local", config, visitor);
+    CHECK(visitor.count == 0);
+    CHECK(visitor.completed == 1);
+
+    CountingVisitor cached_visitor;
+    streamer.stream_list_preferring_cache("local", config, cached_visitor);
+    CHECK(cached_visitor.count == 0);
+    CHECK(cached_visitor.completed == 1);
+}
+
 } // namespace keen_pbr3
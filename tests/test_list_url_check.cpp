@@ -0,0 +1,140 @@
+#include <doctest/doctest.h>
+
+#include "../src/lists/list_url_check.hpp"
+
+#include <map>
+#include <memory>
+#include <stdexcept>
+#include <string>
+#include <vector>
+
+using namespace keen_pbr3;
+
+namespace {
+
+// Answers HEAD and ranged GET requests per URL; unknown URLs are unreachable.
+class MockServerTransport final : public HttpTransport {
+public:
+    HttpTransportResponse perform(const HttpTransportRequest& request) override {
+        requests.push_back(request);
+        const auto it = responses.find(request.method + " " + request.url);
+        if (it == responses.end()) {
+            throw HttpTransportError("HTTP request failed: Couldn't connect to server");
+        }
+        return it->second;
+    }
+
+    std::map<std::string, HttpTransportResponse> responses;
+    std::vector<HttpTransportRequest> requests;
+};
+
+HttpTransportResponse response(long status, std::map<std::string, std::string> headers = {}) {
+    HttpTransportResponse result;
+    result.status_code = status;
+    result.headers = std::move(headers);
+    return result;
+}
+
+} // namespace
+
+TEST_CASE("list url check: reports reachable and unreachable URLs") {
+    auto transport = std::make_shared<MockServerTransport>();
+    transport->responses["HEAD https://a.example/list.txt"] =
+        response(200, {{"content-length", "2048"}, {"content-type", "text/plain"}});
+    transport->responses["HEAD https://b.example/gone.txt"] = response(404);
+    HttpClient client(transport);
+
+    ListConfig reachable;
+    reachable.url = "https://a.example/list.txt";
+    reachable.fallback_urls = std::vector<std::string>{"https://down.example/list.txt"};
+    ListConfig missing;
+    missing.url = "https://b.example/gone.txt";
+    ListConfig disabled;
+    disabled.url = "https://c.example/list.txt";
+    disabled.enabled = false;
+    ListConfig local;
+    local.domains = std::vector<std::string>{"example.com"};
+
+    Config config;
+    config.lists = std::map<std::string, ListConfig>{
+        {"a", reachable}, {"b", missing}, {"c", disabled}, {"d", local}};
+
+    const auto checks = check_list_urls(config, client);
+    REQUIRE(checks.size() == 3);
+    CHECK(transport->requests.size() == 3);
+
+    CHECK(checks[0].list_name == "a");
+    CHECK(checks[0].ok());
+    CHECK(checks[0].status_code == 200);
+    CHECK(checks[0].size == std::optional<uint64_t>(2048));
+    CHECK(checks[0].content_type == "text/plain");
+
+    CHECK(checks[1].url == "https://down.example/list.txt");
+    CHECK_FALSE(checks[1].reachable);
+    CHECK_FALSE(checks[1].ok());
+    CHECK(checks[1].error.find("Couldn't connect to server") != std::string::npos);
+
+    CHECK(checks[2].list_name == "b");
+    CHECK(checks[2].reachable);
+    CHECK_FALSE(checks[2].ok());
+    CHECK(checks[2].status_code == 404);
+
+    const std::string report = format_list_url_checks(checks);
+    CHECK(report.find("OK   a https://a.example/list.txt: HTTP 200, size 2048, type text/plain")
+          != std::string::npos);
+    CHECK(report.find("FAIL a https://down.example/list.txt: HTTP request failed")
+          != std::string::npos);
+    CHECK(report.find("FAIL b https://b.example/gone.txt: HTTP 404, size unknown, type unknown")
+          != std::string::npos);
+}
+
+TEST_CASE("list url check: falls back to a ranged GET when HEAD is rejected") {
+    auto transport = std::make_shared<MockServerTransport>();
+    transport->responses["HEAD https://a.example/list.txt"] = response(405);
+    transport->responses["GET https://a.example/list.txt"] =
+        response(206, {{"content-range", "bytes 0-0/4096"}, {"content-length", "1"}});
+    HttpClient client(transport);
+
+    ListConfig list;
+    list.url = "https://a.example/list.txt";
+    Config config;
+    config.lists = std::map<std::string, ListConfig>{{"a", list}};
+
+    const auto checks = check_list_urls(config, client);
+    REQUIRE(checks.size() == 1);
+    CHECK(checks[0].ok());
+    CHECK(checks[0].size == std::optional<uint64_t>(4096));
+    REQUIRE(transport->requests.size() == 2);
+    CHECK(transport->requests[1].headers == std::vector<std::string>{"Range: bytes=0-0"});
+    CHECK(transport->requests[1].discard_body);
+}
+
+TEST_CASE("list url check: resolves list hosts through daemon.bootstrap_dns") {
+    auto transport = std::make_shared<MockServerTransport>();
+    transport->responses["HEAD https://a.example/list.txt"] = response(200);
+    HttpClient client(transport);
+
+    ListConfig list;
+    list.url = "https://a.example/list.txt";
+    list.fallback_urls = std::vector<std::string>{"https://b.example/list.txt"};
+    Config config;
+    config.daemon = DaemonConfig{};
+    config.daemon->bootstrap_dns = "192.0.2.53";
+    config.lists = std::map<std::string, ListConfig>{{"a", list}};
+
+    const BootstrapResolver resolver = [](const std::string&, const std::string& host) {
+        if (host == "b.example") {
+            throw std::runtime_error("NXDOMAIN");
+        }
+        return std::vector<std::string>{"198.51.100.7"};
+    };
+    const auto checks = check_list_urls(config, client, resolver);
+
+    REQUIRE(checks.size() == 2);
+    CHECK(checks[0].ok());
+    REQUIRE(transport->requests.size() == 1);
+    CHECK(transport->requests[0].resolve ==
+          std::vector<std::string>{"a.example:443:198.51.100.7"});
+    CHECK_FALSE(checks[1].reachable);
+    CHECK(checks[1].error == "bootstrap DNS 192.0.2.53 failed to resolve b.example: NXDOMAIN");
+}
@@ -397,6 +397,23 @@ TEST_CASE("build_rule_add_commands: conntrack restore is masked, ordered, and fa
   CHECK(commands[1]["add"]["rule"]["expr"][0]["match"]["right"] == "@myset");
 }
 
+TEST_CASE("build_mark_rule_json: rule-level inbound interfaces match iifname") {
+  ProtoPortFilter single;
+  single.inbound_interfaces = {"br0"};
+  auto j = T::build_mark_rule_json("myset", AF_INET, 256, single);
+  const auto &expr = j["add"]["rule"]["expr"];
+  CHECK(expr[0]["match"]["right"] == "@myset");
+  CHECK(expr[1]["match"]["op"] == "==");
+  CHECK(expr[1]["match"]["left"]["meta"]["key"] == "iifname");
+  CHECK(expr[1]["match"]["right"] == "br0");
+
+  ProtoPortFilter multiple;
+  multiple.inbound_interfaces = {"br0", "br1"};
+  auto m = T::build_mark_rule_json("myset", AF_INET, 256, multiple);
+  CHECK(m["add"]["rule"]["expr"][1]["match"]["right"]["set"] ==
+        nlohmann::json::array({"br0", "br1"}));
+}
+
 TEST_CASE("build_rule_add_commands: config-derived prefilter omits interface guard when inbound list is empty") {
   auto cfg = parse_valid_config(R"({
     "outbounds":[
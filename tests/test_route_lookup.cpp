@@ -0,0 +1,28 @@
+#include <doctest/doctest.h>
+
+#include "routing/route_lookup.hpp"
+
+namespace keen_pbr3 {
+
+TEST_CASE("parse_ip_route_get_device: returns the egress device") {
+    CHECK(parse_ip_route_get_device(
+              "1.1.1.1 via 10.8.0.1 dev wg0 table 100 src 10.8.0.2 mark 0x10000 uid 0 \n"
+              "    cache \n") == std::optional<std::string>("wg0"));
+    CHECK(parse_ip_route_get_device(
+              "2606:4700::1111 from :: via fe80::1 dev eth1 table 101 proto static src "
+              "2001:db8::2 mark 0x20000 metric 1024 pref medium\n") ==
+          std::optional<std::string>("eth1"));
+    CHECK(parse_ip_route_get_device("8.8.8.8 dev ppp0 src 100.64.0.5 uid 0\n") ==
+          std::optional<std::string>("ppp0"));
+}
+
+TEST_CASE("parse_ip_route_get_device: routes without a device") {
+    CHECK_FALSE(parse_ip_route_get_device(
+                    "unreachable 1.1.1.1 table 100 mark 0x10000 uid 0 \n    cache \n")
+                    .has_value());
+    CHECK_FALSE(parse_ip_route_get_device("blackhole 1.1.1.1 table 102 uid 0\n").has_value());
+    CHECK_FALSE(parse_ip_route_get_device("").has_value());
+    CHECK_FALSE(parse_ip_route_get_device("1.1.1.1 via 10.8.0.1 dev").has_value());
+}
+
+} // namespace keen_pbr3
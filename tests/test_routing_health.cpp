@@ -0,0 +1,182 @@
+#include <doctest/doctest.h>
+
+#include "health/ipv6_prefix_check.hpp"
+#include "health/routing_health_checker.hpp"
+
+namespace keen_pbr3 {
+namespace {
+
+RoutingHealthReport passing_report() {
+    RoutingHealthReport report;
+    report.firewall_backend = FirewallBackend::nftables;
+    report.firewall_chain.chain_present = true;
+    report.firewall_chain.prerouting_hook_present = true;
+
+    RouteTableCheck route;
+    route.table_id = 150;
+    route.outbound_tag = "vpn";
+    route.expected_interface = "wg0";
+    route.table_exists = true;
+    route.default_route_present = true;
+    route.interface_matches = true;
+    route.gateway_matches = true;
+    route.status = CheckStatus::ok;
+    report.route_tables.push_back(route);
+
+    PolicyRuleCheck rule;
+    rule.fwmark = 0x00010000;
+    rule.fwmask = 0x00ff0000;
+    rule.expected_table = 150;
+    rule.priority = 150;
+    rule.rule_present_v4 = true;
+    rule.rule_present_v6 = true;
+    rule.status = CheckStatus::ok;
+    report.policy_rules.push_back(rule);
+
+    report.overall_ok = true;
+    return report;
+}
+
+Config ipv6_prefix_config() {
+    Outbound vpn;
+    vpn.tag = "vpn";
+    vpn.type = OutboundType::INTERFACE;
+    vpn.interface = "wg0";
+
+    RouteRule rule;
+    rule.list = std::vector<std::string>{"remote"};
+    rule.outbound = "vpn";
+
+    RouteConfig route;
+    route.inbound_interfaces = std::vector<std::string>{"br0"};
+    route.rules = std::vector<RouteRule>{rule};
+
+    Config config;
+    config.outbounds = std::vector<Outbound>{vpn};
+    config.route = route;
+    return config;
+}
+
+DumpedInterface dumped_interface(const std::string& name, std::vector<std::string> ipv6) {
+    DumpedInterface interface;
+    interface.name = name;
+    interface.admin_up = true;
+    interface.ipv6_addresses = std::move(ipv6);
+    return interface;
+}
+
+} // namespace
+
+TEST_CASE("routing_health_report_to_json: passing report serializes every check row") {
+    const auto json = routing_health_report_to_json(passing_report());
+
+    CHECK(json.at("overall") == "ok");
+    CHECK(json.at("firewall_backend") == "nftables");
+    CHECK(json.at("firewall").at("chain_present") == true);
+    REQUIRE(json.at("route_tables").size() == 1);
+    CHECK(json.at("route_tables")[0].at("outbound_tag") == "vpn");
+    CHECK(json.at("route_tables")[0].at("status") == "ok");
+    REQUIRE(json.at("policy_rules").size() == 1);
+    CHECK(json.at("policy_rules")[0].at("fwmark") == "0x00010000");
+    CHECK(json.at("firewall_rules").empty());
+    CHECK(routing_health_exit_code(json) == 0);
+}
+
+TEST_CASE("routing_health_exit_code: degraded and failed reports exit non-zero") {
+    auto degraded = passing_report();
+    degraded.route_tables[0].status = CheckStatus::missing;
+    degraded.overall_ok = false;
+    const auto degraded_json = routing_health_report_to_json(degraded);
+    CHECK(degraded_json.at("overall") == "degraded");
+    CHECK(degraded_json.at("route_tables")[0].at("status") == "missing");
+    CHECK(routing_health_exit_code(degraded_json) == 1);
+
+    RoutingHealthReport failed;
+    failed.error = "netlink socket unavailable";
+    const auto failed_json = routing_health_report_to_json(failed);
+    CHECK(failed_json.at("overall") == "error");
+    CHECK(failed_json.at("error") == "netlink socket unavailable");
+    CHECK(routing_health_exit_code(failed_json) == 1);
+}
+
+
+TEST_CASE("find_ipv6_prefix_mismatches: warns when the outbound prefix differs from the LAN") {
+    const auto warnings = find_ipv6_prefix_mismatches(
+        ipv6_prefix_config(),
+        {dumped_interface("br0", {"2001:db8:1::1/64", "fe80::1/64"}),
+         dumped_interface("wg0", {"2001:db8:99:5::2/128"})});
+
+    REQUIRE(warnings.size() == 1);
+    CHECK(warnings[0].find("Outbound 'vpn'") != std::string::npos);
+    CHECK(warnings[0].find("wg0 (2001:db8:99:5::/64)") != std::string::npos);
+    CHECK(warnings[0].find("2001:db8:1::/64 on br0") != std::string::npos);
+}
+
+TEST_CASE("find_ipv6_prefix_mismatches: matching prefixes and link-local tunnels stay quiet") {
+    const Config config = ipv6_prefix_config();
+
+    CHECK(find_ipv6_prefix_mismatches(
+              config,
+              {dumped_interface("br0", {"2001:db8:1::1/64"}),
+               dumped_interface("wg0", {"2001:db8:1::8000/128"})})
+              .empty());
+    // A link-local address alone is not a prefix the LAN traffic could use.
+    CHECK(find_ipv6_prefix_mismatches(
+              config,
+              {dumped_interface("br0", {"2001:db8:1::1/64"}),
+               dumped_interface("wg0", {"fe80::2/64"})})
+              .empty());
+}
+
+TEST_CASE("find_ipv6_prefix_mismatches: a ULA-only outbound needs NAT66") {
+    const auto warnings = find_ipv6_prefix_mismatches(
+        ipv6_prefix_config(),
+        {dumped_interface("br0", {"2001:db8:1::1/64"}),
+         dumped_interface("wg0", {"fe80::2/64", "fd00:5::2/64"})});
+
+    REQUIRE(warnings.size() == 1);
+    CHECK(warnings[0].find("Outbound 'vpn'") != std::string::npos);
+    CHECK(warnings[0].find("only has unique local addresses (fd00:5::/64)") != std::string::npos);
+    CHECK(warnings[0].find("2001:db8:1::/64 on br0") != std::string::npos);
+    CHECK(warnings[0].find("NAT66") != std::string::npos);
+}
+
+TEST_CASE("find_ipv6_prefix_mismatches: falls back to the LAN bridge without inbound interfaces") {
+    Config config = ipv6_prefix_config();
+    config.route->inbound_interfaces.reset();
+
+    const auto warnings = find_ipv6_prefix_mismatches(
+        config,
+        {dumped_interface("br-lan", {"2001:db8:1::1/64"}),
+         dumped_interface("wg0", {"2001:db8:99:5::2/128"})});
+    REQUIRE(warnings.size() == 1);
+    CHECK(warnings[0].find("2001:db8:1::/64 on br-lan") != std::string::npos);
+
+    CHECK(find_ipv6_prefix_mismatches(
+              config, {dumped_interface("wg0", {"2001:db8:99:5::2/128"})})
+              .empty());
+}
+
+TEST_CASE("find_ipv6_prefix_mismatches: deprecated addresses of an old prefix are ignored") {
+    auto wan = dumped_interface("wg0", {"2001:db8:1::2/64", "2001:db8:99::2/64"});
+    wan.deprecated_ipv6_addresses = {"2001:db8:1::2/64"};
+    auto lan = dumped_interface("br0", {"2001:db8:1::1/64"});
+
+    CHECK(find_ipv6_prefix_mismatches(ipv6_prefix_config(), {lan, wan}).size() == 1);
+
+    wan.deprecated_ipv6_addresses.clear();
+    CHECK(find_ipv6_prefix_mismatches(ipv6_prefix_config(), {lan, wan}).empty());
+}
+
+TEST_CASE("routing_health_report_to_json: warnings are serialized without degrading overall") {
+    auto report = passing_report();
+    report.warnings.push_back("IPv6 prefix mismatch");
+    const auto json = routing_health_report_to_json(report);
+
+    CHECK(json.at("overall") == "ok");
+    REQUIRE(json.at("warnings").size() == 1);
+    CHECK(json.at("warnings")[0] == "IPv6 prefix mismatch");
+    CHECK(routing_health_exit_code(json) == 0);
+}
+
+} // namespace keen_pbr3
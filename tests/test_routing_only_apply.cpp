@@ -0,0 +1,172 @@
+#include <doctest/doctest.h>
+
+#include "../src/cache/cache_manager.hpp"
+#include "../src/config/config.hpp"
+#include "../src/daemon/routing_only_apply.hpp"
+#include "../src/firewall/firewall_runtime.hpp"
+#include "../src/lists/list_entry_visitor.hpp"
+#include "../src/routing/routing_reconciler.hpp"
+
+#include <filesystem>
+#include <memory>
+#include <netinet/in.h>
+#include <set>
+#include <string>
+#include <unistd.h>
+#include <vector>
+
+namespace keen_pbr3 {
+namespace {
+
+class FakeSets final : public KernelSetWriter {
+public:
+    bool exists(const std::string& set_name) const override {
+        return present.count(set_name) != 0;
+    }
+    void add(const std::string&, const std::vector<std::string>&) override {}
+
+    std::set<std::string> present;
+};
+
+class FakeNetlink final : public RoutingNetlinkOperations {
+public:
+    explicit FakeNetlink(std::vector<std::string>& events) : events_(events) {}
+
+    RouteAddResult add_route(const RouteSpec& route) override {
+        events_.push_back("route " + route.destination + " table " + std::to_string(route.table));
+        return RouteAddResult::Created;
+    }
+    void delete_route(const RouteSpec&) override {}
+    RuleAddResult add_rule_for_family(const RuleSpec& rule, int) override {
+        events_.push_back("rule " + std::to_string(rule.priority));
+        return RuleAddResult::Created;
+    }
+    void delete_rule_for_family(const RuleSpec&, int) override {}
+    std::vector<DumpedRoute> dump_routes_in_table(uint32_t, int = 0) override { return {}; }
+    std::vector<DumpedRule> dump_policy_rules(int = 0) override { return {}; }
+
+private:
+    std::vector<std::string>& events_;
+};
+
+class FakeFirewall final : public Firewall {
+public:
+    explicit FakeFirewall(std::vector<std::string>& events) : events_(events) {}
+
+    void create_ipset(const std::string& set_name, int, uint32_t) override {
+        events_.push_back("set " + set_name);
+    }
+    void create_mark_rule(uint32_t, const FirewallRuleCriteria& criteria) override {
+        events_.push_back("mark " + criteria.dst_set_name.value_or("-"));
+    }
+    void create_drop_rule(const FirewallRuleCriteria&) override {}
+    void create_pass_rule(const FirewallRuleCriteria&) override {}
+    std::unique_ptr<ListEntryVisitor> create_batch_loader(const std::string& set_name) override {
+        return std::make_unique<FunctionalVisitor>(
+            [this, set_name](EntryType, std::string_view entry) {
+                events_.push_back("load " + set_name + " " + std::string(entry));
+            });
+    }
+    void apply(FirewallApplyMode mode) override {
+        events_.push_back(mode == FirewallApplyMode::RulesOnly ? "apply rules-only"
+                                                               : "apply other");
+    }
+    void cleanup() override {}
+    FirewallBackend backend() const override { return FirewallBackend::iptables; }
+
+private:
+    std::vector<std::string>& events_;
+};
+
+class TempDir {
+public:
+    TempDir() {
+        char pattern[] = "/tmp/keen-pbr-routing-only-XXXXXX";
+        const char* created = ::mkdtemp(pattern);
+        REQUIRE(created != nullptr);
+        path = created;
+    }
+    ~TempDir() { std::filesystem::remove_all(path); }
+    std::filesystem::path path;
+};
+
+const char* kConfig = R"({
+    "daemon":{"ipv6_enabled":false},
+    "fwmark":{"start":"0x10000","mask":"0xff0000"},
+    "lists":{
+        "office":{"ip_cidrs":["10.0.0.0/8"]},
+        "remote":{"url":"http://127.0.0.1:9/remote.lst"}
+    },
+    "outbounds":[{"tag":"vpn","type":"interface","interface":"wg0","gateway":"10.8.0.1"}],
+    "route":{"rules":[{"list":["office","remote"],"outbound":"vpn"}]}
+})";
+
+struct Fixture {
+    TempDir cache_dir;
+    CacheManager cache{cache_dir.path};
+    std::vector<std::string> events;
+    FakeNetlink netlink{events};
+    FakeFirewall firewall{events};
+    FakeSets sets;
+    Config config = parse_config(kConfig);
+    OutboundMarkMap marks = allocate_outbound_marks(config.fwmark.value_or(FwmarkConfig{}),
+                                                    config.outbounds.value_or(std::vector<Outbound>{}));
+
+    void run(const std::vector<std::string>& required_sets) {
+        RouteSpec route;
+        route.destination = "default";
+        route.table = 100;
+        route.interface = "wg0";
+        route.gateway = "10.8.0.1";
+        route.family = AF_INET;
+        const RuleSpec rule{0x10000, 0xff0000, 100, 100, AF_INET};
+        apply_routing_only(
+            required_sets,
+            sets,
+            [&] { RoutingReconciler(netlink).reconcile({route}, {rule}); },
+            [&](FirewallApplyMode mode) {
+                (void)apply_runtime_firewall(config, marks, {}, cache, firewall, mode);
+            });
+    }
+};
+
+} // namespace
+
+TEST_CASE("apply_routing_only reapplies routes, rules and firewall rules without importing lists") {
+    Fixture fixture;
+    fixture.sets.present = {"kpbr4_office"};
+
+    fixture.run({"kpbr4_office"});
+
+    CHECK(fixture.events == std::vector<std::string>{
+        "route default table 100",
+        "rule 100",
+        "set kpbr4_office",
+        "mark kpbr4_office",
+        "apply rules-only",
+    });
+    // The URL list was neither downloaded nor cached.
+    CHECK(std::filesystem::is_empty(fixture.cache_dir.path));
+}
+
+TEST_CASE("apply_routing_only aborts before any change when a set is missing") {
+    Fixture fixture;
+    fixture.sets.present = {"kpbr4_office"};
+
+    CHECK_THROWS_WITH_AS(
+        fixture.run({"kpbr4_office", "kpbr4d_office", "kpbr4_remote"}),
+        "kernel set(s) missing: kpbr4d_office, kpbr4_remote; run a full apply to recreate them",
+        RoutingOnlyApplyError);
+    CHECK(fixture.events.empty());
+}
+
+TEST_CASE("referenced_kernel_sets lists each set of the installed rules once") {
+    RuleState first;
+    first.set_names = {"kpbr4_office", "kpbr4d_office"};
+    RuleState second;
+    second.set_names = {"kpbr4_office", "kpbr4_remote"};
+    CHECK(referenced_kernel_sets({first, RuleState{}, second}) ==
+          std::vector<std::string>{"kpbr4_office", "kpbr4d_office", "kpbr4_remote"});
+}
+
+} // namespace keen_pbr3
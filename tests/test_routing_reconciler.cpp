@@ -1,9 +1,13 @@
 #include <doctest/doctest.h>
 
 #include "../src/routing/routing_reconciler.hpp"
+#include "../src/log/logger.hpp"
 
 #include <netinet/in.h>
+#include <optional>
 #include <stdexcept>
+#include <string>
+#include <utility>
 #include <vector>
 
 namespace keen_pbr3 {
@@ -12,6 +16,11 @@ namespace {
 class FakeRoutingNetlink final : public RoutingNetlinkOperations {
 public:
     RouteAddResult add_route(const RouteSpec& route) override {
+        if ((reject_route_table != 0 && route.table == reject_route_table) ||
+            (!reject_route_interface.empty() && route.interface == reject_route_interface)) {
+            throw RouteRejectedError("Failed to add route: Out of memory: route table full "
+                                     "or kernel out of memory (ENOBUFS)");
+        }
         added_routes.push_back(route);
         return route_add_result;
     }
@@ -20,6 +29,9 @@ public:
         deleted_routes.push_back(route);
     }
     RuleAddResult add_rule_for_family(const RuleSpec& rule, int family) override {
+        if (fail_rule_add && rule.priority == fail_rule_priority) {
+            throw std::runtime_error("rule add failed");
+        }
         added_rules.push_back({rule, family});
         return RuleAddResult::Created;
     }
@@ -40,6 +52,10 @@ public:
     std::vector<std::pair<RuleSpec, int>> added_rules;
     std::vector<std::pair<RuleSpec, int>> deleted_rules;
     bool fail_route_delete{false};
+    bool fail_rule_add{false};
+    uint32_t fail_rule_priority{0};
+    uint32_t reject_route_table{0};
+    std::string reject_route_interface;
     RouteAddResult route_add_result{RouteAddResult::Created};
 };
 
@@ -57,6 +73,40 @@ DumpedRoute dumped(const RouteSpec& route) {
             route.blackhole, route.unreachable, route.family, route.metric, route.protocol};
 }
 
+DumpedRoute foreign_route(const std::string& destination, uint32_t table) {
+    DumpedRoute route;
+    route.destination = destination;
+    route.table = table;
+    route.interface = "eth1";
+    route.gateway = "192.168.1.1";
+    route.family = AF_INET;
+    route.protocol = 4;
+    return route;
+}
+
+// Collects warnings logged while in scope.
+class WarningCapture {
+public:
+    WarningCapture() : previous_level_(Logger::instance().level()) {
+        Logger::instance().set_level(LogLevel::warn);
+        Logger::instance().set_sink([this](const std::string& line) {
+            log += line;
+            log.push_back('\n');
+        });
+    }
+    ~WarningCapture() {
+        Logger::instance().clear_sink();
+        Logger::instance().set_level(previous_level_);
+    }
+
+    std::string take() { return std::exchange(log, {}); }
+
+    std::string log;
+
+private:
+    LogLevel previous_level_;
+};
+
 } // namespace
 
 TEST_CASE("RoutingReconciler adopts an intact generated route after restart") {
@@ -141,6 +191,175 @@ TEST_CASE("RoutingReconciler refuses conflicting foreign route before mutation")
     CHECK(netlink.deleted_routes.empty());
 }
 
+TEST_CASE("RoutingReconciler replace policy removes a foreign default before adding its own") {
+    FakeRoutingNetlink netlink;
+    const auto route = desired_route();
+    DumpedRoute foreign_default;
+    foreign_default.destination = "default";
+    foreign_default.table = route.table;
+    foreign_default.interface = "eth1";
+    foreign_default.gateway = "192.168.1.1";
+    foreign_default.family = AF_INET;
+    foreign_default.metric = 100;
+    foreign_default.protocol = 4;
+    netlink.routes.push_back(foreign_default);
+    DumpedRoute foreign_subnet = foreign_default;
+    foreign_subnet.destination = "10.0.0.0/8";
+    netlink.routes.push_back(foreign_subnet);
+    RoutingReconciler reconciler(netlink, true);
+
+    reconciler.reconcile({route}, {});
+
+    REQUIRE(netlink.deleted_routes.size() == 1);
+    CHECK(netlink.deleted_routes[0].destination == "default");
+    CHECK(netlink.deleted_routes[0].gateway == std::optional<std::string>{"192.168.1.1"});
+    REQUIRE(netlink.added_routes.size() == 1);
+    CHECK(netlink.added_routes[0].blackhole);
+}
+
+TEST_CASE("RoutingReconciler metric policy leaves a foreign default in place") {
+    FakeRoutingNetlink netlink;
+    const auto route = desired_route();
+    DumpedRoute foreign_default;
+    foreign_default.destination = "default";
+    foreign_default.table = route.table;
+    foreign_default.interface = "eth1";
+    foreign_default.family = AF_INET;
+    foreign_default.metric = 100;
+    foreign_default.protocol = 4;
+    netlink.routes.push_back(foreign_default);
+    RoutingReconciler reconciler(netlink);
+
+    reconciler.reconcile({route}, {});
+
+    CHECK(netlink.deleted_routes.empty());
+    CHECK(netlink.added_routes.size() == 1);
+}
+
+TEST_CASE("RoutingReconciler warns about foreign routes in its tables") {
+    FakeRoutingNetlink netlink;
+    auto route = desired_route();
+    route.table = 120;
+    DumpedRoute foreign;
+    foreign.destination = "10.0.0.0/8";
+    foreign.table = route.table;
+    foreign.interface = "eth1";
+    foreign.gateway = "192.168.1.1";
+    foreign.family = AF_INET;
+    foreign.protocol = 4;
+    netlink.routes.push_back(foreign);
+    DumpedRoute other_table = foreign;
+    other_table.table = 220;
+    netlink.routes.push_back(other_table);
+    RoutingReconciler reconciler(netlink);
+
+    auto& logger = Logger::instance();
+    const LogLevel previous_level = logger.level();
+    logger.set_level(LogLevel::warn);
+    std::string log;
+    logger.set_sink([&log](const std::string& line) {
+        log += line;
+        log.push_back('\n');
+    });
+
+    reconciler.reconcile({route}, {});
+
+    logger.clear_sink();
+    logger.set_level(previous_level);
+    CHECK(log.find("Route table 120 contains 1 route(s) keen-pbr did not install: "
+                   "10.0.0.0/8 via 192.168.1.1 dev eth1") != std::string::npos);
+    CHECK(log.find("Route table 220") == std::string::npos);
+    CHECK(netlink.added_routes.size() == 1);
+    CHECK(netlink.deleted_routes.empty());
+}
+
+TEST_CASE("RoutingReconciler warns about the same foreign routes once until they change") {
+    FakeRoutingNetlink netlink;
+    auto route = desired_route();
+    route.table = 150;
+    netlink.routes.push_back(foreign_route("10.0.0.0/8", 150));
+    WarningCapture capture;
+    ForeignRouteReports reports;
+
+    // A fresh reconciler per pass sharing the reports, as the daemon does.
+    RoutingReconciler(netlink, reports).reconcile({route}, {});
+    CHECK(capture.take().find("Route table 150 contains 1 route(s)") != std::string::npos);
+
+    netlink.routes.push_back(dumped(route));
+    RoutingReconciler(netlink, reports).reconcile({route}, {});
+    CHECK(capture.take().find("did not install") == std::string::npos);
+
+    netlink.routes.push_back(foreign_route("172.16.0.0/12", 150));
+    RoutingReconciler(netlink, reports).reconcile({route}, {});
+    CHECK(capture.take().find("Route table 150 contains 2 route(s) keen-pbr did not install: "
+                              "10.0.0.0/8 via 192.168.1.1 dev eth1, "
+                              "172.16.0.0/12 via 192.168.1.1 dev eth1") != std::string::npos);
+
+    // Once the table is clean, a returning route is reported again.
+    netlink.routes = {dumped(route)};
+    RoutingReconciler(netlink, reports).reconcile({route}, {});
+    CHECK(capture.take().find("did not install") == std::string::npos);
+    netlink.routes.push_back(foreign_route("10.0.0.0/8", 150));
+    RoutingReconciler(netlink, reports).reconcile({route}, {});
+    CHECK(capture.take().find("Route table 150 contains 1 route(s)") != std::string::npos);
+}
+
+TEST_CASE("RoutingReconciler reports a table again with fresh foreign route state") {
+    FakeRoutingNetlink netlink;
+    auto route = desired_route();
+    route.table = 150;
+    netlink.routes.push_back(foreign_route("10.0.0.0/8", 150));
+    netlink.routes.push_back(dumped(route));
+    WarningCapture capture;
+
+    ForeignRouteReports first;
+    RoutingReconciler(netlink, first).reconcile({route}, {});
+    CHECK(capture.take().find("Route table 150 contains 1 route(s)") != std::string::npos);
+    RoutingReconciler(netlink, first).reconcile({route}, {});
+    CHECK(capture.take().find("did not install") == std::string::npos);
+
+    // Same table ID, same foreign route: nothing was kept outside `first`.
+    ForeignRouteReports second;
+    RoutingReconciler(netlink, second).reconcile({route}, {});
+    CHECK(capture.take().find("Route table 150 contains 1 route(s)") != std::string::npos);
+}
+
+TEST_CASE("RoutingReconciler does not report foreign routes the replace policy deletes") {
+    FakeRoutingNetlink netlink;
+    auto route = desired_route();
+    route.table = 160;
+    netlink.routes.push_back(foreign_route("default", 160));
+    WarningCapture capture;
+
+    RoutingReconciler(netlink, true).reconcile({route}, {});
+
+    REQUIRE(netlink.deleted_routes.size() == 1);
+    CHECK(netlink.deleted_routes[0].destination == "default");
+    CHECK(capture.log.find("did not install") == std::string::npos);
+}
+
+TEST_CASE("RoutingReconciler does not warn when its tables hold only generated routes") {
+    FakeRoutingNetlink netlink;
+    const auto route = desired_route();
+    netlink.routes.push_back(dumped(route));
+    RoutingReconciler reconciler(netlink);
+
+    auto& logger = Logger::instance();
+    const LogLevel previous_level = logger.level();
+    logger.set_level(LogLevel::warn);
+    std::string log;
+    logger.set_sink([&log](const std::string& line) {
+        log += line;
+        log.push_back('\n');
+    });
+
+    reconciler.reconcile({route}, {});
+
+    logger.clear_sink();
+    logger.set_level(previous_level);
+    CHECK(log.find("did not install") == std::string::npos);
+}
+
 TEST_CASE("RoutingReconciler propagates owned route deletion failures") {
     FakeRoutingNetlink netlink;
     auto extra = desired_route();
@@ -166,4 +385,140 @@ TEST_CASE("RoutingReconciler removes stale rules only from active mark namespace
     CHECK(netlink.deleted_rules.front().first.priority == 1001);
 }
 
+TEST_CASE("RoutingReconciler rolls back routes and rules when a later step fails") {
+    FakeRoutingNetlink netlink;
+    RouteSpec stale = desired_route();
+    stale.destination = "192.0.2.0/24";
+    netlink.routes.push_back(dumped(stale));
+    const RouteSpec route = desired_route();
+
+    RuleSpec first{10, 0xff, 100, 1000, AF_INET};
+    RuleSpec second{11, 0xff, 101, 1001, AF_INET};
+    netlink.fail_rule_add = true;
+    netlink.fail_rule_priority = 1001;
+    RoutingReconciler reconciler(netlink);
+
+    CHECK_THROWS_WITH(reconciler.reconcile({route}, {first, second}), "rule add failed");
+
+    // Forward: add route, prune stale route, add first rule. Then undone in
+    // reverse order: delete first rule, restore stale route, delete route.
+    REQUIRE(netlink.added_rules.size() == 1);
+    REQUIRE(netlink.deleted_rules.size() == 1);
+    CHECK(netlink.deleted_rules.front().first.priority == 1000);
+    CHECK(netlink.deleted_rules.front().second == AF_INET);
+    REQUIRE(netlink.added_routes.size() == 2);
+    CHECK(netlink.added_routes[0].destination == "default");
+    CHECK(netlink.added_routes[1].destination == "192.0.2.0/24");
+    CHECK(netlink.added_routes[1].protocol == KEEN_PBR_GENERATED_ROUTE_PROTOCOL);
+    REQUIRE(netlink.deleted_routes.size() == 2);
+    CHECK(netlink.deleted_routes[0].destination == "192.0.2.0/24");
+    CHECK(netlink.deleted_routes[1].destination == "default");
+}
+
+TEST_CASE("RoutingReconciler does not undo anything it did not change") {
+    FakeRoutingNetlink netlink;
+    const auto route = desired_route();
+    netlink.routes.push_back(dumped(route));
+    RuleSpec rule{10, 0xff, 100, 1000, AF_INET};
+    netlink.fail_rule_add = true;
+    netlink.fail_rule_priority = 1000;
+    RoutingReconciler reconciler(netlink);
+
+    CHECK_THROWS_WITH(reconciler.reconcile({route}, {rule}), "rule add failed");
+
+    CHECK(netlink.added_routes.empty());
+    CHECK(netlink.deleted_routes.empty());
+    CHECK(netlink.deleted_rules.empty());
+}
+
+TEST_CASE("RoutingReconciler skips an outbound whose route the kernel rejects") {
+    FakeRoutingNetlink netlink;
+    netlink.reject_route_table = 100;
+
+    RouteSpec vpn4;
+    vpn4.destination = "default";
+    vpn4.table = 100;
+    vpn4.interface = "wg0";
+    vpn4.gateway = "10.8.0.1";
+    vpn4.family = AF_INET;
+    vpn4.outbound = "vpn";
+    RouteSpec vpn6 = vpn4;
+    vpn6.gateway.reset();
+    vpn6.family = AF_INET6;
+    RouteSpec wan = vpn4;
+    wan.table = 101;
+    wan.interface = "ppp0";
+    wan.gateway.reset();
+    wan.outbound = "wan";
+
+    RuleSpec vpn_rule{0x10000, 0xff0000, 100, 100, AF_INET};
+    RuleSpec wan_rule{0x20000, 0xff0000, 101, 102, AF_INET};
+    RoutingReconciler reconciler(netlink);
+
+    CHECK_NOTHROW(reconciler.reconcile({vpn4, vpn6, wan}, {vpn_rule, wan_rule}));
+
+    // Only the first rejected route is attempted; the other outbound and all
+    // policy rules are applied and nothing is rolled back.
+    REQUIRE(netlink.added_routes.size() == 1);
+    CHECK(netlink.added_routes[0].outbound == "wan");
+    CHECK(netlink.added_rules.size() == 2);
+    CHECK(netlink.deleted_routes.empty());
+    CHECK(netlink.deleted_rules.empty());
+
+    REQUIRE(reconciler.rejected_routes().size() == 1);
+    CHECK(reconciler.rejected_routes()[0] ==
+          "Kernel rejected route default via 10.8.0.1 dev wg0 in table 100 for outbound 'vpn': "
+          "Failed to add route: Out of memory: route table full or kernel out of memory "
+          "(ENOBUFS)");
+
+    netlink.reject_route_table = 0;
+    reconciler.reconcile({vpn4, vpn6, wan}, {vpn_rule, wan_rule});
+    CHECK(reconciler.rejected_routes().empty());
+}
+
+TEST_CASE("RoutingReconciler names the table of a rejected route without an outbound") {
+    FakeRoutingNetlink netlink;
+    netlink.reject_route_table = 100;
+    RoutingReconciler reconciler(netlink);
+
+    CHECK_NOTHROW(reconciler.reconcile({desired_route()}, {}));
+    REQUIRE(reconciler.rejected_routes().size() == 1);
+    CHECK(reconciler.rejected_routes()[0].rfind(
+              "Kernel rejected route blackhole default in table 100: ", 0) == 0);
+}
+
+TEST_CASE("RoutingReconciler keeps the previous routes of an outbound whose new route is rejected") {
+    FakeRoutingNetlink netlink;
+    netlink.reject_route_interface = "wg1";
+
+    RouteSpec vpn4;
+    vpn4.destination = "default";
+    vpn4.table = 100;
+    vpn4.interface = "wg1";
+    vpn4.family = AF_INET;
+    vpn4.outbound = "vpn";
+    RouteSpec vpn6 = vpn4;
+    vpn6.family = AF_INET6;
+
+    // Routes a previous apply installed for the outbound's old interface.
+    RouteSpec old4 = vpn4;
+    old4.interface = "wg0";
+    RouteSpec old6 = old4;
+    old6.family = AF_INET6;
+    netlink.routes = {dumped(old4), dumped(old6)};
+    RoutingReconciler reconciler(netlink);
+
+    CHECK_NOTHROW(reconciler.reconcile({vpn4, vpn6}, {}));
+
+    // The IPv4 route was deleted to make room for the new one and put back
+    // after the kernel refused it; the IPv6 route was never touched.
+    REQUIRE(netlink.deleted_routes.size() == 1);
+    CHECK(netlink.deleted_routes[0].family == AF_INET);
+    CHECK(netlink.deleted_routes[0].interface == std::optional<std::string>("wg0"));
+    REQUIRE(netlink.added_routes.size() == 1);
+    CHECK(netlink.added_routes[0].family == AF_INET);
+    CHECK(netlink.added_routes[0].interface == std::optional<std::string>("wg0"));
+    REQUIRE(reconciler.rejected_routes().size() == 1);
+}
+
 } // namespace keen_pbr3
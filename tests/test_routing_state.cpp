@@ -465,6 +465,7 @@ TEST_CASE("populate_routing_state: strict enforcement installs real default when
     REQUIRE(default_route != nullptr);
     CHECK(default_route->interface == std::optional<std::string>{"wg0"});
     CHECK(default_route->gateway == std::optional<std::string>{"10.8.0.1"});
+    CHECK(default_route->outbound == "vpn");
     CHECK(find_route(routes.get_routes(), 100, false, true, kUnreachableRouteMetric) != nullptr);
 }
 
@@ -570,6 +571,72 @@ TEST_CASE("populate_routing_state: outbound true overrides daemon false") {
     CHECK(find_route(routes.get_routes(), 100, false, true, kUnreachableRouteMetric) != nullptr);
 }
 
+TEST_CASE("populate_routing_state: strict_enforcement_ipv6 false keeps the IPv4 kill switch only") {
+    auto cfg = parse_minimal_config(R"({
+        "iproute":{"table_start":100},
+        "daemon":{"strict_enforcement":true},
+        "outbounds":[
+            {"tag":"vpn","type":"interface","interface":"wg0","gateway":"10.8.0.1",
+             "strict_enforcement_ipv6":false}
+        ]
+    })");
+    auto marks = allocate_outbound_marks(cfg.fwmark.value_or(FwmarkConfig{}),
+                                         cfg.outbounds.value_or(std::vector<Outbound>{}));
+
+    for (const bool up : {false, true}) {
+        CAPTURE(up);
+        NetlinkManager netlink;
+        RouteTable routes(netlink, true);
+        PolicyRuleManager rules(netlink, true);
+
+        populate_routing_state(cfg, marks, routes, rules, [up](const Outbound&) {
+            return up;
+        });
+
+        CHECK(count_routes_by_family(routes.get_routes(), AF_INET6) == 0);
+        if (up) {
+            CHECK(find_route(routes.get_routes(), 100, false, false, 0,
+                             std::optional<std::string>{"wg0"}) != nullptr);
+        } else {
+            REQUIRE(routes.get_routes().size() == 1);
+            CHECK(routes.get_routes()[0].family == AF_INET);
+            CHECK(routes.get_routes()[0].unreachable);
+        }
+        REQUIRE(rules.get_rules().size() == 2);
+        CHECK(rules.get_rules()[0].family == 0);
+        CHECK(rules.get_rules()[1].action == RuleAction::unreachable);
+        CHECK(rules.get_rules()[1].family == AF_INET);
+    }
+}
+
+TEST_CASE("populate_routing_state: strict_enforcement_ipv6 true guards IPv6 alone") {
+    auto cfg = parse_minimal_config(R"({
+        "iproute":{"table_start":100},
+        "daemon":{"strict_enforcement":false},
+        "outbounds":[
+            {"tag":"vpn","type":"interface","interface":"wg0","gateway":"10.8.0.1",
+             "strict_enforcement_ipv6":true}
+        ]
+    })");
+    auto marks = allocate_outbound_marks(cfg.fwmark.value_or(FwmarkConfig{}),
+                                         cfg.outbounds.value_or(std::vector<Outbound>{}));
+
+    NetlinkManager netlink;
+    RouteTable routes(netlink, true);
+    PolicyRuleManager rules(netlink, true);
+
+    populate_routing_state(cfg, marks, routes, rules, [](const Outbound&) {
+        return false;
+    });
+
+    REQUIRE(routes.get_routes().size() == 1);
+    CHECK(routes.get_routes()[0].family == AF_INET6);
+    CHECK(routes.get_routes()[0].unreachable);
+    REQUIRE(rules.get_rules().size() == 2);
+    CHECK(rules.get_rules()[1].action == RuleAction::unreachable);
+    CHECK(rules.get_rules()[1].family == AF_INET6);
+}
+
 TEST_CASE("populate_routing_state: strict urltest installs selected primary and weighted fallbacks") {
     auto cfg = parse_minimal_config(R"({
         "iproute":{"table_start":100},
@@ -620,6 +687,42 @@ TEST_CASE("populate_routing_state: strict urltest installs selected primary and
                         }) == 1);
 }
 
+TEST_CASE("populate_routing_state: replace route policy keeps only the selected urltest default") {
+    auto cfg = parse_minimal_config(R"({
+        "iproute":{"table_start":100,"route_policy":"replace"},
+        "daemon":{"strict_enforcement":false},
+        "outbounds":[
+            {"tag":"vpn1","type":"interface","interface":"wg1","gateway":"10.0.1.1"},
+            {"tag":"vpn2","type":"interface","interface":"wg2","gateway":"10.0.2.1"},
+            {"tag":"auto","type":"urltest","url":"http://example.com",
+             "outbound_groups":[{"outbounds":["vpn1","vpn2"]}]}
+        ]
+    })");
+    auto marks = allocate_outbound_marks(cfg.fwmark.value_or(FwmarkConfig{}),
+                                         cfg.outbounds.value_or(std::vector<Outbound>{}));
+
+    std::map<std::string, std::string> selections{{"auto", "vpn2"}};
+
+    NetlinkManager netlink;
+    RouteTable routes(netlink, true);
+    PolicyRuleManager rules(netlink, true);
+
+    populate_routing_state(
+        cfg,
+        marks,
+        routes,
+        rules,
+        [](const Outbound&) { return true; },
+        &selections);
+
+    CHECK(find_route(routes.get_routes(), 102, false, false, 0, std::optional<std::string>{"wg2"}) != nullptr);
+    CHECK(std::none_of(routes.get_routes().begin(), routes.get_routes().end(),
+                       [](const RouteSpec& route) {
+                           return route.table == 102 && route.metric != 0 &&
+                                  !route.unreachable;
+                       }));
+}
+
 TEST_CASE("populate_routing_state: strict urltest skips unreachable children") {
     auto cfg = parse_minimal_config(R"({
         "iproute":{"table_start":100},
@@ -879,6 +982,18 @@ TEST_CASE("interface_has_routed_ipv6 ignores link-local-only interfaces") {
     CHECK(interface_has_routed_ipv6(interface));
 }
 
+TEST_CASE("interface_has_routed_ipv6 ignores deprecated but accepts temporary addresses") {
+    DumpedInterface interface;
+    interface.ipv6_addresses = {"2001:db8:1::10/64", "fe80::1/64"};
+    interface.deprecated_ipv6_addresses = {"2001:db8:1::10/64"};
+    CHECK_FALSE(interface_has_routed_ipv6(interface));
+
+    // A privacy-extension address of the current prefix is not flagged
+    // deprecated and keeps the interface usable.
+    interface.ipv6_addresses.push_back("2001:db8:2::abcd:1234/64");
+    CHECK(interface_has_routed_ipv6(interface));
+}
+
 TEST_CASE("populate_routing_state: interface outbound with IPv4 gateway closes IPv6 with unreachable default") {
     auto cfg = parse_minimal_config(R"({
         "iproute":{"table_start":100},
@@ -1079,3 +1194,87 @@ TEST_CASE("populate_routing_state: non-strict urltest relies on terminal RPDB gu
                             return rule.action == RuleAction::unreachable;
                         }) == 1);
 }
+
+TEST_CASE("populate_routing_state: multipath installs one weighted ECMP route per family") {
+    auto cfg = parse_minimal_config(R"({
+        "iproute":{"table_start":100},
+        "outbounds":[
+            {"tag":"wan1","type":"interface","interface":"eth0","gateway":"192.0.2.1"},
+            {"tag":"wan2","type":"interface","interface":"eth1","gateway":"198.51.100.1"},
+            {"tag":"ecmp","type":"multipath",
+             "nexthops":[{"outbound":"wan1","weight":3},{"outbound":"wan2"}]}
+        ]
+    })");
+    auto marks = allocate_outbound_marks(cfg.fwmark.value_or(FwmarkConfig{}),
+                                         cfg.outbounds.value_or(std::vector<Outbound>{}));
+
+    NetlinkManager netlink;
+    RouteTable routes(netlink, true);
+    PolicyRuleManager rules(netlink, true);
+
+    populate_routing_state(cfg, marks, routes, rules, [](const Outbound&) { return true; });
+
+    REQUIRE(count_routes_in_table(routes.get_routes(), 102) == 2);
+    const auto multipath = std::find_if(
+        routes.get_routes().begin(), routes.get_routes().end(),
+        [](const RouteSpec& route) { return route.table == 102 && route.family == AF_INET; });
+    REQUIRE(multipath != routes.get_routes().end());
+    CHECK_FALSE(multipath->unreachable);
+    CHECK_FALSE(multipath->interface.has_value());
+    CHECK(multipath->nexthops == std::vector<RouteNexthop>{
+        {"eth0", std::string("192.0.2.1"), 3},
+        {"eth1", std::string("198.51.100.1"), 1},
+    });
+    CHECK(find_route(routes.get_routes(), 102, false, true, kUnreachableRouteMetric) != nullptr);
+
+    const auto mark = marks.at("ecmp");
+    CHECK(std::count_if(rules.get_rules().begin(), rules.get_rules().end(),
+                        [mark](const RuleSpec& rule) {
+                            return rule.fwmark == mark && rule.action == RuleAction::unreachable;
+                        }) == 1);
+}
+
+TEST_CASE("populate_routing_state: multipath degrades as paths become unreachable") {
+    auto cfg = parse_minimal_config(R"({
+        "iproute":{"table_start":100},
+        "outbounds":[
+            {"tag":"wan1","type":"interface","interface":"eth0","gateway":"192.0.2.1"},
+            {"tag":"wan2","type":"interface","interface":"eth1","gateway":"198.51.100.1"},
+            {"tag":"ecmp","type":"multipath",
+             "nexthops":[{"outbound":"wan1","weight":3},{"outbound":"wan2"}]}
+        ]
+    })");
+    auto marks = allocate_outbound_marks(cfg.fwmark.value_or(FwmarkConfig{}),
+                                         cfg.outbounds.value_or(std::vector<Outbound>{}));
+
+    SUBCASE("single remaining path becomes a plain default route") {
+        NetlinkManager netlink;
+        RouteTable routes(netlink, true);
+        PolicyRuleManager rules(netlink, true);
+
+        populate_routing_state(cfg, marks, routes, rules,
+                               [](const Outbound& ob) { return ob.tag != "wan1"; });
+
+        const RouteSpec* route = find_route(routes.get_routes(), 102, false, false, 0,
+                                            std::optional<std::string>{"eth1"});
+        REQUIRE(route != nullptr);
+        CHECK(route->gateway == std::optional<std::string>{"198.51.100.1"});
+        CHECK(route->nexthops.empty());
+    }
+
+    SUBCASE("no remaining path closes the table") {
+        NetlinkManager netlink;
+        RouteTable routes(netlink, true);
+        PolicyRuleManager rules(netlink, true);
+
+        populate_routing_state(cfg, marks, routes, rules,
+                               [](const Outbound& ob) { return ob.type != OutboundType::INTERFACE; });
+
+        CHECK(count_routes_in_table(routes.get_routes(), 102) == 2);
+        CHECK(std::all_of(routes.get_routes().begin(), routes.get_routes().end(),
+                          [](const RouteSpec& route) {
+                              return route.table != 102 ||
+                                     (route.unreachable && route.metric == kUnreachableRouteMetric);
+                          }));
+    }
+}
@@ -0,0 +1,59 @@
+#include <doctest/doctest.h>
+
+#include "../src/daemon/runtime_info.hpp"
+
+using namespace keen_pbr3;
+
+TEST_CASE("runtime info reports configured and derived parameters") {
+    const Config config = parse_config(R"({
+        "api": {"enabled": true, "listen": "192.168.1.1:8080"},
+        "lists_autoupdate": {"enabled": true, "cron": "0 4 * * *"},
+        "outbounds": [
+            {"tag": "vpn", "type": "interface", "interface": "wg0"},
+            {"tag": "auto", "type": "urltest", "url": "http://example.com",
+             "outbound_groups": [{"outbounds": ["vpn"]}]}
+        ],
+        "dns": {
+            "servers": [{"tag": "vpn_dns", "address": "10.8.0.1", "detour": "vpn"}],
+            "fallback": ["vpn_dns"],
+            "system_resolver": {"address": "127.0.0.1"}
+        }
+    })");
+    RuntimeInfoOptions options;
+    options.config_path = "/etc/keen-pbr/config.json";
+    options.firewall_backend = "nftables";
+    options.api_running = true;
+    options.interface_monitor_active = true;
+
+    const auto info = runtime_info_json(config, options);
+
+    CHECK(info["config_path"] == "/etc/keen-pbr/config.json");
+    CHECK(info["firewall_backend"] == "nftables");
+    CHECK(info["interface_monitor"] == true);
+    CHECK(info["watch_config"] == false);
+    CHECK(info["api"]["running"] == true);
+    CHECK(info["api"]["listen"] == "192.168.1.1:8080");
+    CHECK(info["lists_autoupdate"]["enabled"] == true);
+    CHECK(info["lists_autoupdate"]["cron"] == "0 4 * * *");
+    CHECK(info["dns"]["system_resolver"] == "127.0.0.1");
+    REQUIRE(info["dns"]["servers"].size() == 1);
+    CHECK(info["dns"]["servers"][0]["tag"] == "vpn_dns");
+    CHECK(info["dns"]["servers"][0]["type"] == "static");
+    CHECK(info["dns"]["servers"][0]["detour"] == "vpn");
+    CHECK(info["dns"]["fallback"] == nlohmann::json::array({"vpn_dns"}));
+    REQUIRE(info["urltest"].size() == 1);
+    CHECK(info["urltest"][0]["tag"] == "auto");
+    CHECK(info["urltest"][0]["interval_ms"] == 180000);
+}
+
+TEST_CASE("runtime info resolves defaults for a minimal config") {
+    const auto info = runtime_info_json(Config{}, RuntimeInfoOptions{});
+
+    CHECK(info["api"]["running"] == false);
+    CHECK(info["api"]["listen"].is_null());
+    CHECK(info["lists_autoupdate"]["enabled"] == false);
+    CHECK(info["lists_autoupdate"]["force_reimport_interval_hours"] == 0);
+    CHECK(info["dns"]["system_resolver"].is_null());
+    CHECK(info["dns"]["servers"].empty());
+    CHECK(info["urltest"].empty());
+}
@@ -311,3 +311,154 @@ TEST_CASE("compute_test_routing includes route rule conditions in diagnostics")
 
     std::filesystem::remove_all(temp_dir);
 }
+
+TEST_CASE("explain_routing_json assembles DNS rule, list matches and verdict for a domain") {
+    if (!udp_socket_available()) {
+        DOCTEST_INFO("UDP sockets unavailable in current environment");
+        return;
+    }
+
+    const auto temp_dir = make_temp_dir();
+    CacheManager cache(temp_dir);
+    cache.ensure_dir();
+
+    TestDnsServer server({"10.0.0.53"}, {});
+
+    Config config = build_test_config();
+    api::SystemResolver system_resolver;
+    system_resolver.address = server.address();
+    config.dns->system_resolver = system_resolver;
+    ListConfig broad;
+    broad.domains = std::vector<std::string>{"example.com"};
+    ListConfig work;
+    work.domains = std::vector<std::string>{"corp.example.com"};
+    config.lists = std::map<std::string, ListConfig>{{"broad", broad}, {"work", work}};
+
+    DnsRule broad_dns;
+    broad_dns.list = {"broad"};
+    broad_dns.server = "public_dns";
+    DnsRule work_dns;
+    work_dns.list = {"work"};
+    work_dns.server = "corp_dns";
+    config.dns->rules = std::vector<DnsRule>{broad_dns, work_dns};
+    config.dns->fallback = std::vector<std::string>{"public_dns"};
+
+    RouteRule route_rule;
+    route_rule.list = std::vector<std::string>{"work"};
+    route_rule.outbound = "vpn";
+    RouteConfig route;
+    route.rules = std::vector<RouteRule>{route_rule};
+    config.route = route;
+
+    const auto result = compute_test_routing(config, cache, "www.corp.example.com");
+
+    // The most specific domain wins even though the broad rule comes first.
+    REQUIRE(result.dns_rule_match.has_value());
+    CHECK(result.dns_rule_match->rule_index == 1);
+    CHECK(result.dns_rule_match->server == "corp_dns");
+    CHECK(result.dns_rule_match->list_match.list_name == "work");
+
+    const auto explained = explain_routing_json(config, result);
+    CHECK(explained["dns"]["server"] == "corp_dns");
+    CHECK(explained["dns"]["list_match"]["via"] == "corp.example.com");
+    CHECK(explained["dns"]["fallback"].is_null());
+    CHECK(explained["resolved_ips"] == nlohmann::json::array({"10.0.0.53"}));
+    REQUIRE(explained["route_rules"].size() == 1);
+    const auto& rule = explained["route_rules"][0];
+    CHECK(rule["outbound"] == "vpn");
+    CHECK(rule["match"]["list"] == "work");
+    REQUIRE(rule["ips"].size() == 1);
+    CHECK(rule["ips"][0]["ip"] == "10.0.0.53");
+    REQUIRE(explained["entries"].size() == 1);
+    CHECK(explained["entries"][0]["expected_outbound"] == "vpn");
+    CHECK(explained["no_matching_rule"] == false);
+
+    const auto unmatched = compute_test_routing(config, cache, "other.example.org");
+    CHECK_FALSE(unmatched.dns_rule_match.has_value());
+    const auto unmatched_json = explain_routing_json(config, unmatched);
+    CHECK(unmatched_json["dns"]["server"].is_null());
+    CHECK(unmatched_json["dns"]["fallback"] == nlohmann::json::array({"public_dns"}));
+
+    std::filesystem::remove_all(temp_dir);
+}
+
+TEST_CASE("compute_domain_match reports list matches without resolving the domain") {
+    const auto temp_dir = make_temp_dir();
+    CacheManager cache(temp_dir);
+    cache.ensure_dir();
+
+    Config config = build_test_config();
+    ListConfig broad;
+    broad.domains = std::vector<std::string>{"example.com"};
+    ListConfig work;
+    work.domains = std::vector<std::string>{"*.corp.example.com"};
+    config.lists = std::map<std::string, ListConfig>{{"broad", broad}, {"work", work}};
+
+    DnsRule work_dns;
+    work_dns.list = {"work"};
+    work_dns.server = "corp_dns";
+    config.dns->rules = std::vector<DnsRule>{work_dns};
+
+    RouteRule work_rule;
+    work_rule.list = std::vector<std::string>{"work"};
+    work_rule.outbound = "vpn";
+    RouteRule broad_rule;
+    broad_rule.list = std::vector<std::string>{"broad"};
+    broad_rule.outbound = "wan";
+    RouteRule disabled_rule = broad_rule;
+    disabled_rule.enabled = false;
+    RouteConfig route;
+    route.rules = std::vector<RouteRule>{disabled_rule, work_rule, broad_rule};
+    config.route = route;
+
+    const auto result = compute_domain_match(config, cache, "WWW.Corp.Example.com");
+    CHECK(result.domain == "WWW.Corp.Example.com");
+    CHECK(result.outbound == "vpn");
+    REQUIRE(result.route_matches.size() == 2);
+    CHECK(result.route_matches[0].rule_index == 1);
+    CHECK(result.route_matches[0].list_match.list_name == "work");
+    CHECK(result.route_matches[0].list_match.via == "corp.example.com");
+    CHECK(result.route_matches[1].rule_index == 2);
+    CHECK(result.route_matches[1].outbound == "wan");
+    CHECK(result.route_matches[1].list_match.via == "example.com");
+    REQUIRE(result.dns_rule_match.has_value());
+    CHECK(result.dns_rule_match->server == "corp_dns");
+
+    const auto unlisted = compute_domain_match(config, cache, "example.org");
+    CHECK(unlisted.route_matches.empty());
+    CHECK(unlisted.outbound == "(default)");
+    CHECK_FALSE(unlisted.dns_rule_match.has_value());
+
+    std::filesystem::remove_all(temp_dir);
+}
+
+TEST_CASE("compute_domain_match matches tld: entries against every domain under the TLD") {
+    const auto temp_dir = make_temp_dir();
+    CacheManager cache(temp_dir);
+    cache.ensure_dir();
+
+    Config config = build_test_config();
+    ListConfig ru;
+    ru.domains = std::vector<std::string>{"tld:ru"};
+    config.lists = std::map<std::string, ListConfig>{{"ru", ru}};
+    config.dns->rules = std::vector<DnsRule>{};
+
+    RouteRule ru_rule;
+    ru_rule.list = std::vector<std::string>{"ru"};
+    ru_rule.outbound = "vpn";
+    RouteConfig route;
+    route.rules = std::vector<RouteRule>{ru_rule};
+    config.route = route;
+
+    const auto matched = compute_domain_match(config, cache, "example.ru");
+    CHECK(matched.outbound == "vpn");
+    REQUIRE(matched.route_matches.size() == 1);
+    CHECK(matched.route_matches[0].list_match.list_name == "ru");
+    CHECK(matched.route_matches[0].list_match.via == "ru");
+
+    const auto unmatched = compute_domain_match(config, cache, "example.com");
+    CHECK(unmatched.route_matches.empty());
+    CHECK(unmatched.outbound == "(default)");
+
+    std::filesystem::remove_all(temp_dir);
+}
@@ -46,6 +46,14 @@ public:
         });
     }
 
+    std::size_t count(const std::string& needle) const {
+        std::lock_guard<std::mutex> lock(mutex_);
+        return static_cast<std::size_t>(
+            std::count_if(lines_.begin(), lines_.end(), [&needle](const std::string& line) {
+                return line.find(needle) != std::string::npos;
+            }));
+    }
+
     bool wait_for_contains(const std::string& needle,
                            std::chrono::milliseconds timeout = std::chrono::milliseconds(250)) const {
         std::unique_lock<std::mutex> lock(mutex_);
@@ -80,6 +88,47 @@ TEST_CASE("trace logger includes trace id and event metadata") {
     CHECK(capture.contains("value=42"));
 }
 
+TEST_CASE("throttled warnings coalesce repeats and report the suppressed count") {
+    LoggerCapture capture;
+    auto& logger = Logger::instance();
+
+    for (int i = 0; i < 5; ++i) {
+        logger.warn_throttled("unit-test-hour", std::chrono::hours(1), "upstream {} down", "a");
+    }
+    CHECK(capture.count("upstream a down") == 1);
+    CHECK_FALSE(capture.contains("suppressed"));
+
+    // Keys are independent.
+    logger.warn_throttled("unit-test-other", std::chrono::hours(1), "upstream b down");
+    CHECK(capture.count("upstream b down") == 1);
+
+    const auto interval = std::chrono::milliseconds(20);
+    for (int i = 0; i < 4; ++i) {
+        logger.warn_throttled("unit-test-short", interval, "upstream c down");
+    }
+    CHECK(capture.count("upstream c down") == 1);
+    std::this_thread::sleep_for(interval * 2);
+    logger.warn_throttled("unit-test-short", interval, "upstream c down");
+    CHECK(capture.count("upstream c down") == 2);
+    CHECK(capture.contains("[W] upstream c down (3 similar messages suppressed)"));
+}
+
+TEST_CASE("error log level keeps errors and drops info and warnings") {
+    LoggerCapture capture;
+    auto& logger = Logger::instance();
+    logger.set_level(parse_log_level("error"));
+
+    logger.info("quiet info line");
+    logger.warn("quiet warn line");
+    logger.verbose("quiet verbose line");
+    logger.error("Fatal error: {}", "quiet failure");
+
+    CHECK_FALSE(capture.contains("quiet info line"));
+    CHECK_FALSE(capture.contains("quiet warn line"));
+    CHECK_FALSE(capture.contains("quiet verbose line"));
+    CHECK(capture.contains("Fatal error: quiet failure"));
+}
+
 TEST_CASE("blocking executor emits queue and completion trace events") {
     LoggerCapture capture;
     BlockingExecutor executor(1, 4);
@@ -0,0 +1,65 @@
+#include <doctest/doctest.h>
+
+#include "../src/cmd/version.hpp"
+
+#include <keen-pbr/version.hpp>
+
+#include <string>
+
+using namespace keen_pbr3;
+
+namespace {
+
+BuildInfo sample_build_info() {
+    BuildInfo info;
+    info.version = "3.1.4";
+    info.build = "2";
+    info.commit = "0123456789abcdef0123456789abcdef01234567";
+    info.branch = "main";
+    info.target_os = "keenetic";
+    info.target_version = "4.2";
+    info.architecture = "mipsel-3.4";
+    info.variant = "headless";
+    info.compiler = "gcc 12.3.0";
+    return info;
+}
+
+} // namespace
+
+TEST_CASE("version: plain output lists every build field") {
+    const std::string out = format_build_info(sample_build_info());
+
+    CHECK(out.find("version:        3.1.4\n") != std::string::npos);
+    CHECK(out.find("build:          2\n") != std::string::npos);
+    CHECK(out.find("commit:         0123456789abcdef0123456789abcdef01234567\n") != std::string::npos);
+    CHECK(out.find("branch:         main\n") != std::string::npos);
+    CHECK(out.find("target_os:      keenetic\n") != std::string::npos);
+    CHECK(out.find("target_version: 4.2\n") != std::string::npos);
+    CHECK(out.find("architecture:   mipsel-3.4\n") != std::string::npos);
+    CHECK(out.find("variant:        headless\n") != std::string::npos);
+    CHECK(out.find("compiler:       gcc 12.3.0\n") != std::string::npos);
+}
+
+TEST_CASE("version: JSON output carries the same fields") {
+    const auto json = build_info_json(sample_build_info());
+
+    CHECK(json.size() == 9);
+    CHECK(json.at("version") == "3.1.4");
+    CHECK(json.at("build") == "2");
+    CHECK(json.at("commit") == "0123456789abcdef0123456789abcdef01234567");
+    CHECK(json.at("branch") == "main");
+    CHECK(json.at("target_os") == "keenetic");
+    CHECK(json.at("target_version") == "4.2");
+    CHECK(json.at("architecture") == "mipsel-3.4");
+    CHECK(json.at("variant") == "headless");
+    CHECK(json.at("compiler") == "gcc 12.3.0");
+}
+
+TEST_CASE("version: current build info uses the compiled-in version") {
+    const auto info = current_build_info();
+
+    CHECK(info.version == KEEN_PBR3_VERSION_STRING);
+    CHECK(info.build == KEEN_PBR3_VERSION_RELEASE_STRING);
+    CHECK_FALSE(info.commit.empty());
+    CHECK_FALSE(info.compiler.empty());
+}